@@ -0,0 +1,263 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/doganarif/portfinder/internal/config"
+	"github.com/doganarif/portfinder/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// newConfigCmd builds the config command and its subcommands
+func newConfigCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "View and change portfinder settings",
+		Long: `View and change portfinder settings without hand-editing the YAML file.
+
+Settings are named by their key in the file, with dots selecting nested
+entries.
+
+Examples:
+  portfinder config get common_ports
+  portfinder config set history true
+  portfinder config set groups.myapp '[3000, 5432, 6379]'
+  portfinder config add-port 4321
+  portfinder config remove-port 8983
+  portfinder config preset use data-engineering`,
+	}
+
+	getCmd := &cobra.Command{
+		Use:   "get [key]",
+		Short: "Print a setting, or the whole config",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   runConfigGet,
+	}
+
+	setCmd := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Change a setting (the value is parsed as JSON)",
+		Args:  cobra.ExactArgs(2),
+		Run:   runConfigSet,
+	}
+
+	addPortCmd := &cobra.Command{
+		Use:   "add-port <port>...",
+		Short: "Add ports to the common ports",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   runConfigAddPort,
+	}
+
+	removePortCmd := &cobra.Command{
+		Use:   "remove-port <port>...",
+		Short: "Remove ports from the common ports",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   runConfigRemovePort,
+	}
+
+	editCmd := &cobra.Command{
+		Use:   "edit",
+		Short: "Open the config file in $VISUAL or $EDITOR",
+		Args:  cobra.NoArgs,
+		Run:   runConfigEdit,
+	}
+
+	validateCmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Check the config file, pointing at the line of any bad key",
+		Args:  cobra.NoArgs,
+		Run:   runConfigValidate,
+	}
+
+	pathCmd := &cobra.Command{
+		Use:   "path",
+		Short: "Print the config file location",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Println(config.Path())
+		},
+	}
+
+	presetCmd := &cobra.Command{
+		Use:   "preset",
+		Short: "Switch the common ports to a built-in preset",
+	}
+
+	presetListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the built-in presets",
+		Args:  cobra.NoArgs,
+		Run:   runPresetList,
+	}
+
+	presetUseCmd := &cobra.Command{
+		Use:   "use <preset>",
+		Short: "Replace the common ports with a preset's ports and categories",
+		Args:  cobra.ExactArgs(1),
+		Run:   runPresetUse,
+	}
+
+	presetCmd.AddCommand(presetListCmd, presetUseCmd)
+	configCmd.AddCommand(getCmd, setCmd, addPortCmd, removePortCmd, presetCmd, editCmd, validateCmd, pathCmd)
+	return configCmd
+}
+
+// loadConfigFile loads the config for modification, refusing to continue
+// with defaults when the existing file is broken
+func loadConfigFile() *config.Config {
+	cfg, err := config.LoadFile()
+	if err != nil {
+		ui.ErrorMsg("Error reading config: %v", err)
+		os.Exit(1)
+	}
+	return cfg
+}
+
+func saveConfig(cfg *config.Config) {
+	if err := cfg.Save(); err != nil {
+		ui.ErrorMsg("Error saving config: %v", err)
+		os.Exit(1)
+	}
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) {
+	cfg := loadConfigFile()
+
+	key := ""
+	if len(args) == 1 {
+		key = args[0]
+	}
+
+	value, err := cfg.Get(key)
+	if err != nil {
+		ui.ErrorMsg("%v", err)
+		os.Exit(1)
+	}
+	fmt.Println(value)
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) {
+	cfg := loadConfigFile()
+	if err := cfg.Set(args[0], args[1]); err != nil {
+		ui.ErrorMsg("%v", err)
+		os.Exit(1)
+	}
+	saveConfig(cfg)
+	ui.SuccessMsg("Set %s", args[0])
+}
+
+func runConfigAddPort(cmd *cobra.Command, args []string) {
+	ports := parsePorts(args)
+	cfg := loadConfigFile()
+	if err := cfg.AddPort(ports...); err != nil {
+		ui.ErrorMsg("%v", err)
+		os.Exit(1)
+	}
+	saveConfig(cfg)
+	ui.SuccessMsg("Common ports: %v", cfg.CommonPorts)
+}
+
+func runConfigRemovePort(cmd *cobra.Command, args []string) {
+	ports := parsePorts(args)
+	cfg := loadConfigFile()
+	if err := cfg.RemovePort(ports...); err != nil {
+		ui.ErrorMsg("%v", err)
+		os.Exit(1)
+	}
+	saveConfig(cfg)
+	ui.SuccessMsg("Common ports: %v", cfg.CommonPorts)
+}
+
+func runPresetList(cmd *cobra.Command, args []string) {
+	active := config.Load().ActivePreset().Name
+	for _, preset := range config.Presets() {
+		marker := " "
+		if preset.Name == active {
+			marker = "*"
+		}
+		fmt.Printf("%s %-18s %s\n", marker, preset.Name, preset.Description)
+		for _, group := range preset.Groups {
+			ports := make([]string, 0, len(group.Ports))
+			for _, port := range group.Ports {
+				ports = append(ports, fmt.Sprintf("%d (%s)", port, group.Labels[port]))
+			}
+			fmt.Printf("    %s: %s\n", group.Name, strings.Join(ports, ", "))
+		}
+	}
+}
+
+func runPresetUse(cmd *cobra.Command, args []string) {
+	cfg := loadConfigFile()
+	if err := cfg.UsePreset(args[0]); err != nil {
+		ui.ErrorMsg("%v", err)
+		os.Exit(1)
+	}
+	saveConfig(cfg)
+
+	ui.SuccessMsg("Using the %s preset (%d common ports)", cfg.Preset, len(cfg.CommonPorts))
+	if len(cfg.Groups) > 0 {
+		ui.WarnMsg("Your own port groups still take precedence in check; remove \"groups\" to see the preset's categories")
+	}
+}
+
+func runConfigEdit(cmd *cobra.Command, args []string) {
+	path := config.Path()
+
+	// Start from the defaults so there is something to edit
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		saveConfig(config.DefaultConfig())
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+
+	edit := exec.Command(editor, path)
+	edit.Stdin = os.Stdin
+	edit.Stdout = os.Stdout
+	edit.Stderr = os.Stderr
+	if err := edit.Run(); err != nil {
+		ui.ErrorMsg("Error running %s: %v", editor, err)
+		os.Exit(1)
+	}
+
+	if err := config.CheckFile(); err != nil {
+		ui.ErrorMsg("The config is invalid and will be ignored until fixed: %v", err)
+		os.Exit(1)
+	}
+	ui.SuccessMsg("Config saved")
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) {
+	if err := config.CheckFile(); err != nil {
+		ui.ErrorMsg("%v", err)
+		os.Exit(1)
+	}
+	ui.SuccessMsg("%s is valid", config.Path())
+}
+
+// parsePorts converts port arguments, exiting on the first invalid one
+func parsePorts(args []string) []int {
+	ports := make([]int, 0, len(args))
+	for _, arg := range args {
+		port, err := strconv.Atoi(arg)
+		if err != nil || port < 1 || port > 65535 {
+			ui.ErrorMsg("Invalid port number: %s", arg)
+			os.Exit(1)
+		}
+		ports = append(ports, port)
+	}
+	return ports
+}
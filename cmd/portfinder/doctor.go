@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/doganarif/portfinder/internal/config"
+	"github.com/doganarif/portfinder/internal/docker"
+	"github.com/doganarif/portfinder/internal/output"
+	"github.com/doganarif/portfinder/internal/process"
+	"github.com/doganarif/portfinder/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func runDoctor(cmd *cobra.Command, args []string) {
+	if format := outputFormat(cmd); format != output.Table {
+		results := checkResults("discovery", capabilityChecks())
+		results = append(results, checkResults("backends", backendChecks())...)
+		results = append(results, checkResults("tools", toolChecks(config.Load().Tools))...)
+		results = append(results, checkResults("docker", dockerChecks(cmd.Context()))...)
+		printResult(format, results)
+		return
+	}
+
+	ui.DisplayChecks(ui.Icon("🔎 ", "")+"Process discovery:", capabilityChecks())
+	ui.DisplayChecks(ui.Icon("🧩 ", "")+"Discovery backends:", backendChecks())
+	if checks := toolChecks(config.Load().Tools); len(checks) > 0 {
+		ui.DisplayChecks(ui.Icon("🔧 ", "")+"Tool overrides:", checks)
+	}
+	ui.DisplayChecks(ui.Icon("🐳 ", "")+"Docker endpoint:", dockerChecks(cmd.Context()))
+}
+
+// backendChecks reports each discovery backend of the platform, with how
+// to get the missing ones
+func backendChecks() []ui.Check {
+	var checks []ui.Check
+	for _, b := range process.DetectCapabilities().Probes {
+		check := ui.Check{Status: ui.CheckOK, Name: b.Name, Detail: "available"}
+		if !b.Available {
+			check.Status = ui.CheckSkip
+			check.Detail = "unavailable: " + b.Hint
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// toolChecks verifies that every overridden tool can be run
+func toolChecks(tools map[string]process.Tool) []ui.Check {
+	names := make([]string, 0, len(tools))
+	for name := range tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var checks []ui.Check
+	for _, name := range names {
+		path, args := process.ToolCommand(name)
+		check := ui.Check{Status: ui.CheckOK, Name: name, Detail: strings.Join(append([]string{path}, args...), " ")}
+		if resolved, err := exec.LookPath(path); err != nil {
+			check.Status = ui.CheckFail
+			check.Detail += " (not found or not executable)"
+		} else if resolved != path {
+			check.Detail += " (" + resolved + ")"
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// dockerChecks reports every Docker and Podman endpoint tried and which
+// ones are used, and whether nerdctl can list containerd's containers
+func dockerChecks(ctx context.Context) []ui.Check {
+	var checks []ui.Check
+	used := make(map[string]bool)
+	for _, probe := range docker.ProbeAll(ctx) {
+		check := ui.Check{
+			Name:   probe.Endpoint.Name,
+			Detail: probe.Endpoint.Host,
+		}
+		switch {
+		case probe.Err == nil && used[probe.Endpoint.Runtime]:
+			check.Status = ui.CheckSkip
+			check.Detail += fmt.Sprintf(" (answers, another %s daemon is in use)", probe.Endpoint.Runtime)
+		case probe.Err == nil:
+			used[probe.Endpoint.Runtime] = true
+			check.Status = ui.CheckOK
+			check.Detail += fmt.Sprintf(" (in use, %s)", probe.Endpoint.Runtime)
+		case errors.Is(probe.Err, os.ErrNotExist):
+			check.Status = ui.CheckSkip
+			check.Detail += " (not found)"
+		default:
+			check.Status = ui.CheckWarn
+			check.Detail += " (" + probe.Err.Error() + ")"
+		}
+		checks = append(checks, check)
+	}
+
+	if runtime.GOOS == "linux" {
+		if path, err := exec.LookPath("nerdctl"); err == nil {
+			checks = append(checks, ui.Check{Status: ui.CheckOK, Name: "nerdctl", Detail: path + " (lists containerd containers)"})
+		}
+	}
+
+	if len(used) == 0 {
+		checks = append(checks, ui.Check{
+			Status: ui.CheckFail,
+			Name:   "No Docker daemon reachable",
+			Detail: "container names, images and --container are unavailable; set DOCKER_HOST if yours listens elsewhere",
+		})
+	}
+
+	return checks
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/doganarif/portfinder/internal/config"
+	"github.com/doganarif/portfinder/internal/process"
+)
+
+// canElevate reports whether a rescan through sudo could show more: pf
+// isn't root already, and the platform has sudo
+func canElevate() bool {
+	if runtime.GOOS == "windows" {
+		return false
+	}
+	_, err := exec.LookPath("sudo")
+	return err == nil && !process.DetectCapabilities().AllUsers
+}
+
+// sudoAuth returns the command asking for the sudo password, which caches
+// the credentials for sudoList
+func sudoAuth() *exec.Cmd {
+	return exec.Command("sudo", "-v")
+}
+
+// authenticate asks for the sudo password on the terminal
+func authenticate() error {
+	cmd := sudoAuth()
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("sudo failed: %w", err)
+	}
+	return nil
+}
+
+// sudoList lists every listener through a copy of pf run with sudo. It
+// never prompts, so the credentials must have been cached by sudoAuth.
+// The copy reads the user's config rather than root's, so their tool
+// overrides apply there too.
+func sudoList(ctx context.Context) ([]*process.Process, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"-n", exe, "list", "--output", "json", "--timeout", lookupTimeout.String()}
+	if path := config.Path(); path != "" {
+		args = append(args, "--config", path)
+	}
+	cmd := exec.CommandContext(ctx, "sudo", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if detail := strings.TrimSpace(stderr.String()); detail != "" {
+			return nil, fmt.Errorf("sudo: %s", detail)
+		}
+		return nil, fmt.Errorf("sudo failed: %w", err)
+	}
+
+	var processes []*process.Process
+	if err := json.Unmarshal(output, &processes); err != nil {
+		return nil, fmt.Errorf("unexpected output from sudo %s list: %w", exe, err)
+	}
+	return processes, nil
+}
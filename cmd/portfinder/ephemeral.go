@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/doganarif/portfinder/internal/output"
+	"github.com/doganarif/portfinder/internal/process"
+	"github.com/doganarif/portfinder/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// newEphemeralCmd builds the ephemeral command
+func newEphemeralCmd() *cobra.Command {
+	ephemeralCmd := &cobra.Command{
+		Use:   "ephemeral",
+		Short: "Show how much of the ephemeral port range is in use",
+		Long: `Show the range the system picks the local port of outgoing connections
+from, how many of its ports are in use, how many connections wait out
+TIME_WAIT and the processes holding the most of them. Once the range is
+full, connecting fails with "cannot assign requested address", as happens
+under load tests opening a connection per request.
+
+Examples:
+  portfinder ephemeral
+  portfinder ephemeral --output json`,
+		Args: cobra.NoArgs,
+		Run:  runEphemeral,
+	}
+	addOutputFlag(ephemeralCmd)
+	return ephemeralCmd
+}
+
+func runEphemeral(cmd *cobra.Command, args []string) {
+	format := outputFormat(cmd)
+	ctx := cmd.Context()
+	if lookupTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, lookupTimeout)
+		defer cancel()
+	}
+
+	usage, err := process.EphemeralPorts(ctx)
+	if err != nil {
+		ui.ErrorMsg("Error reading the ephemeral ports: %v", err)
+		os.Exit(1)
+	}
+	if format != output.Table {
+		printResult(format, usage)
+		return
+	}
+	timeRender(func() { ui.DisplayEphemeral(usage) })
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/doganarif/portfinder/internal/firewall"
+	"github.com/doganarif/portfinder/internal/process"
+	"github.com/doganarif/portfinder/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// firewalledFinder fills in the firewall status of the ports it finds
+type firewalledFinder struct {
+	process.Finder
+	fw firewall.Firewall
+	// warned keeps a failing firewall from being reported on every refresh
+	warned bool
+}
+
+// firewalled wraps a finder to look up the firewall status of its ports,
+// with one query of the firewall per lookup
+func firewalled(finder process.Finder, fw firewall.Firewall) process.Finder {
+	return &firewalledFinder{Finder: finder, fw: fw}
+}
+
+func (f *firewalledFinder) FindByPort(ctx context.Context, port int) (*process.Process, error) {
+	proc, err := f.Finder.FindByPort(ctx, port)
+	if proc != nil {
+		f.annotate(ctx, []*process.Process{proc})
+	}
+	return proc, err
+}
+
+func (f *firewalledFinder) FindByPorts(ctx context.Context, ports []int) (map[int]*process.Process, error) {
+	found, err := f.Finder.FindByPorts(ctx, ports)
+	processes := make([]*process.Process, 0, len(found))
+	for _, proc := range found {
+		processes = append(processes, proc)
+	}
+	f.annotate(ctx, processes)
+	return found, err
+}
+
+func (f *firewalledFinder) ListAll(ctx context.Context) ([]*process.Process, error) {
+	processes, err := f.Finder.ListAll(ctx)
+	f.annotate(ctx, processes)
+	return processes, err
+}
+
+func (f *firewalledFinder) annotate(ctx context.Context, processes []*process.Process) {
+	if len(processes) == 0 {
+		return
+	}
+	ports := make([]int, len(processes))
+	for i, p := range processes {
+		ports[i] = p.Port
+	}
+	statuses, err := f.fw.Status(ctx, ports)
+	if err != nil {
+		if !f.warned {
+			ui.WarnMsg("Could not read the %s rules: %v", f.fw.Name(), err)
+			f.warned = true
+		}
+		return
+	}
+	for _, p := range processes {
+		p.Firewall = string(statuses[p.Port])
+	}
+}
+
+// newFirewallCmd builds the allow or deny command
+func newFirewallCmd(allow bool) *cobra.Command {
+	use, short, verb := "deny", "Close a port in the host firewall", "Deny"
+	if allow {
+		use, short, verb = "allow", "Open a port in the host firewall", "Allow"
+	}
+	firewallCmd := &cobra.Command{
+		Use:   use + " <port>",
+		Short: short,
+		Long: fmt.Sprintf(`%s inbound TCP connections to a port through the host firewall: ufw or
+firewalld on Linux, pf on macOS and the BSDs, or Windows Firewall. The
+commands are shown and run after confirmation, through sudo when needed.
+
+Examples:
+  portfinder %s 3000
+  portfinder %s 3000 --yes`, verb, use, use),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			runFirewallChange(cmd, args, allow)
+		},
+	}
+	firewallCmd.Flags().BoolP("yes", "y", false, "Skip the confirmation prompt")
+	firewallCmd.Flags().Bool("dry-run", false, "Show the commands without running them")
+	return firewallCmd
+}
+
+func runFirewallChange(cmd *cobra.Command, args []string, allow bool) {
+	port, err := strconv.Atoi(args[0])
+	if err != nil || port < 1 || port > 65535 {
+		ui.ErrorMsg("Invalid port number: %s", args[0])
+		os.Exit(1)
+	}
+	ctx := cmd.Context()
+	fw := firewall.Detect(ctx)
+	if fw == nil {
+		ui.ErrorMsg("No supported firewall found (ufw, firewalld, pf or Windows Firewall)")
+		os.Exit(1)
+	}
+
+	commands, want, action := fw.Deny(port), firewall.Blocked, "Deny"
+	if allow {
+		commands, want, action = fw.Allow(port), firewall.Allowed, "Allow"
+	}
+	ui.InfoMsg("%s would run:", fw.Name())
+	for _, c := range commands {
+		fmt.Println("  " + c.String())
+	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		ui.InfoMsg("Dry run: the firewall was not changed")
+		return
+	}
+	if skip, _ := cmd.Flags().GetBool("yes"); !skip {
+		if !ui.SimpleConfirm(fmt.Sprintf("%s inbound connections to port %d?", action, port)) {
+			ui.InfoMsg("Aborted")
+			return
+		}
+	}
+
+	if err := firewall.Run(ctx, commands); err != nil {
+		ui.ErrorMsg("%v", err)
+		os.Exit(1)
+	}
+	statuses, err := fw.Status(ctx, []int{port})
+	switch {
+	case err != nil:
+		ui.SuccessMsg("Updated %s for port %d", fw.Name(), port)
+	case statuses[port] == firewall.Inactive:
+		ui.SuccessMsg("Updated %s for port %d; it is turned off, so nothing is filtered yet", fw.Name(), port)
+	case statuses[port] != want:
+		ui.WarnMsg("Port %d is still %s by %s: another rule decides it", port, statuses[port], fw.Name())
+	default:
+		ui.SuccessMsg("Port %d is now %s by %s", port, want, fw.Name())
+	}
+}
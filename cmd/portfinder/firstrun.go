@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/doganarif/portfinder/internal/config"
+	"github.com/doganarif/portfinder/internal/docker"
+	"github.com/doganarif/portfinder/internal/process"
+	"github.com/doganarif/portfinder/internal/ui"
+)
+
+// firstRun creates the default config when none exists yet and explains
+// what pf can see on this machine, since that differs between systems
+func firstRun() {
+	path := config.Path()
+	if path == "" {
+		return
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		return
+	}
+
+	if err := config.DefaultConfig().Save(); err != nil {
+		ui.WarnMsg("Could not create the default config: %v", err)
+		return
+	}
+
+	// Keep piped and JSON output clean; the summary is also in pf doctor
+	if !isTerminal(os.Stdout) {
+		return
+	}
+
+	fmt.Println()
+	ui.InfoMsg("First run: created the default config at %s", path)
+	ui.DisplayChecks(ui.Icon("🔎 ", "")+"What portfinder can see here:", capabilityChecks())
+	fmt.Println("\nRun 'portfinder doctor' to see this again.")
+}
+
+// capabilityChecks summarizes the discovery backends, the visibility of
+// other users' processes and the Docker integration
+func capabilityChecks() []ui.Check {
+	caps := process.DetectCapabilities()
+
+	backends := ui.Check{Status: ui.CheckOK, Name: "Socket discovery", Detail: strings.Join(caps.Backends, ", ")}
+	if len(caps.Backends) == 0 {
+		backends.Status = ui.CheckFail
+		backends.Detail = "no supported tool found on PATH"
+	} else if caps.SocketOnly {
+		backends.Status = ui.CheckWarn
+		backends.Detail += " (ports only: processes can't be identified here)"
+	}
+
+	users := ui.Check{Status: ui.CheckOK, Name: "Other users' processes", Detail: "visible"}
+	if !caps.AllUsers {
+		users.Status = ui.CheckWarn
+		users.Detail = "hidden; " + caps.Elevate + " to see every listener"
+	}
+
+	containers := ui.Check{Status: ui.CheckOK, Name: "Docker integration"}
+	if endpoint, _ := docker.Detect(); endpoint != nil {
+		containers.Detail = endpoint.Name
+	} else {
+		containers.Status = ui.CheckSkip
+		containers.Detail = "no daemon reachable"
+	}
+
+	return []ui.Check{backends, users, containers}
+}
+
+// hiddenListener reports whether a port pf found free can't be bound
+// because it is in use, which means its owner is hidden from discovery
+func hiddenListener(port int) bool {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return errors.Is(err, syscall.EADDRINUSE)
+	}
+	listener.Close()
+	return false
+}
+
+// reportMigration tells about the JSON config of an older version being
+// moved to YAML, on terminals only to keep piped output clean
+func reportMigration(migrated bool, err error) {
+	if !isTerminal(os.Stdout) {
+		return
+	}
+	switch {
+	case err != nil:
+		ui.WarnMsg("Could not move the JSON config to YAML: %v", err)
+	case migrated:
+		ui.InfoMsg("Moved the config to %s; the JSON original is kept alongside as config.json.bak", config.Path())
+	}
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
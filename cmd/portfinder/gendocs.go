@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/doganarif/portfinder/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// newGenDocsCmd builds the hidden gen-docs command, run when packaging
+func newGenDocsCmd() *cobra.Command {
+	genDocsCmd := &cobra.Command{
+		Use:   "gen-docs",
+		Short: "Generate man pages and markdown docs for every command",
+		Long: `Write a man page and a markdown page for every command, to
+<dir>/man1 and <dir>/markdown.
+
+Examples:
+  portfinder gen-docs --dir docs
+  portfinder gen-docs --dir docs --format man`,
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		// The docs don't depend on the config, so don't read or migrate it
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+		Run:              runGenDocs,
+	}
+	genDocsCmd.Flags().String("dir", "docs", "Directory to write the docs to")
+	genDocsCmd.Flags().String("format", "all", "Docs to write: man, markdown or all")
+	return genDocsCmd
+}
+
+func runGenDocs(cmd *cobra.Command, args []string) {
+	dir, _ := cmd.Flags().GetString("dir")
+	format, _ := cmd.Flags().GetString("format")
+
+	var generators []func(*cobra.Command, string) error
+	switch format {
+	case "man":
+		generators = append(generators, genManTree)
+	case "markdown":
+		generators = append(generators, genMarkdownTree)
+	case "all":
+		generators = append(generators, genManTree, genMarkdownTree)
+	default:
+		ui.ErrorMsg("Unknown format %q, expected man, markdown or all", format)
+		os.Exit(1)
+	}
+
+	for _, generate := range generators {
+		if err := generate(cmd.Root(), dir); err != nil {
+			ui.ErrorMsg("Error generating docs: %v", err)
+			os.Exit(1)
+		}
+	}
+	ui.SuccessMsg("Wrote the docs to %s", dir)
+}
+
+// documented returns the commands of the tree worth a page, leaving out
+// hidden ones and help
+func documented(cmd *cobra.Command) []*cobra.Command {
+	if !cmd.IsAvailableCommand() && cmd.HasParent() {
+		return nil
+	}
+	commands := []*cobra.Command{cmd}
+	for _, sub := range cmd.Commands() {
+		commands = append(commands, documented(sub)...)
+	}
+	return commands
+}
+
+// genMarkdownTree writes a markdown page per command to dir/markdown, named
+// like portfinder_config_edit.md and linked to its parent and children
+func genMarkdownTree(root *cobra.Command, dir string) error {
+	dir = filepath.Join(dir, "markdown")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, cmd := range documented(root) {
+		page := markdownPage(cmd)
+		if err := os.WriteFile(filepath.Join(dir, pageName(cmd, "_")+".md"), page, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func markdownPage(cmd *cobra.Command) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "## %s\n\n%s\n\n", cmd.CommandPath(), cmd.Short)
+	fmt.Fprintf(&b, "### Synopsis\n\n")
+	if cmd.Long != "" {
+		b.WriteString(markdownText(cmd.Long) + "\n\n")
+	}
+	if cmd.Runnable() {
+		fmt.Fprintf(&b, "```\n%s\n```\n\n", cmd.UseLine())
+	}
+	if cmd.Example != "" {
+		fmt.Fprintf(&b, "### Examples\n\n```\n%s\n```\n\n", cmd.Example)
+	}
+	if flags := cmd.NonInheritedFlags(); flags.HasAvailableFlags() {
+		fmt.Fprintf(&b, "### Options\n\n```\n%s```\n\n", flags.FlagUsages())
+	}
+	if flags := cmd.InheritedFlags(); flags.HasAvailableFlags() {
+		fmt.Fprintf(&b, "### Options inherited from parent commands\n\n```\n%s```\n\n", flags.FlagUsages())
+	}
+
+	b.WriteString("### SEE ALSO\n\n")
+	if cmd.HasParent() {
+		parent := cmd.Parent()
+		fmt.Fprintf(&b, "* [%s](%s.md)\t - %s\n", parent.CommandPath(), pageName(parent, "_"), parent.Short)
+	}
+	for _, sub := range cmd.Commands() {
+		if sub.IsAvailableCommand() {
+			fmt.Fprintf(&b, "* [%s](%s.md)\t - %s\n", sub.CommandPath(), pageName(sub, "_"), sub.Short)
+		}
+	}
+	return b.Bytes()
+}
+
+// markdownText fences the indented blocks of help text, such as its
+// examples, so markdown keeps their layout
+func markdownText(text string) string {
+	var out []string
+	fenced := false
+	for _, line := range strings.Split(strings.TrimRight(text, "\n "), "\n") {
+		indented := strings.HasPrefix(line, " ") && strings.TrimSpace(line) != ""
+		switch {
+		case indented && !fenced:
+			out = append(out, "```")
+			fenced = true
+		case !indented && fenced && strings.TrimSpace(line) != "":
+			out = append(out, "```")
+			fenced = false
+		}
+		out = append(out, strings.TrimRight(line, " "))
+	}
+	if fenced {
+		out = append(out, "```")
+	}
+	return strings.Join(out, "\n")
+}
+
+// genManTree writes a man page per command to dir/man1, named like
+// portfinder-config-edit.1
+func genManTree(root *cobra.Command, dir string) error {
+	dir = filepath.Join(dir, "man1")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, cmd := range documented(root) {
+		page := manPage(cmd)
+		if err := os.WriteFile(filepath.Join(dir, pageName(cmd, "-")+".1"), page, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func manPage(cmd *cobra.Command) []byte {
+	name := pageName(cmd, "-")
+	var b bytes.Buffer
+	fmt.Fprintf(&b, ".TH %q \"1\" \"\" \"portfinder %s\" \"User Commands\"\n", strings.ToUpper(name), version)
+	fmt.Fprintf(&b, ".SH NAME\n%s \\- %s\n", name, roffEscape(cmd.Short))
+	fmt.Fprintf(&b, ".SH SYNOPSIS\n.B %s\n", roffEscape(cmd.UseLine()))
+
+	b.WriteString(".SH DESCRIPTION\n")
+	if cmd.Long != "" {
+		b.WriteString(manText(cmd.Long))
+	} else {
+		b.WriteString(roffEscape(cmd.Short) + "\n")
+	}
+	if cmd.Example != "" {
+		fmt.Fprintf(&b, ".SH EXAMPLES\n.nf\n%s\n.fi\n", roffEscape(cmd.Example))
+	}
+	if flags := cmd.NonInheritedFlags(); flags.HasAvailableFlags() {
+		b.WriteString(".SH OPTIONS\n" + manFlags(flags))
+	}
+	if flags := cmd.InheritedFlags(); flags.HasAvailableFlags() {
+		b.WriteString(".SH OPTIONS INHERITED FROM PARENT COMMANDS\n" + manFlags(flags))
+	}
+
+	var related []string
+	if cmd.HasParent() {
+		related = append(related, fmt.Sprintf("\\fB%s\\fP(1)", pageName(cmd.Parent(), "-")))
+	}
+	for _, sub := range cmd.Commands() {
+		if sub.IsAvailableCommand() {
+			related = append(related, fmt.Sprintf("\\fB%s\\fP(1)", pageName(sub, "-")))
+		}
+	}
+	if len(related) > 0 {
+		fmt.Fprintf(&b, ".SH SEE ALSO\n%s\n", strings.Join(related, ", "))
+	}
+	return b.Bytes()
+}
+
+// manText turns help text into paragraphs, keeping the layout of indented
+// blocks such as examples
+func manText(text string) string {
+	var b strings.Builder
+	indented := false
+	for _, line := range strings.Split(strings.TrimRight(text, "\n "), "\n") {
+		blank := strings.TrimSpace(line) == ""
+		switch {
+		case blank && !indented:
+			b.WriteString(".PP\n")
+			continue
+		case strings.HasPrefix(line, " ") && !blank && !indented:
+			b.WriteString(".PP\n.RS\n.nf\n")
+			indented = true
+		case !strings.HasPrefix(line, " ") && !blank && indented:
+			b.WriteString(".fi\n.RE\n.PP\n")
+			indented = false
+		}
+		b.WriteString(roffEscape(strings.TrimRight(line, " ")) + "\n")
+	}
+	if indented {
+		b.WriteString(".fi\n.RE\n")
+	}
+	return b.String()
+}
+
+// manFlags lists flags as tagged paragraphs
+func manFlags(flags *pflag.FlagSet) string {
+	var b strings.Builder
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Hidden || f.Deprecated != "" {
+			return
+		}
+		b.WriteString(".TP\n")
+		if f.Shorthand != "" && f.ShorthandDeprecated == "" {
+			fmt.Fprintf(&b, "\\fB\\-%s\\fP, ", f.Shorthand)
+		}
+		fmt.Fprintf(&b, "\\fB\\-\\-%s\\fP", roffEscape(f.Name))
+		varname, usage := pflag.UnquoteUsage(f)
+		if varname != "" {
+			fmt.Fprintf(&b, " \\fI%s\\fP", varname)
+		}
+		b.WriteString("\n" + roffEscape(usage))
+		if f.DefValue != "" && f.DefValue != "false" && f.DefValue != "[]" && f.DefValue != "0" {
+			fmt.Fprintf(&b, " (default %s)", roffEscape(f.DefValue))
+		}
+		b.WriteString("\n")
+	})
+	return b.String()
+}
+
+// roffEscape escapes backslashes and the dashes of options, and keeps lines
+// starting with a dot or quote from being read as requests
+func roffEscape(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\e`)
+	text = strings.ReplaceAll(text, "-", `\-`)
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// pageName names a command's page after its path, e.g. portfinder-config-edit
+func pageName(cmd *cobra.Command, sep string) string {
+	return strings.ReplaceAll(cmd.CommandPath(), " ", sep)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/doganarif/portfinder/internal/config"
+	"github.com/doganarif/portfinder/internal/history"
+	"github.com/doganarif/portfinder/internal/output"
+	"github.com/doganarif/portfinder/internal/process"
+	"github.com/doganarif/portfinder/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func runHistory(cmd *cobra.Command, args []string) {
+	format := outputFormat(cmd)
+	port := 0
+	if len(args) == 1 {
+		var err error
+		port, err = strconv.Atoi(args[0])
+		if err != nil {
+			ui.ErrorMsg("Invalid port number: %s", args[0])
+			os.Exit(1)
+		}
+	}
+
+	if !config.Load().History {
+		ui.WarnMsg("History recording is disabled; enable it with 'portfinder config set history true'")
+	}
+
+	events, err := history.Load(port)
+	if err != nil {
+		ui.ErrorMsg("Error reading history: %v", err)
+		os.Exit(1)
+	}
+
+	if since, _ := cmd.Flags().GetDuration("since"); since > 0 {
+		cutoff := time.Now().Add(-since)
+		recent := events[:0]
+		for _, e := range events {
+			if e.Time.After(cutoff) {
+				recent = append(recent, e)
+			}
+		}
+		events = recent
+	}
+
+	if format != output.Table {
+		printResult(format, events)
+		return
+	}
+	ui.DisplayHistory(events)
+}
+
+// recordSeen stores observed listeners when history is enabled
+func recordSeen(cfg *config.Config, processes ...*process.Process) {
+	if !cfg.History {
+		return
+	}
+	if err := history.RecordSeen(processes); err != nil {
+		ui.WarnMsg("Failed to record history: %v", err)
+	}
+}
+
+// crashLoops returns the restart counts of crash looping ports when
+// history is enabled
+func crashLoops(cfg *config.Config) map[int]int {
+	if !cfg.History {
+		return nil
+	}
+	loops, err := history.CrashLoops()
+	if err != nil {
+		ui.WarnMsg("Failed to read history: %v", err)
+	}
+	return loops
+}
+
+// recordKill stores a kill when history is enabled
+func recordKill(cfg *config.Config, proc *process.Process, strategy process.KillStrategy) {
+	if !cfg.History {
+		return
+	}
+	if err := history.RecordKill(proc, strategy.Name()); err != nil {
+		ui.WarnMsg("Failed to record history: %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/doganarif/portfinder/internal/advisor"
+	"github.com/doganarif/portfinder/internal/output"
+	"github.com/doganarif/portfinder/internal/process"
+	"github.com/doganarif/portfinder/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// inspection is the JSON and YAML output of inspect
+type inspection struct {
+	*process.Process
+	Sockets []process.Socket   `json:"sockets,omitempty"`
+	Files   []process.OpenFile `json:"files,omitempty"`
+}
+
+func runInspect(cmd *cobra.Command, args []string) {
+	port, err := strconv.Atoi(args[0])
+	if err != nil {
+		ui.ErrorMsg("Invalid port number: %s", args[0])
+		os.Exit(1)
+	}
+	format := outputFormat(cmd)
+	withFiles, _ := cmd.Flags().GetBool("files")
+
+	proc, err := newFinder().FindByPort(cmd.Context(), port)
+	if err != nil {
+		ui.ErrorMsg("Error checking port: %v", err)
+		os.Exit(1)
+	}
+	if proc == nil {
+		ui.ErrorMsg("Port %d is free", port)
+		os.Exit(1)
+	}
+	proc.LoadLineage()
+
+	result := inspection{Process: proc}
+	// Either list may be unavailable on its own, e.g. open files on Windows
+	var socketsErr, filesErr error
+	if withFiles {
+		result.Sockets, socketsErr = proc.Sockets()
+		var files []process.OpenFile
+		files, filesErr = proc.OpenFiles()
+		result.Files = process.NotableFiles(files)
+	}
+
+	if format != output.Table {
+		printResult(format, result)
+		return
+	}
+
+	ui.ShowProcessDetail(proc, advisor.Advice{}, nil)
+	if !withFiles {
+		return
+	}
+	if socketsErr != nil {
+		ui.WarnMsg("Cannot list sockets: %v", socketsErr)
+	} else {
+		ui.DisplaySockets(proc, result.Sockets)
+	}
+	if filesErr != nil {
+		ui.WarnMsg("Cannot list open files: %v", filesErr)
+	} else {
+		ui.DisplayOpenFiles(result.Files)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/doganarif/portfinder/internal/config"
+	"github.com/doganarif/portfinder/internal/process"
+	"github.com/doganarif/portfinder/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// runKillProject stops every listener of a project, given by path or name
+// and defaulting to the repository of the working directory
+func runKillProject(cmd *cobra.Command, args []string) {
+	var project string
+	if len(args) == 1 {
+		project = args[0]
+	} else {
+		cwd, err := os.Getwd()
+		if err != nil {
+			ui.ErrorMsg("Error reading working directory: %v", err)
+			os.Exit(1)
+		}
+		project = repositoryRoot(cwd)
+	}
+
+	cfg := config.Load()
+	processes, err := newFinderWithConfig(cfg).ListAll(cmd.Context())
+	if err != nil {
+		ui.ErrorMsg("Error listing ports: %v", err)
+		os.Exit(1)
+	}
+
+	matches := process.Filter(processes, func(p *process.Process) bool {
+		if p.ComposeProject != "" {
+			return p.ComposeProject == filepath.Base(project)
+		}
+		return p.InProject(project)
+	})
+	if len(matches) == 0 {
+		ui.InfoMsg("No listening processes belong to %s", project)
+		return
+	}
+
+	killTargets(cmd, cfg, process.KillOrder(matches))
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/doganarif/portfinder/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// setupLogging routes the log of what pf does behind the scenes: the
+// commands it runs, how long they took and output it couldn't parse with
+// --verbose, and also what the commands printed with --debug. Without
+// either, nothing is logged.
+func setupLogging(cmd *cobra.Command) {
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	debug, _ := cmd.Flags().GetBool("debug")
+	logFile, _ := cmd.Flags().GetString("log-file")
+
+	var w io.Writer = os.Stderr
+	level := slog.LevelInfo
+	switch {
+	case debug:
+		level = slog.LevelDebug
+	case !verbose:
+		w = io.Discard
+	}
+
+	if logFile != "" && w != io.Discard {
+		f, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			ui.ErrorMsg("Cannot open the log file: %v", err)
+			os.Exit(1)
+		}
+		w = f
+	}
+
+	slog.SetDefault(slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{
+		Level: level,
+		// Durations are logged where they matter, timestamps only add noise
+		// on a terminal
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 && logFile == "" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})))
+}
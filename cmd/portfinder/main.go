@@ -1,12 +1,23 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/doganarif/portfinder/internal/advisor"
 	"github.com/doganarif/portfinder/internal/config"
+	"github.com/doganarif/portfinder/internal/firewall"
+	"github.com/doganarif/portfinder/internal/inventory"
+	"github.com/doganarif/portfinder/internal/output"
 	"github.com/doganarif/portfinder/internal/process"
+	"github.com/doganarif/portfinder/internal/server"
 	"github.com/doganarif/portfinder/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -17,54 +28,404 @@ var (
 	date    = "unknown"
 )
 
+// wslInterop is set by --wsl to also find Windows processes under WSL
+var wslInterop bool
+
+// noCache is set by --no-cache to disable the finder's lookup cache
+var noCache bool
+
+// lookupTimeout bounds each lookup, from --timeout or the config
+var lookupTimeout time.Duration
+
+// errInterrupted is why lookups stop on Ctrl+C
+var errInterrupted = errors.New("interrupted")
+
+// interruptGrace is how long pf waits after Ctrl+C for the command to
+// stop on its own before exiting
+const interruptGrace = 3 * time.Second
+
+// listProbeTimeout bounds each protocol tried on a port by list --probe,
+// which probes every port at once
+const listProbeTimeout = 500 * time.Millisecond
+
+// usageInterval is how long pf <port> --usage samples the CPU usage for
+const usageInterval = 500 * time.Millisecond
+
 func main() {
+	if err := NewRootCmd().ExecuteContext(interruptContext()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// interruptContext is canceled by Ctrl+C or SIGTERM, killing the tools
+// lookups are running. A command that doesn't stop on its own is given
+// interruptGrace, and a second Ctrl+C exits right away.
+func interruptContext() context.Context {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cancel(errInterrupted)
+		signal.Stop(signals)
+		time.Sleep(interruptGrace)
+		os.Exit(130)
+	}()
+	return ctx
+}
+
+// NewRootCmd builds the pf command tree, for main and for generating the
+// docs
+func NewRootCmd() *cobra.Command {
 	var rootCmd = &cobra.Command{
-		Use:   "portfinder [port]",
+		Use:   "portfinder [port|range]",
 		Short: "Find and manage processes using network ports",
 		Long: `portfinder helps you identify what's using your ports and take action.
         
 Examples:
   portfinder 3000           # Check what's using port 3000
+  portfinder 8000-8100      # List what's listening on a port range
+  portfinder 3000 --all-states  # Also show sockets in TIME_WAIT, CLOSE_WAIT and other states
   portfinder check          # Check common development ports
+  portfinder check myapp    # Check a port group defined in the config
   portfinder list           # List all active ports
-  portfinder kill 3000      # Kill process using port 3000`,
-		Args: cobra.MaximumNArgs(1),
-		Run:  runPortCheck,
+  portfinder kill 3000      # Kill process using port 3000
+  portfinder kill 3000 --container  # Stop the container publishing port 3000
+  portfinder restart 3000 -- npm run dev  # Replace a stale dev server`,
+		Args:              cobra.MaximumNArgs(1),
+		Run:               runPortCheck,
+		PersistentPreRun:  setup,
+		PersistentPostRun: reportStats,
 	}
+	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Log the commands pf runs and output it can't parse, and report how long each phase took")
+	rootCmd.PersistentFlags().Bool("debug", false, "Like --verbose, also logging what each command printed")
+	rootCmd.PersistentFlags().String("log-file", "", "Append the --verbose or --debug log to a file instead of stderr, e.g. while using the list view")
+	rootCmd.PersistentFlags().Bool("plain", false, "Print simple tables and text instead of interactive views")
+	rootCmd.PersistentFlags().Bool("ascii", false, "Plain ASCII output without colors, emoji or TUIs")
+	rootCmd.PersistentFlags().Bool("accessible", false, "Linear, labeled text for screen readers, one line per port")
+	rootCmd.PersistentFlags().Bool("wsl", false, "Under WSL, also find Windows processes holding ports")
+	rootCmd.PersistentFlags().Bool("no-cache", false, "Scan the system on every lookup instead of reusing recent results")
+	rootCmd.PersistentFlags().String("config", "", "Read the settings from this file instead of ~/.config/portfinder/config.yaml")
+	rootCmd.PersistentFlags().Duration("timeout", config.DefaultTimeout, "Give up on a lookup after this long, e.g. when lsof hangs on a stale NFS mount (0 waits forever)")
+	addOutputFlag(rootCmd)
+	rootCmd.Flags().Bool("probe", false, "Send an HTTP(S) request to the port to identify the server and framework")
+	rootCmd.Flags().Bool("usage", false, "Sample the CPU and memory use of the process before printing its details (the interactive view samples it meanwhile)")
+	rootCmd.Flags().Bool("all-states", false, "Also show the port's sockets in other states than LISTEN, such as TIME_WAIT, which can keep it from being bound")
 
 	var checkCmd = &cobra.Command{
-		Use:   "check",
-		Short: "Check common development ports",
+		Use:   "check [group]",
+		Short: "Check common development ports, or a port group from the config",
+		Args:  cobra.MaximumNArgs(1),
 		Run:   runCheckCommon,
 	}
+	checkCmd.Flags().String("profile", "", "Check the ports of a profile from the config (default $"+config.ProfileEnv+")")
+	addOutputFlag(checkCmd)
 
 	var listCmd = &cobra.Command{
 		Use:   "list",
 		Short: "List all ports in use",
 		Run:   runListAll,
 	}
+	listCmd.Flags().Bool("public-only", false, "Only show services listening beyond localhost")
+	listCmd.Flags().Bool("tree", false, "Start in tree mode, grouping listeners by the app that spawned them")
+	listCmd.Flags().String("export", "", "Write the list to a file, as JSON, YAML, CSV or Markdown by its extension (x in the list rewrites it)")
+	listCmd.Flags().Bool("merge", false, "Show each process once with all its ports, e.g. node 4242 on 3000, 9229, 9464")
+	listCmd.Flags().String("range", "", "Only show ports in a range, e.g. 3000-4000")
+	listCmd.Flags().String("user", "", "Only show processes owned by this user (name or uid)")
+	listCmd.Flags().IntSlice("port", nil, "Only show these ports, e.g. 3000,5432")
+	listCmd.Flags().String("name", "", "Only show processes whose name contains this text")
+	listCmd.Flags().String("project", "", "Only show processes of this project (directory or name)")
+	listCmd.Flags().Bool("docker", false, "Only show Docker containers")
+	listCmd.Flags().Duration("min-uptime", 0, "Only show processes running for at least this long, e.g. 1h")
+	listCmd.Flags().Bool("dry-run", false, "Show which signals kills in the list would send without killing anything")
+	listCmd.Flags().String("remote", "", "List the ports of another host running portfinder serve (host:port)")
+	listCmd.Flags().String("token", "", "API token of the --remote host (default: the api_token setting)")
+	listCmd.Flags().StringSlice("columns", nil, "Columns to show, in order (port, address, name, pid, user, conns, cpu, mem, project, uptime, type, label, command, firewall)")
+	listCmd.Flags().Bool("sudo", false, "Rescan with sudo to see the processes of every user")
+	listCmd.Flags().Bool("probe", false, "Probe each port to label it with the server answering, e.g. Postgres 16.1 or Next.js")
+	listCmd.Flags().Bool("firewall", false, "Show whether the host firewall lets connections to each port in")
+	addOutputFlag(listCmd)
 
 	var killCmd = &cobra.Command{
 		Use:   "kill [port]",
 		Short: "Kill process using specified port",
-		Args:  cobra.ExactArgs(1),
-		Run:   runKillProcess,
+		Long: `Kill the process using the specified port, or every listener matching
+--name or --project.
+
+Examples:
+  portfinder kill 3000
+  portfinder kill --name node
+  portfinder kill --project ~/code/myapp`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			name, _ := cmd.Flags().GetString("name")
+			project, _ := cmd.Flags().GetString("project")
+			if name != "" || project != "" {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		Run: runKillProcess,
+	}
+	killCmd.Flags().Bool("container", false, "Stop the Docker container publishing the port instead of the process")
+	killCmd.Flags().String("strategy", "auto", "Kill strategy (auto, signal, container, service, tree, windows)")
+	killCmd.Flags().String("name", "", "Kill every listener with this process name")
+	killCmd.Flags().String("project", "", "Kill every listener belonging to this project directory")
+	killCmd.Flags().BoolP("yes", "y", false, "Skip the confirmation prompt")
+	killCmd.Flags().Bool("allow-protected", false, "Kill processes protected by protected_ports or protected_processes")
+	killCmd.Flags().Bool("dry-run", false, "Show which processes would get which signals without killing anything")
+	killCmd.Flags().String("signal", "TERM", "Signal sent before escalating (e.g. INT, HUP, 15)")
+	killCmd.Flags().Duration("timeout", 2*time.Second, "Grace period before escalating to SIGKILL")
+	killCmd.Flags().Bool("force", false, "Skip the grace period and SIGKILL immediately")
+	killCmd.Flags().Duration("verify", process.RespawnWindow, "How long to watch the port for a respawned listener after the kill (0 to skip)")
+	killCmd.Flags().Bool("parent", false, "Kill the root process that spawned the listener, and all its children")
+
+	var killProjectCmd = &cobra.Command{
+		Use:   "kill-project [path|name]",
+		Short: "Kill every listener of a project",
+		Long: `Kill every listener belonging to a project, given by directory or name,
+the repository of the working directory by default. Children are killed
+before the processes that spawned them and databases last, so nothing is
+respawned halfway.
+
+Examples:
+  portfinder kill-project
+  portfinder kill-project ~/code/myapp --dry-run
+  portfinder kill-project myapp --yes`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  runKillProject,
+	}
+	killProjectCmd.Flags().BoolP("yes", "y", false, "Skip the confirmation prompt")
+	killProjectCmd.Flags().Bool("allow-protected", false, "Kill processes protected by protected_ports or protected_processes")
+	killProjectCmd.Flags().Bool("dry-run", false, "Show which processes would get which signals without killing anything")
+	killProjectCmd.Flags().String("strategy", "auto", "Kill strategy (auto, signal, container, service, tree, windows)")
+	killProjectCmd.Flags().String("signal", "TERM", "Signal sent before escalating (e.g. INT, HUP, 15)")
+	killProjectCmd.Flags().Duration("timeout", 2*time.Second, "Grace period before escalating to SIGKILL")
+	killProjectCmd.Flags().Bool("force", false, "Skip the grace period and SIGKILL immediately")
+
+	var restartCmd = &cobra.Command{
+		Use:   "restart <port> [-- command...]",
+		Short: "Kill the process using a port and start it again",
+		Long: `Kill the process using the port and relaunch it in its original working
+directory. Without a command after --, the occupant's own command line is
+rerun; if the port is already free, the last command killed from it is used
+(requires history).
+
+Examples:
+  portfinder restart 3000
+  portfinder restart 3000 -- npm run dev`,
+		Args: restartArgs,
+		Run:  runRestart,
+	}
+	restartCmd.Flags().String("strategy", "auto", "Kill strategy (auto, signal, service, tree)")
+	restartCmd.Flags().String("signal", "TERM", "Signal sent before escalating (e.g. INT, HUP, 15)")
+	restartCmd.Flags().Duration("timeout", 2*time.Second, "Grace period before escalating to SIGKILL")
+	restartCmd.Flags().Bool("allow-protected", false, "Restart processes protected by protected_ports or protected_processes")
+	restartCmd.Flags().Bool("force", false, "Skip the grace period and SIGKILL immediately")
+	restartCmd.Flags().BoolP("yes", "y", false, "Skip the confirmation prompt")
+
+	var inventoryCmd = &cobra.Command{
+		Use:   "inventory",
+		Short: "Inventory listening software and versions",
+		Run:   runInventory,
+	}
+	addOutputFlag(inventoryCmd)
+
+	var inspectCmd = &cobra.Command{
+		Use:   "inspect <port>",
+		Short: "Show the process using a port and what else it holds",
+		Long: `Show the process using a port. With --files, also list the other
+sockets it holds, such as more listening ports or connections to a
+database, and the notable files it keeps open, such as SQLite databases
+and logs.`,
+		Args: cobra.ExactArgs(1),
+		Run:  runInspect,
+	}
+	inspectCmd.Flags().Bool("files", false, "List the process's sockets and open files")
+	addOutputFlag(inspectCmd)
+
+	var historyCmd = &cobra.Command{
+		Use:   "history [port]",
+		Short: "Show which processes occupied ports over time",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   runHistory,
+	}
+	historyCmd.Flags().Duration("since", 0, "Only show events newer than this (e.g. 24h)")
+	addOutputFlag(historyCmd)
+
+	var serveCmd = &cobra.Command{
+		Use:   "serve",
+		Short: "Serve a local HTTP API for querying and killing port users",
+		Long: `Serve a local HTTP API so dashboards, editors and scripts can query
+and kill port users without spawning the CLI.
+
+Endpoints:
+  GET    /ports         List all ports in use
+  GET    /ports/{port}  Show the process using a port
+  DELETE /ports/{port}  Kill the process using a port (?strategy=name)
+
+Every request must send the API token, from --token, the api_token setting
+or made up at start, as "Authorization: Bearer <token>". Requests naming
+another host than this one, as DNS rebinding would, are refused.`,
+		Args: cobra.NoArgs,
+		Run:  runServe,
+	}
+	serveCmd.Flags().String("listen", "127.0.0.1:7777", "Address to listen on (the API can kill processes, keep it local)")
+	serveCmd.Flags().String("token", "", "Bearer token requests must send (default: the api_token setting, or a random one)")
+
+	var waitCmd = &cobra.Command{
+		Use:   "wait <port>...",
+		Short: "Wait until a port is in use, or free with --free",
+		Long: `Block until every port is in use, e.g. until a database accepts
+connections before running migrations, or with --free until they are all
+released. Exits 0 once the ports are ready, 2 on timeout and 1 on errors.
+
+Examples:
+  portfinder wait 5432 --timeout 60s && npm run migrate
+  portfinder wait 3000 --free`,
+		Args: cobra.MinimumNArgs(1),
+		Run:  runWait,
+	}
+	waitCmd.Flags().Duration("timeout", time.Minute, "Give up after this long (0 waits forever)")
+	waitCmd.Flags().Duration("interval", 500*time.Millisecond, "Time between checks")
+	waitCmd.Flags().Bool("free", false, "Wait until the ports are released instead")
+	addOutputFlag(waitCmd)
+
+	var watchCmd = &cobra.Command{
+		Use:   "watch [port]...",
+		Short: "Report ports being grabbed and freed as it happens",
+		Long: `Poll the ports, or every port when none are given, and print a line
+whenever a process starts listening on one or one is freed. Events selected
+by the notifications rules of the config, or all of them with --notify, are
+also shown as desktop notifications, and the config's hooks run for the
+events they select.
+
+Examples:
+  portfinder watch 3000 5432
+  portfinder watch --notify`,
+		Run: runWatch,
+	}
+	watchCmd.Flags().Duration("interval", 2*time.Second, "Time between checks")
+	watchCmd.Flags().Bool("notify", false, "Show a desktop notification for every event")
+
+	var doctorCmd = &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose the environment portfinder depends on",
+		Args:  cobra.NoArgs,
+		Run:   runDoctor,
+	}
+	addOutputFlag(doctorCmd)
+
+	var statusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "Show whether the current repository's ports and services are ready",
+		Long: `Show a board for the repository in the working directory: the ports its
+config files expect (free, served by the project, or taken by something
+else, with a health probe for its own listeners) and the state of its
+docker compose services. Exits non-zero when anything blocks starting work.`,
+		Args: cobra.NoArgs,
+		Run:  runStatus,
+	}
+	addOutputFlag(statusCmd)
+
+	var projectCmd = &cobra.Command{
+		Use:   "project",
+		Short: "Show the ports the project in the working directory uses",
+		Long: `Read the ports the project in the working directory expects from its
+compose file, Procfile, package.json scripts, dev container config,
+.env files and Vite or Spring configs, and show in one view which are
+free, served by the project, or taken by something else. Exits non-zero
+on conflicts.`,
+		Args: cobra.NoArgs,
+		Run:  runProject,
+	}
+	addOutputFlag(projectCmd)
+
+	var reserveCmd = &cobra.Command{
+		Use:   "reserve [port...]",
+		Short: "Reserve ports for a project",
+		Long: `Set ports aside for a project, by name or directory (the repository of
+the working directory by default). check and list warn when another
+process takes a reserved port, and claim verifies them before the project
+starts. With --hold, portfinder listens on the ports as a placeholder
+until they are released or claimed. Without ports, lists the reservations.`,
+		Example: `  portfinder reserve 3000 5432 --project myapp
+  portfinder reserve 3000 --hold
+  portfinder reserve`,
+		Run: runReserve,
+	}
+	reserveCmd.Flags().String("project", "", "project the ports are reserved for (default: the current repository)")
+	reserveCmd.Flags().Bool("hold", false, "keep the ports bound until they are released or claimed")
+	addOutputFlag(reserveCmd)
+
+	var releaseCmd = &cobra.Command{
+		Use:   "release <port>...",
+		Short: "Release reserved ports",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   runRelease,
+	}
+
+	var claimCmd = &cobra.Command{
+		Use:   "claim",
+		Short: "Check a project's reserved ports are free before starting it",
+		Long: `Check the ports reserved for a project are free or already used by it,
+stopping placeholders started with 'reserve --hold'. Exits non-zero when
+another process holds one, so it can run before the project starts.`,
+		Example: `  portfinder claim --project myapp && npm run dev`,
+		Args:    cobra.NoArgs,
+		Run:     runClaim,
 	}
+	claimCmd.Flags().String("project", "", "project to claim the ports of (default: the current repository)")
+	addOutputFlag(claimCmd)
 
 	var versionCmd = &cobra.Command{
 		Use:   "version",
 		Short: "Print version information",
 		Run: func(cmd *cobra.Command, args []string) {
+			if format := outputFormat(cmd); format != output.Table {
+				printResult(format, struct {
+					Version string `json:"version"`
+					Commit  string `json:"commit"`
+					Date    string `json:"date"`
+				}{version, commit, date})
+				return
+			}
 			fmt.Printf("portfinder %s (%s) built at %s\n", version, commit, date)
 		},
 	}
+	addOutputFlag(versionCmd)
 
-	rootCmd.AddCommand(checkCmd, listCmd, killCmd, versionCmd)
+	rootCmd.AddCommand(checkCmd, listCmd, killCmd, killProjectCmd, restartCmd, inspectCmd, inventoryCmd, historyCmd, serveCmd, waitCmd, watchCmd, newConfigCmd(), doctorCmd, statusCmd, projectCmd, reserveCmd, releaseCmd, claimCmd, newSnapshotCmd(), newTLSCmd(), newScanCmd(), newEphemeralCmd(), newSocketsCmd(), newFirewallCmd(true), newFirewallCmd(false), newPauseCmd(true), newPauseCmd(false), newNiceCmd(), versionCmd, newGenDocsCmd())
+	return rootCmd
+}
 
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+// newFinder creates a process finder configured from the user config
+func newFinder() process.Finder {
+	return newFinderWithConfig(config.Load())
+}
+
+// parseRange parses a port range such as "8000-8100"
+func parseRange(s string) (int, int, error) {
+	lowText, highText, _ := strings.Cut(s, "-")
+	low, errLow := strconv.Atoi(strings.TrimSpace(lowText))
+	high, errHigh := strconv.Atoi(strings.TrimSpace(highText))
+	if errLow != nil || errHigh != nil || low < 1 || high > 65535 || low > high {
+		return 0, 0, fmt.Errorf("invalid port range %q, expected e.g. 8000-8100", s)
 	}
+	return low, high, nil
+}
+
+func newFinderWithConfig(cfg *config.Config) process.Finder {
+	opts := []process.Option{process.WithRules(cfg.ClassificationRules)}
+	if wslInterop || cfg.WSLInterop {
+		opts = append(opts, process.WithWindowsHost())
+	}
+	if !noCache {
+		opts = append(opts, process.WithCache(cfg.CacheDuration()))
+	}
+	opts = append(opts, process.WithTimeout(lookupTimeout))
+	statsFinder = process.NewFinder(opts...)
+	return statsFinder
 }
 
 func runPortCheck(cmd *cobra.Command, args []string) {
@@ -73,66 +434,404 @@ func runPortCheck(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if strings.Contains(args[0], "-") {
+		showProcessList(cmd, args[0])
+		return
+	}
+
 	port, err := strconv.Atoi(args[0])
 	if err != nil {
 		ui.ErrorMsg("Invalid port number: %s", args[0])
 		os.Exit(1)
 	}
+	format := outputFormat(cmd)
 
-	finder := process.NewFinder()
-	proc, err := finder.FindByPort(port)
+	cfg := config.Load()
+	finder := newFinderWithConfig(cfg)
+	proc, err := finder.FindByPort(cmd.Context(), port)
 	if err != nil {
 		ui.ErrorMsg("Error checking port: %v", err)
 		os.Exit(1)
 	}
 
+	if probe, _ := cmd.Flags().GetBool("probe"); probe && proc != nil {
+		if err := proc.Probe(probeTimeout); err != nil {
+			ui.WarnMsg("Probe failed: %v", err)
+		}
+	}
+
+	var others []process.TCPSocket
+	if allStates, _ := cmd.Flags().GetBool("all-states"); allStates {
+		if others, err = process.SocketsOnPort(cmd.Context(), port); err != nil {
+			ui.WarnMsg("Could not read the sockets in other states: %v", err)
+		}
+	}
+
+	if format != output.Table {
+		if proc != nil {
+			recordSeen(cfg, proc)
+		}
+		result := portResult{Port: port, InUse: proc != nil, Process: proc, OtherStates: others}
+		if proc != nil || len(others) > 0 {
+			bind := process.CheckBind(port)
+			result.Bind = &bind
+		}
+		if proc != nil {
+			result.Alternatives = advisor.FreePortsAbove(port, advisor.MaxAlternatives)
+		}
+		printResult(format, result)
+		return
+	}
+
 	if proc == nil {
+		if len(others) > 0 {
+			ui.WarnMsg("Nothing listens on port %d, but sockets in other states still use it", port)
+			ui.DisplayPortStates(port, others)
+			ui.DisplayBindCheck(process.CheckBind(port))
+			return
+		}
+		if hiddenListener(port) {
+			ui.WarnMsg("Port %d looks free, yet binding it fails: a process pf can't see holds it (%s, or see 'portfinder doctor')", port, process.DetectCapabilities().Elevate)
+			return
+		}
 		ui.SuccessMsg("Port %d is free!", port)
 		return
 	}
 
-	ui.ShowProcessDetail(proc, true)
+	recordSeen(cfg, proc)
+	ui.SetKillHook(func(p *process.Process, strategy process.KillStrategy) {
+		recordKill(cfg, p, strategy)
+	})
+	ui.SetKillGuard(protectionGuard(cfg))
+	ui.SetOpener(cfg.OpenWith)
+
+	// The interactive view samples the usage in the background, printed
+	// details wait for it only when asked. It is informational, so
+	// sampling failures are ignored.
+	if usage, _ := cmd.Flags().GetBool("usage"); usage {
+		proc.SampleCPU(usageInterval)
+	}
+	proc.LoadLineage()
+
+	advice := advisor.Advise(proc)
+	// Only offer actions when someone can pick them, and there is a known
+	// process to act on
+	var actions process.Finder
+	if isTerminal(os.Stdin) && isTerminal(os.Stdout) && !proc.SocketOnly {
+		actions = finder
+	}
+	if err := ui.ShowProcessDetail(proc, advice, actions); err != nil {
+		ui.ErrorMsg("Error: %v", err)
+		os.Exit(1)
+	}
+	if len(others) > 0 {
+		ui.DisplayPortStates(port, others)
+	}
+	// Checked once the detail is closed, which may have killed the owner
+	ui.DisplayBindCheck(process.CheckBind(port))
 }
 
 func runCheckCommon(cmd *cobra.Command, args []string) {
+	format := outputFormat(cmd)
 	cfg := config.Load()
-	finder := process.NewFinder()
+	profile, _ := cmd.Flags().GetString("profile")
+	if profile == "" {
+		profile = os.Getenv(config.ProfileEnv)
+	}
+	if profile != "" {
+		if err := cfg.UseProfile(profile); err != nil {
+			ui.ErrorMsg("%v", err)
+			os.Exit(1)
+		}
+	}
+	finder := newFinderWithConfig(cfg)
+
+	groups := cfg.PortGroups()
+	if len(args) == 1 {
+		group, ok := cfg.Group(args[0])
+		if !ok {
+			ui.ErrorMsg("Unknown port group: %s", args[0])
+			os.Exit(1)
+		}
+		groups = []config.PortGroup{group}
+	}
 
-	results := make(map[int]*process.Process)
-	for _, port := range cfg.CommonPorts {
-		proc, _ := finder.FindByPort(port)
+	var ports []int
+	for _, group := range groups {
+		ports = append(ports, group.Ports...)
+	}
+	// One scan covers every group and the reservations, rather than a
+	// lookup per port
+	found, err := finder.FindByPorts(cmd.Context(), append(reservedPorts(cfg), ports...))
+	if err != nil {
+		ui.ErrorMsg("Error checking ports: %v", err)
+		os.Exit(1)
+	}
+	results := make(map[int]*process.Process, len(ports))
+	hints := make(map[int][]advisor.Suggestion)
+	for _, port := range ports {
+		proc := found[port]
 		results[port] = proc
+		if proc != nil {
+			recordSeen(cfg, proc)
+			hints[port] = advisor.Suggest(proc, advisor.NextFreePort(port))
+		}
+	}
+
+	if format != output.Table {
+		var rows []portResult
+		for _, group := range groups {
+			for _, port := range group.Ports {
+				proc := results[port]
+				rows = append(rows, portResult{Port: port, Group: group.Name, Label: group.Labels[port], InUse: proc != nil, Process: proc})
+			}
+		}
+		printResult(format, rows)
+		return
 	}
 
-	if err := ui.ShowPortCheck(results); err != nil {
+	warnReservations(cfg, found)
+	if err := ui.ShowPortCheck(results, groups, hints); err != nil {
 		ui.ErrorMsg("Error: %v", err)
 		os.Exit(1)
 	}
 }
 
 func runListAll(cmd *cobra.Command, args []string) {
-	finder := process.NewFinder()
-	processes, err := finder.ListAll()
+	portRange, _ := cmd.Flags().GetString("range")
+	showProcessList(cmd, portRange)
+}
+
+// showProcessList lists the listening processes, only those on ports in
+// portRange (e.g. "3000-4000") when it is set
+func showProcessList(cmd *cobra.Command, portRange string) {
+	format := outputFormat(cmd)
+	var inRange func(*process.Process) bool
+	if portRange != "" {
+		low, high, err := parseRange(portRange)
+		if err != nil {
+			ui.ErrorMsg("%v", err)
+			os.Exit(1)
+		}
+		inRange = func(p *process.Process) bool {
+			return p.Port >= low && p.Port <= high
+		}
+	}
+	exportPath, _ := cmd.Flags().GetString("export")
+	var exportFormat output.Format
+	if exportPath != "" {
+		var err error
+		if exportFormat, err = output.FormatOf(exportPath); err != nil {
+			ui.ErrorMsg("%v", err)
+			os.Exit(1)
+		}
+	}
+
+	cfg := config.Load()
+	if err := ui.SetKeyBindings(cfg.KeyBindings); err != nil {
+		ui.ErrorMsg("Invalid config: %v", err)
+		os.Exit(1)
+	}
+	columns := cfg.Columns
+	if cmd.Flags().Changed("columns") {
+		columns, _ = cmd.Flags().GetStringSlice("columns")
+	}
+	if err := ui.SetColumns(columns); err != nil {
+		ui.ErrorMsg("Invalid columns: %v", err)
+		os.Exit(1)
+	}
+
+	// The filters apply to an elevated rescan as well
+	remoteHost, _ := cmd.Flags().GetString("remote")
+	remote := remoteHost != ""
+	var fw firewall.Firewall
+	if show, _ := cmd.Flags().GetBool("firewall"); show && !remote {
+		if fw = firewall.Detect(cmd.Context()); fw == nil {
+			ui.WarnMsg("No supported firewall found (ufw, firewalld, pf or Windows Firewall)")
+		} else {
+			ui.AddColumn("firewall")
+		}
+	}
+	narrow := func(finder process.Finder) process.Finder {
+		if publicOnly, _ := cmd.Flags().GetBool("public-only"); publicOnly {
+			finder = process.Filtered(finder, (*process.Process).IsPublic)
+		}
+		if owner, _ := cmd.Flags().GetString("user"); owner != "" {
+			finder = process.Filtered(finder, func(p *process.Process) bool {
+				return p.OwnedBy(owner)
+			})
+		}
+		if keep := listFilter(cmd); keep != nil {
+			finder = process.Filtered(finder, keep)
+		}
+		// One listing filtered by port beats a lookup per port of the range
+		if inRange != nil {
+			finder = process.Filtered(finder, inRange)
+		}
+		// Only the listeners left are probed, and only locally
+		if probe, _ := cmd.Flags().GetBool("probe"); probe && !remote {
+			finder = process.Probed(finder, listProbeTimeout)
+		}
+		if fw != nil {
+			finder = firewalled(finder, fw)
+		}
+		return finder
+	}
+
+	// A remote host's processes are killed through its API, never locally
+	var finder process.Finder
+	var killer ui.Killer
+	var elevation *ui.Elevation
+	elevated := false
+	if remote {
+		token, _ := cmd.Flags().GetString("token")
+		if token == "" {
+			token = cfg.APIToken
+		}
+		client := server.NewClient(remoteHost, token)
+		finder, killer = client, client
+	} else {
+		finder = newFinderWithConfig(cfg)
+		sudo, _ := cmd.Flags().GetBool("sudo")
+		switch {
+		case sudo && canElevate():
+			if err := authenticate(); err != nil {
+				ui.ErrorMsg("%v", err)
+				os.Exit(1)
+			}
+			finder, elevated = process.Elevated(finder, sudoList), true
+		case sudo:
+			if caps := process.DetectCapabilities(); !caps.AllUsers {
+				ui.WarnMsg("Can't rescan with sudo here; %s", caps.Elevate)
+			}
+		case canElevate():
+			elevation = &ui.Elevation{
+				Authenticate: sudoAuth,
+				Provider:     ui.NewFinderProvider(narrow(process.Elevated(finder, sudoList)), nil),
+			}
+		}
+	}
+	finder = narrow(finder)
+
+	processes, err := finder.ListAll(cmd.Context())
 	if err != nil {
 		ui.ErrorMsg("Error listing ports: %v", err)
 		os.Exit(1)
 	}
+	if format != output.Table {
+		if killer == nil {
+			recordSeen(cfg, processes...)
+		}
+		printProcesses(format, processes)
+		if exportPath != "" {
+			if err := ui.ExportList(exportPath, exportFormat, processes); err != nil {
+				ui.ErrorMsg("Export failed: %v", err)
+				os.Exit(1)
+			}
+		}
+		return
+	}
+	if inRange != nil && len(processes) == 0 {
+		ui.SuccessMsg("All ports in %s are free!", portRange)
+		return
+	}
+	if listFilter(cmd) != nil && len(processes) == 0 {
+		ui.InfoMsg("No listening processes match the filters")
+		return
+	}
 
-	if err := ui.ShowProcessList(processes); err != nil {
+	labels := cfg.Labels
+	if killer == nil {
+		recordSeen(cfg, processes...)
+		holders := process.ByPorts(processes, reservedPorts(cfg))
+		warnReservations(cfg, holders)
+		labels = reservationLabels(cfg, holders)
+	}
+	ui.SetKillHook(func(p *process.Process, strategy process.KillStrategy) {
+		recordKill(cfg, p, strategy)
+	})
+	ui.SetKillGuard(protectionGuard(cfg))
+	ui.SetOpener(cfg.OpenWith)
+
+	tree, _ := cmd.Flags().GetBool("tree")
+	merge, _ := cmd.Flags().GetBool("merge")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	provider := ui.NewFinderProvider(finder, killer)
+	if err := ui.ShowProcessList(processes, provider, ui.ListOptions{
+		Tree:     tree,
+		Merge:    merge,
+		Labels:   labels,
+		Restarts: crashLoops(cfg),
+
+		ExportPath:   exportPath,
+		ExportFormat: exportFormat,
+
+		ConfirmKill: cfg.ConfirmKill,
+		Remote:      killer != nil,
+		DryRun:      dryRun,
+		Elevation:   elevation,
+		Elevated:    elevated,
+	}); err != nil {
 		ui.ErrorMsg("Error: %v", err)
 		os.Exit(1)
 	}
 }
 
+// listFilter combines the list's --port, --name, --project, --docker and
+// --min-uptime filters, or returns nil when none is set
+func listFilter(cmd *cobra.Command) func(*process.Process) bool {
+	ports, _ := cmd.Flags().GetIntSlice("port")
+	name, _ := cmd.Flags().GetString("name")
+	project, _ := cmd.Flags().GetString("project")
+	dockerOnly, _ := cmd.Flags().GetBool("docker")
+	minUptime, _ := cmd.Flags().GetDuration("min-uptime")
+	if len(ports) == 0 && name == "" && project == "" && !dockerOnly && minUptime == 0 {
+		return nil
+	}
+
+	wanted := make(map[int]bool, len(ports))
+	for _, port := range ports {
+		wanted[port] = true
+	}
+	name = strings.ToLower(name)
+	return func(p *process.Process) bool {
+		if len(wanted) > 0 && !wanted[p.Port] {
+			return false
+		}
+		if name != "" && !strings.Contains(strings.ToLower(p.Name), name) {
+			return false
+		}
+		if project != "" && !p.InProject(project) {
+			return false
+		}
+		if dockerOnly && !p.IsDocker {
+			return false
+		}
+		// Listeners without a known start time have no uptime to compare
+		if minUptime > 0 && (p.StartTime.IsZero() || time.Since(p.StartTime) < minUptime) {
+			return false
+		}
+		return true
+	}
+}
+
 func runKillProcess(cmd *cobra.Command, args []string) {
+	name, _ := cmd.Flags().GetString("name")
+	project, _ := cmd.Flags().GetString("project")
+	if name != "" || project != "" {
+		runKillMatching(cmd, name, project)
+		return
+	}
+
 	port, err := strconv.Atoi(args[0])
 	if err != nil {
 		ui.ErrorMsg("Invalid port number: %s", args[0])
 		os.Exit(1)
 	}
 
-	finder := process.NewFinder()
-	proc, err := finder.FindByPort(port)
+	cfg := config.Load()
+	finder := newFinderWithConfig(cfg)
+	proc, err := finder.FindByPort(cmd.Context(), port)
 	if err != nil {
 		ui.ErrorMsg("Error checking port: %v", err)
 		os.Exit(1)
@@ -143,10 +842,368 @@ func runKillProcess(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	if err := proc.Kill(); err != nil {
+	refuseProtected(cmd, cfg, proc)
+
+	if parent, _ := cmd.Flags().GetBool("parent"); parent {
+		runKillParent(cmd, cfg, proc)
+		return
+	}
+
+	strategy, err := killStrategy(cmd, proc)
+	if err != nil {
+		ui.ErrorMsg("%v", err)
+		os.Exit(1)
+	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		printKillSteps(proc, strategy)
+		ui.InfoMsg("Dry run: nothing was killed")
+		return
+	}
+	if _, service := strategy.(process.ServiceStopStrategy); service && !cmd.Flags().Changed("strategy") {
+		strategy = offerServiceStop(cmd, proc, strategy)
+	}
+
+	window, _ := cmd.Flags().GetDuration("verify")
+	respawned, err := process.KillAndVerify(cmd.Context(), finder, proc, strategy, window)
+	if err != nil {
 		ui.ErrorMsg("Failed to kill process: %v", err)
 		os.Exit(1)
 	}
+	recordKill(cfg, proc, strategy)
+
+	switch strategy.(type) {
+	case process.ContainerStopStrategy:
+		ui.SuccessMsg("Stopped container %s (%s) on port %d", proc.ContainerName, proc.DockerID, port)
+	case process.ServiceStopStrategy:
+		ui.SuccessMsg("Stopped service %s through %s on port %d", proc.Service, proc.ServiceManager(), port)
+	default:
+		ui.SuccessMsg("Killed process %s (PID: %d) on port %d", proc.Name, proc.PID, port)
+	}
+
+	if respawned != nil {
+		ui.WarnMsg("Port %d was taken again by %s (PID: %d) right after, something restarts it", port, respawned.Name, respawned.PID)
+		if stop := advisor.StopRespawns(respawned); stop != "" {
+			ui.InfoMsg("Stop it for good with: %s", stop)
+		} else {
+			ui.InfoMsg("See what started it with: pf %d", port)
+		}
+		os.Exit(1)
+	}
+}
+
+// offerServiceStop asks whether to stop the service owning the process
+// through its manager, which would otherwise restart it, or to signal the
+// process anyway. Without a terminal to ask, or with --yes, the service is
+// stopped.
+func offerServiceStop(cmd *cobra.Command, proc *process.Process, strategy process.KillStrategy) process.KillStrategy {
+	ui.InfoMsg("%s (PID: %d) is run by %s as %s, which may restart it if it is killed", proc.Name, proc.PID, proc.ServiceManager(), proc.Service)
+	if skip, _ := cmd.Flags().GetBool("yes"); skip || !isTerminal(os.Stdin) {
+		return strategy
+	}
+	if ui.SimpleConfirm(fmt.Sprintf("Stop %s through %s instead?", proc.Service, proc.ServiceManager())) {
+		return strategy
+	}
+
+	opts, err := killOptions(cmd)
+	if err != nil {
+		ui.ErrorMsg("%v", err)
+		os.Exit(1)
+	}
+	return process.WithKillOptions(process.SignalStrategy{}, opts)
+}
+
+// runKillParent kills the root of the app owning the port together with
+// its descendants, so a supervisor can't respawn the listener
+func runKillParent(cmd *cobra.Command, cfg *config.Config, proc *process.Process) {
+	root := proc.Root()
+	if root.PID == proc.PID {
+		ui.InfoMsg("%s (PID: %d) has no parent process to kill besides its shell", proc.Name, proc.PID)
+	}
+
+	opts, err := killOptions(cmd)
+	if err != nil {
+		ui.ErrorMsg("%v", err)
+		os.Exit(1)
+	}
+
+	target := &process.Process{PID: root.PID, Name: root.Name, Port: proc.Port, ProjectPath: proc.ProjectPath}
+	strategy := process.WithKillOptions(process.TreeKillStrategy{}, opts)
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		printKillSteps(target, strategy)
+		ui.InfoMsg("Dry run: nothing was killed")
+		return
+	}
+	if err := target.KillWith(strategy); err != nil {
+		ui.ErrorMsg("Failed to kill process tree: %v", err)
+		os.Exit(1)
+	}
+	recordKill(cfg, proc, strategy)
+
+	ui.SuccessMsg("Killed %s (PID: %d) and its children on port %d", root.Name, root.PID, proc.Port)
+}
+
+// runKillMatching kills every listener matching the given name and project
+func runKillMatching(cmd *cobra.Command, name, project string) {
+	cfg := config.Load()
+	finder := newFinderWithConfig(cfg)
+	processes, err := finder.ListAll(cmd.Context())
+	if err != nil {
+		ui.ErrorMsg("Error listing ports: %v", err)
+		os.Exit(1)
+	}
+
+	matches := process.Filter(processes, func(p *process.Process) bool {
+		if name != "" && !strings.EqualFold(p.Name, name) {
+			return false
+		}
+		if project != "" && !p.InProject(project) {
+			return false
+		}
+		return true
+	})
+	if len(matches) == 0 {
+		ui.InfoMsg("No listening processes match")
+		return
+	}
+
+	killTargets(cmd, cfg, matches)
+}
+
+// killTargets shows the processes about to be killed and kills them in
+// order once confirmed, exiting non-zero when a kill failed. With
+// --dry-run it stops after showing them.
+func killTargets(cmd *cobra.Command, cfg *config.Config, matches []*process.Process) {
+	allowed := matches[:0:0]
+	for _, proc := range matches {
+		if reason := protection(cmd, cfg, proc); reason != "" {
+			ui.WarnMsg("Skipping %s (PID: %d) on port %d: %s", proc.Name, proc.PID, proc.Port, reason)
+			continue
+		}
+		allowed = append(allowed, proc)
+	}
+	if len(allowed) == 0 {
+		ui.ErrorMsg("Every matching process is protected; pass --allow-protected to kill them anyway")
+		os.Exit(1)
+	}
+	matches = allowed
+
+	// A process listening on several ports only needs to be killed once
+	targets := process.UniqueByPID(matches)
+
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	ui.DisplayKillPlan(matches, dryRun)
+	if dryRun {
+		for _, proc := range targets {
+			strategy, err := killStrategy(cmd, proc)
+			if err != nil {
+				ui.ErrorMsg("%v", err)
+				os.Exit(1)
+			}
+			printKillSteps(proc, strategy)
+		}
+		ui.InfoMsg("Dry run: nothing was killed")
+		return
+	}
 
-	ui.SuccessMsg("Killed process %s (PID: %d) on port %d", proc.Name, proc.PID, port)
+	if skip, _ := cmd.Flags().GetBool("yes"); !skip {
+		if !ui.SimpleConfirm(fmt.Sprintf("Kill %d process(es)?", len(targets))) {
+			ui.InfoMsg("Aborted")
+			return
+		}
+	}
+
+	failed := 0
+	for _, proc := range targets {
+		strategy, err := killStrategy(cmd, proc)
+		if err == nil {
+			err = proc.KillWith(strategy)
+		}
+		if err != nil {
+			ui.ErrorMsg("Failed to kill %s (PID: %d): %v", proc.Name, proc.PID, err)
+			failed++
+			continue
+		}
+		recordKill(cfg, proc, strategy)
+		ui.SuccessMsg("Killed %s (PID: %d)", proc.Name, proc.PID)
+	}
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// protection returns why the config protects the process from kills, or
+// "" when it doesn't or --allow-protected was passed
+func protection(cmd *cobra.Command, cfg *config.Config, proc *process.Process) string {
+	if allow, _ := cmd.Flags().GetBool("allow-protected"); allow {
+		return ""
+	}
+	return cfg.Protected(proc)
+}
+
+// refuseProtected exits when the config protects the process from kills
+func refuseProtected(cmd *cobra.Command, cfg *config.Config, proc *process.Process) {
+	if reason := protection(cmd, cfg, proc); reason != "" {
+		ui.ErrorMsg("Refusing to kill %s (PID: %d): %s; pass --allow-protected to kill it anyway", proc.Name, proc.PID, reason)
+		os.Exit(1)
+	}
+}
+
+// protectionGuard refuses kills of protected processes from the views,
+// which have no --allow-protected
+func protectionGuard(cfg *config.Config) func(*process.Process) error {
+	return func(p *process.Process) error {
+		if reason := cfg.Protected(p); reason != "" {
+			return fmt.Errorf("%s, remove it from the config to kill it here", reason)
+		}
+		return nil
+	}
+}
+
+// printKillSteps prints what killing the process with the strategy would
+// do, for --dry-run
+func printKillSteps(proc *process.Process, strategy process.KillStrategy) {
+	fmt.Printf("%s (PID: %d) on port %d, %s strategy:\n", proc.Name, proc.PID, proc.Port, strategy.Name())
+	for _, step := range process.DescribeKill(strategy, proc) {
+		fmt.Printf("  %s\n", step)
+	}
+}
+
+// killStrategy resolves the --strategy and --container flags, applying the
+// signal flags to strategies that send signals
+func killStrategy(cmd *cobra.Command, proc *process.Process) (process.KillStrategy, error) {
+	if stopContainer, _ := cmd.Flags().GetBool("container"); stopContainer {
+		return process.ContainerStopStrategy{}, nil
+	}
+
+	opts, err := killOptions(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	var strategy process.KillStrategy
+	name, _ := cmd.Flags().GetString("strategy")
+	switch {
+	case name != "" && name != "auto":
+		if strategy, err = process.StrategyByName(name); err != nil {
+			return nil, err
+		}
+	case cmd.Flags().Changed("signal") || cmd.Flags().Changed("force"):
+		// Explicit signal flags only make sense for signaling the process
+		strategy = process.SignalStrategy{}
+	default:
+		strategy = process.SelectStrategy(proc)
+	}
+
+	return process.WithKillOptions(strategy, opts), nil
+}
+
+// killOptions builds signal options from the --signal, --timeout and --force flags
+func killOptions(cmd *cobra.Command) (process.KillOptions, error) {
+	signalName, _ := cmd.Flags().GetString("signal")
+	sig, err := process.ParseSignal(signalName)
+	if err != nil {
+		return process.KillOptions{}, err
+	}
+
+	grace, _ := cmd.Flags().GetDuration("timeout")
+	force, _ := cmd.Flags().GetBool("force")
+
+	return process.KillOptions{
+		Signal: sig,
+		Grace:  grace,
+		Force:  force,
+	}, nil
+}
+
+func runInventory(cmd *cobra.Command, args []string) {
+	format := outputFormat(cmd)
+
+	finder := newFinder()
+	processes, err := finder.ListAll(cmd.Context())
+	if err != nil {
+		ui.ErrorMsg("Error listing ports: %v", err)
+		os.Exit(1)
+	}
+
+	items := inventory.Collect(processes)
+
+	if format != output.Table {
+		printResult(format, items)
+		return
+	}
+
+	timeRender(func() { ui.DisplayInventory(items) })
+}
+
+// needsNoConfig reports whether a command runs with a broken config file:
+// the config commands, which repair it, and shell completion
+func needsNoConfig(cmd *cobra.Command) bool {
+	for c := cmd; c != nil && c.HasParent(); c = c.Parent() {
+		switch c.Name() {
+		case "config", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+			return true
+		}
+	}
+	return false
+}
+
+// setup falls back to plain output when asked to or when stdout isn't a
+// terminal, and to ASCII when the terminal can't render the styled UI,
+// handles the first run and applies the configured tool overrides
+func setup(cmd *cobra.Command, args []string) {
+	setupLogging(cmd)
+	configPath, _ := cmd.Flags().GetString("config")
+	if configPath != "" {
+		config.SetPath(configPath)
+	}
+	migrated, err := config.Migrate()
+	cfg, loadErr := config.LoadFile()
+	if loadErr != nil {
+		// Falling back to the defaults would quietly drop the protected
+		// ports and processes, so only the commands fixing the file go on
+		if !needsNoConfig(cmd) {
+			ui.ErrorMsg("Invalid config: %v", loadErr)
+			ui.InfoMsg("Fix it with 'portfinder config edit', or check it with 'portfinder config validate'")
+			os.Exit(1)
+		}
+		cfg = config.DefaultConfig()
+	}
+	// A bad variable only loses its own override, and the commands
+	// repairing the config don't need to hear about it
+	if err := cfg.ApplyEnv(); err != nil && !needsNoConfig(cmd) {
+		ui.WarnMsg("Ignoring invalid environment: %v", err)
+	}
+
+	plain, _ := cmd.Flags().GetBool("plain")
+	ui.SetPlain(plain || !isTerminal(os.Stdout))
+
+	ui.SetColorMode(cfg.Color)
+	ui.SetTheme(cfg.ActiveTheme())
+	ascii, _ := cmd.Flags().GetBool("ascii")
+	ui.SetASCII(ascii || ui.LimitedTerminal())
+	accessible, _ := cmd.Flags().GetBool("accessible")
+	ui.SetAccessible(accessible || cfg.Accessible)
+	reportMigration(migrated, err)
+	// A file named with --config is left to its owner to create
+	if configPath == "" {
+		firstRun()
+	}
+
+	if err := process.SetTools(cfg.Tools); err != nil {
+		ui.ErrorMsg("Invalid config: %v", err)
+		os.Exit(1)
+	}
+
+	wslInterop, _ = cmd.Flags().GetBool("wsl")
+	noCache, _ = cmd.Flags().GetBool("no-cache")
+	// kill, restart and wait have a --timeout of their own, which hides
+	// this one, so only the config sets theirs
+	lookupTimeout = cfg.TimeoutDuration()
+	if flag := cmd.Root().PersistentFlags().Lookup("timeout"); flag.Changed {
+		lookupTimeout, _ = cmd.Root().PersistentFlags().GetDuration("timeout")
+	}
+	if wslInterop && !process.IsWSL() {
+		ui.WarnMsg("--wsl has no effect outside WSL with Windows interop enabled")
+	}
 }
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/doganarif/portfinder/internal/process"
+	"github.com/doganarif/portfinder/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// newNiceCmd builds the nice command
+func newNiceCmd() *cobra.Command {
+	niceCmd := &cobra.Command{
+		Use:   "nice <port>",
+		Short: "Change the priority of the process on a port",
+		Long: `Change the scheduling priority of the process listening on a port, as
+renice does: from -20, the highest, to 19, the lowest, with 0 as the
+default. Lowering the priority of e.g. a background indexer bound to a port
+keeps it from starving a build; raising it usually needs root. On Windows
+the value maps to a priority class, from high (-20 to -11) to idle (11 to
+19).
+
+Examples:
+  portfinder nice 3000                # Lower the priority to 10
+  portfinder nice 3000 --priority 19  # Only run when nothing else wants the CPU
+  sudo portfinder nice 3000 --priority 0`,
+		Args: cobra.ExactArgs(1),
+		Run:  runNice,
+	}
+	niceCmd.Flags().IntP("priority", "n", 10, "Nice value, from -20 (highest priority) to 19 (lowest)")
+	return niceCmd
+}
+
+func runNice(cmd *cobra.Command, args []string) {
+	port, err := strconv.Atoi(args[0])
+	if err != nil || port < 1 || port > 65535 {
+		ui.ErrorMsg("Invalid port number: %s", args[0])
+		os.Exit(1)
+	}
+	nice, _ := cmd.Flags().GetInt("priority")
+	if nice < process.MinNice || nice > process.MaxNice {
+		ui.ErrorMsg("Invalid priority %d: use %d (highest) to %d (lowest)", nice, process.MinNice, process.MaxNice)
+		os.Exit(1)
+	}
+
+	proc, err := newFinder().FindByPort(cmd.Context(), port)
+	if err != nil {
+		ui.ErrorMsg("Error checking port: %v", err)
+		os.Exit(1)
+	}
+	if proc == nil {
+		ui.InfoMsg("Port %d is not in use", port)
+		return
+	}
+
+	before, err := proc.Priority()
+	if err != nil {
+		ui.ErrorMsg("Cannot renice %s (PID: %d): %v", proc.Name, proc.PID, err)
+		os.Exit(1)
+	}
+	if err := proc.SetPriority(nice); err != nil {
+		ui.ErrorMsg("Cannot renice %s (PID: %d): %v", proc.Name, proc.PID, err)
+		os.Exit(1)
+	}
+	ui.SuccessMsg("Changed the priority of %s (PID: %d) on port %d from %d to %d", proc.Name, proc.PID, port, before, nice)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+
+	"github.com/doganarif/portfinder/internal/config"
+	"github.com/doganarif/portfinder/internal/output"
+	"github.com/doganarif/portfinder/internal/process"
+	"github.com/doganarif/portfinder/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// portResult is the state of one port in machine readable output. The
+// process fields are inlined when the port is in use.
+type portResult struct {
+	Port  int    `json:"port"`
+	Group string `json:"group,omitempty"`
+	Label string `json:"label,omitempty"`
+	InUse bool   `json:"in_use"`
+	*process.Process
+	// Alternatives are free ports near a port in use
+	Alternatives []int `json:"alternatives,omitempty"`
+	// OtherStates are the sockets on the port that aren't listening, with
+	// --all-states
+	OtherStates []process.TCPSocket `json:"other_states,omitempty"`
+	// Bind tells whether a server could bind a port that looks occupied
+	Bind *process.BindResult `json:"bind,omitempty"`
+}
+
+// checkResult is a diagnostic check in machine readable output
+type checkResult struct {
+	Section string `json:"section"`
+	Status  string `json:"status"`
+	Name    string `json:"name"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// addOutputFlag adds --output to a command printing results
+func addOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringP("output", "o", "table", "Output format (table, json, yaml, csv, markdown)")
+}
+
+// outputFormat returns the format chosen with --output, else by the
+// output setting
+func outputFormat(cmd *cobra.Command) output.Format {
+	name, _ := cmd.Flags().GetString("output")
+	if setting := config.Load().Output; setting != "" && !cmd.Flags().Changed("output") {
+		name = setting
+	}
+	format, err := output.ParseFormat(name)
+	if err != nil {
+		ui.ErrorMsg("Unsupported output format: %s", name)
+		os.Exit(1)
+	}
+	if format != output.Table {
+		jsonStats = true
+		ui.MessagesToStderr()
+	}
+	return format
+}
+
+// printResult writes a command's result to stdout in a machine readable
+// format
+func printResult(format output.Format, v interface{}) {
+	var err error
+	timeRender(func() { err = output.Encode(os.Stdout, format, v) })
+	if err != nil {
+		ui.ErrorMsg("Error: %v", err)
+		os.Exit(1)
+	}
+}
+
+// printProcesses writes a process list to stdout in a machine readable
+// format
+func printProcesses(format output.Format, processes []*process.Process) {
+	var err error
+	timeRender(func() { err = output.Write(os.Stdout, format, processes) })
+	if err != nil {
+		ui.ErrorMsg("Error: %v", err)
+		os.Exit(1)
+	}
+}
+
+// checkResults converts a section of checks for printResult
+func checkResults(section string, checks []ui.Check) []checkResult {
+	results := make([]checkResult, len(checks))
+	for i, c := range checks {
+		results[i] = checkResult{Section: section, Status: c.Status.String(), Name: c.Name, Detail: c.Detail}
+	}
+	return results
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/doganarif/portfinder/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// newPauseCmd builds the pause or resume command
+func newPauseCmd(pause bool) *cobra.Command {
+	use, short := "resume", "Resume a process paused with pause"
+	if pause {
+		use, short = "pause", "Freeze the process on a port without killing it"
+	}
+	pauseCmd := &cobra.Command{
+		Use:   use + " <port>",
+		Short: short,
+		Long: `Pause freezes the process listening on a port, e.g. one hogging the port
+or the CPU, without killing it: it keeps its port and memory but stops
+running, and connections to the port hang, until resume lets it go on.
+Processes are stopped with SIGSTOP and continued with SIGCONT, suspended
+and resumed on Windows, and containers are paused through Docker.
+
+Examples:
+  portfinder pause 3000
+  portfinder resume 3000`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			runPause(cmd, args, pause)
+		},
+	}
+	return pauseCmd
+}
+
+func runPause(cmd *cobra.Command, args []string, pause bool) {
+	port, err := strconv.Atoi(args[0])
+	if err != nil || port < 1 || port > 65535 {
+		ui.ErrorMsg("Invalid port number: %s", args[0])
+		os.Exit(1)
+	}
+
+	proc, err := newFinder().FindByPort(cmd.Context(), port)
+	if err != nil {
+		ui.ErrorMsg("Error checking port: %v", err)
+		os.Exit(1)
+	}
+	if proc == nil {
+		ui.InfoMsg("Port %d is not in use", port)
+		return
+	}
+
+	if pause {
+		if err := proc.Pause(); err != nil {
+			ui.ErrorMsg("Failed to pause %s (PID: %d): %v", proc.Name, proc.PID, err)
+			os.Exit(1)
+		}
+		ui.SuccessMsg("Paused %s (PID: %d) on port %d; run portfinder resume %d to let it go on", proc.Name, proc.PID, port, port)
+		return
+	}
+	if err := proc.Resume(); err != nil {
+		ui.ErrorMsg("Failed to resume %s (PID: %d): %v", proc.Name, proc.PID, err)
+		os.Exit(1)
+	}
+	ui.SuccessMsg("Resumed %s (PID: %d) on port %d", proc.Name, proc.PID, port)
+}
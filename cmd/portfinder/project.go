@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/doganarif/portfinder/internal/advisor"
+	"github.com/doganarif/portfinder/internal/config"
+	"github.com/doganarif/portfinder/internal/output"
+	"github.com/doganarif/portfinder/internal/process"
+	"github.com/doganarif/portfinder/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// projectPortResult is an expected port in machine readable output
+type projectPortResult struct {
+	Port    int    `json:"port"`
+	Service string `json:"service,omitempty"`
+	Source  string `json:"source"`
+	Status  string `json:"status"`
+	Detail  string `json:"detail"`
+}
+
+func runProject(cmd *cobra.Command, args []string) {
+	format := outputFormat(cmd)
+	dir, err := os.Getwd()
+	if err != nil {
+		ui.ErrorMsg("Error reading working directory: %v", err)
+		os.Exit(1)
+	}
+
+	expectations := advisor.Expectations(dir)
+	if len(expectations) == 0 {
+		if format != output.Table {
+			printResult(format, []projectPortResult{})
+			return
+		}
+		ui.InfoMsg("No expected ports found in %s", dir)
+		ui.InfoMsg("pf reads compose files, Procfiles, package.json scripts, .env files, dev container, Vite and Spring configs")
+		return
+	}
+
+	cfg := config.Load()
+	ports := make([]int, len(expectations))
+	for i, e := range expectations {
+		ports[i] = e.Port
+	}
+	holders, err := newFinderWithConfig(cfg).FindByPorts(cmd.Context(), ports)
+	if err != nil {
+		ui.ErrorMsg("Error checking ports: %v", err)
+		os.Exit(1)
+	}
+
+	conflicts := 0
+	rows := make([]ui.ProjectPort, len(expectations))
+	for i, e := range expectations {
+		rows[i] = projectPort(e, holders[e.Port], dir)
+		if rows[i].Status == ui.CheckFail {
+			conflicts++
+		}
+		if proc := holders[e.Port]; proc != nil {
+			recordSeen(cfg, proc)
+		}
+	}
+
+	if format != output.Table {
+		results := make([]projectPortResult, len(rows))
+		for i, r := range rows {
+			results[i] = projectPortResult{Port: r.Port, Service: r.Service, Source: r.Source, Status: r.Status.String(), Detail: r.Detail}
+		}
+		printResult(format, results)
+	} else {
+		ui.DisplayProjectPorts(dir, rows)
+		fmt.Println()
+		if conflicts == 0 {
+			ui.SuccessMsg("No conflicts, all %d ports are free or served by this project", len(rows))
+		}
+	}
+
+	if conflicts > 0 {
+		if format == output.Table {
+			ui.ErrorMsg("%d of %d ports are taken by other processes", conflicts, len(rows))
+		}
+		os.Exit(1)
+	}
+}
+
+// projectPort grades an expected port like status does, without probing
+// health, suggesting a free port for conflicts
+func projectPort(e advisor.Expectation, proc *process.Process, dir string) ui.ProjectPort {
+	row := ui.ProjectPort{Port: e.Port, Service: e.Service, Source: e.Source}
+	switch {
+	case proc == nil:
+		row.Status = ui.CheckOK
+		row.Detail = "free"
+	case proc.SocketOnly:
+		row.Status = ui.CheckWarn
+		row.Detail = "in use by a process that can't be identified here"
+	case ownedBy(proc, dir):
+		row.Status = ui.CheckOK
+		row.Detail = fmt.Sprintf("served by %s (PID %d)", processLabel(proc), proc.PID)
+	default:
+		row.Status = ui.CheckFail
+		row.Detail = fmt.Sprintf("taken by %s (PID %d)", processLabel(proc), proc.PID)
+		if alt := advisor.NextFreePort(e.Port); alt != 0 {
+			row.Detail += fmt.Sprintf(", %d is free", alt)
+		}
+	}
+	return row
+}
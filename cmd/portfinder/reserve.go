@@ -0,0 +1,383 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/doganarif/portfinder/internal/config"
+	"github.com/doganarif/portfinder/internal/history"
+	"github.com/doganarif/portfinder/internal/output"
+	"github.com/doganarif/portfinder/internal/process"
+	"github.com/doganarif/portfinder/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// holdInterval is how often a held reservation checks it wasn't released
+const holdInterval = 2 * time.Second
+
+// placeholderTimeout is how long claim waits for a placeholder to exit
+const placeholderTimeout = 3 * time.Second
+
+func runReserve(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		showReservations(cmd)
+		return
+	}
+
+	ports := parsePorts(args)
+	project := projectFlag(cmd)
+	cfg := loadConfigFile()
+	if err := cfg.Reserve(project, ports...); err != nil {
+		ui.ErrorMsg("%v", err)
+		os.Exit(1)
+	}
+	saveConfig(cfg)
+	ui.SuccessMsg("Reserved %s for %s", portList(ports), project)
+
+	holders, err := newFinderWithConfig(cfg).FindByPorts(cmd.Context(), ports)
+	if err == nil {
+		warnReservations(cfg, holders)
+	}
+
+	if hold, _ := cmd.Flags().GetBool("hold"); hold {
+		holdPorts(cmd.Context(), project, ports)
+	}
+}
+
+func runRelease(cmd *cobra.Command, args []string) {
+	ports := parsePorts(args)
+	cfg := loadConfigFile()
+	if err := cfg.Release(ports...); err != nil {
+		ui.ErrorMsg("%v", err)
+		os.Exit(1)
+	}
+	saveConfig(cfg)
+	ui.SuccessMsg("Released %s", portList(ports))
+}
+
+// runClaim is the pre-flight check before starting a project: its reserved
+// ports must be free or already its own. Placeholders holding them are
+// stopped so the project can bind the ports.
+func runClaim(cmd *cobra.Command, args []string) {
+	format := outputFormat(cmd)
+	project := projectFlag(cmd)
+	cfg := config.Load()
+
+	var ports []int
+	for port, reserved := range cfg.Reservations {
+		if sameProject(reserved, project) {
+			ports = append(ports, port)
+		}
+	}
+	sort.Ints(ports)
+	if len(ports) == 0 {
+		ui.InfoMsg("No ports are reserved for %s; reserve some with 'portfinder reserve <port> --project %s'", project, project)
+		return
+	}
+
+	finder := newFinderWithConfig(cfg)
+	holders, err := finder.FindByPorts(cmd.Context(), ports)
+	if err != nil {
+		ui.ErrorMsg("Error checking ports: %v", err)
+		os.Exit(1)
+	}
+
+	failed := false
+	checks := make([]ui.Check, 0, len(ports))
+	for _, port := range ports {
+		check := ui.Check{Name: fmt.Sprintf("%d", port)}
+		p := holders[port]
+		switch {
+		case p == nil:
+			check.Status, check.Detail = ui.CheckOK, "free"
+		case isPlaceholder(p):
+			check.Status, check.Detail = ui.CheckOK, "free, placeholder stopped"
+			if err := stopPlaceholder(cmd.Context(), cfg, finder, p); err != nil {
+				check.Status, check.Detail = ui.CheckFail, err.Error()
+			}
+		case holdsReservation(p, project):
+			check.Status, check.Detail = ui.CheckOK, fmt.Sprintf("already served by %s (PID %d)", p.Name, p.PID)
+		default:
+			check.Status, check.Detail = ui.CheckFail, fmt.Sprintf("taken by %s (PID %d)", p.Name, p.PID)
+		}
+		failed = failed || check.Status == ui.CheckFail
+		checks = append(checks, check)
+	}
+
+	if format != output.Table {
+		printResult(format, checkResults("reservations", checks))
+	} else {
+		ui.DisplayChecks(ui.Icon("🔒 ", "")+"Ports reserved for "+project+":", checks)
+		fmt.Println()
+		if failed {
+			ui.ErrorMsg("Some reserved ports are taken by other processes")
+		} else {
+			ui.SuccessMsg("All reserved ports are yours")
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// stopPlaceholder stops a process holding a reservation, unless the config
+// protects it, and waits for its port to be free
+func stopPlaceholder(ctx context.Context, cfg *config.Config, finder process.Finder, p *process.Process) error {
+	if err := protectionGuard(cfg)(p); err != nil {
+		return fmt.Errorf("not stopping the placeholder (PID %d): %v", p.PID, err)
+	}
+	strategy := process.SelectStrategy(p)
+	if err := p.KillWith(strategy); err != nil {
+		return fmt.Errorf("cannot stop the placeholder (PID %d): %v", p.PID, err)
+	}
+	recordKill(cfg, p, strategy)
+	results, err := waitForPorts(ctx, finder, []int{p.Port}, true, placeholderTimeout, placeholderTimeout/10)
+	if err != nil {
+		return err
+	}
+	if results == nil {
+		return fmt.Errorf("the placeholder (PID %d) did not let go of the port", p.PID)
+	}
+	return nil
+}
+
+// showReservations lists the reservations with what holds each port
+func showReservations(cmd *cobra.Command) {
+	format := outputFormat(cmd)
+	cfg := config.Load()
+	ports := reservedPorts(cfg)
+
+	holders := map[int]*process.Process{}
+	if len(ports) > 0 {
+		var err error
+		holders, err = newFinderWithConfig(cfg).FindByPorts(cmd.Context(), ports)
+		if err != nil {
+			ui.ErrorMsg("Error checking ports: %v", err)
+			os.Exit(1)
+		}
+	}
+
+	checks := make([]ui.Check, 0, len(ports))
+	for _, port := range ports {
+		project := cfg.Reservations[port]
+		check := ui.Check{Name: fmt.Sprintf("%d for %s", port, project)}
+		switch p := holders[port]; {
+		case p == nil:
+			check.Status, check.Detail = ui.CheckOK, "free"
+		case isPlaceholder(p):
+			check.Status, check.Detail = ui.CheckOK, fmt.Sprintf("held by a placeholder (PID %d)", p.PID)
+		case holdsReservation(p, project):
+			check.Status, check.Detail = ui.CheckOK, fmt.Sprintf("served by %s (PID %d)", p.Name, p.PID)
+		default:
+			check.Status, check.Detail = ui.CheckWarn, fmt.Sprintf("taken by %s (PID %d)", p.Name, p.PID)
+		}
+		checks = append(checks, check)
+	}
+
+	if format != output.Table {
+		printResult(format, checkResults("reservations", checks))
+		return
+	}
+	if len(checks) == 0 {
+		ui.InfoMsg("No ports are reserved")
+		return
+	}
+	ui.DisplayChecks(ui.Icon("🔒 ", "")+"Reserved ports:", checks)
+}
+
+// holdPorts listens on the ports, refusing every connection, until the
+// reservation is released or pf is interrupted
+func holdPorts(ctx context.Context, project string, ports []int) {
+	listeners := make(map[int]net.Listener)
+	for _, port := range ports {
+		l, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+		if err != nil {
+			ui.WarnMsg("Cannot hold port %d: %v", port, err)
+			continue
+		}
+		listeners[port] = l
+		go func() {
+			for {
+				conn, err := l.Accept()
+				if err != nil {
+					return
+				}
+				conn.Close()
+			}
+		}()
+	}
+	if len(listeners) == 0 {
+		os.Exit(1)
+	}
+	held := make([]int, 0, len(listeners))
+	for port := range listeners {
+		held = append(held, port)
+	}
+	if err := recordPlaceholder(held, true); err != nil {
+		ui.WarnMsg("Cannot record the held ports, claim won't stop this placeholder: %v", err)
+	}
+	defer recordPlaceholder(held, false)
+
+	ui.InfoMsg("Holding the ports until they are released ('portfinder release' or 'portfinder claim') or Ctrl+C")
+
+	ticker := time.NewTicker(holdInterval)
+	defer ticker.Stop()
+	for len(listeners) > 0 {
+		select {
+		case <-ctx.Done():
+			for _, l := range listeners {
+				l.Close()
+			}
+			return
+		case <-ticker.C:
+		}
+
+		reservations := config.Load().Reservations
+		for port, l := range listeners {
+			if reservations[port] != project {
+				l.Close()
+				delete(listeners, port)
+			}
+		}
+	}
+}
+
+// warnReservations warns about reserved ports taken by processes of other
+// projects
+func warnReservations(cfg *config.Config, holders map[int]*process.Process) {
+	for _, port := range reservedPorts(cfg) {
+		project := cfg.Reservations[port]
+		if p := holders[port]; p != nil && !isPlaceholder(p) && !holdsReservation(p, project) {
+			ui.WarnMsg("Port %d is reserved for %s but taken by %s (PID %d)", port, project, p.Name, p.PID)
+		}
+	}
+}
+
+// reservationLabels adds the project a port is reserved for to the labels
+// of the ports, flagging the ones taken by another process
+func reservationLabels(cfg *config.Config, holders map[int]*process.Process) map[int]string {
+	labels := make(map[int]string, len(cfg.Labels)+len(cfg.Reservations))
+	for port, label := range cfg.Labels {
+		labels[port] = label
+	}
+	for port, project := range cfg.Reservations {
+		if labels[port] != "" {
+			continue
+		}
+		labels[port] = "reserved: " + filepath.Base(project)
+		if p := holders[port]; p != nil && !isPlaceholder(p) && !holdsReservation(p, project) {
+			labels[port] = "⚠ " + labels[port]
+		}
+	}
+	return labels
+}
+
+// reservedPorts returns the reserved ports in order
+func reservedPorts(cfg *config.Config) []int {
+	ports := make([]int, 0, len(cfg.Reservations))
+	for port := range cfg.Reservations {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+	return ports
+}
+
+// projectFlag returns the --project flag, defaulting to the repository of
+// the working directory
+func projectFlag(cmd *cobra.Command) string {
+	if project, _ := cmd.Flags().GetString("project"); project != "" {
+		return project
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		ui.ErrorMsg("Error reading working directory: %v", err)
+		os.Exit(1)
+	}
+	return repositoryRoot(cwd)
+}
+
+// holdsReservation reports whether the process belongs to the project a
+// port is reserved for, which may be a name or a directory
+func holdsReservation(p *process.Process, project string) bool {
+	if p.SocketOnly {
+		return false
+	}
+	if p.ComposeProject != "" {
+		return p.ComposeProject == filepath.Base(project)
+	}
+	return p.InProject(project)
+}
+
+// isPlaceholder reports whether the process is pf holding a reservation:
+// the process that recorded holding the port, running this executable
+func isPlaceholder(p *process.Process) bool {
+	if p.PID <= 0 || placeholderPIDs()[p.Port] != p.PID || p.ExePath == "" {
+		return false
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return false
+	}
+	selfInfo, err1 := os.Stat(self)
+	exeInfo, err2 := os.Stat(p.ExePath)
+	return err1 == nil && err2 == nil && os.SameFile(selfInfo, exeInfo)
+}
+
+// placeholdersPath is the file where placeholders record the ports they
+// hold, next to the history
+func placeholdersPath() string {
+	return filepath.Join(filepath.Dir(history.Path()), "placeholders.json")
+}
+
+// placeholderPIDs returns the PIDs of the placeholders by the port they
+// hold
+func placeholderPIDs() map[int]int {
+	pids := make(map[int]int)
+	if data, err := os.ReadFile(placeholdersPath()); err == nil {
+		json.Unmarshal(data, &pids)
+	}
+	return pids
+}
+
+// recordPlaceholder records this process as holding the ports, or when
+// hold is false forgets the ones it recorded
+func recordPlaceholder(ports []int, hold bool) error {
+	pids := placeholderPIDs()
+	for _, port := range ports {
+		switch {
+		case hold:
+			pids[port] = os.Getpid()
+		case pids[port] == os.Getpid():
+			delete(pids, port)
+		}
+	}
+
+	data, err := json.Marshal(pids)
+	if err != nil {
+		return err
+	}
+	path := placeholdersPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// sameProject reports whether a reservation's project is the given one,
+// matching a bare name against the name of a directory
+func sameProject(reserved, project string) bool {
+	if reserved == project {
+		return true
+	}
+	if !strings.ContainsAny(reserved, `/\~`) {
+		return reserved == filepath.Base(project)
+	}
+	return filepath.Clean(reserved) == filepath.Clean(project)
+}
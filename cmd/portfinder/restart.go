@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/doganarif/portfinder/internal/config"
+	"github.com/doganarif/portfinder/internal/history"
+	"github.com/doganarif/portfinder/internal/process"
+	"github.com/doganarif/portfinder/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// portReleaseTimeout bounds how long restart waits for a killed process to
+// release its port
+const portReleaseTimeout = 5 * time.Second
+
+// restartArgs accepts a port, optionally followed by -- and a command
+func restartArgs(cmd *cobra.Command, args []string) error {
+	dash := cmd.ArgsLenAtDash()
+	if dash == 0 || len(args) == 0 {
+		return errors.New("requires a port")
+	}
+	if dash > 1 || (dash < 0 && len(args) > 1) {
+		return errors.New("pass the command to run after --, e.g. restart 3000 -- npm run dev")
+	}
+	return nil
+}
+
+func runRestart(cmd *cobra.Command, args []string) {
+	port, err := strconv.Atoi(args[0])
+	if err != nil {
+		ui.ErrorMsg("Invalid port number: %s", args[0])
+		os.Exit(1)
+	}
+
+	var command []string
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		command = args[dash:]
+	}
+
+	cfg := config.Load()
+	finder := newFinderWithConfig(cfg)
+	proc, err := finder.FindByPort(cmd.Context(), port)
+	if err != nil {
+		ui.ErrorMsg("Error checking port: %v", err)
+		os.Exit(1)
+	}
+
+	// Without an explicit command, relaunch what was running: the current
+	// occupant, or the last process killed from the port. Its arguments
+	// are run as they were, never through a shell.
+	var dir string
+	if proc != nil {
+		dir = proc.WorkDir
+		if len(command) == 0 && proc.IsDocker {
+			ui.ErrorMsg("Port %d is published by a container; use docker restart or pass the command after --", port)
+			os.Exit(1)
+		}
+		if len(command) == 0 {
+			if command = proc.Args; len(command) == 0 {
+				ui.ErrorMsg("Cannot read the arguments of PID %d; pass the command after --", proc.PID)
+				os.Exit(1)
+			}
+		}
+	} else if len(command) == 0 {
+		var line string
+		command, line, dir = lastKilledCommand(port)
+		switch {
+		case line == "":
+			ui.ErrorMsg("Port %d is not in use and no command to restart is known; pass it after --", port)
+			os.Exit(1)
+		case len(command) == 0:
+			ui.ErrorMsg("The last command killed from port %d was recorded without its arguments (%s); pass it after --", port, line)
+			os.Exit(1)
+		}
+	}
+
+	if proc != nil {
+		refuseProtected(cmd, cfg, proc)
+		if skip, _ := cmd.Flags().GetBool("yes"); cfg.ConfirmKill && !skip {
+			if !ui.SimpleConfirm(fmt.Sprintf("Kill %s (PID: %d) on port %d and start it again?", proc.Name, proc.PID, port)) {
+				ui.InfoMsg("Aborted")
+				return
+			}
+		}
+		strategy, err := killStrategy(cmd, proc)
+		if err != nil {
+			ui.ErrorMsg("%v", err)
+			os.Exit(1)
+		}
+		if err := proc.KillWith(strategy); err != nil {
+			ui.ErrorMsg("Failed to kill process: %v", err)
+			os.Exit(1)
+		}
+		recordKill(cfg, proc, strategy)
+		ui.SuccessMsg("Killed process %s (PID: %d) on port %d", proc.Name, proc.PID, port)
+
+		if err := waitForRelease(cmd.Context(), finder, port); err != nil {
+			ui.ErrorMsg("%v", err)
+			os.Exit(1)
+		}
+	}
+
+	launch := exec.Command(command[0], command[1:]...)
+	ui.InfoMsg("Starting: %s", strings.Join(command, " "))
+
+	if dir != "" {
+		if _, err := os.Stat(dir); err == nil {
+			launch.Dir = dir
+		}
+	}
+	launch.Stdin = os.Stdin
+	launch.Stdout = os.Stdout
+	launch.Stderr = os.Stderr
+
+	if err := launch.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
+		ui.ErrorMsg("Failed to start: %v", err)
+		os.Exit(1)
+	}
+}
+
+// lastKilledCommand returns the arguments, command line and working
+// directory of the last process killed from the port, as recorded in the
+// history. Events recorded by older versions have no arguments.
+func lastKilledCommand(port int) ([]string, string, string) {
+	events, err := history.Load(port)
+	if err != nil {
+		return nil, "", ""
+	}
+
+	for i := len(events) - 1; i >= 0; i-- {
+		if e := events[i]; e.Event == history.EventKilled && e.Command != "" {
+			return e.Args, e.Command, e.WorkDir
+		}
+	}
+
+	return nil, "", ""
+}
+
+// waitForRelease polls until nothing listens on the port
+func waitForRelease(ctx context.Context, finder process.Finder, port int) error {
+	deadline := time.Now().Add(portReleaseTimeout)
+	for time.Now().Before(deadline) && ctx.Err() == nil {
+		process.Invalidate(finder)
+		if proc, err := finder.FindByPort(ctx, port); err == nil && proc == nil {
+			return nil
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	return fmt.Errorf("port %d is still in use after %s", port, portReleaseTimeout)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+
+	"github.com/doganarif/portfinder/internal/output"
+	"github.com/doganarif/portfinder/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// newScanCmd builds the scan command
+func newScanCmd() *cobra.Command {
+	scanCmd := &cobra.Command{
+		Use:   "scan [range]",
+		Short: "Summarize what listens on a port range, grouped by process",
+		Long: `List every listener on a port range, all ports by default, in a single
+scan of the system. Listeners are grouped by process, with a count of each
+kind of process and of those reachable from the network.
+
+Examples:
+  portfinder scan 1-10000
+  portfinder scan 3000-9000 --output json`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  runScan,
+	}
+	addOutputFlag(scanCmd)
+	return scanCmd
+}
+
+func runScan(cmd *cobra.Command, args []string) {
+	format := outputFormat(cmd)
+	low, high := 1, 65535
+	if len(args) == 1 {
+		var err error
+		if low, high, err = parseRange(args[0]); err != nil {
+			ui.ErrorMsg("%v", err)
+			os.Exit(1)
+		}
+	}
+
+	processes, err := newFinder().ListAll(cmd.Context())
+	if err != nil {
+		ui.ErrorMsg("Error listing processes: %v", err)
+		os.Exit(1)
+	}
+	summary := ui.SummarizeScan(processes, low, high)
+
+	if format != output.Table {
+		printResult(format, summary)
+		return
+	}
+	timeRender(func() { ui.DisplayScan(summary) })
+}
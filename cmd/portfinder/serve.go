@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+
+	"github.com/doganarif/portfinder/internal/config"
+	"github.com/doganarif/portfinder/internal/process"
+	"github.com/doganarif/portfinder/internal/server"
+	"github.com/doganarif/portfinder/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func runServe(cmd *cobra.Command, args []string) {
+	addr, _ := cmd.Flags().GetString("listen")
+
+	cfg := config.Load()
+	srv := server.New(newFinderWithConfig(cfg), func(p *process.Process, strategy process.KillStrategy) {
+		recordKill(cfg, p, strategy)
+	})
+	srv.SetKillGuard(protectionGuard(cfg))
+
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = cfg.APIToken
+	}
+	generated := token == ""
+	if generated {
+		token = server.NewToken()
+	}
+	srv.SetToken(token)
+
+	watchInBackground(cmd.Context(), cfg)
+
+	ui.InfoMsg("Serving portfinder API on http://%s", addr)
+	ui.InfoMsg("Send \"Authorization: Bearer %s\" with every request", token)
+	if generated {
+		ui.InfoMsg("The token changes on every start; set api_token or pass --token to keep one")
+	}
+	if err := srv.ListenAndServe(cmd.Context(), addr); err != nil {
+		ui.ErrorMsg("Server error: %v", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/doganarif/portfinder/internal/output"
+	"github.com/doganarif/portfinder/internal/process"
+	"github.com/doganarif/portfinder/internal/snapshot"
+	"github.com/doganarif/portfinder/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// newSnapshotCmd builds the snapshot command and its subcommands
+func newSnapshotCmd() *cobra.Command {
+	snapshotCmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Save the ports in use and later see what changed",
+		Long: `Save the current port/process map to a file, and later diff the
+ports in use against it to see which listeners appeared, vanished or
+changed in between.
+
+Examples:
+  portfinder snapshot save dev.json
+  portfinder snapshot diff dev.json`,
+	}
+
+	saveCmd := &cobra.Command{
+		Use:   "save <file>",
+		Short: "Save the ports in use to a file",
+		Args:  cobra.ExactArgs(1),
+		Run:   runSnapshotSave,
+	}
+
+	diffCmd := &cobra.Command{
+		Use:   "diff <file>",
+		Short: "Show the listeners that changed since a snapshot",
+		Args:  cobra.ExactArgs(1),
+		Run:   runSnapshotDiff,
+	}
+	addOutputFlag(diffCmd)
+
+	snapshotCmd.AddCommand(saveCmd, diffCmd)
+	return snapshotCmd
+}
+
+func runSnapshotSave(cmd *cobra.Command, args []string) {
+	processes := listProcesses(cmd.Context())
+	if err := snapshot.New(processes).Save(args[0]); err != nil {
+		ui.ErrorMsg("Error saving snapshot: %v", err)
+		os.Exit(1)
+	}
+	ui.SuccessMsg("Saved %d listeners to %s", len(processes), args[0])
+}
+
+func runSnapshotDiff(cmd *cobra.Command, args []string) {
+	format := outputFormat(cmd)
+	saved, err := snapshot.Load(args[0])
+	if err != nil {
+		ui.ErrorMsg("Error reading snapshot: %v", err)
+		os.Exit(1)
+	}
+
+	changes := saved.Diff(listProcesses(cmd.Context()))
+	if format != output.Table {
+		printResult(format, changes)
+		return
+	}
+	ui.DisplaySnapshotDiff(saved.Time, changes)
+}
+
+// listProcesses lists every listener, exiting on failure
+func listProcesses(ctx context.Context) []*process.Process {
+	processes, err := newFinder().ListAll(ctx)
+	if err != nil {
+		ui.ErrorMsg("Error listing processes: %v", err)
+		os.Exit(1)
+	}
+	return processes
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/doganarif/portfinder/internal/output"
+	"github.com/doganarif/portfinder/internal/process"
+	"github.com/doganarif/portfinder/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// newSocketsCmd builds the sockets command
+func newSocketsCmd() *cobra.Command {
+	socketsCmd := &cobra.Command{
+		Use:   "sockets",
+		Short: "List Unix domain sockets and stale socket files",
+		Long: `List the Unix domain sockets processes listen on, such as docker.sock,
+Postgres's .s.PGSQL.5432 or php-fpm.sock, with the owner and permissions of
+each socket file. Socket files nothing listens on are flagged as stale: a
+server binding one fails with "address already in use" until it is deleted.
+
+Examples:
+  portfinder sockets
+  portfinder sockets --output json`,
+		Args: cobra.NoArgs,
+		Run:  runSockets,
+	}
+	addOutputFlag(socketsCmd)
+
+	cleanCmd := &cobra.Command{
+		Use:   "clean [path...]",
+		Short: "Remove stale socket files",
+		Long: `Remove socket files nothing listens on, the ones pf sockets flags as stale,
+or the given ones, after confirmation. A server such as gunicorn or php-fpm
+that crashed leaves its socket file behind, and fails with "address already
+in use" on restart until it is gone.
+
+Examples:
+  portfinder sockets clean
+  portfinder sockets clean /run/php/php8.2-fpm.sock --yes`,
+		Run: runSocketsClean,
+	}
+	cleanCmd.Flags().BoolP("yes", "y", false, "Skip the confirmation prompt")
+	cleanCmd.Flags().Bool("dry-run", false, "Show which socket files would be removed without removing them")
+	socketsCmd.AddCommand(cleanCmd)
+	return socketsCmd
+}
+
+func runSockets(cmd *cobra.Command, args []string) {
+	format := outputFormat(cmd)
+	sockets, err := process.UnixSockets(cmd.Context())
+	if err != nil {
+		ui.ErrorMsg("Error listing Unix sockets: %v", err)
+		os.Exit(1)
+	}
+	if format != output.Table {
+		printResult(format, sockets)
+		return
+	}
+	timeRender(func() { ui.DisplayUnixSockets(sockets) })
+}
+
+func runSocketsClean(cmd *cobra.Command, args []string) {
+	var stale []process.UnixSocket
+	if len(args) > 0 {
+		for _, path := range args {
+			if !process.StaleSocket(path) {
+				ui.WarnMsg("Skipping %s: not a socket file, or something listens on it", path)
+				continue
+			}
+			stale = append(stale, process.UnixSocket{Path: path, Stale: true})
+		}
+	} else {
+		sockets, err := process.UnixSockets(cmd.Context())
+		if err != nil {
+			ui.ErrorMsg("Error listing Unix sockets: %v", err)
+			os.Exit(1)
+		}
+		for _, s := range sockets {
+			if s.Stale {
+				stale = append(stale, s)
+			}
+		}
+	}
+	if len(stale) == 0 {
+		ui.SuccessMsg("No stale socket files found")
+		return
+	}
+
+	ui.DisplayUnixSockets(stale)
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		ui.InfoMsg("Dry run: nothing was removed")
+		return
+	}
+	if skip, _ := cmd.Flags().GetBool("yes"); !skip {
+		if !ui.SimpleConfirm(fmt.Sprintf("Remove %d stale socket file(s)?", len(stale))) {
+			ui.InfoMsg("Aborted")
+			return
+		}
+	}
+
+	failed := 0
+	for _, s := range stale {
+		if err := process.RemoveStaleSocket(s.Path); err != nil {
+			ui.ErrorMsg("Could not remove %s: %v", s.Path, err)
+			failed++
+			continue
+		}
+		ui.SuccessMsg("Removed %s", s.Path)
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/doganarif/portfinder/internal/process"
+	"github.com/doganarif/portfinder/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// statsFinder is the finder whose timings --verbose reports
+	statsFinder process.Finder
+	// renderTime accumulates the time spent printing results
+	renderTime time.Duration
+	// jsonStats makes --verbose report timings as JSON, for commands
+	// whose output is machine readable
+	jsonStats bool
+)
+
+// timeRender runs fn and counts its duration as rendering time. Only
+// non-interactive output is timed, since TUIs wait on the user.
+func timeRender(fn func()) {
+	start := time.Now()
+	fn()
+	renderTime += time.Since(start)
+}
+
+// reportStats prints per-phase timings to stderr when --verbose is set
+func reportStats(cmd *cobra.Command, args []string) {
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	debug, _ := cmd.Flags().GetBool("debug")
+	if !verbose && !debug || statsFinder == nil {
+		return
+	}
+
+	stats, ok := process.FinderStats(statsFinder)
+	if !ok {
+		return
+	}
+	stats.Render = renderTime
+
+	if jsonStats {
+		json.NewEncoder(os.Stderr).Encode(struct {
+			Stats process.Stats `json:"stats"`
+		}{stats})
+		return
+	}
+
+	dot := ui.Icon("·", "-")
+	fmt.Fprintf(os.Stderr, "%sdiscovery %s %s docker %s %s enrichment %s %s render %s\n", ui.Icon("⏱  ", ""),
+		roundDuration(stats.Discovery), dot, roundDuration(stats.Docker), dot, roundDuration(stats.Enrichment), dot, roundDuration(stats.Render))
+	fmt.Fprintf(os.Stderr, "   %d lookups, %d processes %s cache %d hits, %d misses\n",
+		stats.Calls, stats.Processes, dot, stats.CacheHits, stats.CacheMisses)
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	if d > time.Second {
+		return d.Round(time.Millisecond)
+	}
+	return d.Round(10 * time.Microsecond)
+}
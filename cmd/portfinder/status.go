@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/doganarif/portfinder/internal/advisor"
+	"github.com/doganarif/portfinder/internal/config"
+	"github.com/doganarif/portfinder/internal/docker"
+	"github.com/doganarif/portfinder/internal/output"
+	"github.com/doganarif/portfinder/internal/process"
+	"github.com/doganarif/portfinder/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// probeTimeout bounds each health probe
+const probeTimeout = time.Second
+
+func runStatus(cmd *cobra.Command, args []string) {
+	format := outputFormat(cmd)
+	cwd, err := os.Getwd()
+	if err != nil {
+		ui.ErrorMsg("Error reading working directory: %v", err)
+		os.Exit(1)
+	}
+	root := repositoryRoot(cwd)
+
+	cfg := config.Load()
+	finder := newFinderWithConfig(cfg)
+	processes, err := finder.ListAll(cmd.Context())
+	if err != nil {
+		ui.ErrorMsg("Error listing ports: %v", err)
+		os.Exit(1)
+	}
+	recordSeen(cfg, processes...)
+
+	byPort := make(map[int]*process.Process)
+	for _, p := range processes {
+		byPort[p.Port] = p
+	}
+
+	failed := false
+	expectations := advisor.Expectations(root)
+	portChecks := make([]ui.Check, 0, len(expectations))
+	for _, e := range expectations {
+		check := expectationCheck(e, byPort[e.Port], root)
+		failed = failed || check.Status == ui.CheckFail
+		portChecks = append(portChecks, check)
+	}
+	if len(portChecks) == 0 {
+		portChecks = append(portChecks, ui.Check{
+			Status: ui.CheckSkip,
+			Name:   "No expected ports found",
+			Detail: "none of .env, package.json, Procfile, dev container, vite, compose or Spring configs declare a port",
+		})
+	}
+
+	var serviceChecks []ui.Check
+	if compose := advisor.ComposeFile(root); compose != "" {
+		serviceChecks = composeChecks(compose, root)
+		for _, check := range serviceChecks {
+			failed = failed || check.Status == ui.CheckFail
+		}
+	}
+
+	if format != output.Table {
+		printResult(format, append(checkResults("ports", portChecks), checkResults("services", serviceChecks)...))
+		if failed {
+			os.Exit(1)
+		}
+		return
+	}
+
+	ui.InfoMsg("Status of %s", root)
+	ui.DisplayChecks(ui.Icon("🔌 ", "")+"Project ports:", portChecks)
+	if serviceChecks != nil {
+		ui.DisplayChecks(ui.Icon("🐳 ", "")+"Compose services:", serviceChecks)
+	}
+
+	fmt.Println()
+	if failed {
+		ui.ErrorMsg("Environment is not ready")
+		os.Exit(1)
+	}
+	ui.SuccessMsg("Environment is ready")
+}
+
+// expectationCheck grades an expected port: free ports are ready to be
+// used, ports held by the project itself are probed for health, and ports
+// held by anything else are conflicts
+func expectationCheck(e advisor.Expectation, proc *process.Process, root string) ui.Check {
+	name := fmt.Sprintf("%d", e.Port)
+	if e.Service != "" {
+		name += " (" + e.Service + ")"
+	}
+	check := ui.Check{Name: name}
+
+	switch {
+	case proc == nil:
+		check.Status = ui.CheckOK
+		check.Detail = "free, from " + e.Source
+	case proc.SocketOnly:
+		check.Status = ui.CheckWarn
+		check.Detail = "in use by a process that can't be identified here"
+	case ownedBy(proc, root):
+		check.Status = ui.CheckOK
+		check.Detail = fmt.Sprintf("%s is running", processLabel(proc))
+		if health, err := probe(e.Port); err != nil {
+			check.Status = ui.CheckWarn
+			check.Detail += " but does not respond: " + err.Error()
+		} else {
+			check.Detail += ", " + health
+		}
+	default:
+		check.Status = ui.CheckFail
+		check.Detail = fmt.Sprintf("taken by %s (PID %d), needed by %s", processLabel(proc), proc.PID, e.Source)
+	}
+
+	return check
+}
+
+// ownedBy reports whether the process belongs to the repository, either by
+// running from inside it or as a container of its compose project
+func ownedBy(p *process.Process, root string) bool {
+	if p.ComposeProject != "" {
+		return p.ComposeProject == composeProject(root)
+	}
+	return p.InProject(root)
+}
+
+func processLabel(p *process.Process) string {
+	if p.ContainerName != "" {
+		return "container " + p.ContainerName
+	}
+	return p.Name
+}
+
+// probe checks that the port accepts connections and, when it speaks
+// HTTP, reports the response status
+func probe(port int) (string, error) {
+	addr := fmt.Sprintf("127.0.0.1:%d", port)
+	conn, err := net.DialTimeout("tcp", addr, probeTimeout)
+	if err != nil {
+		return "", err
+	}
+	conn.Close()
+
+	client := &http.Client{Timeout: probeTimeout}
+	resp, err := client.Get("http://" + addr + "/")
+	if err != nil {
+		return "accepting connections", nil
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return "", fmt.Errorf("HTTP %s", resp.Status)
+	}
+	return "HTTP " + resp.Status, nil
+}
+
+// composeChecks reports the state of every service in the compose file
+func composeChecks(path, root string) []ui.Check {
+	services := advisor.ComposeServices(path)
+	if len(services) == 0 {
+		return []ui.Check{{Status: ui.CheckSkip, Name: "No services declared", Detail: path}}
+	}
+
+	client, err := docker.NewClient()
+	if err != nil {
+		return []ui.Check{{Status: ui.CheckSkip, Name: "Docker is not reachable", Detail: "run 'portfinder doctor' for details"}}
+	}
+	project := composeProject(root)
+	containers, err := client.ComposeContainers(project)
+	if err != nil {
+		return []ui.Check{{Status: ui.CheckWarn, Name: "Failed to list containers", Detail: err.Error()}}
+	}
+
+	states := make(map[string]docker.Container)
+	for _, c := range containers {
+		states[c.ComposeService] = c
+	}
+
+	checks := make([]ui.Check, 0, len(services))
+	for _, service := range services {
+		check := ui.Check{Name: service}
+		c, ok := states[service]
+		switch {
+		case !ok:
+			check.Status = ui.CheckSkip
+			check.Detail = "not created (docker compose up " + service + ")"
+		case c.State == "running":
+			check.Status = ui.CheckOK
+			check.Detail = c.Name + " is running"
+		case c.State == "restarting":
+			check.Status = ui.CheckWarn
+			check.Detail = c.Name + " is restarting"
+		default:
+			check.Status = ui.CheckFail
+			check.Detail = c.Name + " is " + c.State
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+var composeNameInvalid = regexp.MustCompile(`[^a-z0-9_-]`)
+
+// composeProject returns the compose project name of the repository the
+// way docker compose derives it
+func composeProject(root string) string {
+	if name := os.Getenv("COMPOSE_PROJECT_NAME"); name != "" {
+		return name
+	}
+	return composeNameInvalid.ReplaceAllString(strings.ToLower(filepath.Base(root)), "")
+}
+
+// repositoryRoot walks up from dir to the enclosing git repository, falling
+// back to dir itself outside of one
+func repositoryRoot(dir string) string {
+	for current := dir; ; {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return current
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir
+		}
+		current = parent
+	}
+}
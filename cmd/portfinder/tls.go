@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/doganarif/portfinder/internal/certs"
+	"github.com/doganarif/portfinder/internal/output"
+	"github.com/doganarif/portfinder/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// tlsTimeout bounds the TLS handshake
+const tlsTimeout = 5 * time.Second
+
+// newTLSCmd builds the tls command
+func newTLSCmd() *cobra.Command {
+	tlsCmd := &cobra.Command{
+		Use:   "tls <port>",
+		Short: "Show the TLS certificate a local listener presents",
+		Long: `Connect to the listener on a port and show the certificate chain it
+presents: subject, SANs, issuer, expiry, whether it is self-signed and
+whether this system trusts it for the server name.
+
+Examples:
+  portfinder tls 8443
+  portfinder tls 8443 --server-name myapp.test`,
+		Args: cobra.ExactArgs(1),
+		Run:  runTLS,
+	}
+	tlsCmd.Flags().String("server-name", "localhost", "Name to send in the handshake and verify the certificate for")
+	addOutputFlag(tlsCmd)
+	return tlsCmd
+}
+
+func runTLS(cmd *cobra.Command, args []string) {
+	port, err := strconv.Atoi(args[0])
+	if err != nil {
+		ui.ErrorMsg("Invalid port number: %s", args[0])
+		os.Exit(1)
+	}
+	format := outputFormat(cmd)
+	serverName, _ := cmd.Flags().GetString("server-name")
+
+	proc, err := newFinder().FindByPort(cmd.Context(), port)
+	if err != nil {
+		ui.ErrorMsg("Error checking port: %v", err)
+		os.Exit(1)
+	}
+	// The listener may be hidden from pf, yet still answer on loopback
+	address := net.JoinHostPort("127.0.0.1", args[0])
+	if proc != nil {
+		address = proc.DialAddress()
+	}
+
+	chain, err := certs.Fetch(address, serverName, tlsTimeout)
+	if err != nil {
+		ui.ErrorMsg("%v", err)
+		os.Exit(1)
+	}
+	if format != output.Table {
+		printResult(format, chain)
+		return
+	}
+	ui.DisplayCertificates(proc, chain)
+}
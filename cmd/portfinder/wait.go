@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/doganarif/portfinder/internal/config"
+	"github.com/doganarif/portfinder/internal/output"
+	"github.com/doganarif/portfinder/internal/process"
+	"github.com/doganarif/portfinder/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// exitTimeout is the status wait exits with when the deadline passes, told
+// apart from 1 for errors so scripts can react to each
+const exitTimeout = 2
+
+func runWait(cmd *cobra.Command, args []string) {
+	format := outputFormat(cmd)
+	ports := parsePorts(args)
+
+	free, _ := cmd.Flags().GetBool("free")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	interval, _ := cmd.Flags().GetDuration("interval")
+	if interval <= 0 {
+		ui.ErrorMsg("--interval must be positive")
+		os.Exit(1)
+	}
+
+	state := "in use"
+	if free {
+		state = "free"
+	}
+	if format == output.Table {
+		ui.InfoMsg("Waiting for %s to be %s...", portList(ports), state)
+	}
+
+	cfg := config.Load()
+	finder := newFinderWithConfig(cfg)
+	results, err := waitForPorts(cmd.Context(), finder, ports, free, timeout, interval)
+	if err != nil {
+		ui.ErrorMsg("Error checking ports: %v", err)
+		os.Exit(1)
+	}
+	if results == nil {
+		ui.ErrorMsg("Timed out after %s waiting for %s to be %s", timeout, portList(ports), state)
+		os.Exit(exitTimeout)
+	}
+
+	if format != output.Table {
+		printResult(format, results)
+		return
+	}
+	for _, r := range results {
+		if r.InUse {
+			ui.SuccessMsg("Port %d is in use by %s (PID: %d)", r.Port, r.Name, r.PID)
+		} else {
+			ui.SuccessMsg("Port %d is free", r.Port)
+		}
+	}
+}
+
+// waitForPorts polls until every port is free, or every port is in use,
+// returning their final state, or nil when the timeout passes first. A
+// zero timeout waits forever.
+func waitForPorts(ctx context.Context, finder process.Finder, ports []int, free bool, timeout, interval time.Duration) ([]portResult, error) {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+
+	for {
+		process.Invalidate(finder)
+		found, err := finder.FindByPorts(ctx, ports)
+		if err != nil {
+			return nil, err
+		}
+		results := make([]portResult, 0, len(ports))
+		for _, port := range ports {
+			proc := found[port]
+			if (proc == nil) != free {
+				break
+			}
+			results = append(results, portResult{Port: port, InUse: proc != nil, Process: proc})
+		}
+		if len(results) == len(ports) {
+			return results, nil
+		}
+
+		wait := interval
+		if !deadline.IsZero() {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				return nil, nil
+			}
+			// Check once more right at the deadline
+			wait = min(wait, remaining)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		case <-time.After(wait):
+		}
+	}
+}
+
+// portList joins ports for messages, e.g. "port 3000" or "ports 3000, 5432"
+func portList(ports []int) string {
+	names := make([]string, len(ports))
+	for i, port := range ports {
+		names[i] = strconv.Itoa(port)
+	}
+	if len(ports) == 1 {
+		return "port " + names[0]
+	}
+	return "ports " + strings.Join(names, ", ")
+}
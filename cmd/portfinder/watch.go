@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/doganarif/portfinder/internal/config"
+	"github.com/doganarif/portfinder/internal/notify"
+	"github.com/doganarif/portfinder/internal/process"
+	"github.com/doganarif/portfinder/internal/ui"
+	"github.com/doganarif/portfinder/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+// serveWatchInterval is how often serve polls the ports of notification
+// rules and hooks
+const serveWatchInterval = 2 * time.Second
+
+// hooksRunning tracks the hooks started for events, so watch lets them
+// finish before exiting
+var hooksRunning sync.WaitGroup
+
+func runWatch(cmd *cobra.Command, args []string) {
+	ports := parsePorts(args)
+	interval, _ := cmd.Flags().GetDuration("interval")
+	if interval <= 0 {
+		ui.ErrorMsg("--interval must be positive")
+		os.Exit(1)
+	}
+
+	cfg := config.Load()
+	rules := cfg.Notifications
+	if notifyAll, _ := cmd.Flags().GetBool("notify"); notifyAll {
+		rules = append(rules, watch.Rule{})
+	}
+
+	target := "all ports"
+	if len(ports) > 0 {
+		target = portList(ports)
+	}
+	ui.InfoMsg("Watching %s, press Ctrl+C to stop", target)
+
+	err := watch.Watch(cmd.Context(), newFinderWithConfig(cfg), ports, interval, func(e watch.Event) {
+		fmt.Printf("%s  %s\n", e.Time.Format("15:04:05"), e)
+		handleEvent(rules, cfg.Hooks, e)
+	})
+	hooksRunning.Wait()
+	if err != nil {
+		ui.ErrorMsg("Error watching ports: %v", err)
+		os.Exit(1)
+	}
+}
+
+// watchInBackground notifies of the events the config's rules select, and
+// runs its hooks, until ctx is done, as serve does
+func watchInBackground(ctx context.Context, cfg *config.Config) {
+	if len(cfg.Notifications) == 0 && len(cfg.Hooks) == 0 {
+		return
+	}
+	rules := append(append([]watch.Rule{}, cfg.Notifications...), watch.HookRules(cfg.Hooks)...)
+
+	// A finder of its own, so polling doesn't flush the server's cache
+	finder := process.NewFinder(process.WithRules(cfg.ClassificationRules), process.WithTimeout(lookupTimeout))
+	go func() {
+		err := watch.Watch(ctx, finder, watch.Ports(rules), serveWatchInterval, func(e watch.Event) {
+			handleEvent(cfg.Notifications, cfg.Hooks, e)
+		})
+		if err != nil {
+			ui.WarnMsg("Port notifications and hooks are off: %v", err)
+		}
+	}()
+}
+
+// handleEvent shows a desktop notification when a rule selects the event,
+// and starts the hooks selecting it without holding up the next poll
+func handleEvent(rules []watch.Rule, hooks []watch.Hook, e watch.Event) {
+	for _, rule := range rules {
+		if !rule.Matches(e) {
+			continue
+		}
+		if err := notify.Send("portfinder", e.String()); err != nil {
+			ui.WarnMsg("Notification failed: %v", err)
+		}
+		break
+	}
+
+	for _, hook := range hooks {
+		if !hook.Matches(e) {
+			continue
+		}
+		hooksRunning.Add(1)
+		go func(hook watch.Hook) {
+			defer hooksRunning.Done()
+			if err := hook.Run(e); err != nil {
+				ui.WarnMsg("Hook failed: %v", err)
+			}
+		}(hook)
+	}
+}
@@ -0,0 +1,280 @@
+package advisor
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/doganarif/portfinder/internal/process"
+)
+
+// Suggestion is a concrete change that would move a project off a port
+type Suggestion struct {
+	// File is the project file to edit, empty for command line changes
+	File string
+	// Change describes the edit
+	Change string
+}
+
+func (s Suggestion) String() string {
+	if s.File == "" {
+		return s.Change
+	}
+	return fmt.Sprintf("%s in %s", s.Change, s.File)
+}
+
+// maxProbe bounds how far NextFreePort searches above a port
+const maxProbe = 100
+
+// MaxAlternatives is how many free ports Advise offers
+const MaxAlternatives = 3
+
+// Advice is what to do about a port taken by another process
+type Advice struct {
+	// Alternatives are the nearest free ports above the taken one
+	Alternatives []int
+	// Command starts a server like the occupant on the first alternative,
+	// e.g. "PORT=3001 npm start", when the occupant is a known dev tool
+	Command string
+	// Suggestions are the edits moving the occupant's project off the port
+	Suggestions []Suggestion
+}
+
+// Advise finds the free ports next to the process's port, how to run a
+// server like it on one of them, and how to move its project elsewhere
+func Advise(p *process.Process) Advice {
+	advice := Advice{Alternatives: FreePortsAbove(p.Port, MaxAlternatives)}
+	if len(advice.Alternatives) > 0 {
+		alt := advice.Alternatives[0]
+		advice.Command = RunCommand(p, alt)
+		advice.Suggestions = Suggest(p, alt)
+	}
+	return advice
+}
+
+// NextFreePort returns the first port above port that nothing listens on
+func NextFreePort(port int) int {
+	if free := FreePortsAbove(port, 1); len(free) > 0 {
+		return free[0]
+	}
+	return 0
+}
+
+// FreePortsAbove returns up to n ports above port that nothing listens on,
+// nearest first
+func FreePortsAbove(port, n int) []int {
+	var free []int
+	for candidate := port + 1; candidate <= port+maxProbe && candidate <= 65535 && len(free) < n; candidate++ {
+		if IsFree(candidate) {
+			free = append(free, candidate)
+		}
+	}
+	return free
+}
+
+// IsFree reports whether a TCP listener can bind the port
+func IsFree(port int) bool {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return false
+	}
+	listener.Close()
+	return true
+}
+
+// OwnProject reports whether the process runs from a project directory
+// under the user's home, as opposed to a system or unknown location
+func OwnProject(p *process.Process) bool {
+	if p.ProjectPath == "" || !filepath.IsAbs(p.ProjectPath) {
+		return false
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(home, p.ProjectPath)
+	return err == nil && !strings.HasPrefix(rel, "..")
+}
+
+// Suggest inspects the config files of the project owning the process and
+// returns the edits that would move it from its port to alt
+func Suggest(p *process.Process, alt int) []Suggestion {
+	if !OwnProject(p) || alt == 0 {
+		return nil
+	}
+
+	dir := p.ProjectPath
+	suggestions := inspectCommand(p.Command, p.Port, alt)
+	for _, inspect := range inspectors {
+		suggestions = append(suggestions, inspect(dir, p.Port, alt)...)
+	}
+
+	return suggestions
+}
+
+// inspector looks for a port setting in one kind of project file
+type inspector func(dir string, port, alt int) []Suggestion
+
+var inspectors = []inspector{
+	inspectEnvFiles,
+	inspectPackageJSON,
+	inspectViteConfig,
+	inspectCompose,
+	inspectSpring,
+}
+
+// inspectCommand finds the port passed as a flag on the command line
+func inspectCommand(command string, port, alt int) []Suggestion {
+	pattern := regexp.MustCompile(`(?:^|\s)(--port[= ]|-p )` + strconv.Itoa(port) + `\b`)
+	m := pattern.FindStringSubmatch(command)
+	if m == nil {
+		return nil
+	}
+	return []Suggestion{{Change: fmt.Sprintf("run with %s%d", m[1], alt)}}
+}
+
+var envFiles = []string{".env", ".env.local", ".env.development", ".env.development.local"}
+
+// inspectEnvFiles finds PORT-like variables set to the port in dotenv files
+func inspectEnvFiles(dir string, port, alt int) []Suggestion {
+	pattern := regexp.MustCompile(`^\s*(?:export\s+)?([A-Z0-9_]*PORT)\s*=\s*["']?` + strconv.Itoa(port) + `["']?\s*$`)
+
+	var suggestions []Suggestion
+	for _, name := range envFiles {
+		path := filepath.Join(dir, name)
+		for _, line := range readLines(path) {
+			if m := pattern.FindStringSubmatch(line); m != nil {
+				suggestions = append(suggestions, Suggestion{
+					File:   displayPath(path),
+					Change: fmt.Sprintf("set %s=%d", m[1], alt),
+				})
+			}
+		}
+	}
+
+	// Frameworks reading PORT work without any file at all
+	if len(suggestions) == 0 && fileExists(filepath.Join(dir, "package.json")) {
+		suggestions = append(suggestions, Suggestion{
+			File:   displayPath(filepath.Join(dir, ".env")),
+			Change: fmt.Sprintf("set PORT=%d", alt),
+		})
+	}
+
+	return suggestions
+}
+
+// inspectPackageJSON finds npm scripts passing the port as a flag
+func inspectPackageJSON(dir string, port, alt int) []Suggestion {
+	path := filepath.Join(dir, "package.json")
+	pattern := regexp.MustCompile(`"([^"]+)"\s*:\s*"[^"]*(--port[= ]|-p )` + strconv.Itoa(port) + `\b`)
+
+	var suggestions []Suggestion
+	for _, line := range readLines(path) {
+		if m := pattern.FindStringSubmatch(line); m != nil {
+			suggestions = append(suggestions, Suggestion{
+				File:   displayPath(path),
+				Change: fmt.Sprintf("change %s%d to %s%d in the %q script", m[2], port, m[2], alt, m[1]),
+			})
+		}
+	}
+
+	return suggestions
+}
+
+// inspectViteConfig finds a hardcoded server.port in Vite configs
+func inspectViteConfig(dir string, port, alt int) []Suggestion {
+	pattern := regexp.MustCompile(`\bport\s*:\s*` + strconv.Itoa(port) + `\b`)
+
+	var suggestions []Suggestion
+	for _, name := range []string{"vite.config.ts", "vite.config.js", "vite.config.mts", "vite.config.mjs"} {
+		path := filepath.Join(dir, name)
+		for _, line := range readLines(path) {
+			if pattern.MatchString(line) {
+				suggestions = append(suggestions, Suggestion{
+					File:   displayPath(path),
+					Change: fmt.Sprintf("set server.port to %d", alt),
+				})
+				break
+			}
+		}
+	}
+
+	return suggestions
+}
+
+// inspectCompose finds published ports in compose files
+func inspectCompose(dir string, port, alt int) []Suggestion {
+	pattern := regexp.MustCompile(`["']?(?:[\d.]+:)?` + strconv.Itoa(port) + `:(\d+)`)
+
+	var suggestions []Suggestion
+	for _, name := range []string{"docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml"} {
+		path := filepath.Join(dir, name)
+		for _, line := range readLines(path) {
+			if m := pattern.FindStringSubmatch(line); m != nil {
+				suggestions = append(suggestions, Suggestion{
+					File:   displayPath(path),
+					Change: fmt.Sprintf("publish %d:%s instead of %d:%s", alt, m[1], port, m[1]),
+				})
+			}
+		}
+	}
+
+	return suggestions
+}
+
+// inspectSpring finds server.port in Spring Boot configs
+func inspectSpring(dir string, port, alt int) []Suggestion {
+	var suggestions []Suggestion
+
+	properties := filepath.Join(dir, "src", "main", "resources", "application.properties")
+	for _, line := range readLines(properties) {
+		if strings.TrimSpace(line) == fmt.Sprintf("server.port=%d", port) {
+			suggestions = append(suggestions, Suggestion{
+				File:   displayPath(properties),
+				Change: fmt.Sprintf("set server.port=%d", alt),
+			})
+		}
+	}
+
+	return suggestions
+}
+
+func readLines(path string) []string {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// displayPath shortens a path relative to the working directory or home
+func displayPath(path string) string {
+	if cwd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(cwd, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.Join("~", rel)
+		}
+	}
+	return path
+}
@@ -0,0 +1,76 @@
+package advisor
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/doganarif/portfinder/internal/process"
+)
+
+// devTool recognizes a dev server by its command line and knows how to
+// start it on another port
+type devTool struct {
+	pattern *regexp.Regexp
+	// command starts the tool, with %d for the port
+	command string
+}
+
+// devTools are tried in order, so specific tools come before the runtimes
+// running them
+var devTools = []devTool{
+	{regexp.MustCompile(`\bnext(\s+dev)?(\s|$)`), "npx next dev -p %d"},
+	{regexp.MustCompile(`\bvite(\s|$)`), "npx vite --port %d"},
+	{regexp.MustCompile(`\bng\s+serve\b`), "npx ng serve --port %d"},
+	{regexp.MustCompile(`\bnuxi?(\s+dev)?(\s|$)`), "npx nuxi dev --port %d"},
+	{regexp.MustCompile(`\bastro\s+dev\b`), "npx astro dev --port %d"},
+	{regexp.MustCompile(`\bwebpack(-dev-server|\s+serve)\b`), "npx webpack serve --port %d"},
+	{regexp.MustCompile(`\breact-scripts\s+start\b`), "PORT=%d npm start"},
+	{regexp.MustCompile(`\brails\s+s(erver)?\b|\bpuma\b`), "bin/rails server -p %d"},
+	{regexp.MustCompile(`\bmanage\.py\s+runserver\b`), "python manage.py runserver %d"},
+	{regexp.MustCompile(`\bflask\s+run\b`), "flask run --port %d"},
+	{regexp.MustCompile(`\bhttp\.server\b`), "python3 -m http.server %d"},
+	{regexp.MustCompile(`\bphx\.server\b`), "PORT=%d mix phx.server"},
+	{regexp.MustCompile(`\bhugo\s+server\b`), "hugo server -p %d"},
+	{regexp.MustCompile(`\bjekyll\s+serve\b`), "bundle exec jekyll serve --port %d"},
+	{regexp.MustCompile(`\bphp\s+-S\b`), "php -S localhost:%d"},
+	{regexp.MustCompile(`\bspring-boot:run\b`), "mvn spring-boot:run -Dspring-boot.run.arguments=--server.port=%d"},
+	{regexp.MustCompile(`(^|/)(node|nodemon|npm|yarn|pnpm|bun)(\s|$)`), "PORT=%d npm start"},
+}
+
+// RunCommand returns the usual way to start the dev tool the process runs
+// on alt, or an empty string for processes that aren't known dev tools
+func RunCommand(p *process.Process, alt int) string {
+	if p.SocketOnly || p.IsDocker || p.Command == "" {
+		return ""
+	}
+	for _, tool := range devTools {
+		if !tool.pattern.MatchString(p.Command) {
+			continue
+		}
+		command := fmt.Sprintf(tool.command, alt)
+		// PowerShell sets variables for a command in a statement of its own
+		if rest, ok := strings.CutPrefix(command, "PORT="); ok && runtime.GOOS == "windows" {
+			port, command, _ := strings.Cut(rest, " ")
+			return fmt.Sprintf("$env:PORT=%s; %s", port, command)
+		}
+		return command
+	}
+	return ""
+}
+
+// StopRespawns returns the pf command stopping whatever restarts a process
+// that came back after a kill: its service, its container, or the
+// supervisor it runs under. It is "" when nothing is known to restart it.
+func StopRespawns(p *process.Process) string {
+	switch {
+	case p.Service != "":
+		return fmt.Sprintf("pf kill %d --strategy service", p.Port)
+	case p.IsDocker && p.DockerID != "":
+		return fmt.Sprintf("pf kill %d --container", p.Port)
+	case p.Root().PID != p.PID:
+		return fmt.Sprintf("pf kill %d --parent", p.Port)
+	}
+	return ""
+}
@@ -0,0 +1,229 @@
+package advisor
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Expectation is a port a project's files say it listens on
+type Expectation struct {
+	Port int
+	// Source is the file declaring the port
+	Source string
+	// Service is what uses the port, if known: a compose service, a
+	// Procfile process type or an npm script
+	Service string
+}
+
+// Expectations scans the project files in dir for the ports the project
+// expects to use, ordered by port
+func Expectations(dir string) []Expectation {
+	var expectations []Expectation
+	seen := make(map[int]bool)
+	add := func(port int, path, service string) {
+		if port <= 0 || port > 65535 || seen[port] {
+			return
+		}
+		seen[port] = true
+		expectations = append(expectations, Expectation{Port: port, Source: displayPath(path), Service: service})
+	}
+
+	envPattern := regexp.MustCompile(`^\s*(?:export\s+)?[A-Z0-9_]*PORT\s*=\s*["']?(\d+)["']?\s*$`)
+	for _, name := range envFiles {
+		path := filepath.Join(dir, name)
+		for _, line := range readLines(path) {
+			if m := envPattern.FindStringSubmatch(line); m != nil {
+				add(atoi(m[1]), path, "")
+			}
+		}
+	}
+
+	scriptPattern := regexp.MustCompile(`"([^"]+)"\s*:\s*"[^"]*(?:--port[= ]|-p |PORT=)(\d+)\b`)
+	path := filepath.Join(dir, "package.json")
+	for _, line := range readLines(path) {
+		if m := scriptPattern.FindStringSubmatch(line); m != nil {
+			add(atoi(m[2]), path, m[1])
+		}
+	}
+
+	for _, e := range procfilePorts(dir) {
+		add(e.Port, e.Source, e.Service)
+	}
+	for _, e := range devcontainerPorts(dir) {
+		add(e.Port, e.Source, e.Service)
+	}
+
+	vitePattern := regexp.MustCompile(`\bport\s*:\s*(\d+)\b`)
+	for _, name := range []string{"vite.config.ts", "vite.config.js", "vite.config.mts", "vite.config.mjs"} {
+		path := filepath.Join(dir, name)
+		for _, line := range readLines(path) {
+			if m := vitePattern.FindStringSubmatch(line); m != nil {
+				add(atoi(m[1]), path, "")
+			}
+		}
+	}
+
+	for _, e := range composePorts(dir) {
+		add(e.Port, e.Source, e.Service)
+	}
+
+	properties := filepath.Join(dir, "src", "main", "resources", "application.properties")
+	for _, line := range readLines(properties) {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), "server.port="); ok {
+			add(atoi(value), properties, "")
+		}
+	}
+
+	sort.Slice(expectations, func(i, j int) bool {
+		return expectations[i].Port < expectations[j].Port
+	})
+	return expectations
+}
+
+// procfilePorts finds the ports the process types of a Procfile pass on
+// their command lines, e.g. "web: bin/rails server -p 5000"
+func procfilePorts(dir string) []Expectation {
+	pattern := regexp.MustCompile(`^([A-Za-z0-9_-]+):.*(?:--port[= ]|-p\s*|PORT=|(?:--bind|-b)[= ]\S*:)(\d+)\b`)
+
+	var expectations []Expectation
+	for _, name := range []string{"Procfile", "Procfile.dev"} {
+		path := filepath.Join(dir, name)
+		for _, line := range readLines(path) {
+			if m := pattern.FindStringSubmatch(line); m != nil {
+				expectations = append(expectations, Expectation{Port: atoi(m[2]), Source: path, Service: m[1]})
+			}
+		}
+	}
+	return expectations
+}
+
+// devcontainerPorts finds the forwardPorts and appPort of a dev container
+// config. Forwarded ports may name a compose service, as in "db:5432", and
+// app ports may map a host port, as in "8000:80"; both use the port the
+// host sees. devcontainer.json allows comments, so it is scanned rather
+// than decoded.
+func devcontainerPorts(dir string) []Expectation {
+	listPattern := regexp.MustCompile(`"(forwardPorts|appPort)"\s*:\s*(\[[^\]]*\]|"[^"]*"|\d+)`)
+	entryPattern := regexp.MustCompile(`(?:"(?:([\w.-]+):)?(\d+)(?::\d+)?"|(\d+))`)
+
+	var expectations []Expectation
+	for _, path := range []string{filepath.Join(dir, ".devcontainer", "devcontainer.json"), filepath.Join(dir, ".devcontainer.json")} {
+		var content strings.Builder
+		for _, line := range readLines(path) {
+			if !strings.HasPrefix(strings.TrimSpace(line), "//") {
+				content.WriteString(line + "\n")
+			}
+		}
+
+		for _, list := range listPattern.FindAllStringSubmatch(content.String(), -1) {
+			for _, entry := range entryPattern.FindAllStringSubmatch(list[2], -1) {
+				port := entry[2] + entry[3]
+				service := entry[1]
+				// The host port of "8000:80" comes first
+				if list[1] == "appPort" {
+					service = ""
+					if host, _, ok := strings.Cut(strings.Trim(entry[0], `"`), ":"); ok {
+						port = host
+					}
+				}
+				expectations = append(expectations, Expectation{Port: atoi(port), Source: path, Service: service})
+			}
+		}
+	}
+	return expectations
+}
+
+// ComposeFile returns the compose file in dir, or "" when there is none
+func ComposeFile(dir string) string {
+	for _, name := range []string{"docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml"} {
+		if path := filepath.Join(dir, name); fileExists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+// ComposeServices returns the service names declared in the compose file
+func ComposeServices(path string) []string {
+	indent := serviceIndent(path)
+
+	var services []string
+	inServices := false
+	for _, line := range readLines(path) {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		depth := len(line) - len(strings.TrimLeft(line, " "))
+		if depth == 0 {
+			inServices = strings.TrimSpace(line) == "services:"
+			continue
+		}
+		if inServices && depth == indent && strings.HasSuffix(strings.TrimSpace(line), ":") {
+			services = append(services, strings.TrimSuffix(strings.TrimSpace(line), ":"))
+		}
+	}
+	return services
+}
+
+// composePorts finds the host ports published by the services of the
+// compose file in dir. This is a line based scan rather than a YAML parse,
+// which covers the short port syntax compose files mostly use.
+func composePorts(dir string) []Expectation {
+	path := ComposeFile(dir)
+	if path == "" {
+		return nil
+	}
+
+	pattern := regexp.MustCompile(`^\s*-\s*["']?(?:[\d.]+:)?(\d+):\d+`)
+	indent := serviceIndent(path)
+
+	var expectations []Expectation
+	service := ""
+	inServices := false
+	for _, line := range readLines(path) {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		depth := len(line) - len(strings.TrimLeft(line, " "))
+		switch {
+		case depth == 0:
+			inServices = trimmed == "services:"
+			service = ""
+		case inServices && depth == indent && strings.HasSuffix(trimmed, ":"):
+			service = strings.TrimSuffix(trimmed, ":")
+		case service != "":
+			if m := pattern.FindStringSubmatch(line); m != nil {
+				expectations = append(expectations, Expectation{Port: atoi(m[1]), Source: path, Service: service})
+			}
+		}
+	}
+	return expectations
+}
+
+// serviceIndent returns the indentation of the service names in a compose
+// file, taken from the first indented line after "services:"
+func serviceIndent(path string) int {
+	inServices := false
+	for _, line := range readLines(path) {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		depth := len(line) - len(strings.TrimLeft(line, " "))
+		if depth == 0 {
+			inServices = trimmed == "services:"
+		} else if inServices {
+			return depth
+		}
+	}
+	return 2
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(strings.TrimSpace(s))
+	return n
+}
@@ -0,0 +1,116 @@
+package certs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Certificate is a certificate of the chain a listener presents
+type Certificate struct {
+	Subject     string    `json:"subject"`
+	Issuer      string    `json:"issuer"`
+	DNSNames    []string  `json:"dns_names,omitempty"`
+	IPAddresses []string  `json:"ip_addresses,omitempty"`
+	NotBefore   time.Time `json:"not_before"`
+	NotAfter    time.Time `json:"not_after"`
+	// SelfSigned marks a certificate signed with its own key
+	SelfSigned bool   `json:"self_signed"`
+	IsCA       bool   `json:"is_ca,omitempty"`
+	SHA256     string `json:"sha256"`
+}
+
+// Expired reports whether the certificate isn't valid at t
+func (c Certificate) Expired(t time.Time) bool {
+	return t.After(c.NotAfter) || t.Before(c.NotBefore)
+}
+
+// Chain is the outcome of a TLS handshake with a listener
+type Chain struct {
+	Address string `json:"address"`
+	// ServerName is the name sent in the handshake and verified against
+	ServerName string `json:"server_name"`
+	Version    string `json:"version"`
+	// Certificates holds the chain as presented, the leaf first
+	Certificates []Certificate `json:"certificates"`
+	// Trusted reports whether the system's roots verify the chain for
+	// ServerName, and VerifyError why not
+	Trusted     bool   `json:"trusted"`
+	VerifyError string `json:"verify_error,omitempty"`
+}
+
+// Fetch connects to address, e.g. "127.0.0.1:8443", and returns the
+// certificate chain it presents for serverName. The chain is read even
+// when it isn't trusted, which is the point for local dev setups.
+func Fetch(address, serverName string, timeout time.Duration) (*Chain, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("TLS handshake with %s failed: %w", address, err)
+	}
+	defer conn.Close()
+
+	state := conn.ConnectionState()
+	if len(state.PeerCertificates) == 0 {
+		return nil, fmt.Errorf("%s presented no certificate", address)
+	}
+	chain := &Chain{
+		Address:    address,
+		ServerName: serverName,
+		Version:    tls.VersionName(state.Version),
+	}
+	for _, cert := range state.PeerCertificates {
+		chain.Certificates = append(chain.Certificates, describe(cert))
+	}
+
+	if err := verify(state.PeerCertificates, serverName); err != nil {
+		chain.VerifyError = err.Error()
+	} else {
+		chain.Trusted = true
+	}
+	return chain, nil
+}
+
+// describe summarizes a certificate
+func describe(cert *x509.Certificate) Certificate {
+	sum := sha256.Sum256(cert.Raw)
+	c := Certificate{
+		Subject:   cert.Subject.String(),
+		Issuer:    cert.Issuer.String(),
+		DNSNames:  cert.DNSNames,
+		NotBefore: cert.NotBefore,
+		NotAfter:  cert.NotAfter,
+		IsCA:      cert.IsCA,
+		SHA256:    hex.EncodeToString(sum[:]),
+	}
+	for _, ip := range cert.IPAddresses {
+		c.IPAddresses = append(c.IPAddresses, ip.String())
+	}
+	// The signature check tells a self-signed certificate from one whose
+	// issuer merely has the same name
+	if bytes.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) == nil {
+		c.SelfSigned = true
+	}
+	return c
+}
+
+// verify checks the chain against the system's roots
+func verify(chain []*x509.Certificate, serverName string) error {
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := chain[0].Verify(x509.VerifyOptions{
+		DNSName:       serverName,
+		Intermediates: intermediates,
+	})
+	return err
+}
@@ -1,16 +1,135 @@
 package config
 
 import (
-	"encoding/json"
+	"bytes"
+	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/doganarif/portfinder/internal/output"
+	"github.com/doganarif/portfinder/internal/process"
+	"github.com/doganarif/portfinder/internal/watch"
 )
 
 // Config holds the application configuration
 type Config struct {
 	CommonPorts []int `json:"common_ports"`
+
+	// ClassificationRules extend the built-in process classes
+	ClassificationRules []process.ClassRule `json:"classification_rules,omitempty"`
+
+	// KeyBindings remaps TUI actions to keys, e.g. {"kill": ["x"]}
+	KeyBindings map[string][]string `json:"key_bindings,omitempty"`
+	// Columns picks the process list's columns and their order, e.g.
+	// ["port", "name", "pid"]
+	Columns []string `json:"columns,omitempty"`
+	// Color is "always" or "never" to force colors on or off, or "auto"
+	// to use them on terminals
+	Color string `json:"color,omitempty"`
+	// Output is the format of results when --output isn't given: table,
+	// json, yaml, csv or markdown
+	Output string `json:"output,omitempty"`
+	// Theme names the built-in colors of the TUI: dark, light,
+	// high-contrast or no-color. Colors overrides single colors of it.
+	Theme  string `json:"theme,omitempty"`
+	Colors *Theme `json:"colors,omitempty"`
+	// Accessible writes results as linear, labeled text for screen
+	// readers, as the --accessible flag does
+	Accessible bool `json:"accessible,omitempty"`
+	// OpenWith is the command the list and detail view open project
+	// directories with: "editor" for $VISUAL or $EDITOR, a command such as
+	// "code" or "idea" given the directory, or empty for the file manager
+	OpenWith string `json:"open_with,omitempty"`
+
+	// Groups are named port sets, e.g. {"myapp": [3000, 5432, 6379]}, shown
+	// by check instead of the built-in categories
+	Groups map[string][]int `json:"groups,omitempty"`
+
+	// Labels annotate ports, e.g. {"3000": "main frontend"}; check and list
+	// show them, overriding the preset's labels
+	Labels map[int]string `json:"labels,omitempty"`
+
+	// Preset names the built-in preset categorizing the common ports
+	Preset string `json:"preset,omitempty"`
+
+	// Tools override the paths and arguments of external commands, e.g.
+	// {"ss": {"path": "sudo", "args": ["-n", "/usr/sbin/ss"]}}
+	Tools map[string]process.Tool `json:"tools,omitempty"`
+
+	// History enables recording port occupants and kills to the history file
+	History bool `json:"history,omitempty"`
+
+	// ConfirmKill makes the list view and restart ask before killing a
+	// process. It is on by default, so it is always written out.
+	ConfirmKill bool `json:"confirm_kill"`
+
+	// WSLInterop also lists Windows processes when running under WSL, as
+	// the --wsl flag does
+	WSLInterop bool `json:"wsl_interop,omitempty"`
+
+	// CacheTTL is how long lookups are reused within a command or by the
+	// server, e.g. "5s", or "0" to always scan afresh. It defaults to
+	// DefaultCacheTTL.
+	CacheTTL string `json:"cache_ttl,omitempty"`
+
+	// Timeout bounds each lookup, so a tool hanging on, say, a stale NFS
+	// mount can't freeze pf, e.g. "10s", or "0" to wait as long as it
+	// takes. It defaults to DefaultTimeout and --timeout overrides it.
+	Timeout string `json:"timeout,omitempty"`
+
+	// APIToken is the bearer token pf serve requires and list --remote
+	// sends. Without one, serve makes one up each time it starts.
+	APIToken string `json:"api_token,omitempty"`
+
+	// Notifications select the port events watch and serve show desktop
+	// notifications for, e.g. [{"ports": [5432], "events": ["freed"]}]
+	Notifications []watch.Rule `json:"notifications,omitempty"`
+
+	// Hooks post the port events they select to a webhook or pass them to
+	// a script, e.g. [{"ports": [3000], "webhook": "https://..."}]
+	Hooks []watch.Hook `json:"hooks,omitempty"`
+
+	// Reservations set ports aside for projects, by name or directory,
+	// e.g. {"3000": "myapp"}
+	Reservations map[int]string `json:"reservations,omitempty"`
+
+	// ProtectedPorts and ProtectedProcesses, glob patterns on the process
+	// name like "postgres*", guard listeners against kills unless
+	// --allow-protected is passed
+	ProtectedPorts     []int    `json:"protected_ports,omitempty"`
+	ProtectedProcesses []string `json:"protected_processes,omitempty"`
+
+	// Profiles are named variants of the ports check shows, e.g.
+	// {"backend": {"common_ports": [4000, 5432]}}, picked with --profile
+	// or PORTFINDER_PROFILE
+	Profiles map[string]Profile `json:"profiles,omitempty"`
+}
+
+// Profile overrides the port set and labels of the config. Its common
+// ports, groups and preset replace the top-level ones when set, and its
+// labels are merged over them. A profile with common ports or a preset but
+// no groups drops the top-level groups, which would be shown instead.
+type Profile struct {
+	CommonPorts []int            `json:"common_ports,omitempty"`
+	Groups      map[string][]int `json:"groups,omitempty"`
+	Labels      map[int]string   `json:"labels,omitempty"`
+	Preset      string           `json:"preset,omitempty"`
 }
 
+// ProfileEnv is the environment variable picking a profile when no
+// --profile flag is given
+const ProfileEnv = "PORTFINDER_PROFILE"
+
+// DefaultCacheTTL is the cache TTL when the config doesn't set one
+const DefaultCacheTTL = 2 * time.Second
+
+// DefaultTimeout is the lookup timeout when the config doesn't set one
+const DefaultTimeout = 30 * time.Second
+
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	return &Config{
@@ -45,29 +164,418 @@ func DefaultConfig() *Config {
 			7000, // Cassandra
 			8983, // Solr
 		},
+		ConfirmKill: true,
+	}
+}
+
+// PortGroup is a named set of ports displayed together by check
+type PortGroup struct {
+	Name  string
+	Ports []int
+	// Labels describe what usually runs on a port, e.g. 5432: "PostgreSQL"
+	Labels map[int]string
+}
+
+// PortGroups returns the groups check should display: the user-defined
+// groups sorted by name, or else the common ports split into the
+// categories of the active preset with uncategorized ports under "Other"
+func (c *Config) PortGroups() []PortGroup {
+	if len(c.Groups) > 0 {
+		names := make([]string, 0, len(c.Groups))
+		for name := range c.Groups {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		groups := make([]PortGroup, 0, len(names))
+		for _, name := range names {
+			groups = append(groups, PortGroup{Name: name, Ports: c.Groups[name], Labels: c.labels(nil)})
+		}
+		return groups
+	}
+
+	common := make(map[int]bool, len(c.CommonPorts))
+	for _, port := range c.CommonPorts {
+		common[port] = true
+	}
+
+	var groups []PortGroup
+	categorized := make(map[int]bool)
+	for _, group := range c.ActivePreset().Groups {
+		var ports []int
+		for _, port := range group.Ports {
+			if common[port] && !categorized[port] {
+				ports = append(ports, port)
+				categorized[port] = true
+			}
+		}
+		if len(ports) > 0 {
+			groups = append(groups, PortGroup{Name: group.Name, Ports: ports, Labels: c.labels(group.Labels)})
+		}
+	}
+
+	var other []int
+	for _, port := range c.CommonPorts {
+		if !categorized[port] {
+			other = append(other, port)
+			categorized[port] = true
+		}
+	}
+	if len(other) > 0 {
+		groups = append(groups, PortGroup{Name: "Other", Ports: other, Labels: c.labels(nil)})
+	}
+
+	return groups
+}
+
+// labels merges the user's port labels over the given preset labels
+func (c *Config) labels(preset map[int]string) map[int]string {
+	if len(c.Labels) == 0 {
+		return preset
+	}
+
+	merged := make(map[int]string, len(preset)+len(c.Labels))
+	for port, label := range preset {
+		merged[port] = label
+	}
+	for port, label := range c.Labels {
+		merged[port] = label
+	}
+	return merged
+}
+
+// CacheDuration returns the cache TTL, zero meaning no caching
+func (c *Config) CacheDuration() time.Duration {
+	if ttl, err := parseTTL(c.CacheTTL); err == nil && c.CacheTTL != "" {
+		return ttl
+	}
+	return DefaultCacheTTL
+}
+
+// TimeoutDuration returns the lookup timeout, zero meaning none
+func (c *Config) TimeoutDuration() time.Duration {
+	if timeout, err := parseTTL(c.Timeout); err == nil && c.Timeout != "" {
+		return timeout
+	}
+	return DefaultTimeout
+}
+
+// parseTTL parses a duration that must not be negative
+func parseTTL(s string) (time.Duration, error) {
+	ttl, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if ttl < 0 {
+		return 0, fmt.Errorf("negative duration %s", s)
+	}
+	return ttl, nil
+}
+
+// Group looks up a port group by name, ignoring case
+func (c *Config) Group(name string) (PortGroup, bool) {
+	for _, group := range c.PortGroups() {
+		if strings.EqualFold(group.Name, name) {
+			return group, true
+		}
+	}
+	return PortGroup{}, false
+}
+
+// Protected returns why the process must not be killed, or "" when it may
+func (c *Config) Protected(p *process.Process) string {
+	for _, port := range c.ProtectedPorts {
+		if port == p.Port {
+			return fmt.Sprintf("port %d is protected", port)
+		}
+	}
+	name := strings.ToLower(p.Name)
+	for _, pattern := range c.ProtectedProcesses {
+		if ok, _ := path.Match(strings.ToLower(pattern), name); ok && name != "" {
+			return fmt.Sprintf("%s matches the protected process pattern %q", p.Name, pattern)
+		}
+	}
+	return ""
+}
+
+// UseProfile applies the named profile over the config
+func (c *Config) UseProfile(name string) error {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		names := make([]string, 0, len(c.Profiles))
+		for n := range c.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return fmt.Errorf("unknown profile %q, the config defines none", name)
+		}
+		return fmt.Errorf("unknown profile %q, expected one of %s", name, strings.Join(names, ", "))
 	}
+
+	if len(profile.CommonPorts) > 0 {
+		c.CommonPorts = profile.CommonPorts
+	}
+	switch {
+	case len(profile.Groups) > 0:
+		c.Groups = profile.Groups
+	case len(profile.CommonPorts) > 0 || profile.Preset != "":
+		// The top-level groups would hide the profile's ports
+		c.Groups = nil
+	}
+	if profile.Preset != "" {
+		c.Preset = profile.Preset
+	}
+	if len(profile.Labels) > 0 {
+		labels := make(map[int]string, len(c.Labels)+len(profile.Labels))
+		for port, label := range c.Labels {
+			labels[port] = label
+		}
+		for port, label := range profile.Labels {
+			labels[port] = label
+		}
+		c.Labels = labels
+	}
+	return nil
 }
 
-// Load loads the configuration from file or returns default
+// Load loads the configuration from file or returns default, with the
+// PORTFINDER_* environment variables applied over it. Invalid variables
+// are skipped; ApplyEnv reports them.
 func Load() *Config {
-	cfg := DefaultConfig()
+	cfg, err := LoadFile()
+	if err != nil {
+		cfg = DefaultConfig()
+	}
+	cfg.ApplyEnv()
+	return cfg
+}
+
+// LoadFile loads the configuration like Load, but reports a config file
+// that exists and cannot be parsed instead of silently using defaults
+func LoadFile() (*Config, error) {
+	cfg, _, err := loadFile()
+	return cfg, err
+}
 
-	// Try to load from config file
+// CheckFile loads the config file and validates it, pointing errors at
+// the line of the offending key
+func CheckFile() error {
+	cfg, lines, err := loadFile()
+	if err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", readPath(), locate(err, lines))
+	}
+	return nil
+}
+
+// loadFile reads the config file, or the JSON file it replaced when it
+// hasn't been migrated yet, with the line of each key
+func loadFile() (*Config, map[string]int, error) {
+	configPath := readPath()
+	if configPath == "" {
+		return DefaultConfig(), nil, nil
+	}
+
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return DefaultConfig(), nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cfg, lines, err := decodeConfig(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", configPath, err)
+	}
+	return cfg, lines, nil
+}
+
+// readPath is the file the config is read from: the YAML file, else a
+// JSON one left from older versions
+func readPath() string {
 	configPath := getConfigPath()
-	if configPath != "" {
-		if data, err := os.ReadFile(configPath); err == nil {
-			json.Unmarshal(data, cfg)
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		if _, err := os.Stat(legacyPath()); err == nil {
+			return legacyPath()
 		}
 	}
+	return configPath
+}
 
-	return cfg
+// Migrate moves a JSON config from older versions to the YAML file,
+// keeping the original as config.json.bak. It reports whether there was
+// one to move. A broken JSON config is left alone and keeps being read,
+// so its errors are reported as before.
+func Migrate() (bool, error) {
+	old := legacyPath()
+	if old == "" || readPath() != old {
+		return false, nil
+	}
+
+	cfg, err := LoadFile()
+	if err != nil {
+		return false, err
+	}
+	if err := cfg.Save(); err != nil {
+		return false, err
+	}
+	return true, os.Rename(old, old+".bak")
 }
 
-// Save saves the configuration to file
+// Validate checks the configuration for values that would be ignored or
+// misbehave at runtime
+func (c *Config) Validate() error {
+	if _, ok := LookupPreset(c.Preset); c.Preset != "" && !ok {
+		return fmt.Errorf("preset: unknown preset %q", c.Preset)
+	}
+
+	if err := validatePorts("common_ports", c.CommonPorts); err != nil {
+		return err
+	}
+
+	switch c.Color {
+	case "", "auto", "always", "never":
+	default:
+		return fmt.Errorf("color: expected auto, always or never, not %q", c.Color)
+	}
+	if _, err := output.ParseFormat(c.Output); err != nil {
+		return fmt.Errorf("output: %v", err)
+	}
+	if err := c.validateTheme(); err != nil {
+		return err
+	}
+
+	for name, ports := range c.Groups {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("groups: group names must not be empty")
+		}
+		if err := validatePorts("groups."+name, ports); err != nil {
+			return err
+		}
+	}
+
+	for port := range c.Labels {
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("labels: invalid port %d", port)
+		}
+	}
+
+	for port, project := range c.Reservations {
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("reservations: invalid port %d", port)
+		}
+		if strings.TrimSpace(project) == "" {
+			return fmt.Errorf("reservations.%d: project is required", port)
+		}
+	}
+
+	if err := validatePorts("protected_ports", c.ProtectedPorts); err != nil {
+		return err
+	}
+	for i, pattern := range c.ProtectedProcesses {
+		if _, err := path.Match(pattern, ""); err != nil || strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("protected_processes[%d]: invalid pattern %q", i, pattern)
+		}
+	}
+
+	known := process.ToolNames()
+	for name, tool := range c.Tools {
+		if i := sort.SearchStrings(known, name); i == len(known) || known[i] != name {
+			return fmt.Errorf("tools: unknown tool %q, expected one of %s", name, strings.Join(known, ", "))
+		}
+		if tool.Path == "" && len(tool.Args) == 0 {
+			return fmt.Errorf("tools.%s: path or args is required", name)
+		}
+	}
+
+	if c.CacheTTL != "" {
+		if _, err := parseTTL(c.CacheTTL); err != nil {
+			return fmt.Errorf("cache_ttl: %v", err)
+		}
+	}
+	if c.Timeout != "" {
+		if _, err := parseTTL(c.Timeout); err != nil {
+			return fmt.Errorf("timeout: %v", err)
+		}
+	}
+
+	for i, rule := range c.Notifications {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("notifications[%d]: %v", i, err)
+		}
+	}
+
+	for i, hook := range c.Hooks {
+		if err := hook.Validate(); err != nil {
+			return fmt.Errorf("hooks[%d]: %v", i, err)
+		}
+	}
+
+	for name, profile := range c.Profiles {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("profiles: profile names must not be empty")
+		}
+		if _, ok := LookupPreset(profile.Preset); profile.Preset != "" && !ok {
+			return fmt.Errorf("profiles.%s.preset: unknown preset %q", name, profile.Preset)
+		}
+		if err := validatePorts("profiles."+name+".common_ports", profile.CommonPorts); err != nil {
+			return err
+		}
+		for group, ports := range profile.Groups {
+			if err := validatePorts("profiles."+name+".groups."+group, ports); err != nil {
+				return err
+			}
+		}
+		for port := range profile.Labels {
+			if port < 1 || port > 65535 {
+				return fmt.Errorf("profiles.%s.labels: invalid port %d", name, port)
+			}
+		}
+	}
+
+	for i, rule := range c.ClassificationRules {
+		if rule.Class == "" {
+			return fmt.Errorf("classification_rules[%d]: class is required", i)
+		}
+		if len(rule.Names) == 0 && len(rule.Commands) == 0 && len(rule.Ports) == 0 {
+			return fmt.Errorf("classification_rules[%d]: at least one of names, commands or ports is required", i)
+		}
+		if err := validatePorts(fmt.Sprintf("classification_rules[%d].ports", i), rule.Ports); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validatePorts(field string, ports []int) error {
+	seen := make(map[int]bool, len(ports))
+	for _, port := range ports {
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("%s: invalid port %d", field, port)
+		}
+		if seen[port] {
+			return fmt.Errorf("%s: duplicate port %d", field, port)
+		}
+		seen[port] = true
+	}
+	return nil
+}
+
+// Save validates the configuration and writes it to file atomically, so
+// a crash or a concurrent reader never sees a partially written config
 func (c *Config) Save() error {
 	configPath := getConfigPath()
 	if configPath == "" {
-		return nil
+		return fmt.Errorf("cannot determine the config file location")
+	}
+
+	if err := c.Validate(); err != nil {
+		return err
 	}
 
 	// Ensure directory exists
@@ -76,25 +584,82 @@ func (c *Config) Save() error {
 		return err
 	}
 
-	data, err := json.MarshalIndent(c, "", "  ")
+	var data bytes.Buffer
+	data.WriteString("# portfinder settings, see pf config --help\n")
+	if err := output.Encode(&data, output.YAML, c); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".config-*.yaml")
 	if err != nil {
 		return err
 	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	// The file may hold the API token, so it is only readable by its
+	// owner unless they chose otherwise
+	mode := os.FileMode(0600)
+	if info, err := os.Stat(configPath); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), configPath)
+}
+
+// Path returns the configuration file path, which is the JSON file of
+// older versions until it has been migrated
+func Path() string {
+	return readPath()
+}
+
+// pathOverride is the file set with SetPath, read instead of the default
+var pathOverride string
 
-	return os.WriteFile(configPath, data, 0644)
+// SetPath makes the config be read from and saved to path instead of the
+// default location. A file named this way isn't migrated.
+func SetPath(path string) {
+	pathOverride = path
 }
 
 // getConfigPath returns the configuration file path
 func getConfigPath() string {
+	if pathOverride != "" {
+		return pathOverride
+	}
+
 	// Check XDG_CONFIG_HOME first
 	if xdgConfig := os.Getenv("XDG_CONFIG_HOME"); xdgConfig != "" {
-		return filepath.Join(xdgConfig, "portfinder", "config.json")
+		return filepath.Join(xdgConfig, "portfinder", "config.yaml")
 	}
 
 	// Fall back to ~/.config
 	if home, err := os.UserHomeDir(); err == nil {
-		return filepath.Join(home, ".config", "portfinder", "config.json")
+		return filepath.Join(home, ".config", "portfinder", "config.yaml")
 	}
 
 	return ""
 }
+
+// legacyPath is where versions before the move to YAML kept the config
+func legacyPath() string {
+	configPath := getConfigPath()
+	if configPath == "" || pathOverride != "" {
+		return ""
+	}
+	return strings.TrimSuffix(configPath, ".yaml") + ".json"
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestUseProfileGroups(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		want    []PortGroup
+	}{
+		{
+			name:    "common ports replace the groups",
+			profile: Profile{CommonPorts: []int{4000, 5432}},
+			want:    []PortGroup{{Name: "Backend", Ports: []int{4000}}, {Name: "Databases", Ports: []int{5432}}},
+		},
+		{
+			name:    "groups replace the groups",
+			profile: Profile{CommonPorts: []int{4000}, Groups: map[string][]int{"api": {4000, 4001}}},
+			want:    []PortGroup{{Name: "api", Ports: []int{4000, 4001}}},
+		},
+		{
+			name:    "labels keep the groups",
+			profile: Profile{Labels: map[int]string{3000: "web"}},
+			want:    []PortGroup{{Name: "web", Ports: []int{3000}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Groups = map[string][]int{"web": {3000}}
+			cfg.Profiles = map[string]Profile{"backend": tt.profile}
+			if err := cfg.UseProfile("backend"); err != nil {
+				t.Fatalf("UseProfile() error = %v", err)
+			}
+
+			var got []PortGroup
+			for _, g := range cfg.PortGroups() {
+				got = append(got, PortGroup{Name: g.Name, Ports: g.Ports})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PortGroups() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUseProfileUnknown(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := cfg.UseProfile("backend"); err == nil {
+		t.Error("UseProfile() accepted a profile the config doesn't define")
+	}
+}
+
+func TestSaveMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no permission bits on Windows")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cfg := DefaultConfig()
+	cfg.APIToken = "secret"
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	info, err := os.Stat(getConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("new config mode = %v, want 0600", mode)
+	}
+
+	// A mode the user chose is kept
+	if err := os.Chmod(getConfigPath(), 0640); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if info, err = os.Stat(getConfigPath()); err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0640 {
+		t.Errorf("saved config mode = %v, want 0640", mode)
+	}
+}
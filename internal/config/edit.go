@@ -0,0 +1,239 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Get returns the JSON encoding of the setting at key. Keys are the JSON
+// field names, with dots selecting nested entries (e.g. "groups.myapp").
+func (c *Config) Get(key string) (string, error) {
+	tree, err := c.tree()
+	if err != nil {
+		return "", err
+	}
+
+	var value interface{} = tree
+	for i, part := range splitKey(key) {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("unknown setting %q", key)
+		}
+		if value, ok = object[part]; !ok {
+			// Empty settings are omitted from the encoding but still exist
+			if field, known := settingNames()[part]; i == 0 && known {
+				value = reflect.Zero(field).Interface()
+				break
+			}
+			return "", fmt.Errorf("unknown setting %q", key)
+		}
+	}
+
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Set replaces the setting at key. The value is parsed as JSON, falling
+// back to a plain string, and the result must still be a valid config.
+func (c *Config) Set(key, value string) error {
+	parts := splitKey(key)
+	if len(parts) == 0 {
+		return fmt.Errorf("empty setting name")
+	}
+	if _, known := settingNames()[parts[0]]; !known {
+		return fmt.Errorf("unknown setting %q", key)
+	}
+
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+		parsed = value
+	}
+
+	tree, err := c.tree()
+	if err != nil {
+		return err
+	}
+
+	object := tree
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := object[part].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			object[part] = child
+		}
+		object = child
+	}
+	object[parts[len(parts)-1]] = parsed
+
+	updated, err := decodeTree(tree)
+	if err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	if err := updated.Validate(); err != nil {
+		return err
+	}
+
+	*c = *updated
+	return nil
+}
+
+// AddPort adds ports to the common ports, skipping ones already present
+func (c *Config) AddPort(ports ...int) error {
+	if err := validatePorts("port", dedupe(ports)); err != nil {
+		return err
+	}
+
+	existing := make(map[int]bool, len(c.CommonPorts))
+	for _, port := range c.CommonPorts {
+		existing[port] = true
+	}
+	for _, port := range ports {
+		if !existing[port] {
+			c.CommonPorts = append(c.CommonPorts, port)
+			existing[port] = true
+		}
+	}
+
+	return nil
+}
+
+// RemovePort removes ports from the common ports
+func (c *Config) RemovePort(ports ...int) error {
+	remove := make(map[int]bool, len(ports))
+	for _, port := range ports {
+		remove[port] = true
+	}
+
+	kept := make([]int, 0, len(c.CommonPorts))
+	for _, port := range c.CommonPorts {
+		if remove[port] {
+			delete(remove, port)
+			continue
+		}
+		kept = append(kept, port)
+	}
+
+	if len(remove) > 0 {
+		missing := make([]int, 0, len(remove))
+		for port := range remove {
+			missing = append(missing, port)
+		}
+		sort.Ints(missing)
+		return fmt.Errorf("not in common ports: %v", missing)
+	}
+
+	c.CommonPorts = kept
+	return nil
+}
+
+// Reserve sets ports aside for a project, failing when one of them is
+// reserved for another project
+func (c *Config) Reserve(project string, ports ...int) error {
+	if err := validatePorts("port", dedupe(ports)); err != nil {
+		return err
+	}
+	for _, port := range ports {
+		if other, ok := c.Reservations[port]; ok && other != project {
+			return fmt.Errorf("port %d is reserved for %s, release it first", port, other)
+		}
+	}
+
+	if c.Reservations == nil {
+		c.Reservations = make(map[int]string)
+	}
+	for _, port := range ports {
+		c.Reservations[port] = project
+	}
+	return nil
+}
+
+// Release drops the reservations of ports
+func (c *Config) Release(ports ...int) error {
+	var missing []int
+	for _, port := range ports {
+		if _, ok := c.Reservations[port]; !ok {
+			missing = append(missing, port)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("not reserved: %v", missing)
+	}
+
+	for _, port := range ports {
+		delete(c.Reservations, port)
+	}
+	if len(c.Reservations) == 0 {
+		c.Reservations = nil
+	}
+	return nil
+}
+
+// tree returns the config as generic JSON values keyed by field name
+func (c *Config) tree() (map[string]interface{}, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := make(map[string]interface{})
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// decodeTree turns the settings, as Set edits them, back into a config
+func decodeTree(tree map[string]interface{}) (*Config, error) {
+	data, err := json.Marshal(tree)
+	if err != nil {
+		return nil, err
+	}
+	updated := &Config{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// settingNames returns the top-level JSON field names and types, including ones
+// omitted from the encoding because they are empty
+func settingNames() map[string]reflect.Type {
+	names := make(map[string]reflect.Type)
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[name] = t.Field(i).Type
+		}
+	}
+	return names
+}
+
+func splitKey(key string) []string {
+	key = strings.Trim(key, ".")
+	if key == "" {
+		return nil
+	}
+	return strings.Split(key, ".")
+}
+
+func dedupe(ports []int) []int {
+	seen := make(map[int]bool, len(ports))
+	result := make([]int, 0, len(ports))
+	for _, port := range ports {
+		if !seen[port] {
+			seen[port] = true
+			result = append(result, port)
+		}
+	}
+	return result
+}
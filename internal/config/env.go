@@ -0,0 +1,152 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// EnvPrefix starts the environment variables overriding settings, which
+// are named after them in upper case, e.g. PORTFINDER_CONFIRM_KILL
+const EnvPrefix = "PORTFINDER_"
+
+// ApplyEnv overrides the settings with the PORTFINDER_* environment
+// variables set. Values are JSON, or plain text for strings; lists may be
+// given comma separated too, e.g. PORTFINDER_COMMON_PORTS=3000,5432.
+// Variables naming no setting, such as PORTFINDER_PROFILE, are left alone.
+//
+// The variables are applied together and the result validated once. An
+// invalid variable is skipped and reported, the others still apply. Only
+// the variables are reported: the settings from elsewhere are left to the
+// config file's own checks, and can't hide an invalid variable.
+func (c *Config) ApplyEnv() error {
+	settings := settingNames()
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tree, err := c.tree()
+	if err != nil {
+		return err
+	}
+	original := make(map[string]interface{}, len(tree))
+	for name, value := range tree {
+		original[name] = value
+	}
+
+	var errs []error
+	// variables maps the settings applied to the variables setting them
+	variables := make(map[string]string)
+	for _, name := range names {
+		variable := EnvPrefix + strings.ToUpper(name)
+		value, ok := os.LookupEnv(variable)
+		if !ok {
+			continue
+		}
+		if t := settings[name]; t.Kind() == reflect.Slice && !strings.HasPrefix(strings.TrimSpace(value), "[") {
+			value = commaList(value, t.Elem().Kind() == reflect.String)
+		}
+
+		var parsed interface{}
+		if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+			parsed = value
+		}
+		// A value of the wrong type can't be told apart later
+		if _, err := decodeTree(map[string]interface{}{name: parsed}); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid value: %w", variable, err))
+			continue
+		}
+		tree[name] = parsed
+		variables[name] = variable
+	}
+
+	// check is what gets validated: the settings applied, with those
+	// invalid before any variable replaced by their defaults
+	check := make(map[string]interface{}, len(tree))
+	for name, value := range tree {
+		check[name] = value
+	}
+	defaults, err := DefaultConfig().tree()
+	if err != nil {
+		return err
+	}
+	defaulted := make(map[string]bool)
+	for {
+		updated, err := decodeTree(check)
+		if err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		err = updated.Validate()
+		if err == nil {
+			break
+		}
+		name := settingOf(err)
+		if variable, ok := variables[name]; ok {
+			// Drop the variable and validate the others again
+			errs = append(errs, fmt.Errorf("%s: %w", variable, err))
+			delete(variables, name)
+			resetSetting(tree, original, name)
+			resetSetting(check, original, name)
+			continue
+		}
+		if defaulted[name] {
+			break
+		}
+		defaulted[name] = true
+		resetSetting(check, defaults, name)
+	}
+
+	updated, err := decodeTree(tree)
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	*c = *updated
+	return errors.Join(errs...)
+}
+
+// resetSetting sets a setting of the tree back to its value in from, or
+// unsets it when from has none
+func resetSetting(tree, from map[string]interface{}, name string) {
+	if value, ok := from[name]; ok {
+		tree[name] = value
+	} else {
+		delete(tree, name)
+	}
+}
+
+// settingOf returns the setting a validation error is about, which its
+// message starts with, e.g. "color" for "color: expected auto, ..."
+func settingOf(err error) string {
+	if err == nil {
+		return ""
+	}
+	message := err.Error()
+	if i := strings.IndexAny(message, ":.["); i >= 0 {
+		return message[:i]
+	}
+	return message
+}
+
+// commaList turns a comma separated list into a JSON array, of strings or
+// of the items as they are
+func commaList(value string, quote bool) string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if quote {
+			encoded, _ := json.Marshal(item)
+			item = string(encoded)
+		}
+		items = append(items, item)
+	}
+	return "[" + strings.Join(items, ",") + "]"
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyEnv(t *testing.T) {
+	t.Setenv("PORTFINDER_COMMON_PORTS", "4000, 5432")
+	t.Setenv("PORTFINDER_CONFIRM_KILL", "false")
+	t.Setenv("PORTFINDER_PROTECTED_PROCESSES", "postgres*,redis-server")
+
+	cfg := DefaultConfig()
+	if err := cfg.ApplyEnv(); err != nil {
+		t.Fatalf("ApplyEnv() error = %v", err)
+	}
+	if want := []int{4000, 5432}; !reflect.DeepEqual(cfg.CommonPorts, want) {
+		t.Errorf("CommonPorts = %v, want %v", cfg.CommonPorts, want)
+	}
+	if cfg.ConfirmKill {
+		t.Error("ConfirmKill = true, want false")
+	}
+	if want := []string{"postgres*", "redis-server"}; !reflect.DeepEqual(cfg.ProtectedProcesses, want) {
+		t.Errorf("ProtectedProcesses = %v, want %v", cfg.ProtectedProcesses, want)
+	}
+}
+
+func TestApplyEnvInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		// file is an invalid setting of the config the variables apply to
+		file func(*Config)
+		// wantErr is the start of the error, "" when the variables are fine
+		wantErr string
+	}{
+		{
+			name:    "invalid value",
+			env:     map[string]string{"PORTFINDER_COLOR": "purple", "PORTFINDER_HISTORY": "true"},
+			wantErr: "PORTFINDER_COLOR: color: expected auto, always or never",
+		},
+		{
+			name:    "wrong type",
+			env:     map[string]string{"PORTFINDER_CONFIRM_KILL": "maybe", "PORTFINDER_HISTORY": "true"},
+			wantErr: "PORTFINDER_CONFIRM_KILL: invalid value",
+		},
+		{
+			name: "invalid config",
+			env:  map[string]string{"PORTFINDER_HISTORY": "true"},
+			file: func(c *Config) { c.Preset = "nope" },
+		},
+		{
+			name:    "invalid variable behind an invalid config",
+			env:     map[string]string{"PORTFINDER_COLOR": "purple", "PORTFINDER_HISTORY": "true"},
+			file:    func(c *Config) { c.CommonPorts = []int{70000} },
+			wantErr: "PORTFINDER_COLOR: color: expected auto, always or never",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			cfg := DefaultConfig()
+			if tt.file != nil {
+				tt.file(cfg)
+			}
+
+			err := cfg.ApplyEnv()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("ApplyEnv() error = %v, want none", err)
+			case tt.wantErr != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.wantErr)):
+				t.Errorf("ApplyEnv() error = %v, want it to start with %q", err, tt.wantErr)
+			}
+			// The valid variables still apply
+			if !cfg.History {
+				t.Error("PORTFINDER_HISTORY was dropped")
+			}
+			if cfg.Color != "" {
+				t.Errorf("Color = %q, want the invalid value skipped", cfg.Color)
+			}
+		})
+	}
+}
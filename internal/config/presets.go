@@ -0,0 +1,145 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Preset is a built-in set of categorized common ports for one kind of work
+type Preset struct {
+	Name        string
+	Description string
+	Groups      []PortGroup
+}
+
+// Ports returns every port of the preset, in display order
+func (p Preset) Ports() []int {
+	var ports []int
+	seen := make(map[int]bool)
+	for _, group := range p.Groups {
+		for _, port := range group.Ports {
+			if !seen[port] {
+				seen[port] = true
+				ports = append(ports, port)
+			}
+		}
+	}
+	return ports
+}
+
+// defaultPreset is used when the config doesn't name one
+const defaultPreset = "web-dev"
+
+var presets = []Preset{
+	{
+		Name:        "web-dev",
+		Description: "Frontend and backend web development",
+		Groups: []PortGroup{
+			{"Frontend", []int{3000, 3001, 4200, 5173, 8080}, map[int]string{
+				3000: "React, Node.js", 3001: "CRA fallback", 4200: "Angular", 5173: "Vite", 8080: "Vue, general web",
+			}},
+			{"Backend", []int{4000, 5000, 8000, 9000}, map[int]string{
+				4000: "Phoenix", 5000: "Flask", 8000: "Django", 9000: "PHP-FPM",
+			}},
+			{"Databases", []int{3306, 5432, 6379, 27017}, map[int]string{
+				3306: "MySQL", 5432: "PostgreSQL", 6379: "Redis", 27017: "MongoDB",
+			}},
+			{"Tools", []int{9200, 9090, 3100, 8983}, map[int]string{
+				9200: "Elasticsearch", 9090: "Prometheus", 3100: "Loki", 8983: "Solr",
+			}},
+		},
+	},
+	{
+		Name:        "data-engineering",
+		Description: "Databases, streaming and batch processing",
+		Groups: []PortGroup{
+			{"Databases", []int{5432, 3306, 27017, 6379, 9042, 8123}, map[int]string{
+				5432: "PostgreSQL", 3306: "MySQL", 27017: "MongoDB", 6379: "Redis", 9042: "Cassandra", 8123: "ClickHouse",
+			}},
+			{"Streaming", []int{9092, 2181, 8081, 8083, 5672, 15672}, map[int]string{
+				9092: "Kafka", 2181: "ZooKeeper", 8081: "Schema Registry", 8083: "Kafka Connect", 5672: "RabbitMQ", 15672: "RabbitMQ UI",
+			}},
+			{"Processing", []int{4040, 7077, 8080, 8888, 8088, 9870}, map[int]string{
+				4040: "Spark UI", 7077: "Spark master", 8080: "Airflow", 8888: "Jupyter", 8088: "Superset", 9870: "HDFS NameNode",
+			}},
+			{"Analytics", []int{9200, 5601, 3000}, map[int]string{
+				9200: "Elasticsearch", 5601: "Kibana", 3000: "Metabase",
+			}},
+		},
+	},
+	{
+		Name:        "mobile-dev",
+		Description: "React Native, Expo, Flutter and Android tooling",
+		Groups: []PortGroup{
+			{"Bundlers", []int{8081, 19000, 19001, 19002}, map[int]string{
+				8081: "Metro", 19000: "Expo", 19001: "Expo", 19002: "Expo DevTools",
+			}},
+			{"Debugging", []int{8097, 9100, 5037, 5554, 5555}, map[int]string{
+				8097: "React DevTools", 9100: "Flutter DevTools", 5037: "adb server", 5554: "Emulator console", 5555: "Emulator adb",
+			}},
+			{"Firebase Emulators", []int{4000, 8080, 9099, 5001, 9199}, map[int]string{
+				4000: "Emulator UI", 8080: "Firestore", 9099: "Auth", 5001: "Functions", 9199: "Storage",
+			}},
+			{"Backend", []int{3000, 8000}, map[int]string{
+				3000: "Node.js API", 8000: "Django API",
+			}},
+		},
+	},
+	{
+		Name:        "devops",
+		Description: "Containers, clusters, monitoring and infrastructure services",
+		Groups: []PortGroup{
+			{"Containers", []int{2375, 2376, 5000, 6443, 10250, 2379, 8001}, map[int]string{
+				2375: "Docker API", 2376: "Docker API (TLS)", 5000: "Registry", 6443: "Kubernetes API", 10250: "kubelet", 2379: "etcd", 8001: "kubectl proxy",
+			}},
+			{"Monitoring", []int{9090, 9093, 9100, 3000, 3100, 16686}, map[int]string{
+				9090: "Prometheus", 9093: "Alertmanager", 9100: "node_exporter", 3000: "Grafana", 3100: "Loki", 16686: "Jaeger",
+			}},
+			{"Infrastructure", []int{8080, 8200, 8500, 9000, 9001}, map[int]string{
+				8080: "Jenkins", 8200: "Vault", 8500: "Consul", 9000: "MinIO", 9001: "MinIO console",
+			}},
+		},
+	},
+}
+
+// Presets returns the built-in presets
+func Presets() []Preset {
+	return presets
+}
+
+// LookupPreset finds a built-in preset by name, ignoring case
+func LookupPreset(name string) (Preset, bool) {
+	for _, preset := range presets {
+		if strings.EqualFold(preset.Name, name) {
+			return preset, true
+		}
+	}
+	return Preset{}, false
+}
+
+// UsePreset switches the common ports and their categories to a preset
+func (c *Config) UsePreset(name string) error {
+	preset, ok := LookupPreset(name)
+	if !ok {
+		names := make([]string, 0, len(presets))
+		for _, p := range presets {
+			names = append(names, p.Name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown preset %q (available: %s)", name, strings.Join(names, ", "))
+	}
+
+	c.Preset = preset.Name
+	c.CommonPorts = preset.Ports()
+	return nil
+}
+
+// ActivePreset returns the preset categorizing the common ports
+func (c *Config) ActivePreset() Preset {
+	if preset, ok := LookupPreset(c.Preset); ok {
+		return preset
+	}
+	preset, _ := LookupPreset(defaultPreset)
+	return preset
+}
@@ -0,0 +1,247 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// keyError is a problem with the value of a key, named by its path
+type keyError struct {
+	path string
+	msg  string
+}
+
+func (e *keyError) Error() string {
+	return e.path + ": " + e.msg
+}
+
+// decodeConfig parses a config document, YAML or JSON, over the defaults.
+// Unknown keys and values of the wrong type are reported with the line
+// they are on.
+func decodeConfig(data []byte) (*Config, map[string]int, error) {
+	cfg := DefaultConfig()
+	tree, lines, err := parseYAML(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tree == nil {
+		return cfg, lines, nil
+	}
+	if _, ok := tree.(map[string]interface{}); !ok {
+		return nil, nil, &yamlError{line: 1, msg: "expected a mapping of settings, not " + describeValue(tree)}
+	}
+	if _, err := checkSchema(tree, reflect.TypeOf(Config{}), "", lines); err != nil {
+		return nil, nil, locate(err, lines)
+	}
+
+	data, err = json.Marshal(tree)
+	if err != nil {
+		return nil, nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return nil, nil, err
+	}
+	return cfg, lines, nil
+}
+
+// locate prefixes an error naming a key, as those of checkSchema and
+// Validate do, with the line the key is on
+func locate(err error, lines map[string]int) error {
+	key, _, ok := strings.Cut(err.Error(), ": ")
+	if !ok {
+		return err
+	}
+	// Fall back to the closest enclosing key that was written out
+	for key != "" {
+		if line, ok := lines[key]; ok {
+			return &yamlError{line: line, msg: err.Error()}
+		}
+		i := strings.LastIndexAny(key, ".[")
+		if i < 0 {
+			break
+		}
+		key = key[:i]
+	}
+	return err
+}
+
+// checkSchema compares a parsed value to the Go type it decodes into,
+// reporting unknown keys and values of the wrong type. Keys are checked in
+// the order of their lines, so the first problem in the file is reported.
+// Numbers given for text, such as cache_ttl: 0, are turned into text, as
+// YAML has no way to tell them apart unquoted.
+func checkSchema(value interface{}, t reflect.Type, path string, lines map[string]int) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		mapping, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, mismatch(path, "a mapping", value)
+		}
+		fields := jsonFields(t)
+		for _, key := range keysByLine(mapping, path, lines) {
+			field, ok := fields[key]
+			if !ok {
+				return nil, &keyError{path: joinPath(path, key), msg: unknownKey(key, fields)}
+			}
+			checked, err := checkSchema(mapping[key], field, joinPath(path, key), lines)
+			if err != nil {
+				return nil, err
+			}
+			mapping[key] = checked
+		}
+	case reflect.Map:
+		mapping, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, mismatch(path, "a mapping", value)
+		}
+		for _, key := range keysByLine(mapping, path, lines) {
+			if t.Key().Kind() == reflect.Int {
+				if _, err := strconv.Atoi(key); err != nil {
+					return nil, &keyError{path: joinPath(path, key), msg: "expected a port number as the key"}
+				}
+			}
+			checked, err := checkSchema(mapping[key], t.Elem(), joinPath(path, key), lines)
+			if err != nil {
+				return nil, err
+			}
+			mapping[key] = checked
+		}
+	case reflect.Slice:
+		list, ok := value.([]interface{})
+		if !ok {
+			return nil, mismatch(path, "a list", value)
+		}
+		for i, item := range list {
+			checked, err := checkSchema(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), lines)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = checked
+		}
+	case reflect.String:
+		switch v := value.(type) {
+		case string:
+		case json.Number:
+			return v.String(), nil
+		default:
+			return nil, mismatch(path, "text", value)
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			return nil, mismatch(path, "true or false", value)
+		}
+	case reflect.Int:
+		n, ok := value.(json.Number)
+		if _, err := n.Int64(); !ok || err != nil {
+			return nil, mismatch(path, "a whole number", value)
+		}
+	}
+	return value, nil
+}
+
+// jsonFields returns the types of a struct's fields by JSON name,
+// including those of embedded structs
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			for name, ft := range jsonFields(f.Type) {
+				fields[name] = ft
+			}
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = f.Type
+		}
+	}
+	return fields
+}
+
+// unknownKey explains an unknown key, suggesting the known one it is
+// closest to
+func unknownKey(key string, fields map[string]reflect.Type) string {
+	best, bestDistance := "", 3
+	for name := range fields {
+		if d := editDistance(key, name); d < bestDistance || d == bestDistance && best != "" && name < best {
+			best, bestDistance = name, d
+		}
+	}
+	if best != "" {
+		return fmt.Sprintf("unknown key, did you mean %q?", best)
+	}
+	return "unknown key"
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func mismatch(path, want string, value interface{}) error {
+	return &keyError{path: path, msg: fmt.Sprintf("expected %s, not %s", want, describeValue(value))}
+}
+
+// describeValue names the kind of a parsed value for error messages
+func describeValue(value interface{}) string {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return "a mapping"
+	case []interface{}:
+		return "a list"
+	case bool:
+		return fmt.Sprint(value)
+	case json.Number:
+		return value.String()
+	case string:
+		return strconv.Quote(value)
+	}
+	return "null"
+}
+
+// keysByLine returns the keys of a mapping in the order they are written
+func keysByLine(mapping map[string]interface{}, path string, lines map[string]int) []string {
+	keys := make([]string, 0, len(mapping))
+	for key := range mapping {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		li, lj := lines[joinPath(path, keys[i])], lines[joinPath(path, keys[j])]
+		if li != lj {
+			return li < lj
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Theme holds the colors of the TUI, each an ANSI 256 color number such as
+// "39" or a hex color such as "#0087ff". An empty color leaves the
+// terminal's own.
+type Theme struct {
+	// Accent colors headers and Docker processes
+	Accent string `json:"accent,omitempty"`
+	// Title and TitleText color the title bar
+	Title     string `json:"title,omitempty"`
+	TitleText string `json:"title_text,omitempty"`
+	// Selection and SelectionText color the selected row
+	Selection     string `json:"selection,omitempty"`
+	SelectionText string `json:"selection_text,omitempty"`
+	// Used and Free color port states
+	Used string `json:"used,omitempty"`
+	Free string `json:"free,omitempty"`
+	// Dim colors secondary text and table rules, Info notes and Hint
+	// suggestions and warnings
+	Dim  string `json:"dim,omitempty"`
+	Info string `json:"info,omitempty"`
+	Hint string `json:"hint,omitempty"`
+	// Border frames panes, Alert the confirmation dialogs
+	Border string `json:"border,omitempty"`
+	Alert  string `json:"alert,omitempty"`
+	// Spinner colors the loading spinner
+	Spinner string `json:"spinner,omitempty"`
+}
+
+// defaultTheme is used when the config doesn't name one
+const defaultTheme = "dark"
+
+// themes are the built-in themes by name
+var themes = map[string]Theme{
+	"dark": {
+		Accent: "39", Title: "62", TitleText: "230", Selection: "57", SelectionText: "229",
+		Used: "196", Free: "46", Dim: "240", Info: "86", Hint: "214",
+		Border: "62", Alert: "196", Spinner: "205",
+	},
+	// Darker shades that stay readable on a white background
+	"light": {
+		Accent: "25", Title: "25", TitleText: "231", Selection: "153", SelectionText: "16",
+		Used: "160", Free: "28", Dim: "244", Info: "30", Hint: "130",
+		Border: "25", Alert: "160", Spinner: "162",
+	},
+	// The 16 basic colors at their brightest, which terminals render with
+	// the most contrast
+	"high-contrast": {
+		Accent: "14", Title: "15", TitleText: "0", Selection: "11", SelectionText: "0",
+		Used: "9", Free: "10", Dim: "7", Info: "14", Hint: "11",
+		Border: "15", Alert: "9", Spinner: "15",
+	},
+	"no-color": {},
+}
+
+// ThemeNames returns the names of the built-in themes, sorted
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ActiveTheme returns the theme the config names, dark by default, with
+// the colors it overrides
+func (c *Config) ActiveTheme() Theme {
+	theme, ok := themes[c.Theme]
+	if !ok {
+		theme = themes[defaultTheme]
+	}
+	if c.Colors == nil {
+		return theme
+	}
+
+	colors := theme.colorFields()
+	for name, override := range c.Colors.colorFields() {
+		if *override != "" {
+			*colors[name] = *override
+		}
+	}
+	return theme
+}
+
+// colorFields returns the theme's colors by their key in the config
+func (t *Theme) colorFields() map[string]*string {
+	return map[string]*string{
+		"accent": &t.Accent, "title": &t.Title, "title_text": &t.TitleText,
+		"selection": &t.Selection, "selection_text": &t.SelectionText,
+		"used": &t.Used, "free": &t.Free, "dim": &t.Dim, "info": &t.Info, "hint": &t.Hint,
+		"border": &t.Border, "alert": &t.Alert, "spinner": &t.Spinner,
+	}
+}
+
+// hexColor matches #rgb and #rrggbb colors
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// validateTheme checks the theme name and the colors overriding it
+func (c *Config) validateTheme() error {
+	if _, ok := themes[c.Theme]; c.Theme != "" && !ok {
+		return fmt.Errorf("theme: unknown theme %q, expected one of %s", c.Theme, strings.Join(ThemeNames(), ", "))
+	}
+	if c.Colors == nil {
+		return nil
+	}
+
+	colors := c.Colors.colorFields()
+	names := make([]string, 0, len(colors))
+	for name := range colors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		color := *colors[name]
+		if n, err := strconv.Atoi(color); color == "" || err == nil && n >= 0 && n <= 255 || hexColor.MatchString(color) {
+			continue
+		}
+		return fmt.Errorf("colors.%s: expected a color number from 0 to 255 or #rrggbb, not %q", name, color)
+	}
+	return nil
+}
@@ -0,0 +1,506 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// yamlParser reads the subset of YAML the config needs: block and flow
+// mappings and sequences, plain and quoted scalars, and comments. JSON is
+// part of that subset, so configs written before the move to YAML parse
+// as well.
+type yamlParser struct {
+	src string
+	pos int
+	// lines records the line of each key and list item by its path, e.g.
+	// "groups.web" or "hooks[0]"
+	lines map[string]int
+}
+
+// parseYAML parses a document into generic values: map[string]interface{}
+// for mappings, []interface{} for sequences, and string, bool, nil or
+// json.Number for scalars. It also returns the line of each key.
+func parseYAML(data []byte) (interface{}, map[string]int, error) {
+	src := strings.TrimPrefix(string(data), "\ufeff")
+	p := &yamlParser{src: strings.ReplaceAll(src, "\r\n", "\n"), lines: make(map[string]int)}
+
+	if !p.skipBlank() {
+		return nil, p.lines, nil
+	}
+	if p.column() == 0 && strings.HasPrefix(p.src[p.pos:], "---") {
+		p.pos += 3
+		if err := p.endLine(); err != nil {
+			return nil, nil, err
+		}
+		if !p.skipBlank() {
+			return nil, p.lines, nil
+		}
+	}
+
+	value, err := p.parseNode("")
+	if err != nil {
+		return nil, nil, err
+	}
+	if p.skipBlank() {
+		return nil, nil, p.errorf("unexpected %q", p.lineRest())
+	}
+	return value, p.lines, nil
+}
+
+// yamlError is a syntax error at a line of the document
+type yamlError struct {
+	line int
+	msg  string
+}
+
+func (e *yamlError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.line, e.msg)
+}
+
+func (p *yamlParser) errorf(format string, args ...interface{}) error {
+	return &yamlError{line: p.line(), msg: fmt.Sprintf(format, args...)}
+}
+
+// line is the 1-based line of the current position
+func (p *yamlParser) line() int {
+	return strings.Count(p.src[:p.pos], "\n") + 1
+}
+
+// column is the current position's offset into its line
+func (p *yamlParser) column() int {
+	return p.pos - (strings.LastIndexByte(p.src[:p.pos], '\n') + 1)
+}
+
+// lineRest is the text from the current position to the end of the line
+func (p *yamlParser) lineRest() string {
+	rest := p.src[p.pos:]
+	if i := strings.IndexByte(rest, '\n'); i >= 0 {
+		rest = rest[:i]
+	}
+	return strings.TrimSpace(rest)
+}
+
+// skipBlank skips whitespace, line breaks and comments, reporting whether
+// anything is left
+func (p *yamlParser) skipBlank() bool {
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		case '#':
+			p.skipComment()
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// skipSpaces skips the spaces and tabs before a value on the same line
+func (p *yamlParser) skipSpaces() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *yamlParser) skipComment() {
+	if i := strings.IndexByte(p.src[p.pos:], '\n'); i >= 0 {
+		p.pos += i
+	} else {
+		p.pos = len(p.src)
+	}
+}
+
+// atLineEnd reports whether only a comment, if anything, is left on the
+// line
+func (p *yamlParser) atLineEnd() bool {
+	return p.pos == len(p.src) || p.src[p.pos] == '\n' || p.src[p.pos] == '#'
+}
+
+// endLine checks that nothing but a comment follows a value on its line
+func (p *yamlParser) endLine() error {
+	p.skipSpaces()
+	if !p.atLineEnd() {
+		return p.errorf("unexpected %q after the value", p.lineRest())
+	}
+	p.skipComment()
+	return nil
+}
+
+// checkIndent rejects lines indented with tabs, which YAML forbids
+func (p *yamlParser) checkIndent() error {
+	for i := strings.LastIndexByte(p.src[:p.pos], '\n') + 1; i < p.pos; i++ {
+		switch p.src[i] {
+		case '\t':
+			return p.errorf("indent with spaces, YAML doesn't allow tabs")
+		case ' ':
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// isDash reports whether a sequence item starts at the current position
+func (p *yamlParser) isDash() bool {
+	rest := p.src[p.pos:]
+	return strings.HasPrefix(rest, "-") && (len(rest) == 1 || rest[1] == ' ' || rest[1] == '\n')
+}
+
+// scanKey looks for a "key:" at the current position without consuming
+// it, returning the key and the position after the colon
+func (p *yamlParser) scanKey() (string, int, bool) {
+	rest := p.src[p.pos:]
+	if i := strings.IndexByte(rest, '\n'); i >= 0 {
+		rest = rest[:i]
+	}
+	if rest == "" || strings.ContainsRune("[{&*!|>", rune(rest[0])) {
+		return "", 0, false
+	}
+
+	isColon := func(i int) bool {
+		return i < len(rest) && rest[i] == ':' && (i+1 == len(rest) || rest[i+1] == ' ' || rest[i+1] == '\t')
+	}
+	if rest[0] == '"' || rest[0] == '\'' {
+		key, n, err := scanQuoted(rest)
+		if err != nil {
+			return "", 0, false
+		}
+		for n < len(rest) && rest[n] == ' ' {
+			n++
+		}
+		if !isColon(n) {
+			return "", 0, false
+		}
+		return key, p.pos + n + 1, true
+	}
+	for i := 0; i < len(rest); i++ {
+		if rest[i] == '#' && i > 0 && rest[i-1] == ' ' {
+			break
+		}
+		if isColon(i) {
+			return strings.TrimSpace(rest[:i]), p.pos + i + 1, true
+		}
+	}
+	return "", 0, false
+}
+
+// parseNode parses the block, flow collection or scalar starting at the
+// current position
+func (p *yamlParser) parseNode(path string) (interface{}, error) {
+	if err := p.checkIndent(); err != nil {
+		return nil, err
+	}
+	if p.isDash() {
+		return p.parseSequence(p.column(), path)
+	}
+	if _, _, ok := p.scanKey(); ok {
+		return p.parseMapping(p.column(), path)
+	}
+	value, err := p.parseInline(path)
+	if err != nil {
+		return nil, err
+	}
+	return value, p.endLine()
+}
+
+// parseMapping parses the keys of a block mapping indented by col
+func (p *yamlParser) parseMapping(col int, path string) (map[string]interface{}, error) {
+	mapping := make(map[string]interface{})
+	for {
+		if err := p.checkIndent(); err != nil {
+			return nil, err
+		}
+		key, end, ok := p.scanKey()
+		if !ok {
+			return nil, p.errorf("expected \"key: value\", not %q", p.lineRest())
+		}
+		keyPath := joinPath(path, key)
+		if _, ok := mapping[key]; ok {
+			return nil, p.errorf("%s is set twice", keyPath)
+		}
+		p.lines[keyPath] = p.line()
+		p.pos = end
+
+		value, err := p.parseValue(col, keyPath, false)
+		if err != nil {
+			return nil, err
+		}
+		mapping[key] = value
+
+		if !p.skipBlank() || p.column() < col {
+			return mapping, nil
+		}
+		if p.column() > col {
+			return nil, p.errorf("unexpected indentation")
+		}
+	}
+}
+
+// parseSequence parses the items of a block sequence indented by col
+func (p *yamlParser) parseSequence(col int, path string) ([]interface{}, error) {
+	list := []interface{}{}
+	for {
+		if err := p.checkIndent(); err != nil {
+			return nil, err
+		}
+		itemPath := fmt.Sprintf("%s[%d]", path, len(list))
+		p.lines[itemPath] = p.line()
+		p.pos++
+
+		value, err := p.parseValue(col, itemPath, true)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+
+		if !p.skipBlank() || p.column() < col {
+			return list, nil
+		}
+		if p.column() > col {
+			return nil, p.errorf("unexpected indentation")
+		}
+		if !p.isDash() {
+			// The next key of the mapping holding the sequence
+			return list, nil
+		}
+	}
+}
+
+// parseValue parses what follows a key or dash at col: a value on the same
+// line, or a block indented below it. An item's value may itself start a
+// mapping or sequence on the dash's line.
+func (p *yamlParser) parseValue(col int, path string, item bool) (interface{}, error) {
+	p.skipSpaces()
+	if !p.atLineEnd() {
+		if _, _, ok := p.scanKey(); item && (ok || p.isDash()) {
+			return p.parseNode(path)
+		}
+		value, err := p.parseInline(path)
+		if err != nil {
+			return nil, err
+		}
+		return value, p.endLine()
+	}
+
+	p.skipComment()
+	if !p.skipBlank() {
+		return nil, nil
+	}
+	// A mapping's sequence may sit at the mapping's own indentation
+	if p.column() > col || (p.column() == col && !item && p.isDash()) {
+		return p.parseNode(path)
+	}
+	return nil, nil
+}
+
+// parseInline parses a flow collection or scalar on the current line
+func (p *yamlParser) parseInline(path string) (interface{}, error) {
+	switch p.src[p.pos] {
+	case '[', '{':
+		return p.parseFlow(path)
+	case '"', '\'':
+		s, n, err := scanQuoted(p.src[p.pos:])
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		p.pos += n
+		return s, nil
+	case '|', '>':
+		return nil, p.errorf("block scalars aren't supported, quote the text instead")
+	case '&', '*', '!':
+		return nil, p.errorf("anchors, aliases and tags aren't supported")
+	}
+
+	text := p.src[p.pos:]
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	if i := strings.Index(text, " #"); i >= 0 {
+		text = text[:i]
+	}
+	p.pos += len(text)
+	return plainValue(strings.TrimSpace(text)), nil
+}
+
+// parseFlow parses a [list] or {mapping}, which may span lines
+func (p *yamlParser) parseFlow(path string) (interface{}, error) {
+	open := p.src[p.pos]
+	p.pos++
+
+	if open == '[' {
+		list := []interface{}{}
+		for {
+			if !p.skipBlank() {
+				return nil, p.errorf("unclosed [")
+			}
+			if p.src[p.pos] == ']' {
+				p.pos++
+				return list, nil
+			}
+			itemPath := fmt.Sprintf("%s[%d]", path, len(list))
+			p.lines[itemPath] = p.line()
+			value, err := p.parseFlowValue(itemPath)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+			if err := p.flowSeparator(']'); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	mapping := make(map[string]interface{})
+	for {
+		if !p.skipBlank() {
+			return nil, p.errorf("unclosed {")
+		}
+		if p.src[p.pos] == '}' {
+			p.pos++
+			return mapping, nil
+		}
+
+		var key string
+		line := p.line()
+		if c := p.src[p.pos]; c == '"' || c == '\'' {
+			s, n, err := scanQuoted(p.src[p.pos:])
+			if err != nil {
+				return nil, p.errorf("%v", err)
+			}
+			key = s
+			p.pos += n
+			p.skipBlank()
+		} else {
+			end := strings.IndexAny(p.src[p.pos:], ":,}\n")
+			if end < 0 {
+				return nil, p.errorf("unclosed {")
+			}
+			key = strings.TrimSpace(p.src[p.pos : p.pos+end])
+			p.pos += end
+		}
+		if p.pos == len(p.src) || p.src[p.pos] != ':' {
+			return nil, p.errorf("expected \"key: value\" in {}")
+		}
+		p.pos++
+
+		keyPath := joinPath(path, key)
+		if _, ok := mapping[key]; ok {
+			return nil, p.errorf("%s is set twice", keyPath)
+		}
+		p.lines[keyPath] = line
+		value, err := p.parseFlowValue(keyPath)
+		if err != nil {
+			return nil, err
+		}
+		mapping[key] = value
+		if err := p.flowSeparator('}'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseFlowValue parses an item or value of a flow collection
+func (p *yamlParser) parseFlowValue(path string) (interface{}, error) {
+	if !p.skipBlank() {
+		return nil, p.errorf("unexpected end of file")
+	}
+	switch p.src[p.pos] {
+	case '[', '{', '"', '\'', '&', '*', '!':
+		return p.parseInline(path)
+	}
+	end := strings.IndexAny(p.src[p.pos:], ",]}\n")
+	if end < 0 {
+		end = len(p.src) - p.pos
+	}
+	text := p.src[p.pos : p.pos+end]
+	if i := strings.Index(text, " #"); i >= 0 {
+		text = text[:i]
+	}
+	p.pos += len(text)
+	return plainValue(strings.TrimSpace(text)), nil
+}
+
+// flowSeparator consumes the comma after a flow item, leaving the closing
+// bracket for the caller
+func (p *yamlParser) flowSeparator(closing byte) error {
+	if !p.skipBlank() {
+		return p.errorf("expected , or %c", closing)
+	}
+	switch p.src[p.pos] {
+	case ',':
+		p.pos++
+		return nil
+	case closing:
+		return nil
+	}
+	return p.errorf("expected , or %c, not %q", closing, p.lineRest())
+}
+
+// scanQuoted reads the double or single quoted string s starts with,
+// returning it and the length of its quoted form
+func scanQuoted(s string) (string, int, error) {
+	if s[0] == '\'' {
+		var b strings.Builder
+		for i := 1; i < len(s); i++ {
+			switch {
+			case s[i] == '\n':
+				return "", 0, fmt.Errorf("unterminated string")
+			case s[i] == '\'' && i+1 < len(s) && s[i+1] == '\'':
+				b.WriteByte('\'')
+				i++
+			case s[i] == '\'':
+				return b.String(), i + 1, nil
+			default:
+				b.WriteByte(s[i])
+			}
+		}
+		return "", 0, fmt.Errorf("unterminated string")
+	}
+
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\n':
+			return "", 0, fmt.Errorf("unterminated string")
+		case '\\':
+			i++
+		case '"':
+			// JSON escapes are a subset of YAML's
+			var value string
+			if err := json.Unmarshal([]byte(s[:i+1]), &value); err != nil {
+				return "", 0, fmt.Errorf("invalid string %s", s[:i+1])
+			}
+			return value, i + 1, nil
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+// yamlNumber matches plain scalars that are also JSON numbers
+var yamlNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+
+// plainValue resolves an unquoted scalar to null, a boolean, a number or
+// a string
+func plainValue(s string) interface{} {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	if yamlNumber.MatchString(s) {
+		return json.Number(s)
+	}
+	return s
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type yamlMap = map[string]interface{}
+type yamlList = []interface{}
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want interface{}
+	}{
+		{"empty", "", nil},
+		{"only comments", "# nothing here\n\n# still nothing\n", nil},
+		{"document marker", "---\ncolor: never\n", yamlMap{"color": "never"}},
+		{"byte order mark", "\ufeffcolor: never\n", yamlMap{"color": "never"}},
+		{"crlf", "color: never\r\nhistory: true\r\n", yamlMap{"color": "never", "history": true}},
+		{
+			"plain scalars",
+			"a: 3000\nb: -1.5e3\nc: true\nd: False\ne: ~\nf: null\ng:\nh: hello world\ni: 0755\n",
+			yamlMap{
+				"a": json.Number("3000"), "b": json.Number("-1.5e3"), "c": true, "d": false,
+				"e": nil, "f": nil, "g": nil, "h": "hello world", "i": "0755",
+			},
+		},
+		{
+			"quoted scalars",
+			`a: "x: y # not a comment"` + "\n" + `b: 'it''s'` + "\n" + `c: "tab\tnew\nline"` + "\n" + `d: "3000"` + "\n",
+			yamlMap{"a": "x: y # not a comment", "b": "it's", "c": "tab\tnew\nline", "d": "3000"},
+		},
+		{"quoted key", `"my key": 1` + "\n", yamlMap{"my key": json.Number("1")}},
+		{"trailing comments", "a: 1 # one\nb: two#not a comment\n", yamlMap{"a": json.Number("1"), "b": "two#not a comment"}},
+		{"colon in value", "url: http://localhost:3000\n", yamlMap{"url": "http://localhost:3000"}},
+		{
+			"nested mappings",
+			"groups:\n  web:\n    - 3000\n    - 8080\n  db: [5432, 6379]\n",
+			yamlMap{"groups": yamlMap{
+				"web": yamlList{json.Number("3000"), json.Number("8080")},
+				"db":  yamlList{json.Number("5432"), json.Number("6379")},
+			}},
+		},
+		{
+			"sequence at the mapping's indentation",
+			"common_ports:\n- 3000\n- 4000\ncolor: never\n",
+			yamlMap{"common_ports": yamlList{json.Number("3000"), json.Number("4000")}, "color": "never"},
+		},
+		{
+			"mappings in a sequence",
+			"hooks:\n  - port: 3000\n    run: echo hi\n  - port: 4000\n",
+			yamlMap{"hooks": yamlList{
+				yamlMap{"port": json.Number("3000"), "run": "echo hi"},
+				yamlMap{"port": json.Number("4000")},
+			}},
+		},
+		{"nested sequences", "- - 1\n  - 2\n- 3\n", yamlList{yamlList{json.Number("1"), json.Number("2")}, json.Number("3")}},
+		{
+			"flow collections across lines",
+			"labels: {3000: web,\n  \"5432\": 'db'}\nports: [\n  1, 2, # two\n  3,\n]\n",
+			yamlMap{
+				"labels": yamlMap{"3000": "web", "5432": "db"},
+				"ports":  yamlList{json.Number("1"), json.Number("2"), json.Number("3")},
+			},
+		},
+		{"spaces in flow items", "a: [1 2, b c]\n", yamlMap{"a": yamlList{"1 2", "b c"}}},
+		{"empty flow collections", "a: []\nb: {}\n", yamlMap{"a": yamlList{}, "b": yamlMap{}}},
+		{
+			"json",
+			`{"common_ports": [3000, 4000], "confirm_kill": true, "labels": {"3000": "web"}}`,
+			yamlMap{
+				"common_ports": yamlList{json.Number("3000"), json.Number("4000")},
+				"confirm_kill": true,
+				"labels":       yamlMap{"3000": "web"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := parseYAML([]byte(tt.src))
+			if err != nil {
+				t.Fatalf("parseYAML() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseYAML() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		// want is the start of the error, with the line it points at
+		want string
+	}{
+		{"duplicate key", "color: never\ncolor: always\n", "line 2: color is set twice"},
+		{"duplicate flow key", "labels: {a: 1, a: 2}\n", "line 1: labels.a is set twice"},
+		{"tab indent", "groups:\n\tweb: [3000]\n", "line 2: indent with spaces"},
+		{"over indented key", "color: never\n  history: true\n", "line 2: unexpected indentation"},
+		{"block scalar", "run: |\n  echo hi\n", "line 1: block scalars aren't supported"},
+		{"anchor", "a: &x 1\n", "line 1: anchors, aliases and tags aren't supported"},
+		{"alias in flow", "a: [*x]\n", "line 1: anchors, aliases and tags aren't supported"},
+		{"unclosed list", "a: [1, 2\n", "line 2: expected , or ]"},
+		{"unclosed empty list", "a: [\n", "line 2: unclosed ["},
+		{"unclosed mapping", "a: {b: 1\n", "line 2: expected , or }"},
+		{"missing comma", "a: [\"x\" 2]\n", "line 1: expected , or ]"},
+		{"unterminated string", "a: \"open\n", "line 1: unterminated string"},
+		{"text after value", "a: [1] extra\n", "line 1: unexpected \"extra\" after the value"},
+		{"not a key", "color: never\njust text\n", "line 2: expected \"key: value\""},
+		{"second document", "a: 1\n--- b\n", "line 2: expected \"key: value\""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := parseYAML([]byte(tt.src))
+			if err == nil {
+				t.Fatalf("parseYAML() succeeded, want error %q", tt.want)
+			}
+			if !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("parseYAML() error = %q, want it to start with %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYAMLLines(t *testing.T) {
+	src := "# config\ncolor: never\ngroups:\n  web:\n    - 3000\n    - 8080\nports: [1,\n  2]\n"
+	_, lines, err := parseYAML([]byte(src))
+	if err != nil {
+		t.Fatalf("parseYAML() error = %v", err)
+	}
+	want := map[string]int{
+		"color":         2,
+		"groups":        3,
+		"groups.web":    4,
+		"groups.web[0]": 5,
+		"groups.web[1]": 6,
+		"ports":         7,
+		"ports[0]":      7,
+		"ports[1]":      8,
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("lines = %v, want %v", lines, want)
+	}
+}
+
+func TestDecodeConfigRejectsUnknownKeys(t *testing.T) {
+	_, _, err := decodeConfig([]byte("protected_ports: [7788]\nconfirm_kil: true\n"))
+	if err == nil {
+		t.Fatal("decodeConfig() accepted a misspelled key")
+	}
+	if want := "line 2: confirm_kil"; !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("decodeConfig() error = %q, want it to start with %q", err, want)
+	}
+}
@@ -0,0 +1,427 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultSocket is where Docker Engine listens on Linux and macOS
+	defaultSocket = "/var/run/docker.sock"
+	// requestTimeout bounds quick metadata queries
+	requestTimeout = 3 * time.Second
+	// stopTimeout leaves room for the daemon's own 10s stop grace period
+	stopTimeout = 15 * time.Second
+)
+
+// Container holds the subset of container metadata portfinder cares about
+type Container struct {
+	ID             string
+	Name           string
+	Image          string
+	ComposeProject string
+	ComposeService string
+	// ComposeWorkingDir is the host directory compose was run from
+	ComposeWorkingDir string
+	// State is e.g. "running", "exited" or "restarting"
+	State string
+	// IPAddress is the container's address on its first network, by name,
+	// and empty on the host network
+	IPAddress string
+	Ports     []PortMapping
+	Mounts    []Mount
+}
+
+// Mount is a volume or host directory mounted into a container
+type Mount struct {
+	// Type is "bind" for host directories, else e.g. "volume"
+	Type        string
+	Source      string
+	Destination string
+}
+
+// HostPath translates a path inside the container to the host directory
+// bind mounted there, or "" when no bind mount covers it
+func (c Container) HostPath(path string) string {
+	best := -1
+	for i, m := range c.Mounts {
+		if m.Type != "bind" || !within(path, m.Destination) {
+			continue
+		}
+		if best < 0 || len(m.Destination) > len(c.Mounts[best].Destination) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return ""
+	}
+	m := c.Mounts[best]
+	return m.Source + strings.TrimPrefix(path, strings.TrimSuffix(m.Destination, "/"))
+}
+
+// within reports whether path is dir or below it
+func within(path, dir string) bool {
+	dir = strings.TrimSuffix(dir, "/")
+	return path == dir || strings.HasPrefix(path, dir+"/")
+}
+
+// PortMapping describes a port published by a container
+type PortMapping struct {
+	IP          string
+	PrivatePort int
+	PublicPort  int
+	Type        string
+}
+
+// Client talks to the Docker Engine API
+type Client struct {
+	http     *http.Client
+	base     string
+	endpoint Endpoint
+}
+
+// NewClient creates a client for the first reachable daemon among the
+// endpoints returned by Candidates
+func NewClient() (*Client, error) {
+	endpoint, probes := Detect()
+	if endpoint == nil {
+		if len(probes) == 1 {
+			return nil, fmt.Errorf("docker daemon not available at %s: %w", probes[0].Endpoint.Host, probes[0].Err)
+		}
+		return nil, fmt.Errorf("no docker daemon found (tried %d endpoints)", len(probes))
+	}
+
+	return newClient(*endpoint)
+}
+
+// NewClients creates a client for each runtime answering, see DetectAll
+func NewClients(ctx context.Context) []*Client {
+	var clients []*Client
+	for _, endpoint := range DetectAll(ctx) {
+		if client, err := newClient(endpoint); err == nil {
+			clients = append(clients, client)
+		}
+	}
+	return clients
+}
+
+// NewClientFor creates a client for the daemon of a runtime, such as
+// RuntimePodman, or for the first one answering when runtime is empty
+func NewClientFor(runtime string) (*Client, error) {
+	if runtime == "" {
+		return NewClient()
+	}
+	for _, endpoint := range DetectAll(context.Background()) {
+		if endpoint.Runtime == runtime {
+			return newClient(endpoint)
+		}
+	}
+	return nil, fmt.Errorf("no %s daemon found", runtime)
+}
+
+// NewClientAt creates a client for the daemon of the named candidate
+// endpoint, e.g. "Colima", when it answers
+func NewClientAt(ctx context.Context, name string) (*Client, error) {
+	for _, endpoint := range Candidates() {
+		if endpoint.Name != name {
+			continue
+		}
+		runtime, err := ping(ctx, endpoint.Host)
+		if err != nil {
+			continue
+		}
+		endpoint.Runtime = runtime
+		return newClient(endpoint)
+	}
+	return nil, fmt.Errorf("no %s daemon found", name)
+}
+
+func newClient(endpoint Endpoint) (*Client, error) {
+	httpClient, base, err := newTransport(endpoint.Host)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{http: httpClient, base: base, endpoint: endpoint}, nil
+}
+
+// Endpoint returns the endpoint the client talks to
+func (c *Client) Endpoint() Endpoint {
+	return c.endpoint
+}
+
+// Runtime returns the runtime of the daemon, RuntimeDocker or
+// RuntimePodman
+func (c *Client) Runtime() string {
+	return c.endpoint.Runtime
+}
+
+// apiContainer mirrors the JSON returned by GET /containers/json
+type apiContainer struct {
+	ID     string            `json:"Id"`
+	Names  []string          `json:"Names"`
+	Image  string            `json:"Image"`
+	Labels map[string]string `json:"Labels"`
+	State  string            `json:"State"`
+	Mounts []struct {
+		Type        string `json:"Type"`
+		Source      string `json:"Source"`
+		Destination string `json:"Destination"`
+	} `json:"Mounts"`
+	Ports []struct {
+		IP          string `json:"IP"`
+		PrivatePort int    `json:"PrivatePort"`
+		PublicPort  int    `json:"PublicPort"`
+		Type        string `json:"Type"`
+	} `json:"Ports"`
+	NetworkSettings struct {
+		Networks map[string]struct {
+			IPAddress string `json:"IPAddress"`
+		} `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+// ListContainers returns all running containers
+func (c *Client) ListContainers(ctx context.Context) ([]Container, error) {
+	return c.listContainers(ctx, nil)
+}
+
+// ComposeContainers returns the containers of a compose project, stopped
+// ones included
+func (c *Client) ComposeContainers(project string) ([]Container, error) {
+	filters, err := json.Marshal(map[string][]string{
+		"label": {"com.docker.compose.project=" + project},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.listContainers(context.Background(), url.Values{"all": {"1"}, "filters": {string(filters)}})
+}
+
+func (c *Client) listContainers(ctx context.Context, query url.Values) ([]Container, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	endpoint := c.base + "/containers/json"
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := c.http.Do(req)
+	if err != nil {
+		slog.Info("docker request failed", "url", endpoint, "took", time.Since(start), "err", err)
+		return nil, fmt.Errorf("docker request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	slog.Info("docker request", "url", endpoint, "status", resp.StatusCode, "took", time.Since(start))
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("docker returned %s", resp.Status)
+	}
+
+	var raw []apiContainer
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode docker response: %w", err)
+	}
+
+	containers := make([]Container, 0, len(raw))
+	for _, rc := range raw {
+		container := Container{
+			ID:             rc.ID,
+			Image:          rc.Image,
+			ComposeProject: rc.Labels["com.docker.compose.project"],
+			ComposeService: rc.Labels["com.docker.compose.service"],
+			// Set by compose v2
+			ComposeWorkingDir: rc.Labels["com.docker.compose.project.working_dir"],
+			State:             rc.State,
+		}
+		if len(rc.Names) > 0 {
+			container.Name = strings.TrimPrefix(rc.Names[0], "/")
+		}
+		networks := slices.Sorted(maps.Keys(rc.NetworkSettings.Networks))
+		for _, name := range networks {
+			if ip := rc.NetworkSettings.Networks[name].IPAddress; ip != "" {
+				container.IPAddress = ip
+				break
+			}
+		}
+		for _, m := range rc.Mounts {
+			container.Mounts = append(container.Mounts, Mount{Type: m.Type, Source: m.Source, Destination: m.Destination})
+		}
+		for _, p := range rc.Ports {
+			container.Ports = append(container.Ports, PortMapping{
+				IP:          p.IP,
+				PrivatePort: p.PrivatePort,
+				PublicPort:  p.PublicPort,
+				Type:        p.Type,
+			})
+		}
+		containers = append(containers, container)
+	}
+
+	return containers, nil
+}
+
+// StopContainer stops the container with the given ID or name
+func (c *Client) StopContainer(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.base+"/containers/"+url.PathEscape(id)+"/stop", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("docker request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 304 means the container was already stopped
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotModified {
+		return fmt.Errorf("failed to stop container %s: %s", id, resp.Status)
+	}
+
+	return nil
+}
+
+// PauseContainer freezes every process of a container, keeping its ports
+// bound
+func (c *Client) PauseContainer(id string) error {
+	return c.post(id, "pause")
+}
+
+// UnpauseContainer resumes a paused container
+func (c *Client) UnpauseContainer(id string) error {
+	return c.post(id, "unpause")
+}
+
+// post sends a request without body to a container endpoint, e.g. pause
+func (c *Client) post(id, action string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.base+"/containers/"+url.PathEscape(id)+"/"+action, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("docker request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("failed to %s container %s: %s", action, id, resp.Status)
+	}
+
+	return nil
+}
+
+// FollowLogs streams the last lines of a container's stdout and stderr,
+// then new ones as they are written, until ctx is done
+func (c *Client) FollowLogs(ctx context.Context, id string, tail int) (io.ReadCloser, error) {
+	query := url.Values{"follow": {"1"}, "stdout": {"1"}, "stderr": {"1"}, "tail": {strconv.Itoa(tail)}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.base+"/containers/"+url.PathEscape(id)+"/logs?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("docker request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to read the logs of container %s: %s", id, resp.Status)
+	}
+
+	r, w := io.Pipe()
+	go func() {
+		defer resp.Body.Close()
+		w.CloseWithError(demuxLogs(bufio.NewReader(resp.Body), w))
+	}()
+	return r, nil
+}
+
+// demuxLogs copies a log stream to w. Containers without a TTY send their
+// output in frames, each with an 8 byte header naming the stream and the
+// frame's length; a TTY's output comes as it is.
+func demuxLogs(r *bufio.Reader, w io.Writer) error {
+	header, err := r.Peek(8)
+	if err != nil && len(header) == 0 {
+		return err
+	}
+	if len(header) < 8 || header[0] > 2 || header[1] != 0 || header[2] != 0 || header[3] != 0 {
+		_, err := io.Copy(w, r)
+		return err
+	}
+
+	header = make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(w, r, int64(binary.BigEndian.Uint32(header[4:]))); err != nil {
+			return err
+		}
+	}
+}
+
+// FindByPort returns the container publishing the given host port
+func FindByPort(containers []Container, port int) *Container {
+	for i := range containers {
+		for _, p := range containers[i].Ports {
+			if p.PublicPort == port {
+				return &containers[i]
+			}
+		}
+	}
+	return nil
+}
+
+// Mapping returns the mapping publishing the host port, preferring TCP
+func (c Container) Mapping(port int) (PortMapping, bool) {
+	var found PortMapping
+	for _, m := range c.Ports {
+		if m.PublicPort != port {
+			continue
+		}
+		if m.Type == "tcp" {
+			return m, true
+		}
+		found = m
+	}
+	return found, found.PublicPort != 0
+}
+
+// FindByID returns the container whose ID starts with the given prefix
+func FindByID(containers []Container, id string) *Container {
+	if id == "" || id == "unknown" {
+		return nil
+	}
+	for i := range containers {
+		if strings.HasPrefix(containers[i].ID, id) {
+			return &containers[i]
+		}
+	}
+	return nil
+}
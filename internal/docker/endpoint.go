@@ -0,0 +1,258 @@
+package docker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// pingTimeout bounds the reachability check of each candidate endpoint
+const pingTimeout = time.Second
+
+// Container runtimes speaking the Docker API
+const (
+	RuntimeDocker = "docker"
+	RuntimePodman = "podman"
+)
+
+// Endpoint is a place a Docker-compatible daemon may listen
+type Endpoint struct {
+	// Name says where the endpoint comes from, e.g. "Docker Desktop"
+	Name string
+	// Host is the endpoint in DOCKER_HOST syntax, e.g. unix:///var/run/docker.sock
+	Host string
+	// Runtime is "podman" or "docker", after the daemon answered
+	Runtime string
+}
+
+// Probe is the outcome of trying an endpoint. Err wraps os.ErrNotExist
+// when the socket or pipe does not exist at all.
+type Probe struct {
+	Endpoint Endpoint
+	Err      error
+}
+
+// Candidates returns the endpoints to try, in order. DOCKER_HOST is
+// authoritative when set, like it is for the docker CLI; otherwise the
+// current docker context comes first, then the well-known sockets of
+// Docker Engine, Docker Desktop, rootless Docker, Podman, Colima and friends.
+func Candidates() []Endpoint {
+	if host := os.Getenv("DOCKER_HOST"); host != "" {
+		return []Endpoint{{Name: "DOCKER_HOST", Host: host}}
+	}
+
+	var candidates []Endpoint
+	if name, host := contextHost(); host != "" {
+		candidates = append(candidates, Endpoint{Name: "docker context " + name, Host: host})
+	}
+
+	home, _ := os.UserHomeDir()
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" && runtime.GOOS == "linux" {
+		runtimeDir = fmt.Sprintf("/run/user/%d", os.Getuid())
+	}
+
+	// unix adds a socket candidate unless its base directory is unknown
+	unix := func(name string, path ...string) {
+		if path[0] == "" {
+			return
+		}
+		candidates = append(candidates, Endpoint{Name: name, Host: "unix://" + filepath.Join(path...)})
+	}
+
+	switch runtime.GOOS {
+	case "windows":
+		candidates = append(candidates,
+			Endpoint{Name: "Docker Desktop", Host: "npipe:////./pipe/docker_engine"},
+			Endpoint{Name: "Podman machine", Host: "npipe:////./pipe/podman-machine-default"},
+		)
+	case "darwin":
+		unix("Docker Engine", defaultSocket)
+		unix("Docker Desktop", home, ".docker", "run", "docker.sock")
+		unix("Colima", home, ".colima", "default", "docker.sock")
+		unix("OrbStack", home, ".orbstack", "run", "docker.sock")
+		unix("Rancher Desktop", home, ".rd", "docker.sock")
+		unix("Podman machine", home, ".local", "share", "containers", "podman", "machine", "podman.sock")
+		unix("Podman machine", home, ".local", "share", "containers", "podman", "machine", "qemu", "podman.sock")
+	default:
+		unix("Docker Engine", defaultSocket)
+		unix("Rootless Docker", runtimeDir, "docker.sock")
+		unix("Docker Desktop", home, ".docker", "desktop", "docker.sock")
+		unix("Podman (rootless)", runtimeDir, "podman", "podman.sock")
+		unix("Podman", "/run/podman/podman.sock")
+		unix("Colima", home, ".colima", "default", "docker.sock")
+	}
+
+	return candidates
+}
+
+// Detect probes the candidate endpoints in order and returns the first
+// daemon that answers, along with every probe made for diagnostics
+func Detect() (*Endpoint, []Probe) {
+	var probes []Probe
+	for _, endpoint := range Candidates() {
+		var err error
+		endpoint.Runtime, err = ping(context.Background(), endpoint.Host)
+		probes = append(probes, Probe{Endpoint: endpoint, Err: err})
+		if err == nil {
+			found := endpoint
+			return &found, probes
+		}
+	}
+	return nil, probes
+}
+
+// DetectAll probes every candidate endpoint and returns the first daemon
+// that answers of each runtime, so Docker and Podman containers running
+// side by side are both found. ctx bounds the probes.
+func DetectAll(ctx context.Context) []Endpoint {
+	var found []Endpoint
+	seen := make(map[string]bool)
+	for _, probe := range ProbeAll(ctx) {
+		if probe.Err != nil || seen[probe.Endpoint.Runtime] {
+			continue
+		}
+		seen[probe.Endpoint.Runtime] = true
+		found = append(found, probe.Endpoint)
+	}
+	return found
+}
+
+// ProbeAll tries every candidate endpoint, for diagnostics
+func ProbeAll(ctx context.Context) []Probe {
+	var probes []Probe
+	for _, endpoint := range Candidates() {
+		var err error
+		endpoint.Runtime, err = ping(ctx, endpoint.Host)
+		probes = append(probes, Probe{Endpoint: endpoint, Err: err})
+	}
+	return probes
+}
+
+// ping checks that a daemon answers on the host, and tells Podman, which
+// names its own API version in the reply, from Docker
+func ping(ctx context.Context, host string) (string, error) {
+	httpClient, base, err := newTransport(host)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/_ping", nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		slog.Debug("docker ping failed", "host", host, "err", err)
+		return "", fmt.Errorf("no response: %w", err)
+	}
+	resp.Body.Close()
+	slog.Debug("docker ping", "host", host, "status", resp.StatusCode)
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("ping returned %s", resp.Status)
+	}
+	if resp.Header.Get("Libpod-Api-Version") != "" {
+		return RuntimePodman, nil
+	}
+	return RuntimeDocker, nil
+}
+
+// newTransport returns an HTTP client and base URL for a DOCKER_HOST style
+// address
+func newTransport(host string) (*http.Client, string, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid docker host %q: %w", host, err)
+	}
+
+	switch u.Scheme {
+	case "unix":
+		socket := u.Path
+		if _, err := os.Stat(socket); err != nil {
+			return nil, "", fmt.Errorf("socket %s: %w", socket, os.ErrNotExist)
+		}
+		transport := &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		}
+		return &http.Client{Transport: transport}, "http://docker", nil
+	case "npipe":
+		// npipe:////./pipe/docker_engine names \\.\pipe\docker_engine
+		pipe := strings.ReplaceAll(u.Path, "/", `\`)
+		transport, err := newPipeTransport(pipe)
+		if err != nil {
+			return nil, "", err
+		}
+		return &http.Client{Transport: transport}, "http://docker", nil
+	case "tcp", "http":
+		return &http.Client{}, "http://" + u.Host, nil
+	default:
+		return nil, "", fmt.Errorf("unsupported docker host scheme: %s", u.Scheme)
+	}
+}
+
+// contextHost returns the name and host of the current docker context when
+// it is not the default one, as set up by Docker Desktop, Colima and others
+func contextHost() (string, string) {
+	configDir := os.Getenv("DOCKER_CONFIG")
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", ""
+		}
+		configDir = filepath.Join(home, ".docker")
+	}
+
+	name := os.Getenv("DOCKER_CONTEXT")
+	if name == "" {
+		data, err := os.ReadFile(filepath.Join(configDir, "config.json"))
+		if err != nil {
+			return "", ""
+		}
+		var cfg struct {
+			CurrentContext string `json:"currentContext"`
+		}
+		if json.Unmarshal(data, &cfg) != nil {
+			return "", ""
+		}
+		name = cfg.CurrentContext
+	}
+	if name == "" || name == "default" {
+		return "", ""
+	}
+
+	// Context metadata lives in a directory named by the hash of its name
+	sum := sha256.Sum256([]byte(name))
+	data, err := os.ReadFile(filepath.Join(configDir, "contexts", "meta", hex.EncodeToString(sum[:]), "meta.json"))
+	if err != nil {
+		return "", ""
+	}
+	var meta struct {
+		Endpoints map[string]struct {
+			Host string `json:"Host"`
+		} `json:"Endpoints"`
+	}
+	if json.Unmarshal(data, &meta) != nil {
+		return "", ""
+	}
+
+	return name, meta.Endpoints["docker"].Host
+}
@@ -0,0 +1,12 @@
+//go:build !windows
+
+package docker
+
+import (
+	"errors"
+	"net/http"
+)
+
+func newPipeTransport(path string) (http.RoundTripper, error) {
+	return nil, errors.New("named pipes are only supported on Windows")
+}
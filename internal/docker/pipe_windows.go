@@ -0,0 +1,59 @@
+//go:build windows
+
+package docker
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// pipeTransport sends each request over a fresh connection to a named pipe.
+// Pipes opened by os.OpenFile are synchronous, so the request is written in
+// full before the response is read rather than using http.Transport, whose
+// read loop would block the write.
+type pipeTransport struct {
+	path string
+}
+
+func newPipeTransport(path string) (http.RoundTripper, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("pipe %s: %w", path, os.ErrNotExist)
+	}
+	return &pipeTransport{path: path}, nil
+}
+
+func (t *pipeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	pipe, err := os.OpenFile(t.path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := req.Write(pipe); err != nil {
+		pipe.Close()
+		return nil, err
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(pipe), req)
+	if err != nil {
+		pipe.Close()
+		return nil, err
+	}
+
+	resp.Body = &pipeBody{ReadCloser: resp.Body, pipe: pipe}
+	return resp, nil
+}
+
+// pipeBody closes the pipe along with the response body
+type pipeBody struct {
+	io.ReadCloser
+	pipe *os.File
+}
+
+func (b *pipeBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.pipe.Close()
+	return err
+}
@@ -0,0 +1,173 @@
+// Package firewall reads and changes whether the host firewall lets
+// inbound TCP connections to a port in, through ufw, firewalld, pf or
+// Windows Firewall
+package firewall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/doganarif/portfinder/internal/process"
+)
+
+// Status is whether a firewall lets inbound TCP connections to a port in
+type Status string
+
+const (
+	Allowed Status = "allowed"
+	Blocked Status = "blocked"
+	// Inactive means the firewall is turned off, so nothing is filtered
+	Inactive Status = "inactive"
+)
+
+// Firewall is a host firewall pf can query and change
+type Firewall interface {
+	// Name names the firewall, e.g. "ufw"
+	Name() string
+	// Status reports whether each of the ports lets connections in. It
+	// needs root for most firewalls.
+	Status(ctx context.Context, ports []int) (map[int]Status, error)
+	// Allow and Deny return the commands opening or closing a port, to be
+	// shown before Run runs them
+	Allow(port int) []Command
+	Deny(port int) []Command
+}
+
+// Command is a command changing the firewall
+type Command struct {
+	Args []string
+	// MayFail marks cleanup steps, such as deleting a rule that may not
+	// exist
+	MayFail bool
+}
+
+// String quotes the arguments that need it, as a shell would take them
+func (c Command) String() string {
+	quoted := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		quoted[i] = arg
+		if arg == "" || strings.ContainsAny(arg, " '\"|;&$<>()*") {
+			quoted[i] = strconv.Quote(arg)
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+// Detect returns the firewall managing this host, or nil when none of the
+// supported ones is found. On Linux a running firewalld is preferred over
+// ufw, as both may be installed.
+func Detect(ctx context.Context) Firewall {
+	switch runtime.GOOS {
+	case "linux":
+		if installed("firewall-cmd") && firewalldRunning(ctx) {
+			return firewalld{}
+		}
+		if installed("ufw") {
+			return ufw{}
+		}
+		if installed("firewall-cmd") {
+			return firewalld{}
+		}
+	case "darwin", "freebsd", "openbsd":
+		if installed("pfctl") {
+			return pf{}
+		}
+	case "windows":
+		return windowsFirewall{}
+	}
+	return nil
+}
+
+// Run runs the commands in order on the terminal, so sudo can ask for a
+// password, stopping at the first failure. Their tools were resolved when
+// they were built.
+func Run(ctx context.Context, commands []Command) error {
+	for _, c := range commands {
+		var stdout, stderr io.Writer = os.Stdout, os.Stderr
+		if c.MayFail {
+			stdout, stderr = nil, nil
+		}
+		err := process.RunToolOn(ctx, os.Stdin, stdout, stderr, c.Args[0], c.Args[1:]...)
+		if err != nil && !c.MayFail {
+			return fmt.Errorf("%s failed: %w", c.Args[0], err)
+		}
+	}
+	return nil
+}
+
+// tool returns the command line running a tool, with the overrides of
+// the tools setting applied
+func tool(name string, args ...string) []string {
+	path, extra := process.ToolCommand(name)
+	return append(append([]string{path}, extra...), args...)
+}
+
+// installed reports whether a tool, as overridden, can be found
+func installed(name string) bool {
+	path, _ := process.ToolCommand(name)
+	_, err := exec.LookPath(path)
+	return err == nil
+}
+
+// asRoot is tool prefixed with sudo unless pf runs as root. Windows
+// commands run as they are, failing without an elevated prompt.
+func asRoot(name string, args ...string) []string {
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		return tool(name, args...)
+	}
+	return append([]string{"sudo"}, tool(name, args...)...)
+}
+
+// output runs a tool and returns what it printed to stdout, with its
+// stderr in the error
+func output(ctx context.Context, name string, args ...string) (string, error) {
+	out, err := process.ToolOutput(ctx, name, args...)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			if detail := strings.TrimSpace(string(exitErr.Stderr)); detail != "" {
+				return string(out), fmt.Errorf("%s: %s", name, detail)
+			}
+		}
+		return string(out), fmt.Errorf("%s failed: %w", name, err)
+	}
+	return string(out), nil
+}
+
+// query runs a command reading the firewall's state, retrying through
+// sudo when it needs root and sudo has cached credentials. It never asks
+// for a password.
+func query(ctx context.Context, name string, args ...string) (string, error) {
+	out, err := output(ctx, name, args...)
+	if err == nil || runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		return out, err
+	}
+	if out, sudoErr := output(ctx, "sudo", append([]string{"-n"}, tool(name, args...)...)...); sudoErr == nil {
+		return out, nil
+	}
+	return out, fmt.Errorf("%w (reading the firewall's rules may need root, try with sudo)", err)
+}
+
+// inPorts reports whether port is in a list of ports and ranges such as
+// "80,443,8000-8100", with sep separating the ends of a range
+func inPorts(list string, port int, sep string) bool {
+	for _, item := range strings.Split(list, ",") {
+		low, high, isRange := strings.Cut(strings.TrimSpace(item), sep)
+		if !isRange {
+			high = low
+		}
+		l, err1 := strconv.Atoi(low)
+		h, err2 := strconv.Atoi(high)
+		if err1 == nil && err2 == nil && port >= l && port <= h {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package firewall
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/doganarif/portfinder/internal/process"
+)
+
+// firewalld is the firewall of Fedora, RHEL and openSUSE, whose default
+// zone opens the ports and services listed in it and rejects the rest
+type firewalld struct{}
+
+func (firewalld) Name() string { return "firewalld" }
+
+// firewalldRunning reports whether the firewalld daemon runs
+func firewalldRunning(ctx context.Context) bool {
+	_, err := process.ToolOutput(ctx, "firewall-cmd", "--state")
+	return err == nil
+}
+
+func (firewalld) Status(ctx context.Context, ports []int) (map[int]Status, error) {
+	statuses := make(map[int]Status, len(ports))
+	if !firewalldRunning(ctx) {
+		for _, port := range ports {
+			statuses[port] = Inactive
+		}
+		return statuses, nil
+	}
+
+	out, err := query(ctx, "firewall-cmd", "--list-ports")
+	if err != nil {
+		return nil, err
+	}
+	open := strings.Fields(out)
+	services, err := query(ctx, "firewall-cmd", "--list-services")
+	if err != nil {
+		return nil, err
+	}
+	// A service opens the ports it defines, e.g. 22/tcp for ssh
+	for _, service := range strings.Fields(services) {
+		if info, err := query(ctx, "firewall-cmd", "--info-service="+service); err == nil {
+			open = append(open, serviceInfoPorts(info)...)
+		}
+	}
+
+	for _, port := range ports {
+		statuses[port] = Blocked
+		for _, spec := range open {
+			if firewalldMatches(spec, port) {
+				statuses[port] = Allowed
+				break
+			}
+		}
+	}
+	return statuses, nil
+}
+
+// serviceInfoPorts reads the "ports:" line of firewall-cmd --info-service
+func serviceInfoPorts(info string) []string {
+	for _, line := range strings.Split(info, "\n") {
+		if ports, ok := strings.CutPrefix(strings.TrimSpace(line), "ports:"); ok {
+			return strings.Fields(ports)
+		}
+	}
+	return nil
+}
+
+// firewalldMatches reports whether a port entry such as "3000/tcp" or
+// "8000-8100/tcp" covers a TCP port
+func firewalldMatches(spec string, port int) bool {
+	ports, proto, _ := strings.Cut(spec, "/")
+	return proto == "tcp" && inPorts(ports, port, "-")
+}
+
+// Allow opens the port in the running firewall and in the permanent
+// config, so it stays open after a reload
+func (firewalld) Allow(port int) []Command {
+	spec := "--add-port=" + strconv.Itoa(port) + "/tcp"
+	return []Command{
+		{Args: asRoot("firewall-cmd", spec)},
+		{Args: asRoot("firewall-cmd", "--permanent", spec)},
+	}
+}
+
+// Deny closes a port opened with Allow. A service opening it, such as
+// http for port 80, keeps it open.
+func (firewalld) Deny(port int) []Command {
+	spec := "--remove-port=" + strconv.Itoa(port) + "/tcp"
+	return []Command{
+		{Args: asRoot("firewall-cmd", spec)},
+		{Args: asRoot("firewall-cmd", "--permanent", spec)},
+	}
+}
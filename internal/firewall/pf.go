@@ -0,0 +1,183 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// pf is the packet filter of macOS, FreeBSD and OpenBSD. Its rules are
+// evaluated in order, the last matching one deciding unless one marked
+// quick matches first; packets no rule matches pass.
+type pf struct{}
+
+func (pf) Name() string { return "pf" }
+
+// pfAnchor is the anchor holding the rules pf adds. macOS's pf.conf loads
+// every anchor under com.apple; on the BSDs the anchor must be referenced
+// in /etc/pf.conf.
+func pfAnchor() string {
+	if runtime.GOOS == "darwin" {
+		return "com.apple/portfinder"
+	}
+	return "portfinder"
+}
+
+func (pf) Status(ctx context.Context, ports []int) (map[int]Status, error) {
+	statuses := make(map[int]Status, len(ports))
+	info, err := query(ctx, "pfctl", "-s", "info")
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(info, "Status: Enabled") {
+		for _, port := range ports {
+			statuses[port] = Inactive
+		}
+		return statuses, nil
+	}
+
+	rules, err := query(ctx, "pfctl", "-s", "rules")
+	if err != nil {
+		return nil, err
+	}
+	// pf's own rules are evaluated where the main ruleset loads its anchor
+	own, _ := query(ctx, "pfctl", "-a", pfAnchor(), "-s", "rules")
+	for _, port := range ports {
+		statuses[port] = evaluatePF(rules, own, port)
+	}
+	return statuses, nil
+}
+
+// evaluatePF decides whether a ruleset, as pfctl -s rules prints it, lets
+// inbound TCP connections to a port in
+func evaluatePF(rules, own string, port int) Status {
+	status := Allowed
+	for _, line := range strings.Split(rules, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "anchor" && len(fields) > 1 && anchorLoads(strings.Trim(fields[1], `"`)) {
+			for _, rule := range strings.Split(own, "\n") {
+				if decided, quick := pfRule(strings.Fields(rule), port); decided != "" {
+					status = decided
+					if quick {
+						return status
+					}
+				}
+			}
+			continue
+		}
+		if decided, quick := pfRule(fields, port); decided != "" {
+			status = decided
+			if quick {
+				return status
+			}
+		}
+	}
+	return status
+}
+
+// anchorLoads reports whether an anchor rule of the main ruleset, e.g.
+// "com.apple/*", loads pf's own anchor
+func anchorLoads(name string) bool {
+	own := pfAnchor()
+	if prefix, ok := strings.CutSuffix(name, "/*"); ok {
+		return strings.HasPrefix(own, prefix+"/")
+	}
+	return name == own
+}
+
+// pfRule returns what a pass or block rule decides for inbound TCP
+// connections to a port, or "" when it doesn't match them, and whether it
+// is quick. Rules bound to the loopback interface don't filter connections
+// from elsewhere, and are skipped.
+func pfRule(fields []string, port int) (Status, bool) {
+	if len(fields) == 0 || fields[0] != "pass" && fields[0] != "block" {
+		return "", false
+	}
+	quick, to := false, false
+	for i, field := range fields {
+		switch field {
+		case "out":
+			return "", false
+		case "quick":
+			quick = true
+		case "on":
+			if i+1 < len(fields) && strings.HasPrefix(fields[i+1], "lo") {
+				return "", false
+			}
+		case "proto":
+			if i+1 < len(fields) && fields[i+1] != "tcp" && fields[i+1] != "{" {
+				return "", false
+			}
+		case "to":
+			to = true
+		case "port":
+			// Only the destination port, after "to", filters by port
+			if to && !pfPortMatches(fields[i+1:], port) {
+				return "", false
+			}
+		}
+	}
+	if fields[0] == "block" {
+		return Blocked, quick
+	}
+	return Allowed, quick
+}
+
+// pfPortMatches reports whether a port spec as pfctl prints it, such as
+// "= 3000", "3000:3010" or "{ 80 443 }", covers a port
+func pfPortMatches(spec []string, port int) bool {
+	if len(spec) == 0 {
+		return false
+	}
+	switch spec[0] {
+	case "=":
+		return len(spec) > 1 && spec[1] == strconv.Itoa(port)
+	case "{":
+		for _, item := range spec[1:] {
+			if item == "}" {
+				break
+			}
+			if pfPortMatches([]string{strings.Trim(item, ",")}, port) {
+				return true
+			}
+		}
+		return false
+	}
+	return inPorts(spec[0], port, ":")
+}
+
+// pfChange replaces the rules for a port in pf's anchor with rule, keeping
+// its rules for other ports
+func pfChange(port int, rule string) []Command {
+	anchor := pfAnchor()
+	pfctl := shellWords(tool("pfctl"))
+	script := fmt.Sprintf("(%s -a %s -s rules 2>/dev/null | grep -v -E 'port = %d( |$)'; echo '%s') | %s -a %s -f -",
+		pfctl, anchor, port, rule, pfctl, anchor)
+	return []Command{{Args: asRoot("sh", "-c", script)}}
+}
+
+// shellWords joins arguments into a shell command line, quoting those
+// that need it
+func shellWords(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = arg
+		if arg == "" || strings.ContainsAny(arg, " \t'\"\\|;&$<>()*?`~#") {
+			quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+func (pf) Allow(port int) []Command {
+	return pfChange(port, fmt.Sprintf("pass in quick proto tcp from any to any port = %d", port))
+}
+
+func (pf) Deny(port int) []Command {
+	return pfChange(port, fmt.Sprintf("block drop in quick proto tcp from any to any port = %d", port))
+}
@@ -0,0 +1,100 @@
+package firewall
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// ufw is Ubuntu's Uncomplicated Firewall
+type ufw struct{}
+
+func (ufw) Name() string { return "ufw" }
+
+func (ufw) Status(ctx context.Context, ports []int) (map[int]Status, error) {
+	out, err := query(ctx, "ufw", "status", "verbose")
+	if err != nil {
+		return nil, err
+	}
+	return parseUFWStatus(out, ports), nil
+}
+
+// parseUFWStatus evaluates ufw status verbose output: the first rule
+// matching a port decides, else the default incoming policy
+func parseUFWStatus(out string, ports []int) map[int]Status {
+	statuses := make(map[int]Status, len(ports))
+	fallback := Blocked
+	type rule struct {
+		ports  string
+		action string
+	}
+	var rules []rule
+	for _, line := range strings.Split(out, "\n") {
+		if state, ok := strings.CutPrefix(line, "Status: "); ok && strings.TrimSpace(state) != "active" {
+			for _, port := range ports {
+				statuses[port] = Inactive
+			}
+			return statuses
+		}
+		if policy, ok := strings.CutPrefix(line, "Default: "); ok && strings.HasPrefix(policy, "allow (incoming)") {
+			fallback = Allowed
+		}
+
+		// To, Action and direction, From. To may hold an address and an
+		// interface around the ports, e.g. "10.0.0.5 3000/tcp on eth0", or
+		// name an app profile, whose ports aren't known
+		fields := strings.Fields(line)
+		for i := 1; i+1 < len(fields); i++ {
+			if fields[i+1] != "IN" {
+				continue
+			}
+			switch fields[i] {
+			case "ALLOW", "LIMIT", "DENY", "REJECT":
+				for j := i - 1; j >= 0; j-- {
+					if fields[j][0] >= '0' && fields[j][0] <= '9' {
+						rules = append(rules, rule{ports: fields[j], action: fields[i]})
+						break
+					}
+				}
+			}
+			break
+		}
+	}
+
+	for _, port := range ports {
+		statuses[port] = fallback
+		for _, r := range rules {
+			if ufwMatches(r.ports, port) {
+				statuses[port] = Allowed
+				if r.action == "DENY" || r.action == "REJECT" {
+					statuses[port] = Blocked
+				}
+				break
+			}
+		}
+	}
+	return statuses
+}
+
+// ufwMatches reports whether a rule's ports, e.g. "3000", "80,443/tcp" or
+// "8000:8100/tcp", cover a TCP port
+func ufwMatches(spec string, port int) bool {
+	list, proto, _ := strings.Cut(spec, "/")
+	if proto != "" && proto != "tcp" {
+		return false
+	}
+	return inPorts(list, port, ":")
+}
+
+func (ufw) Allow(port int) []Command {
+	return []Command{{Args: asRoot("ufw", "allow", strconv.Itoa(port)+"/tcp")}}
+}
+
+func (ufw) Deny(port int) []Command {
+	spec := strconv.Itoa(port) + "/tcp"
+	// An allow rule listed first would win over the deny rule
+	return []Command{
+		{Args: asRoot("ufw", "delete", "allow", spec), MayFail: true},
+		{Args: asRoot("ufw", "deny", spec)},
+	}
+}
@@ -0,0 +1,114 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// windowsFirewall is Windows Defender Firewall, managed with netsh. It
+// blocks inbound connections no rule allows, and a block rule wins over
+// allow rules.
+type windowsFirewall struct{}
+
+func (windowsFirewall) Name() string { return "Windows Firewall" }
+
+func (windowsFirewall) Status(ctx context.Context, ports []int) (map[int]Status, error) {
+	statuses := make(map[int]Status, len(ports))
+	state, err := output(ctx, "netsh", "advfirewall", "show", "currentprofile", "state")
+	if err != nil {
+		return nil, err
+	}
+	if !netshEnabled(state) {
+		for _, port := range ports {
+			statuses[port] = Inactive
+		}
+		return statuses, nil
+	}
+
+	out, err := output(ctx, "netsh", "advfirewall", "firewall", "show", "rule", "name=all", "dir=in")
+	if err != nil {
+		return nil, err
+	}
+	rules := parseNetshRules(out)
+	for _, port := range ports {
+		statuses[port] = evaluateNetsh(rules, port)
+	}
+	return statuses, nil
+}
+
+// netshEnabled reads the "State ON" line of the current profile
+func netshEnabled(out string) bool {
+	for _, line := range strings.Split(out, "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "State" {
+			return fields[1] == "ON"
+		}
+	}
+	// Other languages translate the label, assume the default
+	return true
+}
+
+// parseNetshRules splits netsh's rule listing into the "Label: value"
+// fields of each rule. Only English labels are understood.
+func parseNetshRules(out string) []map[string]string {
+	var rules []map[string]string
+	for _, line := range strings.Split(out, "\n") {
+		label, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		label, value = strings.TrimSpace(label), strings.TrimSpace(value)
+		if label == "Rule Name" {
+			rules = append(rules, map[string]string{})
+		}
+		if len(rules) > 0 {
+			rules[len(rules)-1][label] = value
+		}
+	}
+	return rules
+}
+
+// evaluateNetsh decides whether the enabled TCP rules let connections to
+// a port in. Rules for any port usually allow a program rather than a
+// port, whose listeners aren't known here, so they are skipped.
+func evaluateNetsh(rules []map[string]string, port int) Status {
+	status := Blocked
+	for _, rule := range rules {
+		if rule["Enabled"] != "Yes" || rule["Protocol"] != "TCP" || !inPorts(rule["LocalPort"], port, "-") {
+			continue
+		}
+		if rule["Action"] == "Block" {
+			return Blocked
+		}
+		if rule["Action"] == "Allow" {
+			status = Allowed
+		}
+	}
+	return status
+}
+
+// netshRule names the rules pf adds for a port
+func netshRule(port int, action string) string {
+	return fmt.Sprintf("name=portfinder-%s-%d", action, port)
+}
+
+// Allow deletes pf's block rule for the port, which would win, and adds an
+// allow rule
+func (windowsFirewall) Allow(port int) []Command {
+	return netshChange(port, "allow", "block")
+}
+
+func (windowsFirewall) Deny(port int) []Command {
+	return netshChange(port, "block", "allow")
+}
+
+// netshChange adds pf's rule taking the action on the port, after deleting
+// its rule taking the opposite one and any copy of the new one, since
+// netsh adds a rule of the same name again on every run
+func netshChange(port int, action, opposite string) []Command {
+	return []Command{
+		{Args: tool("netsh", "advfirewall", "firewall", "delete", "rule", netshRule(port, opposite)), MayFail: true},
+		{Args: tool("netsh", "advfirewall", "firewall", "delete", "rule", netshRule(port, action)), MayFail: true},
+		{Args: tool("netsh", "advfirewall", "firewall", "add", "rule", netshRule(port, action), "dir=in", "action="+action, "protocol=TCP", fmt.Sprintf("localport=%d", port))},
+	}
+}
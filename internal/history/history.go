@@ -0,0 +1,291 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"github.com/doganarif/portfinder/internal/process"
+)
+
+// Event kinds
+const (
+	EventSeen   = "seen"
+	EventKilled = "killed"
+)
+
+// CrashLoopRestarts is how often a listener has to restart within the
+// crash loop window to be flagged
+const CrashLoopRestarts = 3
+
+// CrashLoopWindow is how far back restarts are counted
+const CrashLoopWindow = 10 * time.Minute
+
+// MaxEvents and MaxAge bound the history file. Once either is exceeded,
+// writing drops the oldest events down to nine tenths of the bounds, so
+// the file isn't rewritten on every event after.
+const (
+	MaxEvents = 10000
+	MaxAge    = 90 * 24 * time.Hour
+)
+
+// Event records a process observed on, or killed from, a port
+type Event struct {
+	Time        time.Time `json:"time"`
+	Event       string    `json:"event"`
+	ProcessID   string    `json:"process_id,omitempty"`
+	Port        int       `json:"port"`
+	PID         int       `json:"pid"`
+	Name        string    `json:"name"`
+	Command     string    `json:"command,omitempty"`
+	Args        []string  `json:"args,omitempty"`
+	WorkDir     string    `json:"cwd,omitempty"`
+	ProjectPath string    `json:"project,omitempty"`
+	Actor       string    `json:"actor,omitempty"`
+	Strategy    string    `json:"strategy,omitempty"`
+}
+
+// RecordSeen appends a seen event for every process whose port changed
+// occupant since it was last recorded
+func RecordSeen(processes []*process.Process) error {
+	if len(processes) == 0 {
+		return nil
+	}
+
+	events, err := Load(0)
+	if err != nil {
+		return err
+	}
+
+	// Latest known occupant per port
+	last := make(map[int]Event)
+	for _, e := range events {
+		if e.Event == EventSeen {
+			last[e.Port] = e
+		} else if e.Event == EventKilled {
+			delete(last, e.Port)
+		}
+	}
+
+	now := time.Now()
+	var fresh []Event
+	for _, p := range processes {
+		if e, ok := last[p.Port]; ok && sameOccupant(e, p) {
+			continue
+		}
+		e := newEvent(now, EventSeen, p)
+		fresh = append(fresh, e)
+		last[p.Port] = e
+	}
+
+	return appendEvents(fresh)
+}
+
+// RecordKill appends a killed event for the process
+func RecordKill(p *process.Process, strategy string) error {
+	e := newEvent(time.Now(), EventKilled, p)
+	e.Strategy = strategy
+	if u, err := user.Current(); err == nil {
+		e.Actor = u.Username
+	}
+	return appendEvents([]Event{e})
+}
+
+// Load returns the recorded events for a port, or all events when port is 0
+func Load(port int) ([]Event, error) {
+	file, err := os.Open(Path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			// Skip lines damaged by an interrupted write
+			continue
+		}
+		if port == 0 || e.Port == port {
+			events = append(events, e)
+		}
+	}
+
+	return events, scanner.Err()
+}
+
+// Restarts counts per port how often a new process with the same command
+// replaced the previous occupant after since, without being killed through
+// pf in between. Crash looping and flapping services show up this way.
+func Restarts(events []Event, since time.Time) map[int]int {
+	restarts := make(map[int]int)
+	last := make(map[int]Event)
+	for _, e := range events {
+		switch e.Event {
+		case EventKilled:
+			delete(last, e.Port)
+		case EventSeen:
+			if prev, ok := last[e.Port]; ok && e.Time.After(since) &&
+				prev.Name == e.Name && prev.Command == e.Command {
+				restarts[e.Port]++
+			}
+			last[e.Port] = e
+		}
+	}
+	return restarts
+}
+
+// CrashLoops returns the restart count of the ports that restarted at
+// least CrashLoopRestarts times within CrashLoopWindow
+func CrashLoops() (map[int]int, error) {
+	events, err := Load(0)
+	if err != nil {
+		return nil, err
+	}
+
+	loops := Restarts(events, time.Now().Add(-CrashLoopWindow))
+	for port, count := range loops {
+		if count < CrashLoopRestarts {
+			delete(loops, port)
+		}
+	}
+	return loops, nil
+}
+
+// Path returns the history file path
+func Path() string {
+	// Check XDG_DATA_HOME first
+	if xdgData := os.Getenv("XDG_DATA_HOME"); xdgData != "" {
+		return filepath.Join(xdgData, "portfinder", "history.jsonl")
+	}
+
+	// Fall back to ~/.local/share
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "share", "portfinder", "history.jsonl")
+	}
+
+	return filepath.Join(os.TempDir(), "portfinder-history.jsonl")
+}
+
+// sameOccupant reports whether a seen event recorded the process, going by
+// the process ID when the event has one, so a reused PID counts as new
+func sameOccupant(e Event, p *process.Process) bool {
+	if e.ProcessID != "" && p.ID != "" {
+		return e.ProcessID == string(p.ID)
+	}
+	return e.PID == p.PID
+}
+
+func newEvent(t time.Time, kind string, p *process.Process) Event {
+	return Event{
+		Time:        t,
+		Event:       kind,
+		ProcessID:   string(p.ID),
+		Port:        p.Port,
+		PID:         p.PID,
+		Name:        p.Name,
+		Command:     p.Command,
+		Args:        p.Args,
+		WorkDir:     p.WorkDir,
+		ProjectPath: p.ProjectPath,
+	}
+}
+
+// appendEvents adds the events to the history file, pruning it when it
+// outgrew MaxEvents or MaxAge
+func appendEvents(events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	path := Path()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	existing, err := Load(0)
+	if err != nil {
+		return err
+	}
+	if kept, ok := prune(append(existing, events...), time.Now()); ok {
+		return rewrite(path, kept)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, e := range events {
+		if err := encoder.Encode(e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// prune drops the events older than nine tenths of MaxAge, then the
+// oldest beyond nine tenths of MaxEvents, once either bound is exceeded.
+// It reports whether it dropped any.
+func prune(events []Event, now time.Time) ([]Event, bool) {
+	expired := false
+	for _, e := range events {
+		if now.Sub(e.Time) > MaxAge {
+			expired = true
+			break
+		}
+	}
+	if !expired && len(events) <= MaxEvents {
+		return events, false
+	}
+
+	cutoff := now.Add(-MaxAge * 9 / 10)
+	kept := events[:0:0]
+	for _, e := range events {
+		if e.Time.After(cutoff) {
+			kept = append(kept, e)
+		}
+	}
+	if limit := MaxEvents * 9 / 10; len(kept) > limit {
+		kept = kept[len(kept)-limit:]
+	}
+	return kept, true
+}
+
+// rewrite replaces the history file with the events, through a temporary
+// file so an interrupted write leaves the old history intact
+func rewrite(path string, events []Event) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".history-*.jsonl")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	encoder := json.NewEncoder(tmp)
+	for _, e := range events {
+		if err := encoder.Encode(e); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,221 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/doganarif/portfinder/internal/process"
+)
+
+// probeTimeout bounds every external lookup so a hung binary can't stall the inventory
+const probeTimeout = 2 * time.Second
+
+// maxProbes bounds how many listeners are looked into at once
+const maxProbes = 8
+
+// Item describes the software listening on a single port
+type Item struct {
+	ProcessID     string `json:"process_id"`
+	Port          int    `json:"port"`
+	PID           int    `json:"pid"`
+	Process       string `json:"process"`
+	Executable    string `json:"executable,omitempty"`
+	Version       string `json:"version,omitempty"`
+	VersionSource string `json:"version_source,omitempty"`
+	Package       string `json:"package,omitempty"`
+	ServerHeader  string `json:"server_header,omitempty"`
+	Container     string `json:"container,omitempty"`
+	Image         string `json:"image,omitempty"`
+}
+
+// versionFlags lists binaries known to print their version and exit when
+// given a flag. Running arbitrary listeners with --version is unsafe, since
+// a server that ignores the flag would simply start a second instance.
+var versionFlags = map[string]string{
+	"node":         "--version",
+	"deno":         "--version",
+	"bun":          "--version",
+	"python":       "--version",
+	"python3":      "--version",
+	"ruby":         "--version",
+	"php":          "--version",
+	"php-fpm":      "--version",
+	"java":         "-version",
+	"nginx":        "-v",
+	"httpd":        "-v",
+	"apache2":      "-v",
+	"postgres":     "--version",
+	"mysqld":       "--version",
+	"mariadbd":     "--version",
+	"redis-server": "--version",
+	"mongod":       "--version",
+	"caddy":        "version",
+	"traefik":      "version",
+	"dotnet":       "--version",
+}
+
+var versionPattern = regexp.MustCompile(`\d+\.\d+(\.\d+)?([.-][0-9A-Za-z]+)*`)
+
+// Collect gathers version information for each listening process,
+// several at a time
+func Collect(processes []*process.Process) []Item {
+	items := make([]Item, len(processes))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(maxProbes, len(processes)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				items[i] = collect(processes[i])
+			}
+		}()
+	}
+	for i := range processes {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Port < items[j].Port
+	})
+
+	return items
+}
+
+// collect gathers the version information of one listening process
+func collect(p *process.Process) Item {
+	item := Item{
+		ProcessID:  string(p.ID),
+		Port:       p.Port,
+		PID:        p.PID,
+		Process:    p.Name,
+		Executable: p.ExePath,
+		Container:  p.ContainerName,
+		Image:      p.ContainerImage,
+	}
+
+	item.ServerHeader = probeServerHeader(p.Port)
+
+	if p.ExePath != "" {
+		item.Package = lookupPackage(p.ExePath)
+	}
+
+	switch {
+	case p.ContainerImage != "" && strings.Contains(p.ContainerImage, ":"):
+		item.Version = p.ContainerImage[strings.LastIndex(p.ContainerImage, ":")+1:]
+		item.VersionSource = "image-tag"
+	case p.ExePath != "":
+		if v := binaryVersion(p.ExePath); v != "" {
+			item.Version = v
+			item.VersionSource = "binary"
+		}
+	}
+
+	if item.Version == "" && item.ServerHeader != "" {
+		if v := versionPattern.FindString(item.ServerHeader); v != "" {
+			item.Version = v
+			item.VersionSource = "http-header"
+		}
+	}
+
+	if item.Version == "" && item.Package != "" {
+		if v := versionPattern.FindString(item.Package); v != "" {
+			item.Version = v
+			item.VersionSource = "package"
+		}
+	}
+
+	return item
+}
+
+// binaryVersion runs the executable with its known version flag
+func binaryVersion(exePath string) string {
+	name := strings.TrimSuffix(strings.ToLower(filepath.Base(exePath)), ".exe")
+	// Strip interpreter version suffixes like python3.12 or php-fpm8.2
+	name = strings.TrimRight(name, "0123456789.")
+
+	flag, ok := versionFlags[name]
+	if !ok {
+		return ""
+	}
+
+	output, err := runWithTimeout(exePath, flag)
+	if err != nil && output == "" {
+		return ""
+	}
+
+	return versionPattern.FindString(output)
+}
+
+// probeServerHeader issues a HEAD request to the local port and returns the Server header
+func probeServerHeader(port int) string {
+	client := &http.Client{
+		Timeout: probeTimeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := client.Head(fmt.Sprintf("http://127.0.0.1:%d/", port))
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+
+	return resp.Header.Get("Server")
+}
+
+// lookupPackage asks the platform package manager which package owns the executable
+func lookupPackage(exePath string) string {
+	switch runtime.GOOS {
+	case "linux":
+		if out, err := runWithTimeout("dpkg", "-S", exePath); err == nil {
+			// Output format: "package:arch: /path"
+			pkg := strings.SplitN(out, ":", 2)[0]
+			if ver, err := runWithTimeout("dpkg-query", "-W", "-f=${Version}", pkg); err == nil && ver != "" {
+				return pkg + " " + ver
+			}
+			return pkg
+		}
+		if out, err := runWithTimeout("rpm", "-qf", exePath); err == nil {
+			return out
+		}
+		if out, err := runWithTimeout("pacman", "-Qo", exePath); err == nil {
+			// Output format: "/path is owned by package version"
+			if idx := strings.Index(out, "is owned by "); idx != -1 {
+				return out[idx+len("is owned by "):]
+			}
+		}
+	case "darwin":
+		// Homebrew installs live under a Cellar/<formula>/<version>/ prefix
+		if resolved, err := filepath.EvalSymlinks(exePath); err == nil {
+			parts := strings.Split(resolved, string(filepath.Separator))
+			for i, part := range parts {
+				if part == "Cellar" && i+2 < len(parts) {
+					return parts[i+1] + " " + parts[i+2]
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+func runWithTimeout(name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	// Some tools (java, nginx) print their version on stderr
+	output, err := process.RunTool(ctx, name, args...)
+	return strings.TrimSpace(string(output)), err
+}
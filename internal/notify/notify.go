@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Send shows a desktop notification: through osascript on macOS,
+// notify-send on Linux and the BSDs, and a PowerShell toast on Windows
+func Send(title, message string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		// Arguments reach the script as argv, so they need no escaping
+		cmd = exec.Command("osascript",
+			"-e", "on run argv",
+			"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+			"-e", "end run",
+			title, message)
+	case "windows":
+		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", toastScript(title, message))
+	default:
+		cmd = exec.Command("notify-send", "--app-name=portfinder", title, message)
+	}
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if detail := strings.TrimSpace(string(output)); detail != "" {
+			return fmt.Errorf("%s failed: %s", cmd.Args[0], detail)
+		}
+		return fmt.Errorf("%s failed: %w", cmd.Args[0], err)
+	}
+	return nil
+}
+
+// toastScript builds the PowerShell script showing a toast notification
+// through the WinRT notification API
+func toastScript(title, message string) string {
+	return fmt.Sprintf(`[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $template.GetElementsByTagName('text')
+$text.Item(0).AppendChild($template.CreateTextNode(%s)) > $null
+$text.Item(1).AppendChild($template.CreateTextNode(%s)) > $null
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('portfinder').Show([Windows.UI.Notifications.ToastNotification]::new($template))`,
+		psQuote(title), psQuote(message))
+}
+
+// psQuote quotes a string for PowerShell, where single quotes are escaped
+// by doubling them
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,276 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// field is one key of a decoded JSON object, kept in document order so
+// YAML and table columns follow the struct field order
+type field struct {
+	key   string
+	value interface{}
+}
+
+// object is a decoded JSON object
+type object []field
+
+// decode converts v to its JSON form, decoding objects as ordered fields,
+// arrays as []interface{} and numbers as json.Number
+func decode(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decodeValue(decoder)
+}
+
+func decodeValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		var obj object
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, field{key: key.(string), value: value})
+		}
+		_, err := decoder.Token()
+		if obj == nil {
+			obj = object{}
+		}
+		return obj, err
+	case json.Delim('['):
+		list := []interface{}{}
+		for decoder.More() {
+			value, err := decodeValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err := decoder.Token()
+		return list, err
+	}
+	return token, nil
+}
+
+// flatten turns a list of objects, or a single object, into table rows.
+// Columns are the union of the fields in order of first appearance, and
+// nested values are written as JSON, except lists of scalars which are
+// joined with semicolons.
+func flatten(v interface{}) ([]string, [][]string, error) {
+	value, err := decode(v)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var items []interface{}
+	switch value := value.(type) {
+	case []interface{}:
+		items = value
+	case object:
+		items = []interface{}{value}
+	default:
+		return []string{"value"}, [][]string{{cell(value)}}, nil
+	}
+
+	var header []string
+	index := make(map[string]int)
+	for _, item := range items {
+		obj, ok := item.(object)
+		if !ok {
+			return nil, nil, fmt.Errorf("cannot write a list of %T as a table", item)
+		}
+		for _, f := range obj {
+			if _, seen := index[f.key]; !seen {
+				index[f.key] = len(header)
+				header = append(header, f.key)
+			}
+		}
+	}
+
+	rows := make([][]string, len(items))
+	for i, item := range items {
+		rows[i] = make([]string, len(header))
+		for _, f := range item.(object) {
+			rows[i][index[f.key]] = cell(f.value)
+		}
+	}
+	return header, rows, nil
+}
+
+func cell(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case []interface{}:
+		parts := make([]string, 0, len(value))
+		for _, item := range value {
+			switch item.(type) {
+			case object, []interface{}:
+				return compactJSON(value)
+			}
+			parts = append(parts, cell(item))
+		}
+		return strings.Join(parts, ";")
+	case object:
+		return compactJSON(value)
+	}
+	return fmt.Sprint(value)
+}
+
+func compactJSON(value interface{}) string {
+	var b strings.Builder
+	writeJSON(&b, value)
+	return b.String()
+}
+
+func writeJSON(b *strings.Builder, value interface{}) {
+	switch value := value.(type) {
+	case object:
+		b.WriteByte('{')
+		for i, f := range value {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			key, _ := json.Marshal(f.key)
+			b.Write(key)
+			b.WriteByte(':')
+			writeJSON(b, f.value)
+		}
+		b.WriteByte('}')
+	case []interface{}:
+		b.WriteByte('[')
+		for i, item := range value {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeJSON(b, item)
+		}
+		b.WriteByte(']')
+	default:
+		data, _ := json.Marshal(value)
+		b.Write(data)
+	}
+}
+
+// writeYAML writes v as a YAML document. JSON strings are valid YAML
+// double-quoted scalars, so strings that YAML would otherwise read as
+// another type are quoted the JSON way.
+func writeYAML(w io.Writer, v interface{}) error {
+	value, err := decode(v)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	switch value := value.(type) {
+	case object, []interface{}:
+		if isEmpty(value) {
+			b.WriteString(inlineYAML(value) + "\n")
+		} else {
+			writeYAMLBlock(&b, value, 0)
+		}
+	default:
+		b.WriteString(inlineYAML(value) + "\n")
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func writeYAMLBlock(b *strings.Builder, value interface{}, indent int) {
+	pad := strings.Repeat("  ", indent)
+	switch value := value.(type) {
+	case object:
+		for _, f := range value {
+			b.WriteString(pad + yamlString(f.key) + ":")
+			writeYAMLChild(b, f.value, indent)
+		}
+	case []interface{}:
+		for _, item := range value {
+			b.WriteString(pad + "-")
+			if obj, ok := item.(object); ok && len(obj) > 0 {
+				// The first field shares the dash line
+				var nested strings.Builder
+				writeYAMLBlock(&nested, obj, indent+1)
+				b.WriteString(" " + strings.TrimPrefix(nested.String(), pad+"  "))
+				continue
+			}
+			writeYAMLChild(b, item, indent)
+		}
+	}
+}
+
+// writeYAMLChild writes the value following a key or dash, inline for
+// scalars and empty collections, else as an indented block
+func writeYAMLChild(b *strings.Builder, value interface{}, indent int) {
+	switch value.(type) {
+	case object, []interface{}:
+		if !isEmpty(value) {
+			b.WriteString("\n")
+			writeYAMLBlock(b, value, indent+1)
+			return
+		}
+	}
+	b.WriteString(" " + inlineYAML(value) + "\n")
+}
+
+func isEmpty(value interface{}) bool {
+	switch value := value.(type) {
+	case object:
+		return len(value) == 0
+	case []interface{}:
+		return len(value) == 0
+	}
+	return false
+}
+
+func inlineYAML(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return yamlString(value)
+	case object:
+		return "{}"
+	case []interface{}:
+		return "[]"
+	}
+	return fmt.Sprint(value)
+}
+
+// plainScalar matches strings YAML reads back as the same string unquoted
+var plainScalar = regexp.MustCompile(`^[A-Za-z_/~][A-Za-z0-9_./~()+@-]*(?: [A-Za-z0-9_./~()+@-]+)*$`)
+
+// yamlKeywords are plain strings YAML 1.1 readers turn into booleans or null
+var yamlKeywords = map[string]bool{
+	"y": true, "n": true, "yes": true, "no": true, "on": true, "off": true,
+	"true": true, "false": true, "null": true, "~": true,
+}
+
+func yamlString(s string) string {
+	if plainScalar.MatchString(s) && !yamlKeywords[strings.ToLower(s)] {
+		return s
+	}
+	data, _ := json.Marshal(s)
+	return string(data)
+}
@@ -0,0 +1,169 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/doganarif/portfinder/internal/process"
+)
+
+// Format is a serialization format for command results
+type Format string
+
+// Supported formats. Table is the human readable output of each command,
+// which this package leaves to the ui package.
+const (
+	Table    Format = "table"
+	JSON     Format = "json"
+	YAML     Format = "yaml"
+	CSV      Format = "csv"
+	Markdown Format = "markdown"
+)
+
+// Formats lists the supported formats
+var Formats = []Format{Table, JSON, YAML, CSV, Markdown}
+
+// ParseFormat parses a format name, accepting "yml" for yaml and "md" for
+// markdown
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "", "table":
+		return Table, nil
+	case "json":
+		return JSON, nil
+	case "yaml", "yml":
+		return YAML, nil
+	case "csv":
+		return CSV, nil
+	case "md", "markdown":
+		return Markdown, nil
+	}
+	return "", fmt.Errorf("unsupported output format %q", name)
+}
+
+// FormatOf picks the format of a file from its extension, e.g. Markdown
+// for results.md. Tables have no file format.
+func FormatOf(path string) (Format, error) {
+	ext := strings.TrimPrefix(filepath.Ext(path), ".")
+	format, err := ParseFormat(ext)
+	if err != nil || format == Table {
+		return "", fmt.Errorf("can't tell the format of %s, use a .json, .yaml, .csv or .md file", path)
+	}
+	return format, nil
+}
+
+// Extension returns the file extension for the format
+func (f Format) Extension() string {
+	if f == Markdown {
+		return "md"
+	}
+	return string(f)
+}
+
+// columns are the fields written by the tabular formats
+var columns = []string{"port", "address", "pid", "name", "user", "project", "command", "started", "container", "tags", "connections"}
+
+// Write serializes the processes to w in the given format. The tabular
+// formats use a fixed set of columns rather than every field.
+func Write(w io.Writer, format Format, processes []*process.Process) error {
+	if processes == nil {
+		processes = []*process.Process{}
+	}
+	switch format {
+	case CSV:
+		rows := make([][]string, len(processes))
+		for i, p := range processes {
+			rows[i] = row(p)
+		}
+		return writeCSV(w, columns, rows)
+	case Markdown:
+		rows := make([][]string, len(processes))
+		for i, p := range processes {
+			rows[i] = row(p)
+		}
+		return writeMarkdown(w, columns, rows)
+	}
+	return Encode(w, format, processes)
+}
+
+// Encode serializes any JSON-encodable result to w. The tabular formats
+// turn a list of objects into one row per object and a column per field,
+// and a single object into one row.
+func Encode(w io.Writer, format Format, v interface{}) error {
+	// Empty lists are written as [] rather than null
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice && rv.IsNil() {
+		v = reflect.MakeSlice(rv.Type(), 0, 0).Interface()
+	}
+
+	switch format {
+	case JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case YAML:
+		return writeYAML(w, v)
+	case CSV, Markdown:
+		header, rows, err := flatten(v)
+		if err != nil {
+			return err
+		}
+		if format == CSV {
+			return writeCSV(w, header, rows)
+		}
+		return writeMarkdown(w, header, rows)
+	}
+	return fmt.Errorf("unsupported output format %q", format)
+}
+
+func writeCSV(w io.Writer, header []string, rows [][]string) error {
+	writer := csv.NewWriter(w)
+	writer.Write(header)
+	for _, r := range rows {
+		writer.Write(r)
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeMarkdown(w io.Writer, header []string, rows [][]string) error {
+	fmt.Fprintf(w, "| %s |\n", strings.Join(header, " | "))
+	fmt.Fprintf(w, "|%s\n", strings.Repeat(" --- |", len(header)))
+	for _, r := range rows {
+		cells := make([]string, len(r))
+		for i, cell := range r {
+			cells[i] = strings.ReplaceAll(strings.ReplaceAll(cell, "|", "\\|"), "\n", " ")
+		}
+		if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func row(p *process.Process) []string {
+	started := ""
+	if !p.StartTime.IsZero() {
+		started = p.StartTime.Format(time.RFC3339)
+	}
+
+	return []string{
+		strconv.Itoa(p.Port),
+		p.Address,
+		strconv.Itoa(p.PID),
+		p.Name,
+		p.User,
+		p.ProjectPath,
+		p.Command,
+		started,
+		p.ContainerName,
+		strings.Join(p.Tags, ";"),
+		strconv.Itoa(p.Connections),
+	}
+}
@@ -0,0 +1,76 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// BindResult tells whether a server could bind a port now, depending on
+// the socket options it sets
+type BindResult struct {
+	Port int `json:"port"`
+	// Plain is whether a socket without options can bind the port
+	Plain bool `json:"plain"`
+	// ReuseAddr is whether one setting SO_REUSEADDR, as most servers do,
+	// can: it may bind over connections waiting out TIME_WAIT, not over a
+	// listener
+	ReuseAddr bool `json:"reuse_addr"`
+	// ReusePort is whether one also setting SO_REUSEPORT can, sharing the
+	// port with a listener that set it too
+	ReusePort bool `json:"reuse_port"`
+	// Error is why the port couldn't be tested, e.g. permission denied for
+	// a privileged port
+	Error string `json:"error,omitempty"`
+}
+
+// Conflict reports whether no server can bind the port, whatever options
+// it sets
+func (b BindResult) Conflict() bool {
+	return b.Error == "" && !b.Plain && !b.ReuseAddr && !b.ReusePort
+}
+
+// CheckBind tries binding a port on all addresses, as a server would,
+// without options and with SO_REUSEADDR and SO_REUSEPORT, to tell a port
+// really taken from one held by lingering connections
+func CheckBind(port int) BindResult {
+	result := BindResult{Port: port}
+	var err error
+	if result.Plain, err = tryBind(port, false, false); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if !reuseOptions {
+		// Windows binds over lingering connections without options, and
+		// SO_REUSEADDR lets a socket take over a port another listens on
+		result.ReuseAddr = result.Plain
+		return result
+	}
+	result.ReuseAddr, _ = tryBind(port, true, false)
+	result.ReusePort, _ = tryBind(port, true, true)
+	return result
+}
+
+// tryBind listens on the port with the options on or off. A port in use is
+// no error, other failures are.
+func tryBind(port int, reuseAddr, reusePort bool) (bool, error) {
+	lc := net.ListenConfig{Control: func(network, address string, c syscall.RawConn) error {
+		var optErr error
+		if err := c.Control(func(fd uintptr) {
+			optErr = setReuse(fd, reuseAddr, reusePort)
+		}); err != nil {
+			return err
+		}
+		return optErr
+	}}
+	listener, err := lc.Listen(context.Background(), "tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		if addrInUse(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	listener.Close()
+	return true, nil
+}
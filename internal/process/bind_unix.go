@@ -0,0 +1,37 @@
+//go:build !windows
+
+package process
+
+import (
+	"errors"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// reuseOptions is whether binding is tried with SO_REUSEADDR and
+// SO_REUSEPORT
+const reuseOptions = true
+
+// setReuse sets SO_REUSEADDR, which Go turns on for every listener, and
+// SO_REUSEPORT
+func setReuse(fd uintptr, reuseAddr, reusePort bool) error {
+	if err := unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEADDR, boolInt(reuseAddr)); err != nil {
+		return err
+	}
+	if reusePort {
+		return unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+	}
+	return nil
+}
+
+func addrInUse(err error) bool {
+	return errors.Is(err, syscall.EADDRINUSE)
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,21 @@
+//go:build windows
+
+package process
+
+import (
+	"errors"
+
+	"golang.org/x/sys/windows"
+)
+
+// reuseOptions is whether binding is tried with SO_REUSEADDR and
+// SO_REUSEPORT, which mean something else on Windows
+const reuseOptions = false
+
+func setReuse(fd uintptr, reuseAddr, reusePort bool) error {
+	return nil
+}
+
+func addrInUse(err error) bool {
+	return errors.Is(err, windows.WSAEADDRINUSE)
+}
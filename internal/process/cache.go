@@ -0,0 +1,113 @@
+package process
+
+import (
+	"sync"
+	"time"
+)
+
+// WithCache keeps lookup results for ttl, so repeated lookups within one
+// command, or across the requests of a server, don't scan the system each
+// time. Once all processes were listed, port lookups are answered from
+// that snapshot. A zero ttl disables caching.
+func WithCache(ttl time.Duration) Option {
+	return func(f *enrichingFinder) {
+		if ttl > 0 {
+			f.cache = &lookupCache{ttl: ttl, ports: make(map[int]cachedPort)}
+		}
+	}
+}
+
+// Invalidate drops the results cached by a finder created with NewFinder,
+// possibly wrapped by Filtered, so the next lookup sees the current state.
+// Anything changing which ports are in use, such as a kill, should call it.
+func Invalidate(f Finder) {
+	if i, ok := f.(invalidator); ok {
+		i.Invalidate()
+	}
+}
+
+// invalidator is implemented by finders that cache results
+type invalidator interface {
+	Invalidate()
+}
+
+// lookupCache holds the last full listing and single port lookups. A nil
+// cache holds nothing, so callers needn't check whether caching is on.
+type lookupCache struct {
+	ttl time.Duration
+
+	mu    sync.Mutex
+	all   []*Process
+	allAt time.Time
+	ports map[int]cachedPort
+}
+
+type cachedPort struct {
+	proc *Process
+	at   time.Time
+}
+
+// list returns the cached listing while it is fresh
+func (c *lookupCache) list() ([]*Process, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.all == nil || time.Since(c.allAt) > c.ttl {
+		return nil, false
+	}
+	// Callers may sort or filter the slice, but not the cached one
+	return append([]*Process(nil), c.all...), true
+}
+
+// port returns the process on a port, or nil when it was free, while the
+// listing or an earlier lookup of the port is fresh
+func (c *lookupCache) port(port int) (*Process, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.all != nil && time.Since(c.allAt) <= c.ttl {
+		return ByPorts(c.all, []int{port})[port], true
+	}
+	if entry, ok := c.ports[port]; ok && time.Since(entry.at) <= c.ttl {
+		return entry.proc, true
+	}
+	return nil, false
+}
+
+func (c *lookupCache) storeList(processes []*Process) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.all = append([]*Process(nil), processes...)
+	c.allAt = time.Now()
+}
+
+func (c *lookupCache) storePort(port int, proc *Process) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ports[port] = cachedPort{proc: proc, at: time.Now()}
+}
+
+func (c *lookupCache) clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.all = nil
+	c.ports = make(map[int]cachedPort)
+}
@@ -0,0 +1,71 @@
+package process
+
+import (
+	"os"
+	"os/exec"
+)
+
+// Capabilities describes what process discovery can see on this machine,
+// which varies with the installed tools and the privileges pf runs with
+type Capabilities struct {
+	// Backends are the tools or APIs available to discover sockets, in the
+	// order the finder tries them
+	Backends []string
+	// AllUsers reports whether processes owned by other users are visible
+	AllUsers bool
+	// SocketOnly reports that only a socket table is available, so ports
+	// are listed without their processes
+	SocketOnly bool
+	// Probes lists every backend the platform finder knows, available or
+	// not
+	Probes []Backend
+	// Elevate tells how to run pf to see every user's processes
+	Elevate string
+}
+
+// Backend is a discovery tool or API and whether it can be used here
+type Backend struct {
+	Name      string
+	Available bool
+	// Hint tells how to make a missing backend available
+	Hint string
+}
+
+// DetectCapabilities reports the discovery backends and visibility of the
+// platform finder
+func DetectCapabilities() Capabilities {
+	found, socketOnly := backends()
+	return Capabilities{
+		Backends:   found,
+		AllUsers:   seesAllUsers(),
+		SocketOnly: socketOnly,
+		Probes:     probeBackends(),
+		Elevate:    elevateHint,
+	}
+}
+
+// probeTool checks that a tool can be run, with a hint for when it can't
+func probeTool(tool, hint string) Backend {
+	return Backend{Name: tool, Available: len(installed(tool)) > 0, Hint: hint}
+}
+
+// probeFile checks that a file is readable, with a hint for when it isn't
+func probeFile(path, hint string) Backend {
+	f, err := os.Open(path)
+	if err == nil {
+		f.Close()
+	}
+	return Backend{Name: path, Available: err == nil, Hint: hint}
+}
+
+// installed returns the tools that can be run, after overrides
+func installed(tools ...string) []string {
+	var found []string
+	for _, tool := range tools {
+		path, _ := ToolCommand(tool)
+		if _, err := exec.LookPath(path); err == nil {
+			found = append(found, tool)
+		}
+	}
+	return found
+}
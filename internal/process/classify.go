@@ -0,0 +1,125 @@
+package process
+
+import (
+	"path"
+	"strings"
+)
+
+// Built-in process classes
+const (
+	ClassSystem           = "system"
+	ClassDevServer        = "dev-server"
+	ClassDatabase         = "database"
+	ClassContainerRuntime = "container-runtime"
+	ClassTunnel           = "tunnel"
+	ClassIDEHelper        = "ide-helper"
+)
+
+// ClassRule tags processes with a class. Every criterion that is set must
+// match; within a criterion any entry may match.
+type ClassRule struct {
+	Class    string   `json:"class"`
+	Names    []string `json:"names,omitempty"`    // glob patterns on the process name
+	Commands []string `json:"commands,omitempty"` // substrings of the command line
+	Ports    []int    `json:"ports,omitempty"`
+}
+
+var builtinRules = []ClassRule{
+	// Container runtimes and the proxies they use to publish ports
+	{Class: ClassContainerRuntime, Names: []string{"docker-proxy", "dockerd", "containerd*", "com.docker.*", "podman", "conmon", "rootlessport*", "colima", "limactl", "vpnkit*", "rancher*"}},
+
+	// Databases and caches
+	{Class: ClassDatabase, Names: []string{"postgres*", "mysqld*", "mariadbd*", "mongod*", "redis-server", "valkey-server", "memcached", "cockroach", "clickhouse*", "influxd", "etcd", "sqlservr*", "couchdb*", "neo4j*"}},
+	{Class: ClassDatabase, Names: []string{"java*"}, Commands: []string{"cassandra", "elasticsearch", "opensearch", "solr", "neo4j"}},
+
+	// Tunnels and VPN clients
+	{Class: ClassTunnel, Names: []string{"ngrok", "cloudflared", "frpc", "autossh", "tailscaled", "openvpn", "wireguard-go", "zrok", "bore", "chisel"}},
+	{Class: ClassTunnel, Names: []string{"ssh"}},
+	{Class: ClassTunnel, Commands: []string{"kubectl port-forward", "localtunnel"}},
+
+	// Editor and IDE background helpers
+	{Class: ClassIDEHelper, Names: []string{"code", "code helper*", "code-helper*", "cursor*", "idea*", "goland*", "pycharm*", "webstorm*", "rider*", "clion*", "gopls", "rust-analyzer", "zed*", "*language-server*", "*language_server*"}},
+	{Class: ClassIDEHelper, Commands: []string{".vscode-server", ".cursor-server", "jetbrains"}},
+
+	// Development servers
+	{Class: ClassDevServer, Names: []string{"node", "deno", "bun", "vite", "next-server*", "air", "hugo", "jekyll", "webpack*", "nodemon", "puma", "rails", "uvicorn", "gunicorn", "flask", "php", "dotnet", "caddy"}},
+	{Class: ClassDevServer, Names: []string{"python*", "ruby*", "java*"}, Commands: []string{"runserver", "flask", "uvicorn", "gunicorn", "rails", "jekyll", "http.server", "spring-boot", "quarkus", "bootrun"}},
+
+	// Operating system services
+	{Class: ClassSystem, Names: []string{"sshd", "systemd*", "launchd", "mdnsresponder", "cupsd", "rpcbind", "avahi-daemon", "chronyd", "ntpd", "dnsmasq", "rapportd", "controlcenter", "sharingd", "remoted", "svchost.exe", "lsass.exe", "wininit.exe", "services.exe", "spoolsv.exe", "system", "smbd", "nmbd", "exim*", "master"}},
+}
+
+// classify returns the classes matching a process, user rules first
+func classify(p *Process, rules []ClassRule) []string {
+	var tags []string
+	seen := make(map[string]bool)
+
+	for _, rule := range rules {
+		if rule.Class == "" || seen[rule.Class] || !rule.matches(p) {
+			continue
+		}
+		seen[rule.Class] = true
+		tags = append(tags, rule.Class)
+	}
+
+	return tags
+}
+
+func (r ClassRule) matches(p *Process) bool {
+	if len(r.Names) == 0 && len(r.Commands) == 0 && len(r.Ports) == 0 {
+		return false
+	}
+
+	if len(r.Names) > 0 {
+		name := strings.ToLower(p.Name)
+		matched := false
+		for _, pattern := range r.Names {
+			if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(r.Commands) > 0 {
+		command := strings.ToLower(p.Command)
+		matched := false
+		for _, fragment := range r.Commands {
+			if strings.Contains(command, strings.ToLower(fragment)) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(r.Ports) > 0 {
+		matched := false
+		for _, port := range r.Ports {
+			if port == p.Port {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+// HasTag reports whether the process was classified with the given class
+func (p *Process) HasTag(tag string) bool {
+	for _, t := range p.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
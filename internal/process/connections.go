@@ -0,0 +1,36 @@
+package process
+
+import (
+	"context"
+	"sort"
+)
+
+// maxPeers bounds the remote addresses kept per process; the count covers
+// every connection
+const maxPeers = 50
+
+// LoadConnections fills in the established connections of each process's
+// port, so it's clear whether killing a listener drops active clients.
+// All ports are read in one pass over the system's connections, which
+// is abandoned once ctx is done. The tools reading them go through run.
+func LoadConnections(ctx context.Context, run Runner, processes []*Process) error {
+	if len(processes) == 0 {
+		return nil
+	}
+
+	peers, err := establishedPeers(ctx, run)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range processes {
+		remote := peers[p.Port]
+		sort.Strings(remote)
+		p.Connections = len(remote)
+		p.Peers = nil
+		if len(remote) > 0 {
+			p.Peers = append([]string{}, remote[:min(len(remote), maxPeers)]...)
+		}
+	}
+	return nil
+}
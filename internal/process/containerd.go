@@ -0,0 +1,118 @@
+package process
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/doganarif/portfinder/internal/docker"
+)
+
+// Container runtimes, as set in Process.ContainerRuntime
+const (
+	RuntimeDocker     = docker.RuntimeDocker
+	RuntimePodman     = docker.RuntimePodman
+	RuntimeContainerd = "containerd"
+)
+
+// nerdctlTimeout bounds listing the containerd containers
+const nerdctlTimeout = 3 * time.Second
+
+// nerdctlContainer mirrors a line of nerdctl ps --format '{{json .}}'
+type nerdctlContainer struct {
+	ID     string `json:"ID"`
+	Names  string `json:"Names"`
+	Image  string `json:"Image"`
+	Ports  string `json:"Ports"`
+	Labels string `json:"Labels"`
+	Status string `json:"Status"`
+}
+
+// nerdctlContainers lists the running containerd containers through
+// nerdctl, which has no daemon API of its own to ask. It returns nothing
+// when nerdctl isn't installed.
+func nerdctlContainers(ctx context.Context, run Runner) ([]docker.Container, error) {
+	ctx, cancel := context.WithTimeout(ctx, nerdctlTimeout)
+	defer cancel()
+	output, err := run.Output(ctx, "nerdctl", "ps", "--format", "{{json .}}")
+	if errors.Is(err, exec.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("nerdctl failed: %w", err)
+	}
+	return parseNerdctlContainers(string(output)), nil
+}
+
+func parseNerdctlContainers(output string) []docker.Container {
+	var containers []docker.Container
+	for _, line := range strings.Split(output, "\n") {
+		var nc nerdctlContainer
+		if json.Unmarshal([]byte(line), &nc) != nil || nc.ID == "" {
+			continue
+		}
+		labels := make(map[string]string)
+		for _, label := range strings.Split(nc.Labels, ",") {
+			if k, v, ok := strings.Cut(label, "="); ok {
+				labels[k] = v
+			}
+		}
+		containers = append(containers, docker.Container{
+			ID:                nc.ID,
+			Name:              nc.Names,
+			Image:             nc.Image,
+			ComposeProject:    labels["com.docker.compose.project"],
+			ComposeService:    labels["com.docker.compose.service"],
+			ComposeWorkingDir: labels["com.docker.compose.project.working_dir"],
+			State:             "running",
+			Ports:             parsePortList(nc.Ports),
+		})
+	}
+	return containers
+}
+
+// parsePortList parses published ports as docker ps prints them, e.g.
+// "0.0.0.0:8080->80/tcp, :::8080->80/tcp"
+func parsePortList(list string) []docker.PortMapping {
+	var mappings []docker.PortMapping
+	for _, item := range strings.Split(list, ",") {
+		public, private, ok := strings.Cut(strings.TrimSpace(item), "->")
+		if !ok {
+			continue
+		}
+		i := strings.LastIndex(public, ":")
+		if i < 0 {
+			continue
+		}
+		port, proto, _ := strings.Cut(private, "/")
+		publicPort, err1 := strconv.Atoi(public[i+1:])
+		privatePort, err2 := strconv.Atoi(port)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		mappings = append(mappings, docker.PortMapping{
+			IP:          strings.Trim(public[:i], "[]"),
+			PublicPort:  publicPort,
+			PrivatePort: privatePort,
+			Type:        proto,
+		})
+	}
+	return mappings
+}
+
+// nerdctl runs a nerdctl subcommand on a container, e.g. stop or pause
+func nerdctl(action, id string) error {
+	output, err := command("nerdctl", action, id).CombinedOutput()
+	if err != nil {
+		if detail := strings.TrimSpace(string(output)); detail != "" {
+			return fmt.Errorf("nerdctl %s failed: %s", action, detail)
+		}
+		return fmt.Errorf("nerdctl %s failed: %w", action, err)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package process
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/doganarif/portfinder/internal/docker"
+)
+
+// containerSource holds the running containers of a runtime, and the VM
+// they run in when they were listed through a VM's endpoint
+type containerSource struct {
+	runtime    string
+	vm         string
+	containers []docker.Container
+}
+
+// vmForwarders map the processes publishing container ports on the host
+// for a container VM, as on macOS where containers don't run on the host,
+// to the names of the candidate endpoints of the daemon inside the VM
+var vmForwarders = []struct {
+	pattern string
+	vms     []string
+}{
+	{"com.docker.*", []string{"Docker Desktop"}},
+	{"vpnkit*", []string{"Docker Desktop"}},
+	{"colima", []string{"Colima"}},
+	{"limactl", []string{"Colima", "Rancher Desktop"}},
+	{"orbstack*", []string{"OrbStack"}},
+	{"rancher*", []string{"Rancher Desktop"}},
+}
+
+// forwardingVMs returns the VMs a process may forward ports for, or nil
+// when it isn't a VM's port forwarder
+func forwardingVMs(name string) []string {
+	name = strings.ToLower(name)
+	for _, f := range vmForwarders {
+		if ok, _ := path.Match(f.pattern, name); ok {
+			return f.vms
+		}
+	}
+	return nil
+}
+
+// resolveContainers attaches container details to processes that either run
+// inside a container or proxy one of its published ports
+func resolveContainers(sources []containerSource, processes []*Process) {
+	for _, proc := range processes {
+		source, container, inside := findContainer(sources, proc)
+		if container == nil {
+			continue
+		}
+		if project := containerProject(proc, container, inside); project != "" {
+			proc.ProjectPath = project
+		}
+
+		proc.IsDocker = true
+		proc.ContainerRuntime = source.runtime
+		proc.DockerID = shortID(container.ID)
+		proc.ContainerName = container.Name
+		proc.ContainerImage = container.Image
+		proc.ComposeProject = container.ComposeProject
+		if m, ok := container.Mapping(proc.Port); ok {
+			proc.ContainerIP, proc.ContainerPort = container.IPAddress, m.PrivatePort
+		}
+		if vms := forwardingVMs(proc.Name); vms != nil && !inside {
+			proc.ForwardedBy = vms[0]
+			if source.vm != "" {
+				proc.ForwardedBy = source.vm
+			}
+		}
+		resolveClusterNode(proc, container)
+	}
+}
+
+// findContainer returns the container a process runs in, or else the one
+// publishing its port, with the source listing it
+func findContainer(sources []containerSource, proc *Process) (*containerSource, *docker.Container, bool) {
+	for i, s := range sources {
+		if container := docker.FindByID(s.containers, proc.DockerID); container != nil {
+			return &sources[i], container, true
+		}
+	}
+	for i, s := range sources {
+		if container := docker.FindByPort(s.containers, proc.Port); container != nil {
+			return &sources[i], container, false
+		}
+	}
+	return nil, nil, false
+}
+
+// containerProject finds the host project of a container. The cwd of a
+// process inside it lives in the container filesystem, so it is mapped
+// through the bind mounts; otherwise compose's working directory or the
+// only bind mounted directory stands for the project.
+func containerProject(proc *Process, container *docker.Container, inside bool) string {
+	if inside && proc.WorkDir != "" {
+		if host := container.HostPath(proc.WorkDir); host != "" {
+			return detectProject(proc.PID, host)
+		}
+	}
+
+	if container.ComposeWorkingDir != "" {
+		return container.ComposeWorkingDir
+	}
+
+	// Sockets and config files are often mounted too, only directories
+	// can hold a project
+	var binds []string
+	for _, m := range container.Mounts {
+		if info, err := os.Stat(m.Source); m.Type == "bind" && err == nil && info.IsDir() {
+			binds = append(binds, m.Source)
+		}
+	}
+	if len(binds) == 1 {
+		return detectProject(proc.PID, binds[0])
+	}
+	return ""
+}
+
+// RuntimeName names the container runtime of the process for display, e.g.
+// "Podman", with "Docker" when it isn't known
+func (p *Process) RuntimeName() string {
+	switch p.ContainerRuntime {
+	case RuntimePodman:
+		return "Podman"
+	case RuntimeContainerd:
+		return "containerd"
+	}
+	return "Docker"
+}
+
+// DisplayName names the process for display: the container behind a VM's
+// port forwarder says more than the forwarder's name
+func (p *Process) DisplayName() string {
+	if p.ForwardedBy != "" && p.ContainerName != "" {
+		return p.ContainerName
+	}
+	if p.ForwardedBy != "" && p.ContainerImage != "" {
+		return p.ContainerImage
+	}
+	return p.Name
+}
+
+// ContainerTarget describes where a published port leads inside its
+// container, e.g. "172.17.0.2:80", or "" when it isn't published
+func (p *Process) ContainerTarget() string {
+	if p.ContainerPort == 0 {
+		return ""
+	}
+	if p.ContainerIP == "" {
+		return strconv.Itoa(p.ContainerPort)
+	}
+	return net.JoinHostPort(p.ContainerIP, strconv.Itoa(p.ContainerPort))
+}
+
+// StopContainer stops the container behind the process instead of
+// signaling the process itself
+func (p *Process) StopContainer() error {
+	if !p.IsDocker || p.DockerID == "" || p.DockerID == "unknown" {
+		return fmt.Errorf("process %d is not associated with a known container", p.PID)
+	}
+
+	if p.ContainerRuntime == RuntimeContainerd {
+		return nerdctl("stop", p.DockerID)
+	}
+	client, err := docker.NewClientFor(p.ContainerRuntime)
+	if err != nil {
+		return err
+	}
+
+	return client.StopContainer(p.DockerID)
+}
+
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
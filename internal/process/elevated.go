@@ -0,0 +1,79 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// elevatedFinder fills in a finder's listing with one taken with more
+// privileges
+type elevatedFinder struct {
+	base Finder
+	list func(context.Context) ([]*Process, error)
+}
+
+// Elevated returns a Finder merging base's listing with the one of list,
+// which runs discovery with more privileges, such as pf itself through
+// sudo. Listeners are taken from the elevated listing, which sees the
+// processes of every user, and those only base sees are kept.
+func Elevated(base Finder, list func(context.Context) ([]*Process, error)) Finder {
+	return &elevatedFinder{base: base, list: list}
+}
+
+func (f *elevatedFinder) FindByPort(ctx context.Context, port int) (*Process, error) {
+	found, err := f.FindByPorts(ctx, []int{port})
+	if err != nil {
+		return nil, err
+	}
+	return found[port], nil
+}
+
+func (f *elevatedFinder) FindByPorts(ctx context.Context, ports []int) (map[int]*Process, error) {
+	processes, err := f.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ByPorts(processes, ports), nil
+}
+
+func (f *elevatedFinder) ListAll(ctx context.Context) ([]*Process, error) {
+	processes, err := f.base.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	elevated, err := f.list(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("elevated rescan failed: %w", err)
+	}
+	return merge(processes, elevated), nil
+}
+
+func (f *elevatedFinder) Invalidate() {
+	Invalidate(f.base)
+}
+
+func (f *elevatedFinder) Stats() Stats {
+	stats, _ := FinderStats(f.base)
+	return stats
+}
+
+// merge returns the elevated listeners, followed by those of processes on
+// a port and address the elevated listing lacks
+func merge(processes, elevated []*Process) []*Process {
+	seen := make(map[string]bool, len(elevated))
+	for _, p := range elevated {
+		seen[listenerKey(p)] = true
+	}
+	merged := append([]*Process(nil), elevated...)
+	for _, p := range processes {
+		if !seen[listenerKey(p)] {
+			merged = append(merged, p)
+		}
+	}
+	return merged
+}
+
+func listenerKey(p *Process) string {
+	return p.Address + ":" + strconv.Itoa(p.Port)
+}
@@ -0,0 +1,90 @@
+package process
+
+import (
+	"context"
+	"sort"
+)
+
+// EphemeralUsage is how much of the ephemeral port range, which the
+// system picks the local port of outgoing connections from, is taken.
+// Connecting fails with "cannot assign requested address" once it's full.
+type EphemeralUsage struct {
+	Low  int `json:"low"`
+	High int `json:"high"`
+	// InUse counts the ports of the range bound by a TCP socket
+	InUse int `json:"in_use"`
+	// TimeWait counts the closed connections from the range waiting out
+	// TIME_WAIT, which keep their port until then
+	TimeWait int `json:"time_wait"`
+	// Consumers are the processes holding the most ports of the range
+	Consumers []EphemeralConsumer `json:"consumers"`
+}
+
+// EphemeralConsumer is a process and the number of ephemeral ports it holds
+type EphemeralConsumer struct {
+	PID   int    `json:"pid"`
+	Name  string `json:"name"`
+	Ports int    `json:"ports"`
+}
+
+// Size is the number of ports in the range
+func (u *EphemeralUsage) Size() int {
+	return u.High - u.Low + 1
+}
+
+// maxConsumers bounds the consumers reported
+const maxConsumers = 10
+
+// EphemeralPorts reads the ephemeral port range and counts the TCP sockets
+// using it, in one pass over the system's sockets
+func EphemeralPorts(ctx context.Context) (*EphemeralUsage, error) {
+	low, high, err := ephemeralRange(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sockets, err := tcpSockets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return summarizeEphemeral(low, high, sockets), nil
+}
+
+func summarizeEphemeral(low, high int, sockets []TCPSocket) *EphemeralUsage {
+	usage := &EphemeralUsage{Low: low, High: high, Consumers: []EphemeralConsumer{}}
+	used := make(map[int]bool)
+	held := make(map[int]map[int]bool)
+	names := make(map[int]string)
+	for _, s := range sockets {
+		if s.Port < low || s.Port > high {
+			continue
+		}
+		used[s.Port] = true
+		if s.State == "TIME_WAIT" {
+			usage.TimeWait++
+		}
+		if s.PID <= 0 {
+			continue
+		}
+		if held[s.PID] == nil {
+			held[s.PID] = make(map[int]bool)
+		}
+		held[s.PID][s.Port] = true
+		names[s.PID] = s.Name
+	}
+	usage.InUse = len(used)
+
+	for pid, ports := range held {
+		usage.Consumers = append(usage.Consumers, EphemeralConsumer{PID: pid, Name: names[pid], Ports: len(ports)})
+	}
+	sort.Slice(usage.Consumers, func(i, j int) bool {
+		a, b := usage.Consumers[i], usage.Consumers[j]
+		if a.Ports != b.Ports {
+			return a.Ports > b.Ports
+		}
+		return a.PID < b.PID
+	})
+	if len(usage.Consumers) > maxConsumers {
+		usage.Consumers = usage.Consumers[:maxConsumers]
+	}
+	return usage
+}
@@ -0,0 +1,34 @@
+//go:build freebsd || openbsd
+
+package process
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// portOwners maps the local ports of TCP sockets to the processes holding
+// them with sockstat, which OpenBSD lacks
+func portOwners(ctx context.Context) map[int]TCPSocket {
+	owners := make(map[int]TCPSocket)
+	output, err := commandContext(ctx, "sockstat", "-46", "-P", "tcp").Output()
+	if err != nil {
+		return owners
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		// USER COMMAND PID FD PROTO LOCAL FOREIGN
+		if len(fields) < 7 || fields[0] == "USER" {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+		if _, port, ok := splitAddress(fields[5]); ok {
+			owners[port] = TCPSocket{PID: pid, Name: fields[1]}
+		}
+	}
+	return owners
+}
@@ -0,0 +1,35 @@
+package process
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// portOwners maps the local ports of TCP sockets to the processes holding
+// them with lsof. Only the processes lsof may inspect are found.
+func portOwners(ctx context.Context) map[int]TCPSocket {
+	owners := make(map[int]TCPSocket)
+	// lsof exits with 1 when some processes can't be read, output is kept
+	output, _ := commandContext(ctx, "lsof", "-iTCP", "-n", "-P", "-F", "pcn").Output()
+
+	var owner TCPSocket
+	for _, line := range strings.Split(string(output), "\n") {
+		if line == "" {
+			continue
+		}
+		switch line[0] {
+		case 'p':
+			owner.PID, _ = strconv.Atoi(line[1:])
+		case 'c':
+			owner.Name = line[1:]
+		case 'n':
+			// "local->remote" when connected
+			local, _, _ := strings.Cut(line[1:], "->")
+			if _, port, ok := splitAddress(local); ok {
+				owners[port] = owner
+			}
+		}
+	}
+	return owners
+}
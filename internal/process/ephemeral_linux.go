@@ -0,0 +1,102 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ephemeralRange reads the range from /proc/sys/net/ipv4/ip_local_port_range,
+// which IPv6 shares
+func ephemeralRange(ctx context.Context) (int, int, error) {
+	data, err := os.ReadFile("/proc/sys/net/ipv4/ip_local_port_range")
+	if err != nil {
+		return 0, 0, fmt.Errorf("cannot read the ephemeral port range: %w", err)
+	}
+	var low, high int
+	if _, err := fmt.Sscan(string(data), &low, &high); err != nil {
+		return 0, 0, fmt.Errorf("cannot parse the ephemeral port range %q", strings.TrimSpace(string(data)))
+	}
+	return low, high, nil
+}
+
+// tcpSockets reads the TCP sockets of /proc/net/tcp and tcp6, matching
+// their inodes to the processes holding them
+func tcpSockets(ctx context.Context) ([]TCPSocket, error) {
+	var sockets []TCPSocket
+	inodes := make(map[string]int)
+	read := false
+	for _, file := range []string{"/proc/net/tcp", "/proc/net/tcp6"} {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		read = true
+
+		lines := strings.Split(string(content), "\n")
+		for _, line := range lines[1:] {
+			// sl local_address rem_address st ... uid timeout inode
+			fields := strings.Fields(line)
+			if len(fields) < 10 {
+				continue
+			}
+			host, port, ok := parseProcAddress(fields[1])
+			if !ok {
+				continue
+			}
+			s := TCPSocket{Port: port, Local: net.JoinHostPort(host, strconv.Itoa(port)), State: tcpStates[fields[3]]}
+			if host, port, ok := parseProcAddress(fields[2]); ok && port != 0 {
+				s.Remote = net.JoinHostPort(host, strconv.Itoa(port))
+			}
+			sockets = append(sockets, s)
+			if fields[9] != "0" {
+				inodes[fields[9]] = len(sockets) - 1
+			}
+		}
+	}
+	if !read {
+		return nil, fmt.Errorf("cannot read /proc/net/tcp")
+	}
+
+	err := inodeOwners(ctx, func(inode string, pid int, name string) {
+		if i, ok := inodes[inode]; ok {
+			sockets[i].PID, sockets[i].Name = pid, name
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sockets, nil
+}
+
+// inodeOwners calls found with the inode of every socket descriptor in
+// /proc/<pid>/fd and the process holding it. Only the processes we may
+// inspect are seen.
+func inodeOwners(ctx context.Context, found func(inode string, pid int, name string)) error {
+	names := make(map[int]string)
+	fds, _ := filepath.Glob("/proc/[0-9]*/fd/*")
+	for _, fd := range fds {
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+		target, err := os.Readlink(fd)
+		if err != nil {
+			continue
+		}
+		inode, ok := strings.CutPrefix(target, "socket:[")
+		if !ok {
+			continue
+		}
+		pid, _ := strconv.Atoi(strings.Split(fd, "/")[2])
+		if _, ok := names[pid]; !ok {
+			comm, _ := os.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+			names[pid] = strings.TrimSpace(string(comm))
+		}
+		found(strings.TrimSuffix(inode, "]"), pid, names[pid])
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+//go:build darwin || freebsd || openbsd
+
+package process
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ephemeralRange reads the range with sysctl, under the names of macOS and
+// FreeBSD or else those of OpenBSD
+func ephemeralRange(ctx context.Context) (int, int, error) {
+	for _, names := range [][]string{
+		{"net.inet.ip.portrange.first", "net.inet.ip.portrange.last"},
+		{"net.inet.ip.porthifirst", "net.inet.ip.porthilast"},
+	} {
+		output, err := commandContext(ctx, "sysctl", append([]string{"-n"}, names...)...).Output()
+		if err != nil {
+			continue
+		}
+		var low, high int
+		if _, err := fmt.Sscan(string(output), &low, &high); err == nil {
+			return low, high, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("cannot read the ephemeral port range with sysctl")
+}
+
+// tcpSockets lists the TCP sockets with netstat, which shows those in
+// TIME_WAIT, attributing them to the processes holding their port
+func tcpSockets(ctx context.Context) ([]TCPSocket, error) {
+	output, err := commandContext(ctx, "netstat", "-an", "-p", "tcp").Output()
+	if err != nil {
+		return nil, fmt.Errorf("netstat failed: %w", err)
+	}
+	sockets := parseNetstatSockets(string(output))
+
+	owners := portOwners(ctx)
+	for i := range sockets {
+		if owner, ok := owners[sockets[i].Port]; ok && sockets[i].State != "TIME_WAIT" {
+			sockets[i].PID, sockets[i].Name = owner.PID, owner.Name
+		}
+	}
+	return sockets, nil
+}
+
+// parseNetstatSockets parses BSD netstat -an output, whose addresses are
+// "host.port"
+func parseNetstatSockets(output string) []TCPSocket {
+	var sockets []TCPSocket
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		// Proto Recv-Q Send-Q Local Foreign (state)
+		if len(fields) < 6 || !strings.HasPrefix(fields[0], "tcp") {
+			continue
+		}
+		host, port, ok := splitNetstatAddress(fields[3])
+		if !ok {
+			continue
+		}
+		s := TCPSocket{Port: port, Local: net.JoinHostPort(host, strconv.Itoa(port)), State: fields[5]}
+		if host, port, ok := splitNetstatAddress(fields[4]); ok {
+			s.Remote = net.JoinHostPort(host, strconv.Itoa(port))
+		}
+		sockets = append(sockets, s)
+	}
+	return sockets
+}
+
+// splitNetstatAddress splits a BSD netstat address such as "127.0.0.1.3000"
+// or "*.3000", failing for unconnected ones such as "*.*"
+func splitNetstatAddress(addr string) (string, int, bool) {
+	i := strings.LastIndex(addr, ".")
+	if i < 0 {
+		return "", 0, false
+	}
+	port, err := strconv.Atoi(addr[i+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	return addr[:i], port, true
+}
@@ -0,0 +1,66 @@
+//go:build windows
+
+package process
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ephemeralRange reads the dynamic port range of IPv4 with netsh, printed
+// as its start port and number of ports
+func ephemeralRange(ctx context.Context) (int, int, error) {
+	output, err := commandContext(ctx, "netsh", "int", "ipv4", "show", "dynamicport", "tcp").Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("netsh failed: %w", err)
+	}
+	// The labels are translated, so take the numbers in their order
+	var values []int
+	for _, line := range strings.Split(string(output), "\n") {
+		if _, value, ok := strings.Cut(line, ":"); ok {
+			if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+				values = append(values, n)
+			}
+		}
+	}
+	if len(values) < 2 {
+		return 0, 0, fmt.Errorf("cannot parse the dynamic port range netsh printed")
+	}
+	return values[0], values[0] + values[1] - 1, nil
+}
+
+// tcpSockets lists the TCP sockets and the PIDs holding them with netstat
+func tcpSockets(ctx context.Context) ([]TCPSocket, error) {
+	output, err := commandContext(ctx, "netstat", "-ano", "-p", "tcp").Output()
+	if err != nil {
+		return nil, fmt.Errorf("netstat failed: %w", err)
+	}
+
+	var sockets []TCPSocket
+	names := make(map[int]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		// Proto, Local Address, Foreign Address, State, PID
+		if len(fields) < 5 || fields[0] != "TCP" {
+			continue
+		}
+		_, port, ok := splitAddress(fields[1])
+		if !ok {
+			continue
+		}
+		s := TCPSocket{Port: port, Local: fields[1], Remote: fields[2], State: fields[3]}
+		if pid, err := strconv.Atoi(fields[4]); err == nil && pid > 0 {
+			if _, ok := names[pid]; !ok {
+				if exePath, _, err := nativeProcessInfo(pid); err == nil {
+					names[pid] = strings.TrimSuffix(filepath.Base(exePath), ".exe")
+				}
+			}
+			s.PID, s.Name = pid, names[pid]
+		}
+		sockets = append(sockets, s)
+	}
+	return sockets, nil
+}
@@ -0,0 +1,344 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/doganarif/portfinder/internal/docker"
+)
+
+// Option configures a Finder
+type Option func(*enrichingFinder)
+
+// WithRules adds user-defined classification rules, which take precedence
+// over the built-in ones
+func WithRules(rules []ClassRule) Option {
+	return func(f *enrichingFinder) {
+		f.rules = append(append([]ClassRule{}, rules...), f.rules...)
+	}
+}
+
+// enrichingFinder decorates a platform finder with information that isn't
+// platform specific: container metadata from the Docker daemon, when one is
+// reachable, and process classification
+type enrichingFinder struct {
+	base  Finder
+	rules []ClassRule
+	// run runs the tools enrichment reads, as the platform finder does
+	run Runner
+
+	// mu guards stats, docker and vms, since servers share one finder
+	mu    sync.Mutex
+	stats Stats
+
+	// docker holds a client per container runtime, reused once a daemon
+	// was found, so repeated lookups don't probe every candidate endpoint
+	// again
+	docker []*docker.Client
+	// vms holds the clients of the daemons inside container VMs, by
+	// endpoint name, see vmSources
+	vms map[string]*docker.Client
+
+	// cache is nil unless WithCache enabled it
+	cache *lookupCache
+
+	// timeout bounds each discovery, unless zero
+	timeout time.Duration
+}
+
+// WithTimeout bounds each lookup, killing the tools it runs once timeout
+// has passed, so one hanging on, say, a stale NFS mount fails the lookup
+// rather than freezing it. A zero timeout leaves lookups unbounded.
+func WithTimeout(timeout time.Duration) Option {
+	return func(f *enrichingFinder) {
+		f.timeout = timeout
+	}
+}
+
+// NewFinder creates a platform-specific process finder
+func NewFinder(opts ...Option) Finder {
+	f := &enrichingFinder{
+		base:  &platformFinder{run: execRunner{}},
+		rules: builtinRules,
+		run:   execRunner{},
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+func (f *enrichingFinder) FindByPort(ctx context.Context, port int) (*Process, error) {
+	if proc, ok := f.cache.port(port); ok {
+		f.addStats(Stats{Calls: 1, CacheHits: 1})
+		return proc, nil
+	}
+
+	ctx, cancel := f.bound(ctx)
+	defer cancel()
+	start := time.Now()
+	proc, err := f.base.FindByPort(ctx, port)
+	f.addStats(Stats{Calls: 1, Discovery: time.Since(start)})
+	// An interrupted lookup may have fallen back to a partial result
+	if err != nil || ctx.Err() == context.Canceled {
+		return nil, f.lookupErr(ctx, err)
+	}
+
+	if proc != nil {
+		f.enrich(ctx, []*Process{proc})
+	}
+	f.cache.storePort(port, proc)
+	return proc, nil
+}
+
+func (f *enrichingFinder) FindByPorts(ctx context.Context, ports []int) (map[int]*Process, error) {
+	if processes, ok := f.cache.list(); ok {
+		f.addStats(Stats{Calls: 1, CacheHits: 1})
+		return ByPorts(processes, ports), nil
+	}
+
+	ctx, cancel := f.bound(ctx)
+	defer cancel()
+	start := time.Now()
+	found, err := f.base.FindByPorts(ctx, ports)
+	f.addStats(Stats{Calls: 1, Discovery: time.Since(start)})
+	// An interrupted lookup may have fallen back to a partial result
+	if err != nil || ctx.Err() == context.Canceled {
+		return nil, f.lookupErr(ctx, err)
+	}
+
+	processes := make([]*Process, 0, len(found))
+	for _, proc := range found {
+		processes = append(processes, proc)
+	}
+	f.enrich(ctx, processes)
+	for _, port := range ports {
+		f.cache.storePort(port, found[port])
+	}
+	return found, nil
+}
+
+func (f *enrichingFinder) ListAll(ctx context.Context) ([]*Process, error) {
+	if processes, ok := f.cache.list(); ok {
+		f.addStats(Stats{Calls: 1, CacheHits: 1})
+		return processes, nil
+	}
+
+	ctx, cancel := f.bound(ctx)
+	defer cancel()
+	start := time.Now()
+	processes, err := f.base.ListAll(ctx)
+	f.addStats(Stats{Calls: 1, Discovery: time.Since(start)})
+	// An interrupted lookup may have fallen back to a partial result
+	if err != nil || ctx.Err() == context.Canceled {
+		return nil, f.lookupErr(ctx, err)
+	}
+
+	processes = dedupListeners(processes)
+	f.enrich(ctx, processes)
+	f.cache.storeList(processes)
+	return processes, nil
+}
+
+// bound applies the timeout to a lookup's context
+func (f *enrichingFinder) bound(ctx context.Context) (context.Context, context.CancelFunc) {
+	if f.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, f.timeout)
+}
+
+// lookupErr explains the failure of a lookup cut short, which otherwise
+// shows as the tool it ran being killed
+func (f *enrichingFinder) lookupErr(ctx context.Context, err error) error {
+	switch {
+	case ctx.Err() == context.DeadlineExceeded && f.timeout > 0:
+		return fmt.Errorf("gave up after %s: %w", f.timeout, err)
+	case ctx.Err() != nil:
+		return context.Cause(ctx)
+	}
+	return err
+}
+
+// Invalidate drops cached results
+func (f *enrichingFinder) Invalidate() {
+	f.cache.clear()
+}
+
+// Stats returns the time spent so far in each phase
+func (f *enrichingFinder) Stats() Stats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.stats
+}
+
+func (f *enrichingFinder) addStats(delta Stats) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stats.Calls += delta.Calls
+	f.stats.Processes += delta.Processes
+	f.stats.Discovery += delta.Discovery
+	f.stats.Docker += delta.Docker
+	f.stats.Enrichment += delta.Enrichment
+	f.stats.CacheHits += delta.CacheHits
+}
+
+// dockerClients returns the cached clients of the Docker and Podman
+// daemons, connecting on first use. Daemons missed because ctx ended are
+// looked for again next time.
+func (f *enrichingFinder) dockerClients(ctx context.Context) []*docker.Client {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.docker != nil {
+		f.stats.CacheHits++
+		return f.docker
+	}
+
+	f.stats.CacheMisses++
+	clients := docker.NewClients(ctx)
+	if len(clients) > 0 && ctx.Err() == nil {
+		f.docker = clients
+	}
+	return clients
+}
+
+// containerSources lists the running containers of every runtime found:
+// Docker and Podman through their API, containerd through nerdctl, and
+// those of the container VMs forwarding the ports of processes
+func (f *enrichingFinder) containerSources(ctx context.Context, processes []*Process) []containerSource {
+	var sources []containerSource
+	tried := make(map[string]bool)
+	for _, client := range f.dockerClients(ctx) {
+		tried[client.Endpoint().Name] = true
+		if containers, err := client.ListContainers(ctx); err == nil {
+			sources = append(sources, containerSource{runtime: client.Runtime(), containers: containers})
+		}
+	}
+	if runtime.GOOS == "linux" {
+		if containers, err := nerdctlContainers(ctx, f.run); err == nil && len(containers) > 0 {
+			sources = append(sources, containerSource{runtime: RuntimeContainerd, containers: containers})
+		}
+	}
+	return f.vmSources(ctx, sources, tried, processes)
+}
+
+// vmSources adds the containers of the VMs whose port forwarders hold ports
+// no known container publishes, e.g. Colima's when the docker context
+// points to Docker Desktop. The VMs in tried were already listed.
+func (f *enrichingFinder) vmSources(ctx context.Context, sources []containerSource, tried map[string]bool, processes []*Process) []containerSource {
+	for _, proc := range processes {
+		vms := forwardingVMs(proc.Name)
+		if vms == nil {
+			continue
+		}
+		if _, container, _ := findContainer(sources, proc); container != nil {
+			continue
+		}
+		for _, vm := range vms {
+			if tried[vm] {
+				continue
+			}
+			tried[vm] = true
+			client := f.vmClient(ctx, vm)
+			if client == nil {
+				continue
+			}
+			if containers, err := client.ListContainers(ctx); err == nil {
+				sources = append(sources, containerSource{runtime: client.Runtime(), vm: vm, containers: containers})
+			}
+		}
+	}
+	return sources
+}
+
+// vmClient returns the cached client of the daemon inside a container VM,
+// or nil when it doesn't answer
+func (f *enrichingFinder) vmClient(ctx context.Context, vm string) *docker.Client {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if client := f.vms[vm]; client != nil {
+		return client
+	}
+	client, err := docker.NewClientAt(ctx, vm)
+	if err != nil {
+		return nil
+	}
+	if f.vms == nil {
+		f.vms = make(map[string]*docker.Client)
+	}
+	f.vms[vm] = client
+	return client
+}
+
+// filteredFinder hides the processes its keep function rejects
+type filteredFinder struct {
+	base Finder
+	keep func(*Process) bool
+}
+
+// Filtered returns a Finder that only reports processes for which keep
+// returns true, so views refreshing through it keep the filter applied
+func Filtered(base Finder, keep func(*Process) bool) Finder {
+	return &filteredFinder{base: base, keep: keep}
+}
+
+func (f *filteredFinder) FindByPort(ctx context.Context, port int) (*Process, error) {
+	proc, err := f.base.FindByPort(ctx, port)
+	if err != nil || proc == nil || !f.keep(proc) {
+		return nil, err
+	}
+	return proc, nil
+}
+
+func (f *filteredFinder) FindByPorts(ctx context.Context, ports []int) (map[int]*Process, error) {
+	found, err := f.base.FindByPorts(ctx, ports)
+	if err != nil {
+		return nil, err
+	}
+	for port, proc := range found {
+		if !f.keep(proc) {
+			delete(found, port)
+		}
+	}
+	return found, nil
+}
+
+func (f *filteredFinder) ListAll(ctx context.Context) ([]*Process, error) {
+	processes, err := f.base.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return Filter(processes, f.keep), nil
+}
+
+func (f *filteredFinder) Invalidate() {
+	Invalidate(f.base)
+}
+
+func (f *filteredFinder) Stats() Stats {
+	stats, _ := FinderStats(f.base)
+	return stats
+}
+
+func (f *enrichingFinder) enrich(ctx context.Context, processes []*Process) {
+	start := time.Now()
+	resolveContainers(f.containerSources(ctx, processes), processes)
+	dockerTime := time.Since(start)
+
+	start = time.Now()
+	for _, p := range processes {
+		p.ID = NewProcessID(p.PID, p.StartTime, p.Port)
+		p.Tags = classify(p, f.rules)
+	}
+	identifyProjects(processes)
+	resolveKubernetes(ctx, f.run, processes)
+	// Connection counts are informational, so failures are ignored
+	LoadConnections(ctx, f.run, processes)
+
+	f.addStats(Stats{Processes: len(processes), Docker: dockerTime, Enrichment: time.Since(start)})
+}
@@ -0,0 +1,281 @@
+package process
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fingerprint identifies the server behind a port from its answer to a
+// probe
+type Fingerprint struct {
+	// Protocol is the protocol the port answered: "http", "https",
+	// "postgres", "mysql", "redis", "mongodb", "ssh" or "h2c"
+	Protocol string `json:"protocol"`
+	// Status is the HTTP status code of the response to GET /
+	Status int `json:"status,omitempty"`
+	// Server and PoweredBy are the response's Server and X-Powered-By
+	// headers
+	Server    string `json:"server,omitempty"`
+	PoweredBy string `json:"powered_by,omitempty"`
+	// Framework is the framework or server recognized, e.g. "Next.js"
+	Framework string `json:"framework,omitempty"`
+	// Product and Version name the server of other protocols, e.g.
+	// "Postgres" and "16.1". The version is empty when the server only
+	// reveals it to logged in clients.
+	Product string `json:"product,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// Label names what answers on the port, e.g. "Next.js" or "Postgres 16.1"
+func (f *Fingerprint) Label() string {
+	if f.Product == "" {
+		return f.Framework
+	}
+	if f.Version == "" {
+		return f.Product
+	}
+	return f.Product + " " + f.Version
+}
+
+// probeBodyLimit bounds how much of a response is read for the markers of
+// frameworks
+const probeBodyLimit = 64 << 10
+
+// Probe talks the protocols pf knows to the port, those usually found on
+// it first, and fills Fingerprint from the first that answers. Each
+// attempt is bounded by timeout. It fails when none answers.
+func (p *Process) Probe(timeout time.Duration) error {
+	addr := p.DialAddress()
+	for _, pp := range orderedProbes(p.Port) {
+		if f, err := pp.probe(addr, timeout); err == nil {
+			p.Fingerprint = f
+			return nil
+		}
+	}
+	return fmt.Errorf("port %d doesn't answer HTTP or another protocol pf knows", p.Port)
+}
+
+// probeHTTP requests / over HTTPS, then plain HTTP, recognizing the
+// framework from the response
+func probeHTTP(addr string, timeout time.Duration) (*Fingerprint, error) {
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// Local dev certificates are rarely trusted, and only the
+			// server's identity as a program matters here
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+		// The first response tells most, redirects included
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	defer client.CloseIdleConnections()
+
+	var err error
+	for _, scheme := range []string{"https", "http"} {
+		var resp *http.Response
+		resp, err = client.Get(scheme + "://" + addr + "/")
+		if err != nil {
+			continue
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, probeBodyLimit))
+		resp.Body.Close()
+
+		return &Fingerprint{
+			Protocol:  scheme,
+			Status:    resp.StatusCode,
+			Server:    resp.Header.Get("Server"),
+			PoweredBy: resp.Header.Get("X-Powered-By"),
+			Framework: detectFramework(resp.Header, string(body)),
+		}, nil
+	}
+	return nil, err
+}
+
+// DialAddress is the host:port to reach the listener at, on loopback when
+// it is bound to all interfaces
+func (p *Process) DialAddress() string {
+	host := p.Address
+	switch host {
+	case "", "*", "0.0.0.0":
+		host = "127.0.0.1"
+	case "::":
+		host = "::1"
+	}
+	return net.JoinHostPort(host, strconv.Itoa(p.Port))
+}
+
+// frameworkMarkers are strings in a page that give away the framework
+// that served it
+var frameworkMarkers = []struct{ marker, framework string }{
+	{"/@vite/client", "Vite"},
+	{"__NEXT_DATA__", "Next.js"},
+	{"/_next/static", "Next.js"},
+	{"__NUXT__", "Nuxt"},
+	{"/_nuxt/", "Nuxt"},
+	{"ng-version=", "Angular"},
+	{"/webpack-dev-server", "webpack-dev-server"},
+	{"data-sveltekit", "SvelteKit"},
+	{"/_astro/", "Astro"},
+	{"__remixContext", "Remix"},
+	// MongoDB answers HTTP requests on its driver port with a hint
+	{"trying to access MongoDB over HTTP", "MongoDB"},
+}
+
+// serverPrefixes map the prefixes of Server headers to the server or
+// framework sending them
+var serverPrefixes = []struct{ prefix, framework string }{
+	{"nginx", "nginx"},
+	{"apache", "Apache"},
+	{"caddy", "Caddy"},
+	{"envoy", "Envoy"},
+	{"traefik", "Traefik"},
+	{"gunicorn", "Gunicorn"},
+	{"uvicorn", "Uvicorn"},
+	{"hypercorn", "Hypercorn"},
+	{"werkzeug", "Flask (Werkzeug)"},
+	{"wsgiserver", "Django"},
+	{"simplehttp", "Python http.server"},
+	{"tornado", "Tornado"},
+	{"puma", "Puma"},
+	{"webrick", "WEBrick"},
+	{"kestrel", "ASP.NET Core"},
+	{"jetty", "Jetty"},
+	{"cowboy", "Cowboy (Phoenix)"},
+	{"deno", "Deno"},
+	{"bun", "Bun"},
+	{"minio", "MinIO"},
+}
+
+// detectFramework recognizes the framework or server behind a response,
+// from the markers in its page first, then its headers
+func detectFramework(header http.Header, body string) string {
+	for _, m := range frameworkMarkers {
+		if strings.Contains(body, m.marker) {
+			return m.framework
+		}
+	}
+
+	poweredBy := strings.ToLower(header.Get("X-Powered-By"))
+	switch {
+	case strings.Contains(poweredBy, "next.js"):
+		return "Next.js"
+	case strings.Contains(poweredBy, "nuxt"):
+		return "Nuxt"
+	case strings.Contains(poweredBy, "express"):
+		return "Express"
+	case strings.Contains(poweredBy, "php"):
+		return "PHP"
+	case strings.Contains(poweredBy, "asp.net"):
+		return "ASP.NET"
+	}
+
+	server := strings.ToLower(header.Get("Server"))
+	for _, s := range serverPrefixes {
+		if strings.HasPrefix(server, s.prefix) {
+			return s.framework
+		}
+	}
+	return ""
+}
+
+// ProbeAll probes the ports of the processes in parallel, leaving the
+// fingerprint of those where nothing answers empty
+func ProbeAll(processes []*Process, timeout time.Duration) {
+	var wg sync.WaitGroup
+	for _, p := range processes {
+		wg.Add(1)
+		go func(p *Process) {
+			defer wg.Done()
+			p.Probe(timeout)
+		}(p)
+	}
+	wg.Wait()
+}
+
+// probedFinder fingerprints the listeners of a finder
+type probedFinder struct {
+	base    Finder
+	timeout time.Duration
+
+	mu    sync.Mutex
+	known map[ProcessID]*Fingerprint
+}
+
+// Probed returns a Finder probing the ports of the processes base finds,
+// reusing the fingerprint of a listener seen before, so views refreshing
+// through it only probe new ones
+func Probed(base Finder, timeout time.Duration) Finder {
+	return &probedFinder{base: base, timeout: timeout, known: make(map[ProcessID]*Fingerprint)}
+}
+
+func (f *probedFinder) FindByPort(ctx context.Context, port int) (*Process, error) {
+	proc, err := f.base.FindByPort(ctx, port)
+	if err != nil || proc == nil {
+		return proc, err
+	}
+	f.probe([]*Process{proc})
+	return proc, nil
+}
+
+func (f *probedFinder) FindByPorts(ctx context.Context, ports []int) (map[int]*Process, error) {
+	found, err := f.base.FindByPorts(ctx, ports)
+	if err != nil {
+		return nil, err
+	}
+	processes := make([]*Process, 0, len(found))
+	for _, p := range found {
+		processes = append(processes, p)
+	}
+	f.probe(processes)
+	return found, nil
+}
+
+func (f *probedFinder) ListAll(ctx context.Context) ([]*Process, error) {
+	processes, err := f.base.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	f.probe(processes)
+	return processes, nil
+}
+
+func (f *probedFinder) Invalidate() {
+	Invalidate(f.base)
+}
+
+func (f *probedFinder) Stats() Stats {
+	stats, _ := FinderStats(f.base)
+	return stats
+}
+
+// probe fingerprints the processes, probing the ports of new listeners
+func (f *probedFinder) probe(processes []*Process) {
+	var fresh []*Process
+	f.mu.Lock()
+	for _, p := range processes {
+		if fingerprint, ok := f.known[p.ID]; ok {
+			p.Fingerprint = fingerprint
+		} else {
+			fresh = append(fresh, p)
+		}
+	}
+	f.mu.Unlock()
+
+	ProbeAll(fresh, f.timeout)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, p := range fresh {
+		f.known[p.ID] = p.Fingerprint
+	}
+}
@@ -0,0 +1,209 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OpenFile is a file descriptor held by a process
+type OpenFile struct {
+	// FD is the descriptor number, or a role such as "cwd" or "txt"
+	FD string `json:"fd"`
+	// Path is the file, socket or pipe the descriptor refers to
+	Path string `json:"path"`
+	// Kind is "database" or "log" for files recognized as such
+	Kind string `json:"kind,omitempty"`
+}
+
+// Socket is a network socket held by a process
+type Socket struct {
+	Proto  string `json:"proto"`
+	Local  string `json:"local"`
+	Remote string `json:"remote,omitempty"`
+	// State is the TCP state, e.g. LISTEN or ESTABLISHED
+	State string `json:"state,omitempty"`
+}
+
+// Listening reports whether the socket accepts connections
+func (s Socket) Listening() bool {
+	return s.State == "LISTEN"
+}
+
+// Environ returns the environment variables of the process, sorted, as
+// KEY=value strings
+func (p *Process) Environ() ([]string, error) {
+	if p.PID <= 0 || p.Host != "" {
+		return nil, fmt.Errorf("the environment of %s is not available", p.Name)
+	}
+	env, err := processEnviron(p.PID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(env)
+	return env, nil
+}
+
+// OpenFiles lists the file descriptors the process holds
+func (p *Process) OpenFiles() ([]OpenFile, error) {
+	if p.PID <= 0 || p.Host != "" {
+		return nil, fmt.Errorf("the open files of %s are not available", p.Name)
+	}
+	return processOpenFiles(p.PID)
+}
+
+// Sockets lists the TCP and UDP sockets the process holds, listeners first
+func (p *Process) Sockets() ([]Socket, error) {
+	if p.PID <= 0 || p.Host != "" {
+		return nil, fmt.Errorf("the sockets of %s are not available", p.Name)
+	}
+	sockets, err := processSockets(p.PID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(sockets, func(i, j int) bool {
+		if sockets[i].Listening() != sockets[j].Listening() {
+			return sockets[i].Listening()
+		}
+		return sockets[i].Local < sockets[j].Local
+	})
+	return sockets, nil
+}
+
+// NotableFiles picks the regular files out of a process's descriptors,
+// such as databases and logs, leaving out devices, pipes, sockets and the
+// executable and libraries lsof lists by role. A file opened more than
+// once is listed once.
+func NotableFiles(files []OpenFile) []OpenFile {
+	var notable []OpenFile
+	seen := make(map[string]bool)
+	for _, f := range files {
+		if _, err := strconv.Atoi(f.FD); err != nil {
+			continue
+		}
+		if !filepath.IsAbs(f.Path) || seen[f.Path] || systemPath(f.Path) {
+			continue
+		}
+		seen[f.Path] = true
+		f.Kind = fileKind(f.Path)
+		notable = append(notable, f)
+	}
+	return notable
+}
+
+// systemPath reports whether a path is a device or kernel interface
+func systemPath(path string) bool {
+	for _, prefix := range []string{"/dev/", "/proc/", "/sys/"} {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// fileKind recognizes SQLite databases, with their journals, and logs
+func fileKind(path string) string {
+	name := strings.ToLower(strings.TrimSuffix(path, " (deleted)"))
+	for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	switch filepath.Ext(name) {
+	case ".db", ".sqlite", ".sqlite3":
+		return "database"
+	case ".log":
+		return "log"
+	}
+	if strings.HasPrefix(name, "/var/log/") {
+		return "log"
+	}
+	return ""
+}
+
+// splitNull splits NUL separated strings, dropping empty ones
+func splitNull(data []byte) []string {
+	var values []string
+	for _, value := range strings.Split(string(data), "\x00") {
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// parseLsofFiles parses lsof -F fn output, where each descriptor is an "f"
+// line followed by its "n" name line
+func parseLsofFiles(output string) []OpenFile {
+	var files []OpenFile
+	fd := ""
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+		switch line[0] {
+		case 'f':
+			fd = line[1:]
+		case 'n':
+			files = append(files, OpenFile{FD: fd, Path: line[1:]})
+		}
+	}
+	return files
+}
+
+// lsofOpenFiles lists the descriptors of a process with lsof
+func lsofOpenFiles(ctx context.Context, run Runner, pid int) ([]OpenFile, error) {
+	output, err := run.Output(ctx, "lsof", "-n", "-P", "-p", fmt.Sprint(pid), "-F", "fn")
+	if err != nil {
+		return nil, fmt.Errorf("lsof failed: %w", err)
+	}
+	return parseLsofFiles(string(output)), nil
+}
+
+// parseLsofSockets parses lsof -F PnT output, where each descriptor starts
+// with an "f" line followed by its protocol, its name ("local->remote" when
+// connected) and TCP details such as "TST=LISTEN"
+func parseLsofSockets(output string) []Socket {
+	var sockets []Socket
+	var current *Socket
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+		switch line[0] {
+		case 'f':
+			sockets = append(sockets, Socket{})
+			current = &sockets[len(sockets)-1]
+		case 'P':
+			if current != nil {
+				current.Proto = line[1:]
+			}
+		case 'n':
+			if current != nil {
+				current.Local, current.Remote, _ = strings.Cut(line[1:], "->")
+			}
+		case 'T':
+			if state, ok := strings.CutPrefix(line[1:], "ST="); ok && current != nil {
+				current.State = state
+			}
+		}
+	}
+	return sockets
+}
+
+// lsofSockets lists the internet sockets of a process with lsof
+func lsofSockets(ctx context.Context, run Runner, pid int) ([]Socket, error) {
+	output, err := run.Output(ctx, "lsof", "-n", "-P", "-a", "-p", fmt.Sprint(pid), "-i", "-F", "PnT")
+	if err != nil {
+		// lsof fails without output when the process holds no sockets
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(output) == 0 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("lsof failed: %w", err)
+	}
+	return parseLsofSockets(string(output)), nil
+}
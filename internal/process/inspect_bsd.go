@@ -0,0 +1,33 @@
+//go:build freebsd || openbsd
+
+package process
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// processEnviron reads the kern.proc.env sysctl, which only FreeBSD has
+func processEnviron(pid int) ([]string, error) {
+	if runtime.GOOS != "freebsd" {
+		return nil, fmt.Errorf("reading the environment of a process is not supported on %s", runtime.GOOS)
+	}
+	data, err := unix.SysctlRaw("kern.proc.env", pid)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the environment of PID %d: %w", pid, err)
+	}
+	return splitNull(data), nil
+}
+
+// processOpenFiles lists the descriptors with lsof, from ports on both
+func processOpenFiles(pid int) ([]OpenFile, error) {
+	return lsofOpenFiles(context.Background(), execRunner{}, pid)
+}
+
+// processSockets lists the sockets with lsof
+func processSockets(pid int) ([]Socket, error) {
+	return lsofSockets(context.Background(), execRunner{}, pid)
+}
@@ -0,0 +1,47 @@
+package process
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// processEnviron reads the environment from the kern.procargs2 sysctl,
+// which holds argc, the executable path, the arguments and then the
+// environment, all NUL separated
+func processEnviron(pid int) ([]string, error) {
+	data, err := unix.SysctlRaw("kern.procargs2", pid)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the environment of PID %d: %w", pid, err)
+	}
+	if len(data) < 4 {
+		return nil, fmt.Errorf("cannot read the environment of PID %d", pid)
+	}
+	argc := int(binary.LittleEndian.Uint32(data))
+
+	// The executable path is padded with NULs up to the arguments
+	values := splitNull(data[4:])
+	if len(values) < 1+argc {
+		return nil, nil
+	}
+	var env []string
+	for _, value := range values[1+argc:] {
+		if strings.Contains(value, "=") {
+			env = append(env, value)
+		}
+	}
+	return env, nil
+}
+
+// processOpenFiles lists the descriptors with lsof
+func processOpenFiles(pid int) ([]OpenFile, error) {
+	return lsofOpenFiles(context.Background(), execRunner{}, pid)
+}
+
+// processSockets lists the sockets with lsof
+func processSockets(pid int) ([]Socket, error) {
+	return lsofSockets(context.Background(), execRunner{}, pid)
+}
@@ -0,0 +1,108 @@
+package process
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// tcpStates names the hex states of /proc/net/tcp
+var tcpStates = map[string]string{
+	"01": "ESTABLISHED",
+	"02": "SYN_SENT",
+	"03": "SYN_RECV",
+	"04": "FIN_WAIT1",
+	"05": "FIN_WAIT2",
+	"06": "TIME_WAIT",
+	"07": "CLOSE",
+	"08": "CLOSE_WAIT",
+	"09": "LAST_ACK",
+	"0A": "LISTEN",
+	"0B": "CLOSING",
+}
+
+// processEnviron reads /proc/<pid>/environ
+func processEnviron(pid int) ([]string, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/environ", pid))
+	if err != nil {
+		return nil, err
+	}
+	return splitNull(data), nil
+}
+
+// processOpenFiles resolves the links in /proc/<pid>/fd
+func processOpenFiles(pid int) ([]OpenFile, error) {
+	dir := fmt.Sprintf("/proc/%d/fd", pid)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]OpenFile, 0, len(entries))
+	for _, entry := range entries {
+		target, err := os.Readlink(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		files = append(files, OpenFile{FD: entry.Name(), Path: target})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		a, _ := strconv.Atoi(files[i].FD)
+		b, _ := strconv.Atoi(files[j].FD)
+		return a < b
+	})
+	return files, nil
+}
+
+// processSockets matches the socket inodes in /proc/<pid>/fd against the
+// socket tables of the process's network namespace
+func processSockets(pid int) ([]Socket, error) {
+	files, err := processOpenFiles(pid)
+	if err != nil {
+		return nil, err
+	}
+	inodes := make(map[string]bool)
+	for _, f := range files {
+		if inode, ok := strings.CutPrefix(f.Path, "socket:["); ok {
+			inodes[strings.TrimSuffix(inode, "]")] = true
+		}
+	}
+	if len(inodes) == 0 {
+		return nil, nil
+	}
+
+	var sockets []Socket
+	for _, table := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		content, err := os.ReadFile(fmt.Sprintf("/proc/%d/net/%s", pid, table))
+		if err != nil {
+			continue
+		}
+		proto := strings.ToUpper(strings.TrimSuffix(table, "6"))
+
+		lines := strings.Split(string(content), "\n")
+		for _, line := range lines[1:] {
+			// sl local_address rem_address st ... uid timeout inode
+			fields := strings.Fields(line)
+			if len(fields) < 10 || !inodes[fields[9]] {
+				continue
+			}
+			host, port, ok := parseProcAddress(fields[1])
+			if !ok {
+				continue
+			}
+			s := Socket{Proto: proto, Local: net.JoinHostPort(host, strconv.Itoa(port))}
+			if host, port, ok := parseProcAddress(fields[2]); ok && port != 0 {
+				s.Remote = net.JoinHostPort(host, strconv.Itoa(port))
+			}
+			if proto == "TCP" {
+				s.State = tcpStates[fields[3]]
+			}
+			sockets = append(sockets, s)
+		}
+	}
+	return sockets, nil
+}
@@ -0,0 +1,57 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// processEnviron is unsupported: Windows keeps the environment in the
+// memory of the process itself
+func processEnviron(pid int) ([]string, error) {
+	return nil, fmt.Errorf("reading the environment of a process is not supported on Windows")
+}
+
+// processOpenFiles is unsupported: Windows has no lsof equivalent built in
+func processOpenFiles(pid int) ([]OpenFile, error) {
+	return nil, fmt.Errorf("listing open files is not supported on Windows")
+}
+
+// processSockets picks the sockets of the process out of netstat -ano
+func processSockets(pid int) ([]Socket, error) {
+	output, err := execRunner{}.Output(context.Background(), "netstat", "-ano")
+	if err != nil {
+		return nil, fmt.Errorf("netstat failed: %w", err)
+	}
+	return parseNetstatSockets(string(output), pid), nil
+}
+
+// parseNetstatSockets parses netstat -ano lines such as
+// "TCP  0.0.0.0:3000  0.0.0.0:0  LISTENING  1234" and
+// "UDP  0.0.0.0:5353  *:*  1234", which have no state
+func parseNetstatSockets(output string, pid int) []Socket {
+	var sockets []Socket
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || (fields[0] != "TCP" && fields[0] != "UDP") {
+			continue
+		}
+		if owner, err := strconv.Atoi(fields[len(fields)-1]); err != nil || owner != pid {
+			continue
+		}
+
+		s := Socket{Proto: fields[0], Local: fields[1]}
+		if remote := fields[2]; remote != "*:*" && !strings.HasSuffix(remote, ":0") {
+			s.Remote = remote
+		}
+		if s.Proto == "TCP" && len(fields) >= 5 {
+			s.State = fields[3]
+			if s.State == "LISTENING" {
+				s.State = "LISTEN"
+			}
+		}
+		sockets = append(sockets, s)
+	}
+	return sockets
+}
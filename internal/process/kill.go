@@ -0,0 +1,284 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// KillStrategy terminates a process in one particular way
+type KillStrategy interface {
+	// Name identifies the strategy for the --strategy flag
+	Name() string
+	// Applicable reports whether the strategy can handle the process
+	Applicable(p *Process) bool
+	// Kill terminates the process
+	Kill(p *Process) error
+}
+
+// defaultGrace is how long a process gets to exit before it is force killed
+const defaultGrace = 2 * time.Second
+
+// KillOptions controls how processes are signaled. The zero value sends
+// SIGTERM and escalates to SIGKILL after two seconds.
+type KillOptions struct {
+	// Signal is sent first; SIGTERM when nil
+	Signal os.Signal
+	// Grace is how long to wait before escalating; defaultGrace when zero
+	Grace time.Duration
+	// Force skips the initial signal and kills immediately
+	Force bool
+}
+
+func (o KillOptions) signal() os.Signal {
+	if o.Signal == nil {
+		return syscall.SIGTERM
+	}
+	return o.Signal
+}
+
+func (o KillOptions) grace() time.Duration {
+	if o.Grace <= 0 {
+		return defaultGrace
+	}
+	return o.Grace
+}
+
+// SignalStrategy sends a signal and escalates to SIGKILL after a grace period
+type SignalStrategy struct {
+	Options KillOptions
+}
+
+// ContainerStopStrategy stops the Docker container behind the process
+type ContainerStopStrategy struct{}
+
+// ServiceStopStrategy stops the service manager unit owning the process,
+// so it isn't restarted behind our back
+type ServiceStopStrategy struct{}
+
+// TreeKillStrategy terminates the process and all of its descendants,
+// children first, so a parent can't respawn them
+type TreeKillStrategy struct {
+	Options KillOptions
+}
+
+// Strategies lists the available kill strategies in auto-selection order
+var Strategies = []KillStrategy{
+	WindowsHostStrategy{},
+	ContainerStopStrategy{},
+	ServiceStopStrategy{},
+	TreeKillStrategy{},
+	SignalStrategy{},
+}
+
+// StrategyByName returns the strategy with the given name
+func StrategyByName(name string) (KillStrategy, error) {
+	names := make([]string, 0, len(Strategies))
+	for _, s := range Strategies {
+		if s.Name() == name {
+			return s, nil
+		}
+		names = append(names, s.Name())
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("unknown kill strategy %q (available: auto, %s)", name, strings.Join(names, ", "))
+}
+
+// SelectStrategy picks the strategy best suited to the process.
+// Tree kills are never selected automatically since they reach beyond
+// the listener itself.
+func SelectStrategy(p *Process) KillStrategy {
+	for _, s := range Strategies {
+		if _, isTree := s.(TreeKillStrategy); isTree {
+			continue
+		}
+		if s.Applicable(p) {
+			return s
+		}
+	}
+	return SignalStrategy{}
+}
+
+// WithKillOptions applies signal options to strategies that send signals
+func WithKillOptions(s KillStrategy, opts KillOptions) KillStrategy {
+	switch s := s.(type) {
+	case SignalStrategy:
+		s.Options = opts
+		return s
+	case TreeKillStrategy:
+		s.Options = opts
+		return s
+	case WindowsHostStrategy:
+		s.Options = opts
+		return s
+	}
+	return s
+}
+
+// Kill terminates the process using the automatically selected strategy
+func (p *Process) Kill() error {
+	return p.KillWith(SelectStrategy(p))
+}
+
+// KillWith terminates the process using the given strategy
+func (p *Process) KillWith(s KillStrategy) error {
+	if !s.Applicable(p) {
+		return fmt.Errorf("%s strategy does not apply to %s (PID: %d)", s.Name(), p.Name, p.PID)
+	}
+	return s.Kill(p)
+}
+
+// DescribeKill lists the steps killing the process with the strategy would
+// take, e.g. "SIGTERM to PID 123, then SIGKILL ...", for dry runs
+func DescribeKill(s KillStrategy, p *Process) []string {
+	switch s := s.(type) {
+	case SignalStrategy:
+		return []string{describeTerminate([]int{p.PID}, s.Options)}
+	case TreeKillStrategy:
+		return []string{describeTerminate(treePIDs(p.PID), s.Options)}
+	case ContainerStopStrategy:
+		container := shortID(p.DockerID)
+		if p.ContainerName != "" {
+			container = p.ContainerName
+		}
+		return []string{"stop container " + container}
+	case ServiceStopStrategy:
+		return []string{fmt.Sprintf("stop %s through %s", p.Service, p.ServiceManager())}
+	case WindowsHostStrategy:
+		if s.Options.Force {
+			return []string{fmt.Sprintf("taskkill.exe /F /PID %d", p.PID)}
+		}
+		return []string{fmt.Sprintf("taskkill.exe /PID %d, then taskkill.exe /F if it is still running after %s", p.PID, s.Options.grace())}
+	}
+	return []string{fmt.Sprintf("kill PID %d with the %s strategy", p.PID, s.Name())}
+}
+
+// RespawnWindow is how long KillAndVerify watches a port after a kill
+const RespawnWindow = 3 * time.Second
+
+// respawnPoll is how often KillAndVerify looks the port up
+const respawnPoll = 250 * time.Millisecond
+
+// KillAndVerify kills the process with the strategy, then watches its port
+// for window. It returns the process that bound the port again, such as
+// one a supervisor or nodemon respawned, or nil when the port stayed free.
+func KillAndVerify(ctx context.Context, finder Finder, p *Process, s KillStrategy, window time.Duration) (*Process, error) {
+	if err := p.KillWith(s); err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(window)
+	for time.Now().Before(deadline) && ctx.Err() == nil {
+		time.Sleep(respawnPoll)
+		Invalidate(finder)
+		current, err := finder.FindByPort(ctx, p.Port)
+		if err != nil {
+			continue
+		}
+		// The old process may hold the port for a moment while it exits
+		if current != nil && current.PID != p.PID && !current.SocketOnly {
+			return current, nil
+		}
+	}
+	return nil, nil
+}
+
+func (SignalStrategy) Name() string { return "signal" }
+
+func (SignalStrategy) Applicable(p *Process) bool { return p.PID > 0 && p.Host == "" }
+
+func (s SignalStrategy) Kill(p *Process) error { return terminatePIDs([]int{p.PID}, s.Options) }
+
+func (ContainerStopStrategy) Name() string { return "container" }
+
+func (ContainerStopStrategy) Applicable(p *Process) bool {
+	return p.IsDocker && p.DockerID != "" && p.DockerID != "unknown"
+}
+
+func (ContainerStopStrategy) Kill(p *Process) error { return p.StopContainer() }
+
+// ServiceManager names the service manager running the process's
+// Service, e.g. "systemd", or "" when it has none
+func (p *Process) ServiceManager() string {
+	if p.Service == "" {
+		return ""
+	}
+	switch runtime.GOOS {
+	case "linux":
+		if p.UserService {
+			return "systemd --user"
+		}
+		return "systemd"
+	case "darwin":
+		return "launchd"
+	case "windows":
+		return "the service control manager"
+	}
+	return "the service manager"
+}
+
+func (ServiceStopStrategy) Name() string { return "service" }
+
+func (ServiceStopStrategy) Applicable(p *Process) bool { return p.Service != "" }
+
+func (ServiceStopStrategy) Kill(p *Process) error { return stopService(p) }
+
+func (TreeKillStrategy) Name() string { return "tree" }
+
+func (TreeKillStrategy) Applicable(p *Process) bool { return p.PID > 0 && p.Host == "" }
+
+func (s TreeKillStrategy) Kill(p *Process) error {
+	// The whole tree is signaled before waiting, so it shares one grace
+	// period, and the deepest descendants go first
+	return terminatePIDs(treePIDs(p.PID), s.Options)
+}
+
+// treePIDs returns pid and its descendants, the deepest first
+func treePIDs(pid int) []int {
+	descendants := descendantPIDs(pid)
+	pids := make([]int, 0, len(descendants)+1)
+	for i := len(descendants) - 1; i >= 0; i-- {
+		pids = append(pids, descendants[i])
+	}
+	return append(pids, pid)
+}
+
+// pidList names the processes, e.g. "PID 42" or "PIDs 43, 42"
+func pidList(pids []int) string {
+	if len(pids) == 1 {
+		return fmt.Sprintf("PID %d", pids[0])
+	}
+	names := make([]string, len(pids))
+	for i, pid := range pids {
+		names[i] = strconv.Itoa(pid)
+	}
+	return "PIDs " + strings.Join(names, ", ")
+}
+
+// pidError attributes err to pid when several processes were terminated
+// together
+func pidError(pids []int, pid int, err error) error {
+	if len(pids) == 1 {
+		return err
+	}
+	return fmt.Errorf("PID %d: %w", pid, err)
+}
+
+// descendantPIDs returns all descendants of pid in breadth-first order
+func descendantPIDs(pid int) []int {
+	var result []int
+	queue := childPIDs(pid)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		result = append(result, current)
+		queue = append(queue, childPIDs(current)...)
+	}
+	return result
+}
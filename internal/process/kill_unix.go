@@ -0,0 +1,117 @@
+//go:build !windows
+
+package process
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var signalNames = map[string]syscall.Signal{
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"KILL": syscall.SIGKILL,
+	"TERM": syscall.SIGTERM,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+}
+
+// ParseSignal parses a signal name (TERM, SIGINT) or number (15)
+func ParseSignal(name string) (os.Signal, error) {
+	if n, err := strconv.Atoi(name); err == nil && n > 0 {
+		return syscall.Signal(n), nil
+	}
+
+	key := strings.TrimPrefix(strings.ToUpper(name), "SIG")
+	if sig, ok := signalNames[key]; ok {
+		return sig, nil
+	}
+
+	return nil, fmt.Errorf("unknown signal %q", name)
+}
+
+// describeTerminate says which signals terminatePIDs sends to the
+// processes
+func describeTerminate(pids []int, opts KillOptions) string {
+	if opts.Force || opts.signal() == syscall.SIGKILL {
+		return "SIGKILL to " + pidList(pids)
+	}
+	if len(pids) == 1 {
+		return fmt.Sprintf("%s to PID %d, then SIGKILL if it is still running after %s", signalName(opts.signal()), pids[0], opts.grace())
+	}
+	return fmt.Sprintf("%s to %s, then SIGKILL to those still running after %s", signalName(opts.signal()), pidList(pids), opts.grace())
+}
+
+// signalName returns the name of a signal, e.g. "SIGTERM"
+func signalName(sig os.Signal) string {
+	for name, s := range signalNames {
+		if s == sig {
+			return "SIG" + name
+		}
+	}
+	if s, ok := sig.(syscall.Signal); ok {
+		return fmt.Sprintf("signal %d", int(s))
+	}
+	return sig.String()
+}
+
+// terminatePIDs sends the configured signal to the processes in order,
+// waits for them to shut down within a single grace period, then SIGKILLs
+// those still running
+func terminatePIDs(pids []int, opts KillOptions) error {
+	var errs []error
+	var running []*os.Process
+	for _, pid := range pids {
+		process, err := os.FindProcess(pid)
+		if err != nil {
+			errs = append(errs, pidError(pids, pid, fmt.Errorf("process not found: %w", err)))
+			continue
+		}
+		running = append(running, process)
+	}
+
+	if !opts.Force {
+		sig := opts.signal()
+		signaled := running[:0]
+		for _, process := range running {
+			if err := process.Signal(sig); err != nil {
+				if !errors.Is(err, os.ErrProcessDone) {
+					errs = append(errs, pidError(pids, process.Pid, fmt.Errorf("failed to send %v: %w", sig, err)))
+				}
+				continue
+			}
+			signaled = append(signaled, process)
+		}
+		running = signaled
+
+		// Wait for graceful shutdown
+		deadline := time.Now().Add(opts.grace())
+		for len(running) > 0 && time.Now().Before(deadline) {
+			alive := running[:0]
+			for _, process := range running {
+				if process.Signal(syscall.Signal(0)) == nil {
+					alive = append(alive, process)
+				}
+			}
+			running = alive
+			if len(running) > 0 {
+				time.Sleep(100 * time.Millisecond)
+			}
+		}
+	}
+
+	// Processes still running, force kill
+	for _, process := range running {
+		if err := process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			errs = append(errs, pidError(pids, process.Pid, fmt.Errorf("failed to kill process: %w", err)))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,129 @@
+//go:build windows
+
+package process
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ParseSignal parses a signal name. Windows has no signals, so only the
+// names with a taskkill equivalent are accepted: KILL maps to a forced
+// taskkill and everything else to a graceful close request.
+func ParseSignal(name string) (os.Signal, error) {
+	switch strings.TrimPrefix(strings.ToUpper(name), "SIG") {
+	case "TERM", "15":
+		return syscall.SIGTERM, nil
+	case "INT", "2":
+		return syscall.SIGINT, nil
+	case "HUP", "1":
+		return syscall.SIGHUP, nil
+	case "KILL", "9":
+		return syscall.SIGKILL, nil
+	}
+	return nil, fmt.Errorf("unsupported signal %q on Windows", name)
+}
+
+// describeTerminate says which taskkill calls terminatePIDs makes
+func describeTerminate(pids []int, opts KillOptions) string {
+	if opts.Force || opts.signal() == syscall.SIGKILL {
+		return "taskkill /F " + strings.Join(pidArgs(pids), " ")
+	}
+	if len(pids) == 1 {
+		return fmt.Sprintf("taskkill /PID %d, then taskkill /F if it is still running after %s", pids[0], opts.grace())
+	}
+	return fmt.Sprintf("taskkill %s, then taskkill /F for those still running after %s", strings.Join(pidArgs(pids), " "), opts.grace())
+}
+
+// terminatePIDs asks the processes to close with taskkill, waits for
+// them to shut down within a single grace period, then forces the
+// termination of those still running
+func terminatePIDs(pids []int, opts KillOptions) error {
+	if !opts.Force && opts.signal() != syscall.SIGKILL {
+		// Without /F taskkill posts WM_CLOSE, which console apps may ignore
+		command("taskkill", pidArgs(pids)...).Run()
+
+		deadline := time.Now().Add(opts.grace())
+		for time.Now().Before(deadline) {
+			if len(runningPIDs(pids)) == 0 {
+				return nil
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	running := runningPIDs(pids)
+	if len(running) == 0 {
+		return nil
+	}
+
+	output, err := command("taskkill", append([]string{"/F"}, pidArgs(running)...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to kill process: %s", strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// pidArgs returns the taskkill arguments selecting the processes
+func pidArgs(pids []int) []string {
+	args := make([]string, 0, 2*len(pids))
+	for _, pid := range pids {
+		args = append(args, "/PID", strconv.Itoa(pid))
+	}
+	return args
+}
+
+// runningPIDs returns the processes that still exist, listing them all
+// with a single tasklist
+func runningPIDs(pids []int) []int {
+	if len(pids) == 1 {
+		if pidRunning(pids[0]) {
+			return pids
+		}
+		return nil
+	}
+
+	output, err := command("tasklist", "/FO", "CSV", "/NH").Output()
+	if err != nil {
+		return nil
+	}
+	records, err := csv.NewReader(bytes.NewReader(output)).ReadAll()
+	if err != nil {
+		return nil
+	}
+
+	// Each record reads "name","PID","session","session#","memory"
+	listed := make(map[int]bool, len(records))
+	for _, record := range records {
+		if len(record) < 2 {
+			continue
+		}
+		if pid, err := strconv.Atoi(record[1]); err == nil {
+			listed[pid] = true
+		}
+	}
+
+	var running []int
+	for _, pid := range pids {
+		if listed[pid] {
+			running = append(running, pid)
+		}
+	}
+	return running
+}
+
+// pidRunning reports whether a process with the PID still exists
+func pidRunning(pid int) bool {
+	output, err := command("tasklist", "/FI", fmt.Sprintf("PID eq %d", pid), "/FO", "CSV", "/NH").Output()
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(output), fmt.Sprintf("\"%d\"", pid))
+}
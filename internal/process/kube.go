@@ -0,0 +1,231 @@
+package process
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/doganarif/portfinder/internal/docker"
+)
+
+// kubectlTimeout bounds the kubectl lookups resolving node ports
+const kubectlTimeout = 3 * time.Second
+
+// KubeTarget describes where a Kubernetes forward leads, e.g.
+// "kubectl port-forward -n payments svc/api", or "" for other processes
+func (p *Process) KubeTarget() string {
+	if p.KubeVia == "" {
+		return ""
+	}
+	parts := []string{p.KubeVia}
+	if p.KubeNamespace != "" && p.KubeNamespace != "default" {
+		parts = append(parts, "-n", p.KubeNamespace)
+	}
+	if p.KubeResource != "" {
+		parts = append(parts, p.KubeResource)
+	}
+	return strings.Join(parts, " ")
+}
+
+// resolveKubernetes fills in the Kubernetes targets of kubectl and
+// minikube forwards, and of the node ports kind clusters publish
+func resolveKubernetes(ctx context.Context, run Runner, processes []*Process) {
+	nodePorts := make(map[string][]*Process)
+	for _, p := range processes {
+		if p.nodePort > 0 {
+			nodePorts[p.KubeCluster] = append(nodePorts[p.KubeCluster], p)
+			continue
+		}
+		parseKubeCommand(p)
+	}
+
+	for cluster, procs := range nodePorts {
+		services, err := nodePortServices(ctx, run, cluster)
+		if err != nil {
+			continue
+		}
+		for _, p := range procs {
+			if svc, ok := services[p.nodePort]; ok {
+				p.KubeNamespace, p.KubeResource = svc[0], "svc/"+svc[1]
+			}
+		}
+	}
+}
+
+// parseKubeCommand recognizes the kubectl and minikube commands that
+// forward local ports into a cluster
+func parseKubeCommand(p *Process) {
+	args := strings.Fields(p.Command)
+	if len(args) < 2 {
+		return
+	}
+
+	tool := strings.TrimSuffix(filepath.Base(args[0]), ".exe")
+	namespace, rest := kubeArgs(args[1:])
+	if len(rest) == 0 {
+		return
+	}
+
+	switch {
+	case (tool == "kubectl" || tool == "oc") && rest[0] == "port-forward" && len(rest) > 1:
+		p.KubeVia = tool + " port-forward"
+		p.KubeNamespace = namespace
+		p.KubeResource = rest[1]
+		if !strings.Contains(p.KubeResource, "/") {
+			p.KubeResource = "pod/" + p.KubeResource
+		}
+		if remote := forwardedPort(rest[2:], p.Port); remote > 0 && remote != p.Port {
+			p.KubeResource += ":" + strconv.Itoa(remote)
+		}
+	case (tool == "kubectl" || tool == "oc") && rest[0] == "proxy":
+		p.KubeVia = tool + " proxy"
+		p.KubeResource = "apiserver"
+	case tool == "minikube" && rest[0] == "service" && len(rest) > 1:
+		p.KubeVia = "minikube service"
+		p.KubeNamespace = namespace
+		p.KubeResource = "svc/" + rest[1]
+		p.KubeCluster = "minikube"
+	case tool == "minikube" && rest[0] == "tunnel":
+		p.KubeVia = "minikube tunnel"
+		p.KubeCluster = "minikube"
+	}
+}
+
+// kubeArgs separates the namespace flag from the positional arguments,
+// dropping the other flags that take a value
+func kubeArgs(args []string) (string, []string) {
+	valued := map[string]bool{
+		"-n": true, "--namespace": true, "--context": true, "--address": true,
+		"--kubeconfig": true, "--cluster": true, "--user": true, "-s": true,
+		"--server": true, "--pod-running-timeout": true, "-p": true, "--profile": true,
+	}
+
+	namespace := ""
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			rest = append(rest, arg)
+			continue
+		}
+		name, value, inline := strings.Cut(arg, "=")
+		if !inline && valued[name] && i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+		if name == "-n" || name == "--namespace" {
+			namespace = value
+		}
+	}
+	return namespace, rest
+}
+
+// forwardedPort returns the remote port a port-forward maps local to,
+// given its port arguments ("8080:80", "8080" or ":80")
+func forwardedPort(specs []string, local int) int {
+	for _, spec := range specs {
+		localPart, remotePart, mapped := strings.Cut(spec, ":")
+		if !mapped {
+			remotePart = localPart
+		}
+		remote, err := strconv.Atoi(remotePart)
+		if err != nil {
+			continue
+		}
+		// ":80" forwards from a random local port
+		if localPart == "" || localPart == strconv.Itoa(local) {
+			return remote
+		}
+	}
+	return 0
+}
+
+// resolveClusterNode recognizes the node containers of kind and minikube
+// clusters publishing a port. The API server port is named right away,
+// other ports are node ports resolved by resolveKubernetes.
+func resolveClusterNode(p *Process, container *docker.Container) {
+	var cluster string
+	switch {
+	case strings.HasPrefix(container.Image, "kindest/node"):
+		name := container.Name
+		if i := strings.LastIndex(name, "-control-plane"); i > 0 {
+			name = name[:i]
+		} else if i := strings.LastIndex(name, "-worker"); i > 0 {
+			name = name[:i]
+		}
+		cluster = "kind-" + name
+	case strings.Contains(container.Image, "k8s-minikube/kicbase"):
+		cluster = container.Name
+	default:
+		return
+	}
+
+	private := 0
+	for _, m := range container.Ports {
+		if m.PublicPort == p.Port {
+			private = m.PrivatePort
+			break
+		}
+	}
+	if private == 0 {
+		return
+	}
+
+	switch private {
+	case 22, 2376, 32443:
+		// minikube's SSH, Docker and registry ports
+		return
+	case 6443, 8443:
+		p.KubeCluster = cluster
+		p.KubeVia = cluster
+		p.KubeResource = "apiserver"
+	default:
+		p.KubeCluster = cluster
+		p.KubeVia = cluster + " node port"
+		p.KubeResource = strconv.Itoa(private)
+		p.nodePort = private
+	}
+}
+
+// nodePortServices maps the node ports of a cluster to the namespace and
+// name of the service exposing them
+func nodePortServices(ctx context.Context, run Runner, cluster string) (map[int][2]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, kubectlTimeout)
+	defer cancel()
+
+	out, err := run.Output(ctx, "kubectl", "--context", cluster, "get", "services", "--all-namespaces", "-o", "json")
+	if err != nil {
+		return nil, fmt.Errorf("kubectl get services: %w", err)
+	}
+
+	var list struct {
+		Items []struct {
+			Metadata struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+			Spec struct {
+				Ports []struct {
+					NodePort int `json:"nodePort"`
+				} `json:"ports"`
+			} `json:"spec"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(out, &list); err != nil {
+		return nil, err
+	}
+
+	services := make(map[int][2]string)
+	for _, item := range list.Items {
+		for _, port := range item.Spec.Ports {
+			if port.NodePort > 0 {
+				services[port.NodePort] = [2]string{item.Metadata.Namespace, item.Metadata.Name}
+			}
+		}
+	}
+	return services, nil
+}
@@ -0,0 +1,244 @@
+//go:build darwin && cgo
+
+package process
+
+/*
+#include <stdlib.h>
+#include <string.h>
+#include <arpa/inet.h>
+#include <sys/param.h>
+#include <libproc.h>
+#include <sys/proc_info.h>
+#include <sys/sysctl.h>
+
+// listening_port returns the local port of a TCP socket in LISTEN state,
+// or -1 for any other descriptor, and copies its bound address to addr
+static int listening_port(int pid, int fd, char *addr, int addr_size) {
+	struct socket_fdinfo si;
+	int n = proc_pidfdinfo(pid, fd, PROC_PIDFDSOCKETINFO, &si, PROC_PIDFDSOCKETINFO_SIZE);
+	if (n < PROC_PIDFDSOCKETINFO_SIZE) return -1;
+	if (si.psi.soi_family != AF_INET && si.psi.soi_family != AF_INET6) return -1;
+	if (si.psi.soi_kind != SOCKINFO_TCP) return -1;
+	if (si.psi.soi_proto.pri_tcp.tcpsi_state != TSI_S_LISTEN) return -1;
+
+	struct in_sockinfo *ini = &si.psi.soi_proto.pri_tcp.tcpsi_ini;
+	addr[0] = 0;
+	if (ini->insi_vflag & INI_IPV4) {
+		inet_ntop(AF_INET, &ini->insi_laddr.ina_46.i46a_addr4, addr, addr_size);
+	} else {
+		inet_ntop(AF_INET6, &ini->insi_laddr.ina_6, addr, addr_size);
+	}
+	return ntohs((unsigned short)ini->insi_lport);
+}
+
+// bsd_info fills the name, start time, parent and owner of a process
+static int bsd_info(int pid, char *name, int name_size, long *start_sec, long *start_usec, int *ppid, unsigned int *uid) {
+	struct proc_bsdinfo info;
+	int n = proc_pidinfo(pid, PROC_PIDTBSDINFO, 0, &info, PROC_PIDTBSDINFO_SIZE);
+	if (n < PROC_PIDTBSDINFO_SIZE) return -1;
+	const char *src = info.pbi_name[0] ? info.pbi_name : info.pbi_comm;
+	strncpy(name, src, name_size - 1);
+	name[name_size - 1] = 0;
+	*start_sec = (long)info.pbi_start_tvsec;
+	*start_usec = (long)info.pbi_start_tvusec;
+	*ppid = (int)info.pbi_ppid;
+	*uid = (unsigned int)info.pbi_uid;
+	return 0;
+}
+
+// cwd_path copies the current working directory of a process
+static int cwd_path(int pid, char *buf, int size) {
+	struct proc_vnodepathinfo info;
+	int n = proc_pidinfo(pid, PROC_PIDVNODEPATHINFO, 0, &info, PROC_PIDVNODEPATHINFO_SIZE);
+	if (n < PROC_PIDVNODEPATHINFO_SIZE) return -1;
+	strncpy(buf, info.pvi_cdir.vip_path, size - 1);
+	buf[size - 1] = 0;
+	return 0;
+}
+
+// proc_args reads the raw KERN_PROCARGS2 buffer of a process
+static int proc_args(int pid, char *buf, int size) {
+	int mib[3] = {CTL_KERN, KERN_PROCARGS2, pid};
+	size_t len = (size_t)size;
+	if (sysctl(mib, 3, buf, &len, NULL, 0) != 0) return -1;
+	return (int)len;
+}
+*/
+import "C"
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// libprocAvailable reports whether the libproc backend is compiled in
+const libprocAvailable = true
+
+// argsBufferSize bounds the KERN_PROCARGS2 read; the kernel's ARG_MAX is
+// larger, but command lines beyond this are truncated for display anyway
+const argsBufferSize = 64 * 1024
+
+// nativeListAll enumerates listening TCP sockets through libproc, avoiding
+// an lsof run that can take seconds on machines with many open files
+func nativeListAll() ([]*Process, error) {
+	count := C.proc_listallpids(nil, 0)
+	if count <= 0 {
+		return nil, fmt.Errorf("proc_listallpids failed")
+	}
+
+	// Leave headroom for processes spawned between the two calls
+	pids := make([]C.int, int(count)+64)
+	count = C.proc_listallpids(unsafe.Pointer(&pids[0]), C.int(len(pids))*C.int(unsafe.Sizeof(pids[0])))
+	if count <= 0 {
+		return nil, fmt.Errorf("proc_listallpids failed")
+	}
+
+	var processes []*Process
+	for _, pid := range pids[:count] {
+		if pid <= 0 {
+			continue
+		}
+		for _, l := range listeningPorts(int(pid)) {
+			proc := &Process{PID: int(pid), Port: l.port, Address: l.address}
+			enrichNative(proc)
+			processes = append(processes, proc)
+		}
+	}
+
+	return processes, nil
+}
+
+// listener is a listening socket found through libproc
+type listener struct {
+	port    int
+	address string
+}
+
+// listeningPorts returns the distinct TCP ports a process listens on
+func listeningPorts(pid int) []listener {
+	size := C.proc_pidinfo(C.int(pid), C.PROC_PIDLISTFDS, 0, nil, 0)
+	if size <= 0 {
+		return nil
+	}
+
+	fdSize := C.int(unsafe.Sizeof(C.struct_proc_fdinfo{}))
+	fds := make([]C.struct_proc_fdinfo, int(size/fdSize))
+	if len(fds) == 0 {
+		return nil
+	}
+
+	size = C.proc_pidinfo(C.int(pid), C.PROC_PIDLISTFDS, 0, unsafe.Pointer(&fds[0]), C.int(len(fds))*fdSize)
+	if size <= 0 {
+		return nil
+	}
+
+	var listeners []listener
+	seen := make(map[int]bool)
+	var addr [C.INET6_ADDRSTRLEN]C.char
+	for _, fd := range fds[:int(size/fdSize)] {
+		if fd.proc_fdtype != C.PROX_FDTYPE_SOCKET {
+			continue
+		}
+		port := int(C.listening_port(C.int(pid), fd.proc_fd, &addr[0], C.int(len(addr))))
+		if port > 0 && !seen[port] {
+			seen[port] = true
+			listeners = append(listeners, listener{port: port, address: wildcardAddress(C.GoString(&addr[0]))})
+		}
+	}
+
+	return listeners
+}
+
+// wildcardAddress reports unspecified addresses as "*", like lsof does
+func wildcardAddress(addr string) string {
+	if addr == "" || addr == "0.0.0.0" || addr == "::" {
+		return "*"
+	}
+	return addr
+}
+
+// enrichNative fills process details through libproc and sysctl
+func enrichNative(proc *Process) {
+	var name [256]C.char
+	var startSec, startUsec C.long
+	var ppid C.int
+	var uid C.uint
+	if C.bsd_info(C.int(proc.PID), &name[0], C.int(len(name)), &startSec, &startUsec, &ppid, &uid) == 0 {
+		proc.Name = C.GoString(&name[0])
+		proc.StartTime = time.Unix(int64(startSec), int64(startUsec)*int64(time.Microsecond))
+		proc.PPID = int(ppid)
+		proc.User = userName(strconv.Itoa(int(uid)))
+	}
+
+	var path [C.PROC_PIDPATHINFO_MAXSIZE]C.char
+	if C.proc_pidpath(C.int(proc.PID), unsafe.Pointer(&path[0]), C.uint32_t(len(path))) > 0 {
+		proc.ExePath = C.GoString(&path[0])
+	}
+
+	var cwd [C.MAXPATHLEN]C.char
+	if C.cwd_path(C.int(proc.PID), &cwd[0], C.int(len(cwd))) == 0 {
+		proc.WorkDir = C.GoString(&cwd[0])
+		proc.ProjectPath = detectProject(proc.PID, proc.WorkDir)
+	}
+
+	buf := make([]byte, argsBufferSize)
+	if n := C.proc_args(C.int(proc.PID), (*C.char)(unsafe.Pointer(&buf[0])), C.int(len(buf))); n > 0 {
+		proc.Args = parseProcArgs(buf[:n])
+		proc.Command = strings.Join(proc.Args, " ")
+	}
+
+	// Simple Docker detection on macOS
+	if strings.Contains(proc.Command, "docker") || strings.Contains(proc.Name, "com.docker") {
+		proc.IsDocker = true
+	}
+}
+
+// nativeParent returns the parent PID and name of a process through libproc
+func nativeParent(pid int) (int, string, error) {
+	var name [256]C.char
+	var startSec, startUsec C.long
+	var ppid C.int
+	var uid C.uint
+	if C.bsd_info(C.int(pid), &name[0], C.int(len(name)), &startSec, &startUsec, &ppid, &uid) != 0 {
+		return 0, "", fmt.Errorf("proc_pidinfo failed for PID %d", pid)
+	}
+	return int(ppid), C.GoString(&name[0]), nil
+}
+
+// parseProcArgs extracts the argument vector from a KERN_PROCARGS2 buffer:
+// argc, the exec path, NUL padding, then argc NUL-terminated arguments
+func parseProcArgs(buf []byte) []string {
+	if len(buf) < 4 {
+		return nil
+	}
+	argc := int(binary.LittleEndian.Uint32(buf[:4]))
+	rest := buf[4:]
+
+	// Skip the exec path and its padding
+	end := bytes.IndexByte(rest, 0)
+	if end == -1 {
+		return nil
+	}
+	rest = rest[end:]
+	for len(rest) > 0 && rest[0] == 0 {
+		rest = rest[1:]
+	}
+
+	var args []string
+	for len(args) < argc && len(rest) > 0 {
+		end := bytes.IndexByte(rest, 0)
+		if end == -1 {
+			args = append(args, string(rest))
+			break
+		}
+		args = append(args, string(rest[:end]))
+		rest = rest[end+1:]
+	}
+
+	return args
+}
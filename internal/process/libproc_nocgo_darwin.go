@@ -0,0 +1,18 @@
+//go:build darwin && !cgo
+
+package process
+
+import "errors"
+
+// libprocAvailable reports whether the libproc backend is compiled in
+const libprocAvailable = false
+
+// nativeListAll needs cgo to call libproc; without it the lsof backend is used
+func nativeListAll() ([]*Process, error) {
+	return nil, errors.New("libproc backend requires cgo")
+}
+
+// nativeParent needs cgo as well; lookupParent falls back to ps
+func nativeParent(pid int) (int, string, error) {
+	return 0, "", errors.New("libproc backend requires cgo")
+}
@@ -0,0 +1,67 @@
+package process
+
+import (
+	"log/slog"
+	"os/exec"
+	"time"
+)
+
+// outputLogLimit bounds how much of a command's output debug logs show
+const outputLogLimit = 4 << 10
+
+// toolCmd is an external command prepared by command. Running it logs the
+// command line, how long it took and how it ended, and at debug level what
+// it printed.
+type toolCmd struct {
+	*exec.Cmd
+}
+
+func (c *toolCmd) Output() ([]byte, error) {
+	start := time.Now()
+	output, err := c.Cmd.Output()
+	c.log(start, output, err)
+	return output, err
+}
+
+func (c *toolCmd) CombinedOutput() ([]byte, error) {
+	start := time.Now()
+	output, err := c.Cmd.CombinedOutput()
+	c.log(start, output, err)
+	return output, err
+}
+
+func (c *toolCmd) Run() error {
+	start := time.Now()
+	err := c.Cmd.Run()
+	c.log(start, nil, err)
+	return err
+}
+
+func (c *toolCmd) log(start time.Time, output []byte, err error) {
+	took := time.Since(start).Round(time.Microsecond)
+	if err != nil {
+		attrs := []any{"cmd", c.String(), "took", took, "err", err}
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			attrs = append(attrs, "stderr", truncateLog(exitErr.Stderr))
+		}
+		slog.Info("command failed", attrs...)
+		return
+	}
+	slog.Info("ran command", "cmd", c.String(), "took", took, "bytes", len(output))
+	if len(output) > 0 {
+		slog.Debug("command output", "cmd", c.Path, "output", truncateLog(output))
+	}
+}
+
+// logUnparsed logs a line of a tool's output that looked like a listener
+// but couldn't be read, the usual reason for a port appearing free
+func logUnparsed(tool, line, reason string) {
+	slog.Info("skipped unparsable line", "tool", tool, "reason", reason, "line", line)
+}
+
+func truncateLog(output []byte) string {
+	if len(output) > outputLogLimit {
+		return string(output[:outputLogLimit]) + "…"
+	}
+	return string(output)
+}
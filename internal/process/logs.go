@@ -0,0 +1,226 @@
+package process
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/doganarif/portfinder/internal/docker"
+)
+
+// LogSource is where the output of a process can be read from: its
+// systemd unit's journal, its container's logs or a file its stdout or
+// stderr is redirected to
+type LogSource struct {
+	// Name describes the source, e.g. "journalctl -u nginx" or a path
+	Name string
+	// One of path, container and unit is set
+	path      string
+	container string
+	runtime   string
+	unit      string
+	userUnit  bool
+}
+
+const (
+	// logPollInterval is how often a followed file is checked for new lines
+	logPollInterval = 500 * time.Millisecond
+	// logTailBytes bounds how far back the last lines of a file are read
+	logTailBytes = 64 << 10
+)
+
+// Logs finds where the output of the process goes. It fails when stdout
+// and stderr go to a terminal or pipe, whose output can't be read back.
+func (p *Process) Logs() (*LogSource, error) {
+	if p.Host != "" {
+		return nil, errors.New("the logs of processes on a remote host are not available")
+	}
+	if p.IsDocker && p.DockerID != "" && p.DockerID != "unknown" {
+		name := "docker logs "
+		switch p.ContainerRuntime {
+		case RuntimePodman:
+			name = "podman logs "
+		case RuntimeContainerd:
+			name = "nerdctl logs "
+		}
+		return &LogSource{Name: name + shortID(p.DockerID), container: p.DockerID, runtime: p.ContainerRuntime}, nil
+	}
+	if p.Service != "" && runtime.GOOS == "linux" {
+		return &LogSource{Name: "journalctl -u " + p.Service, unit: p.Service, userUnit: p.UserService}, nil
+	}
+	if p.PID <= 0 {
+		return nil, fmt.Errorf("the logs of %s are not available", p.Name)
+	}
+
+	files, err := p.OpenFiles()
+	if err != nil {
+		return nil, err
+	}
+	// stdout is preferred, stderr is what servers often log to
+	for _, want := range []string{"1", "2"} {
+		for _, f := range files {
+			if strings.TrimRight(f.FD, "rwu") != want {
+				continue
+			}
+			if info, err := os.Stat(f.Path); err == nil && info.Mode().IsRegular() {
+				return &LogSource{Name: f.Path, path: f.Path}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("the output of %s goes to a terminal, pipe or /dev/null, not to a file pf can read", p.Name)
+}
+
+// Follow sends the last lines of the log, then new ones as they are
+// written, until ctx is done or the log ends. The channel is closed then.
+func (s *LogSource) Follow(ctx context.Context, lines int) (<-chan string, error) {
+	out := make(chan string, 256)
+	switch {
+	case s.path != "":
+		f, err := os.Open(s.path)
+		if err != nil {
+			return nil, err
+		}
+		go func() {
+			defer close(out)
+			defer f.Close()
+			followFile(ctx, f, lines, out)
+		}()
+
+	case s.container != "" && s.runtime != RuntimeContainerd:
+		client, err := docker.NewClientFor(s.runtime)
+		if err != nil {
+			return nil, err
+		}
+		r, err := client.FollowLogs(ctx, s.container, lines)
+		if err != nil {
+			return nil, err
+		}
+		go sendLines(ctx, r, "docker", out)
+
+	default:
+		name, args := s.command(lines)
+		r, w := io.Pipe()
+		cmd := commandContext(ctx, name, args...)
+		cmd.Stdout, cmd.Stderr = w, w
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf("%s failed: %w", name, err)
+		}
+		go func() {
+			w.CloseWithError(cmd.Wait())
+		}()
+		go sendLines(ctx, r, name, out)
+	}
+	return out, nil
+}
+
+// command returns the tool printing the last lines of the log and
+// following it: nerdctl for containerd, which has no API pf speaks, else
+// journalctl
+func (s *LogSource) command(lines int) (string, []string) {
+	if s.container != "" {
+		return "nerdctl", []string{"logs", "--follow", "--tail", strconv.Itoa(lines), s.container}
+	}
+	args := []string{"--follow", "--lines", strconv.Itoa(lines), "--unit", s.unit}
+	if s.userUnit {
+		args = append(args, "--user")
+	}
+	return "journalctl", args
+}
+
+// sendLines sends the lines read from r, then why the stream ended unless
+// ctx was canceled, and closes out
+func sendLines(ctx context.Context, r io.ReadCloser, name string, out chan<- string) {
+	defer close(out)
+	defer r.Close()
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		select {
+		case out <- scanner.Text():
+		case <-ctx.Done():
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		select {
+		case out <- fmt.Sprintf("%s ended: %v", name, err):
+		case <-ctx.Done():
+		}
+	}
+}
+
+// followFile sends the last lines of a file, then polls it for lines
+// appended, starting over when it is truncated, e.g. by log rotation
+func followFile(ctx context.Context, f *os.File, lines int, out chan<- string) {
+	info, err := f.Stat()
+	if err != nil {
+		return
+	}
+	offset := max(info.Size()-logTailBytes, 0)
+	tail := make([]byte, info.Size()-offset)
+	if _, err := f.ReadAt(tail, offset); err != nil && err != io.EOF {
+		return
+	}
+	if offset > 0 {
+		// Drop the line cut in half by the offset
+		if i := bytes.IndexByte(tail, '\n'); i >= 0 {
+			tail = tail[i+1:]
+		}
+	}
+	last := strings.Split(strings.TrimSuffix(string(tail), "\n"), "\n")
+	if len(tail) == 0 {
+		last = nil
+	}
+	for _, line := range last[max(len(last)-lines, 0):] {
+		select {
+		case out <- line:
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	pos := info.Size()
+	var partial string
+	ticker := time.NewTicker(logPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		info, err := f.Stat()
+		if err != nil {
+			return
+		}
+		if info.Size() < pos {
+			pos, partial = 0, ""
+		}
+		if info.Size() == pos {
+			continue
+		}
+		chunk := make([]byte, info.Size()-pos)
+		n, err := f.ReadAt(chunk, pos)
+		if err != nil && err != io.EOF {
+			return
+		}
+		pos += int64(n)
+		text := partial + string(chunk[:n])
+		complete := strings.Split(text, "\n")
+		partial = complete[len(complete)-1]
+		for _, line := range complete[:len(complete)-1] {
+			select {
+			case out <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/doganarif/portfinder/internal/docker"
+)
+
+// Pause freezes a process without killing it, so it keeps its port and
+// memory but stops using CPU and answering until Resume. A container is
+// paused as a whole through its runtime.
+func (p *Process) Pause() error {
+	if err := p.checkSuspendable(); err != nil {
+		return err
+	}
+	if p.pausesContainer() && p.ContainerRuntime == RuntimeContainerd {
+		return nerdctl("pause", p.DockerID)
+	}
+	if p.pausesContainer() {
+		client, err := docker.NewClientFor(p.ContainerRuntime)
+		if err != nil {
+			return err
+		}
+		return client.PauseContainer(p.DockerID)
+	}
+	return suspendPID(p.PID)
+}
+
+// Resume lets a paused process run again
+func (p *Process) Resume() error {
+	if err := p.checkSuspendable(); err != nil {
+		return err
+	}
+	if p.pausesContainer() && p.ContainerRuntime == RuntimeContainerd {
+		return nerdctl("unpause", p.DockerID)
+	}
+	if p.pausesContainer() {
+		client, err := docker.NewClientFor(p.ContainerRuntime)
+		if err != nil {
+			return err
+		}
+		return client.UnpauseContainer(p.DockerID)
+	}
+	return resumePID(p.PID)
+}
+
+// checkSuspendable refuses processes that can't be paused from here
+func (p *Process) checkSuspendable() error {
+	if p.Host != "" {
+		return errors.New("processes on a remote host can't be paused")
+	}
+	if p.PID <= 0 && !p.pausesContainer() {
+		return fmt.Errorf("the process on port %d is unknown", p.Port)
+	}
+	return nil
+}
+
+// pausesContainer reports whether pausing goes through Docker, since
+// freezing the proxy forwarding the port would leave the container running
+func (p *Process) pausesContainer() bool {
+	return p.IsDocker && p.DockerID != "" && p.DockerID != "unknown"
+}
@@ -0,0 +1,24 @@
+//go:build !windows
+
+package process
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// suspendPID stops the process with SIGSTOP, which it can't catch or ignore
+func suspendPID(pid int) error {
+	if err := syscall.Kill(pid, syscall.SIGSTOP); err != nil {
+		return fmt.Errorf("failed to send SIGSTOP: %w", err)
+	}
+	return nil
+}
+
+// resumePID continues a stopped process with SIGCONT
+func resumePID(pid int) error {
+	if err := syscall.Kill(pid, syscall.SIGCONT); err != nil {
+		return fmt.Errorf("failed to send SIGCONT: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+//go:build windows
+
+package process
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// NtSuspendProcess and NtResumeProcess freeze and thaw every thread of a
+// process, as Resource Monitor and Process Explorer do
+var (
+	ntdll                = windows.NewLazySystemDLL("ntdll.dll")
+	procNtSuspendProcess = ntdll.NewProc("NtSuspendProcess")
+	procNtResumeProcess  = ntdll.NewProc("NtResumeProcess")
+)
+
+// suspendPID suspends every thread of the process
+func suspendPID(pid int) error {
+	return callSuspend(procNtSuspendProcess, pid, "suspend")
+}
+
+// resumePID resumes the threads of a suspended process
+func resumePID(pid int) error {
+	return callSuspend(procNtResumeProcess, pid, "resume")
+}
+
+func callSuspend(proc *windows.LazyProc, pid int, action string) error {
+	handle, err := windows.OpenProcess(windows.PROCESS_SUSPEND_RESUME, false, uint32(pid))
+	if err != nil {
+		return fmt.Errorf("failed to open process: %w", err)
+	}
+	defer windows.CloseHandle(handle)
+
+	if status, _, _ := proc.Call(uintptr(handle)); status != 0 {
+		return fmt.Errorf("failed to %s process: %w", action, windows.NTStatus(status))
+	}
+	return nil
+}
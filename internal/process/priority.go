@@ -0,0 +1,46 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Nice values range from MinNice, the highest priority, to MaxNice, the
+// lowest, with 0 as the default
+const (
+	MinNice = -20
+	MaxNice = 19
+)
+
+// Priority returns the nice value of the process
+func (p *Process) Priority() (int, error) {
+	if err := p.checkRenice(); err != nil {
+		return 0, err
+	}
+	return getPriority(p.PID)
+}
+
+// SetPriority changes the nice value of the process, between MinNice and
+// MaxNice. Raising the priority above the default usually needs root.
+func (p *Process) SetPriority(nice int) error {
+	if nice < MinNice || nice > MaxNice {
+		return fmt.Errorf("priority %d is outside %d to %d", nice, MinNice, MaxNice)
+	}
+	if err := p.checkRenice(); err != nil {
+		return err
+	}
+	return setPriority(p.PID, nice)
+}
+
+// checkRenice refuses processes whose priority can't be changed from here
+func (p *Process) checkRenice() error {
+	switch {
+	case p.Host != "":
+		return errors.New("processes on a remote host can't be reniced")
+	case p.IsDocker:
+		return errors.New("the port is published by a container, renice the processes inside it instead")
+	case p.PID <= 0:
+		return fmt.Errorf("the process on port %d is unknown", p.Port)
+	}
+	return nil
+}
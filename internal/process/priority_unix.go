@@ -0,0 +1,36 @@
+//go:build !windows
+
+package process
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// getPriority reads the nice value. Linux's getpriority returns 20 minus
+// the nice value, so it never looks like an error.
+func getPriority(pid int) (int, error) {
+	prio, err := unix.Getpriority(unix.PRIO_PROCESS, pid)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read priority: %w", err)
+	}
+	if runtime.GOOS == "linux" {
+		return 20 - prio, nil
+	}
+	return prio, nil
+}
+
+// setPriority sets the nice value, as renice does
+func setPriority(pid, nice int) error {
+	err := unix.Setpriority(unix.PRIO_PROCESS, pid, nice)
+	if errors.Is(err, unix.EACCES) || errors.Is(err, unix.EPERM) {
+		return fmt.Errorf("failed to set priority: %w (raising a priority or changing another user's process needs root, try with sudo)", err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set priority: %w", err)
+	}
+	return nil
+}
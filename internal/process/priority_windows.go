@@ -0,0 +1,67 @@
+//go:build windows
+
+package process
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// priorityClasses map nice values to Windows priority classes, from the
+// highest priority down. A nice value gets the first class whose bound it
+// doesn't exceed; realtime is never set.
+var priorityClasses = []struct {
+	nice  int
+	class uint32
+}{
+	{-11, windows.HIGH_PRIORITY_CLASS},
+	{-1, windows.ABOVE_NORMAL_PRIORITY_CLASS},
+	{0, windows.NORMAL_PRIORITY_CLASS},
+	{10, windows.BELOW_NORMAL_PRIORITY_CLASS},
+	{MaxNice, windows.IDLE_PRIORITY_CLASS},
+}
+
+// getPriority reads the priority class as the nice value it is set from
+func getPriority(pid int) (int, error) {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return 0, fmt.Errorf("failed to open process: %w", err)
+	}
+	defer windows.CloseHandle(handle)
+
+	class, err := windows.GetPriorityClass(handle)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read priority: %w", err)
+	}
+	if class == windows.REALTIME_PRIORITY_CLASS {
+		return MinNice, nil
+	}
+	for _, c := range priorityClasses {
+		if c.class == class {
+			return c.nice, nil
+		}
+	}
+	return 0, nil
+}
+
+// setPriority sets the priority class the nice value maps to
+func setPriority(pid, nice int) error {
+	handle, err := windows.OpenProcess(windows.PROCESS_SET_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return fmt.Errorf("failed to open process: %w", err)
+	}
+	defer windows.CloseHandle(handle)
+
+	class := uint32(windows.IDLE_PRIORITY_CLASS)
+	for _, c := range priorityClasses {
+		if nice <= c.nice {
+			class = c.class
+			break
+		}
+	}
+	if err := windows.SetPriorityClass(handle, class); err != nil {
+		return fmt.Errorf("failed to set priority: %w", err)
+	}
+	return nil
+}
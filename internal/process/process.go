@@ -1,62 +1,311 @@
 package process
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
-	"syscall"
 	"time"
 )
 
 // Process represents a process using a network port
 type Process struct {
-	PID         int
-	Name        string
-	Port        int
-	Command     string
-	ProjectPath string
-	StartTime   time.Time
-	IsDocker    bool
-	DockerID    string
+	ID      ProcessID `json:"id"`
+	PID     int       `json:"pid"`
+	Name    string    `json:"name"`
+	Port    int       `json:"port"`
+	Address string    `json:"address,omitempty"`
+	// Addresses lists every address the process listens on the port on,
+	// when it is more than one, such as 0.0.0.0 and :: for a dual-stack
+	// server. Address is then the widest of them.
+	Addresses []string `json:"addresses,omitempty"`
+	Command   string   `json:"command,omitempty"`
+	// Args is the argument vector Command joins, when the platform tells
+	// the arguments apart, so the process can be started again as it was
+	Args        []string `json:"args,omitempty"`
+	ExePath     string   `json:"exe_path,omitempty"`
+	WorkDir     string   `json:"cwd,omitempty"`
+	ProjectPath string   `json:"project,omitempty"`
+	// ProjectName and ProjectType are read from the project's manifest,
+	// e.g. "myapp" and "Node.js"
+	ProjectName string    `json:"project_name,omitempty"`
+	ProjectType string    `json:"project_type,omitempty"`
+	StartTime   time.Time `json:"start_time"`
+	PPID        int       `json:"ppid,omitempty"`
+	User        string    `json:"user,omitempty"`
+	// Host is "windows" for Windows processes listed from WSL, else empty
+	Host string `json:"host,omitempty"`
+	// IsDocker and DockerID are set for containers of every runtime, not
+	// only Docker's
+	IsDocker bool   `json:"is_docker"`
+	DockerID string `json:"docker_id,omitempty"`
+
+	// Container metadata resolved through the Docker or Podman API, or
+	// nerdctl for containerd
+	ContainerName  string `json:"container_name,omitempty"`
+	ContainerImage string `json:"container_image,omitempty"`
+	ComposeProject string `json:"compose_project,omitempty"`
+	// ContainerRuntime is RuntimeDocker, RuntimePodman or RuntimeContainerd
+	ContainerRuntime string `json:"container_runtime,omitempty"`
+	// ContainerIP and ContainerPort are where a published port leads
+	// inside the container
+	ContainerIP   string `json:"container_ip,omitempty"`
+	ContainerPort int    `json:"container_port,omitempty"`
+	// ForwardedBy names the VM publishing the container's port, e.g.
+	// "Docker Desktop", when the process holding it is the VM's port
+	// forwarder rather than the container
+	ForwardedBy string `json:"forwarded_by,omitempty"`
+
+	// Kubernetes target of a kubectl port-forward or a local cluster's
+	// port proxy: KubeVia is the mechanism (e.g. "kubectl port-forward"),
+	// KubeResource what it leads to (e.g. "svc/api")
+	KubeVia       string `json:"kube_via,omitempty"`
+	KubeResource  string `json:"kube_resource,omitempty"`
+	KubeNamespace string `json:"kube_namespace,omitempty"`
+	KubeCluster   string `json:"kube_cluster,omitempty"`
+
+	// nodePort is the container port a kind node publishes the port from
+	nodePort int
+
+	// Service is the service manager unit owning the process, if any
+	Service     string `json:"service,omitempty"`
+	UserService bool   `json:"user_service,omitempty"`
+
+	// CPUPercent is filled by SampleCPU or from SampleUsage. It is relative
+	// to CPULimit (in cores) when the process runs under a cgroup quota,
+	// else to the host.
+	CPUPercent float64 `json:"cpu_percent,omitempty"`
+	CPULimit   float64 `json:"cpu_limit,omitempty"`
+	// RSS is the resident memory in bytes, filled by SampleCPU or from
+	// SampleUsage
+	RSS uint64 `json:"rss,omitempty"`
+
+	// Tags holds the classes assigned by the classification rules
+	Tags []string `json:"tags,omitempty"`
+
+	// SocketOnly marks a listener found without its owning process, when
+	// neither /proc nor the socket tools reveal it (restricted containers,
+	// sandboxes, other users' processes without root). Only the port and
+	// address are known.
+	SocketOnly bool `json:"socket_only,omitempty"`
+
+	// Connections counts the established connections to the port, and
+	// Peers lists their remote addresses
+	Connections int      `json:"connections"`
+	Peers       []string `json:"peers,omitempty"`
+
+	// Lineage holds the ancestors of the process, nearest first. It is
+	// only filled by LoadLineage.
+	Lineage []Ancestor `json:"lineage,omitempty"`
+
+	// Fingerprint identifies the server answering on the port. It is only
+	// filled by Probe.
+	Fingerprint *Fingerprint `json:"fingerprint,omitempty"`
+
+	// Firewall is whether the host firewall lets connections to the port
+	// in: allowed, blocked or inactive. It is only filled when asked for,
+	// as with list --firewall.
+	Firewall string `json:"firewall,omitempty"`
+}
+
+// ProcessID identifies one listener of one process instance. Unlike the
+// PID it stays stable across refreshes without colliding when the PID is
+// reused, and it tells apart the ports of a process listening on several.
+type ProcessID string
+
+// NewProcessID derives the ID from the PID, start time and port
+func NewProcessID(pid int, start time.Time, port int) ProcessID {
+	// Start times are only reliable to the second on every platform
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%d:%d", pid, start.Unix(), port)))
+	return ProcessID(hex.EncodeToString(sum[:8]))
 }
 
 // Finder interface for finding processes
 type Finder interface {
-	FindByPort(port int) (*Process, error)
-	ListAll() ([]*Process, error)
+	FindByPort(ctx context.Context, port int) (*Process, error)
+	// FindByPorts looks several ports up in a single scan, mapping the
+	// ports in use to their process. Free ports are left out.
+	FindByPorts(ctx context.Context, ports []int) (map[int]*Process, error)
+	ListAll(ctx context.Context) ([]*Process, error)
 }
 
-// NewFinder creates a platform-specific process finder
-func NewFinder() Finder {
-	return &platformFinder{}
+// ByPorts maps each of the ports to the first of the processes listening
+// on it, as FindByPort picks one
+func ByPorts(processes []*Process, ports []int) map[int]*Process {
+	wanted := make(map[int]bool, len(ports))
+	for _, port := range ports {
+		wanted[port] = true
+	}
+	found := make(map[int]*Process)
+	for _, p := range processes {
+		if _, seen := found[p.Port]; wanted[p.Port] && !seen {
+			found[p.Port] = p
+		}
+	}
+	return found
+}
+
+// IsPublic reports whether the process listens beyond localhost, on all
+// interfaces or on a specific external address
+func (p *Process) IsPublic() bool {
+	switch p.Address {
+	case "":
+		return false
+	case "*":
+		return true
+	case "localhost":
+		return false
+	}
+	ip := net.ParseIP(p.Address)
+	return ip == nil || !ip.IsLoopback()
+}
+
+// DualStack reports whether the process listens on the port on both IPv4
+// and IPv6 addresses
+func (p *Process) DualStack() bool {
+	var v4, v6 bool
+	for _, address := range p.Addresses {
+		if ip := net.ParseIP(address); ip != nil && ip.To4() != nil {
+			v4 = true
+		} else if ip != nil {
+			v6 = true
+		}
+	}
+	return v4 && v6
+}
+
+// dedupListeners merges the sockets a process listens on one port with,
+// such as the IPv4 and IPv6 ones of a dual-stack server, into a single
+// listener keeping every address. Listeners of unknown processes are kept
+// apart.
+func dedupListeners(processes []*Process) []*Process {
+	type listener struct{ pid, port int }
+	first := make(map[listener]*Process)
+	kept := processes[:0]
+	for _, p := range processes {
+		key := listener{p.PID, p.Port}
+		prev, seen := first[key]
+		if p.PID <= 0 || p.SocketOnly || !seen {
+			first[key] = p
+			kept = append(kept, p)
+			continue
+		}
+		if p.Address == prev.Address || slices.Contains(prev.Addresses, p.Address) {
+			continue
+		}
+		if prev.Addresses == nil {
+			prev.Addresses = []string{prev.Address}
+		}
+		prev.Addresses = append(prev.Addresses, p.Address)
+		if addressScope(p.Address) > addressScope(prev.Address) {
+			prev.Address = p.Address
+		}
+	}
+	return kept
+}
+
+// addressScope ranks how widely a listen address is reachable: all
+// interfaces, then an external address, then loopback
+func addressScope(address string) int {
+	switch address {
+	case "*", "0.0.0.0", "::":
+		return 2
+	}
+	if ip := net.ParseIP(address); ip != nil && ip.IsLoopback() {
+		return 0
+	}
+	return 1
 }
 
-// Kill terminates the process
-func (p *Process) Kill() error {
-	// Try graceful shutdown first
-	process, err := os.FindProcess(p.PID)
+// splitAddress splits a local socket address as printed by ss, netstat and
+// lsof (e.g. "0.0.0.0:80", "[::]:80", "*:80", "127.0.0.53%lo:53") into the
+// bound host and port
+func splitAddress(addr string) (string, int, bool) {
+	i := strings.LastIndex(addr, ":")
+	if i < 0 {
+		return "", 0, false
+	}
+
+	port, err := strconv.Atoi(addr[i+1:])
 	if err != nil {
-		return fmt.Errorf("process not found: %w", err)
+		return "", 0, false
+	}
+
+	host := strings.Trim(addr[:i], "[]")
+	if zone := strings.Index(host, "%"); zone >= 0 {
+		host = host[:zone]
+	}
+	if host == "" {
+		host = "*"
 	}
 
-	// Send SIGTERM for graceful shutdown
-	if err := process.Signal(syscall.SIGTERM); err != nil {
-		return fmt.Errorf("failed to send SIGTERM: %w", err)
+	return host, port, true
+}
+
+// unattributed returns a socket-only listener
+func unattributed(port int, address string) *Process {
+	return &Process{Port: port, Address: address, Name: "(unknown)", SocketOnly: true}
+}
+
+// Filter returns the processes for which keep returns true
+func Filter(processes []*Process, keep func(*Process) bool) []*Process {
+	result := make([]*Process, 0, len(processes))
+	for _, p := range processes {
+		if keep(p) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// UniqueByPID drops repeated entries for processes listening on several ports
+func UniqueByPID(processes []*Process) []*Process {
+	seen := make(map[int]bool)
+	result := make([]*Process, 0, len(processes))
+	for _, p := range processes {
+		if seen[p.PID] {
+			continue
+		}
+		seen[p.PID] = true
+		result = append(result, p)
+	}
+	return result
+}
+
+// InProject reports whether the process belongs to the given project,
+// which may be a directory path (with ~ expansion) or a project name
+func (p *Process) InProject(project string) bool {
+	if p.ProjectPath == "" || p.ProjectPath == "unknown" {
+		return false
 	}
 
-	// Wait a moment for graceful shutdown
-	time.Sleep(2 * time.Second)
+	// A bare name matches the project directory or manifest name
+	if !strings.ContainsAny(project, `/\~`) {
+		return filepath.Base(p.ProjectPath) == project || p.ProjectName == project
+	}
 
-	// Check if process still exists
-	if err := process.Signal(syscall.Signal(0)); err == nil {
-		// Process still running, force kill
-		if err := process.Kill(); err != nil {
-			return fmt.Errorf("failed to kill process: %w", err)
+	if strings.HasPrefix(project, "~") {
+		if home, err := os.UserHomeDir(); err == nil {
+			project = filepath.Join(home, project[1:])
 		}
 	}
+	if abs, err := filepath.Abs(project); err == nil && filepath.IsAbs(p.ProjectPath) {
+		project = abs
+	}
+	project = filepath.Clean(project)
 
-	return nil
+	if p.ProjectPath == project || strings.HasPrefix(p.ProjectPath, project+string(filepath.Separator)) {
+		return true
+	}
+
+	// detectProject may only know the trailing path components
+	return !filepath.IsAbs(p.ProjectPath) && strings.HasSuffix(project, string(filepath.Separator)+p.ProjectPath)
 }
 
 // detectProject tries to determine the project directory
@@ -68,22 +317,11 @@ func detectProject(pid int, cwd string) string {
 	// Clean up the path
 	cwd = filepath.Clean(cwd)
 
-	// Look for common project indicators
-	indicators := []string{
-		"package.json",
-		"go.mod",
-		"Cargo.toml",
-		"pom.xml",
-		"build.gradle",
-		"requirements.txt",
-		"Gemfile",
-		".git",
-	}
-
+	// Look for the nearest directory a project detector recognizes
 	current := cwd
 	for {
-		for _, indicator := range indicators {
-			if _, err := os.Stat(filepath.Join(current, indicator)); err == nil {
+		for _, d := range Detectors {
+			if _, ok := d.Detect(current); ok {
 				return current
 			}
 		}
@@ -107,32 +345,71 @@ func detectProject(pid int, cwd string) string {
 	return filepath.Base(cwd)
 }
 
-// isDockerProcess checks if a process is running in Docker
-func isDockerProcess(pid int) (bool, string) {
-	// Check if process is in a container by examining cgroup
-	cgroupPath := fmt.Sprintf("/proc/%d/cgroup", pid)
-	data, err := os.ReadFile(cgroupPath)
+// cgroupScopes map the prefixes container runtimes give the cgroups of
+// their containers, e.g. "docker-<id>.scope" with the systemd cgroup
+// driver, to the runtime. conmon, which Podman runs next to a container in
+// "libpod-conmon-<id>.scope", isn't part of it.
+var cgroupScopes = []struct{ prefix, runtime string }{
+	{"libpod-conmon-", ""},
+	{"docker-", RuntimeDocker},
+	{"libpod-", RuntimePodman},
+	{"cri-containerd-", RuntimeContainerd},
+	{"nerdctl-", RuntimeContainerd},
+}
+
+// containerOf finds the container a process runs in from its cgroup,
+// returning the runtime and the short container ID, "unknown" when the
+// runtime shows but the ID doesn't, or empty strings outside containers
+func containerOf(pid int) (string, string) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
 	if err != nil {
-		return false, ""
+		return "", ""
 	}
+	return parseCgroup(string(data))
+}
 
-	content := string(data)
-	if strings.Contains(content, "docker") {
-		// Try to extract container ID
-		lines := strings.Split(content, "\n")
-		for _, line := range lines {
-			if strings.Contains(line, "docker") {
-				parts := strings.Split(line, "/")
-				if len(parts) > 0 {
-					containerID := parts[len(parts)-1]
-					if len(containerID) >= 12 {
-						return true, containerID[:12]
+func parseCgroup(content string) (string, string) {
+	for _, line := range strings.Split(content, "\n") {
+		parts := strings.Split(line, "/")
+		if len(parts) < 2 {
+			continue
+		}
+		// Podman may nest the container's processes one level down, e.g.
+		// in libpod-<id>.scope/container
+		for _, part := range slices.Backward(parts) {
+			part = strings.TrimSuffix(part, ".scope")
+			for _, scope := range cgroupScopes {
+				if id, ok := strings.CutPrefix(part, scope.prefix); ok {
+					if scope.runtime == "" {
+						return "", ""
 					}
+					return scope.runtime, containerID(id)
 				}
 			}
 		}
-		return true, "unknown"
+		last, parent := parts[len(parts)-1], parts[len(parts)-2]
+		// The cgroupfs driver nests containers under the runtime's name,
+		// or containerd's namespace
+		switch {
+		case parent == "docker":
+			return RuntimeDocker, containerID(last)
+		case strings.HasPrefix(parent, "libpod"):
+			return RuntimePodman, containerID(last)
+		case parent == "default" && len(last) == 64:
+			return RuntimeContainerd, containerID(last)
+		}
+	}
+	if strings.Contains(content, "docker") {
+		return RuntimeDocker, "unknown"
 	}
+	return "", ""
+}
 
-	return false, ""
+// containerID shortens a container ID, or returns "unknown" for a name
+// too short to be one
+func containerID(id string) string {
+	if len(id) < 12 {
+		return "unknown"
+	}
+	return id[:12]
 }
@@ -0,0 +1,262 @@
+//go:build freebsd || openbsd
+
+package process
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type platformFinder struct {
+	run Runner
+}
+
+func (f *platformFinder) FindByPort(ctx context.Context, port int) (*Process, error) {
+	processes, err := f.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, proc := range processes {
+		if proc.Port == port {
+			return proc, nil
+		}
+	}
+	return nil, nil
+}
+
+// FindByPorts lists all listeners once rather than looking each port up
+func (f *platformFinder) FindByPorts(ctx context.Context, ports []int) (map[int]*Process, error) {
+	processes, err := f.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ByPorts(processes, ports), nil
+}
+
+// ListAll lists listeners with sockstat on FreeBSD, or fstat on OpenBSD,
+// which has no sockstat
+func (f *platformFinder) ListAll(ctx context.Context) ([]*Process, error) {
+	var processes []*Process
+	if output, err := f.run.Output(ctx, "sockstat", "-46l", "-P", "tcp"); err == nil {
+		processes = parseSockstat(string(output))
+	} else if output, fstatErr := f.run.Output(ctx, "fstat"); fstatErr == nil {
+		processes = parseFstat(string(output))
+	} else {
+		// Degrade to ports without owners rather than failing
+		if listeners, netstatErr := netstatListeners(ctx, f.run); netstatErr == nil {
+			return listeners, nil
+		}
+		return nil, fmt.Errorf("sockstat failed: %w", err)
+	}
+
+	for _, proc := range processes {
+		f.enrichProcessInfo(ctx, proc)
+	}
+	return processes, nil
+}
+
+// parseSockstat parses FreeBSD sockstat output:
+// USER COMMAND PID FD PROTO LOCAL-ADDRESS FOREIGN-ADDRESS
+func parseSockstat(output string) []*Process {
+	seen := make(map[string]bool)
+	var processes []*Process
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 7 || fields[0] == "USER" || !strings.HasPrefix(fields[4], "tcp") {
+			continue
+		}
+		host, port, ok := splitAddress(fields[5])
+		if !ok {
+			continue
+		}
+
+		// "?" marks sockets whose owner is hidden
+		if fields[0] == "?" {
+			processes = append(processes, unattributed(port, host))
+			continue
+		}
+		pid, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+
+		// A socket shared by forked workers is listed once per process
+		key := fmt.Sprintf("%d-%d", pid, port)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		processes = append(processes, &Process{PID: pid, Name: fields[1], User: fields[0], Port: port, Address: host})
+	}
+	return processes
+}
+
+// parseFstat parses the internet sockets of OpenBSD fstat output:
+// USER CMD PID FD internet[6] stream tcp PCB LOCAL [<-- or --> REMOTE].
+// Listening sockets are the ones without a remote address.
+func parseFstat(output string) []*Process {
+	seen := make(map[string]bool)
+	var processes []*Process
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 9 || !strings.HasPrefix(fields[4], "internet") || fields[5] != "stream" || fields[6] != "tcp" {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+		host, port, ok := splitAddress(fields[8])
+		if !ok || port == 0 {
+			continue
+		}
+
+		key := fmt.Sprintf("%d-%d", pid, port)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		processes = append(processes, &Process{PID: pid, Name: fields[1], User: fields[0], Port: port, Address: host})
+	}
+	return processes
+}
+
+func (f *platformFinder) enrichProcessInfo(ctx context.Context, proc *Process) {
+	if proc.SocketOnly {
+		return
+	}
+
+	// lstart is five words, e.g. "Thu Dec 28 10:30:45 2023", and the
+	// command line runs to the end
+	output, err := f.run.Output(ctx, "ps", "-ww", "-o", "ppid=,lstart=,args=", "-p", strconv.Itoa(proc.PID))
+	if err == nil {
+		if fields := strings.Fields(string(output)); len(fields) >= 7 {
+			proc.PPID, _ = strconv.Atoi(fields[0])
+			if t, err := time.ParseInLocation("Mon Jan _2 15:04:05 2006", strings.Join(fields[1:6], " "), time.Local); err == nil {
+				proc.StartTime = t
+			}
+			proc.Command = strings.Join(fields[6:], " ")
+		}
+	}
+
+	// procstat is FreeBSD only; OpenBSD doesn't expose other processes'
+	// working directories
+	if output, err := f.run.Output(ctx, "procstat", "-h", "-b", strconv.Itoa(proc.PID)); err == nil {
+		if fields := strings.Fields(string(output)); len(fields) >= 4 {
+			proc.ExePath = fields[len(fields)-1]
+		}
+	}
+	if output, err := f.run.Output(ctx, "procstat", "-h", "-f", strconv.Itoa(proc.PID)); err == nil {
+		if cwd := parseProcstatCwd(string(output)); cwd != "" {
+			proc.WorkDir = cwd
+			proc.ProjectPath = detectProject(proc.PID, cwd)
+		}
+	}
+	if proc.ProjectPath == "" {
+		proc.ProjectPath = "unknown"
+	}
+}
+
+// parseProcstatCwd finds the cwd entry of procstat -f output:
+// PID COMM FD T V FLAGS REF OFFSET PRO NAME
+func parseProcstatCwd(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 4 && fields[2] == "cwd" {
+			return fields[len(fields)-1]
+		}
+	}
+	return ""
+}
+
+// stopService stops the service manager unit owning the process
+func stopService(p *Process) error {
+	return fmt.Errorf("stopping services is not supported on BSD yet")
+}
+
+// lookupParent returns the parent PID and name of a process
+func lookupParent(pid int) (int, string, error) {
+	return psParent(context.Background(), execRunner{}, pid)
+}
+
+// backends lists sockstat or fstat, or netstat when neither is available
+func backends() ([]string, bool) {
+	if found := installed("sockstat", "fstat"); len(found) > 0 {
+		return found, false
+	}
+	// netstat only lists ports
+	found := installed("netstat")
+	return found, len(found) > 0
+}
+
+// elevateHint tells how to see every user's processes
+const elevateHint = "run pf as root, e.g. doas portfinder list"
+
+// probeBackends checks each socket tool
+func probeBackends() []Backend {
+	return []Backend{
+		probeTool("sockstat", "sockstat ships with the base system; check your PATH"),
+		probeTool("fstat", "fstat ships with the base system; check your PATH"),
+		probeTool("netstat", "netstat ships with the base system; check your PATH"),
+	}
+}
+
+// seesAllUsers reports whether other users' sockets are visible: always
+// for root, and on FreeBSD unless security.bsd.see_other_uids is off
+func seesAllUsers() bool {
+	if os.Geteuid() == 0 {
+		return true
+	}
+	output, err := execRunner{}.Output(context.Background(), "sysctl", "-n", "security.bsd.see_other_uids")
+	return err == nil && strings.TrimSpace(string(output)) == "1"
+}
+
+// establishedPeers lists the remote addresses of established TCP
+// connections, keyed by local port
+func establishedPeers(ctx context.Context, run Runner) (map[int][]string, error) {
+	if output, err := run.Output(ctx, "sockstat", "-46c", "-P", "tcp"); err == nil {
+		return parseSockstatPeers(string(output)), nil
+	}
+
+	output, err := run.Output(ctx, "fstat")
+	if err != nil {
+		return nil, fmt.Errorf("fstat failed: %w", err)
+	}
+	return parseFstatPeers(string(output)), nil
+}
+
+// parseSockstatPeers parses sockstat -c output, whose FOREIGN ADDRESS
+// column is the remote end
+func parseSockstatPeers(output string) map[int][]string {
+	peers := make(map[int][]string)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 7 || fields[0] == "USER" {
+			continue
+		}
+		if _, port, ok := splitAddress(fields[5]); ok {
+			peers[port] = append(peers[port], fields[6])
+		}
+	}
+	return peers
+}
+
+// parseFstatPeers parses the connected TCP sockets of OpenBSD fstat
+// output, which end in "LOCAL <-- REMOTE" or "LOCAL --> REMOTE"
+func parseFstatPeers(output string) map[int][]string {
+	peers := make(map[int][]string)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 11 || fields[6] != "tcp" {
+			continue
+		}
+		if _, port, ok := splitAddress(fields[8]); ok {
+			peers[port] = append(peers[port], fields[10])
+		}
+	}
+	return peers
+}
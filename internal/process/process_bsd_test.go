@@ -0,0 +1,170 @@
+//go:build freebsd || openbsd
+
+package process
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSockstat(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []fakeListener
+	}{
+		{
+			name: "freebsd",
+			output: `USER     COMMAND    PID   FD  PROTO  LOCAL ADDRESS         FOREIGN ADDRESS
+www      nginx      1245  6   tcp4   *:80                  *:*
+root     nginx      1244  6   tcp4   *:80                  *:*
+postgres postgres   987   5   tcp6   ::1:5432              *:*
+postgres postgres   987   6   tcp4   127.0.0.1:5432        *:*
+dev      node       2301  21  tcp46  *:3000                *:*
+root     sshd       812   3   tcp6   *:22                  *:*
+root     sshd       812   4   tcp4   *:22                  *:*
+root     syslogd    634   6   udp4   *:514                 *:*
+?        ?          ?     ?   tcp4   *:8080                *:*
+`,
+			// A socket listened on over IPv4 and IPv6 is kept once per
+			// process, and only the owner of 8080 is hidden
+			want: []fakeListener{
+				{PID: 812, Name: "sshd", Port: 22, Address: "*"},
+				{PID: 1244, Name: "nginx", Port: 80, Address: "*"},
+				{PID: 1245, Name: "nginx", Port: 80, Address: "*"},
+				{PID: 2301, Name: "node", Port: 3000, Address: "*"},
+				{PID: 987, Name: "postgres", Port: 5432, Address: "::1"},
+				{Name: "(unknown)", Port: 8080, Address: "*"},
+			},
+		},
+		{
+			name:   "header only",
+			output: "USER     COMMAND    PID   FD  PROTO  LOCAL ADDRESS         FOREIGN ADDRESS\n",
+			want:   []fakeListener{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := listeners(parseSockstat(tt.output)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSockstat() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFstat(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []fakeListener
+	}{
+		{
+			name: "openbsd",
+			output: `USER     CMD          PID   FD MOUNT        INUM  MODE         R/W    SZ|DV
+root     sshd       72542    3* internet stream tcp 0xffff800000a1c7a0 *:22
+root     sshd       72542    4* internet6 stream tcp 0xffff800000a1c9d8 [::]:22
+www      httpd      41252    6* internet stream tcp 0xffff800000a1d3b0 *:80
+_postgresql postgres 71234   5* internet6 stream tcp 0xffff800000a1e0c8 [::1]:5432
+_postgresql postgres 71234   6* internet stream tcp 0xffff800000a1e2f0 127.0.0.1:5432
+dev      ssh        90211    3* internet stream tcp 0xffff800000a1f118 192.168.1.20:41022 --> 203.0.113.5:22
+root     dhcpleased 30112    5* internet dgram udp 0xffff800000a20a48 *:68
+root     unbound    50233    3* unix stream 0xffff800000a21070
+dev      ksh        88120   wd /home          2073  drwxr-xr-x     r      512
+`,
+			want: []fakeListener{
+				{PID: 72542, Name: "sshd", Port: 22, Address: "*"},
+				{PID: 41252, Name: "httpd", Port: 80, Address: "*"},
+				{PID: 71234, Name: "postgres", Port: 5432, Address: "::1"},
+			},
+		},
+		{
+			name:   "no sockets",
+			output: "USER     CMD          PID   FD MOUNT        INUM  MODE         R/W    SZ|DV\n",
+			want:   []fakeListener{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := listeners(parseFstat(tt.output)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFstat() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePeers(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func(string) map[int][]string
+		// output is that of sockstat -46c -P tcp or fstat
+		output string
+		want   map[int][]string
+	}{
+		{
+			name:  "sockstat",
+			parse: parseSockstatPeers,
+			output: `USER     COMMAND    PID   FD  PROTO  LOCAL ADDRESS         FOREIGN ADDRESS
+dev      node       2301  23  tcp4   127.0.0.1:3000        127.0.0.1:52118
+dev      node       2301  24  tcp6   ::1:3000              ::1:52120
+dev      firefox    3410  61  tcp4   127.0.0.1:52118       127.0.0.1:3000
+root     sshd       1502  4   tcp4   192.168.1.20:22       192.168.1.31:61022
+`,
+			want: map[int][]string{
+				3000:  {"127.0.0.1:52118", "::1:52120"},
+				52118: {"127.0.0.1:3000"},
+				22:    {"192.168.1.31:61022"},
+			},
+		},
+		{
+			name:  "fstat",
+			parse: parseFstatPeers,
+			output: `USER     CMD          PID   FD MOUNT        INUM  MODE         R/W    SZ|DV
+root     sshd       72542    3* internet stream tcp 0xffff800000a1c7a0 *:22
+dev      ssh        90211    3* internet stream tcp 0xffff800000a1f118 192.168.1.20:41022 --> 203.0.113.5:22
+root     sshd       10422    4* internet stream tcp 0xffff800000a1f340 192.168.1.20:22 <-- 192.168.1.31:61022
+`,
+			want: map[int][]string{
+				41022: {"203.0.113.5:22"},
+				22:    {"192.168.1.31:61022"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.parse(tt.output); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseProcstatCwd(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{
+			name: "freebsd",
+			output: `  2301 node                text v r r-------   -       -   - /usr/local/bin/node
+  2301 node                 cwd v d r-------   -       -   - /home/dev/app
+  2301 node                root v d r-------   -       -   - /
+  2301 node                   0 v c rw------   6       0   - /dev/pts/1
+`,
+			want: "/home/dev/app",
+		},
+		{"no cwd", "  2301 node                root v d r-------   -       -   - /\n", ""},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseProcstatCwd(tt.output); got != tt.want {
+				t.Errorf("parseProcstatCwd() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
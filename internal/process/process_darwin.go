@@ -3,7 +3,9 @@
 package process
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"os/exec"
 	"regexp"
 	"strconv"
@@ -11,34 +13,73 @@ import (
 	"time"
 )
 
-type platformFinder struct{}
+type platformFinder struct {
+	run Runner
+}
+
+func (f *platformFinder) FindByPort(ctx context.Context, port int) (*Process, error) {
+	// libproc is fast enough to scan everything and filter
+	if processes, err := nativeListAll(); err == nil {
+		for _, proc := range processes {
+			if proc.Port == port {
+				return proc, nil
+			}
+		}
+		return nil, nil
+	}
 
-func (f *platformFinder) FindByPort(port int) (*Process, error) {
-	// Use lsof on macOS
-	cmd := exec.Command("lsof", "-i", fmt.Sprintf(":%d", port), "-n", "-P")
-	output, err := cmd.Output()
+	// Fall back to lsof
+	output, err := f.run.Output(ctx, "lsof", "-i", fmt.Sprintf(":%d", port), "-n", "-P")
 	if err != nil {
 		// No process found is not an error
 		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
 			return nil, nil
 		}
+		// Degrade to the port without its owner rather than failing
+		if listeners, netstatErr := netstatListeners(ctx, f.run); netstatErr == nil {
+			for _, proc := range listeners {
+				if proc.Port == port {
+					return proc, nil
+				}
+			}
+			return nil, nil
+		}
 		return nil, fmt.Errorf("lsof failed: %w", err)
 	}
 
-	return f.parseLsofOutput(string(output), port)
+	return f.parseLsofOutput(ctx, string(output), port)
 }
 
-func (f *platformFinder) ListAll() ([]*Process, error) {
-	cmd := exec.Command("lsof", "-i", "-n", "-P")
-	output, err := cmd.Output()
+// FindByPorts lists all listeners once rather than looking each port up
+func (f *platformFinder) FindByPorts(ctx context.Context, ports []int) (map[int]*Process, error) {
+	processes, err := f.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ByPorts(processes, ports), nil
+}
+
+func (f *platformFinder) ListAll(ctx context.Context) ([]*Process, error) {
+	if processes, err := nativeListAll(); err == nil {
+		return processes, nil
+	}
+
+	output, err := f.run.Output(ctx, "lsof", "-i", "-n", "-P")
 	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return nil, nil
+		}
+		// Degrade to ports without owners rather than failing
+		if listeners, netstatErr := netstatListeners(ctx, f.run); netstatErr == nil {
+			return listeners, nil
+		}
 		return nil, fmt.Errorf("lsof failed: %w", err)
 	}
 
-	return f.parseLsofOutputMultiple(string(output))
+	return f.parseLsofOutputMultiple(ctx, string(output))
 }
 
-func (f *platformFinder) parseLsofOutput(output string, port int) (*Process, error) {
+func (f *platformFinder) parseLsofOutput(ctx context.Context, output string, port int) (*Process, error) {
 	lines := strings.Split(strings.TrimSpace(output), "\n")
 	if len(lines) < 2 {
 		return nil, nil
@@ -59,7 +100,9 @@ func (f *platformFinder) parseLsofOutput(output string, port int) (*Process, err
 		proc := &Process{
 			Name: fields[0],
 			Port: port,
+			User: fields[2],
 		}
+		proc.Address, _, _ = splitAddress(fields[8])
 
 		// Parse PID
 		pid, err := strconv.Atoi(fields[1])
@@ -69,7 +112,8 @@ func (f *platformFinder) parseLsofOutput(output string, port int) (*Process, err
 		proc.PID = pid
 
 		// Get additional process info
-		f.enrichProcessInfo(proc)
+		f.enrichProcessInfo(ctx, proc)
+		resolveLaunchdJobs(ctx, f.run, []*Process{proc})
 
 		return proc, nil
 	}
@@ -77,7 +121,7 @@ func (f *platformFinder) parseLsofOutput(output string, port int) (*Process, err
 	return nil, nil
 }
 
-func (f *platformFinder) parseLsofOutputMultiple(output string) ([]*Process, error) {
+func (f *platformFinder) parseLsofOutputMultiple(ctx context.Context, output string) ([]*Process, error) {
 	lines := strings.Split(strings.TrimSpace(output), "\n")
 	if len(lines) < 2 {
 		return nil, nil
@@ -87,27 +131,31 @@ func (f *platformFinder) parseLsofOutputMultiple(output string) ([]*Process, err
 	processMap := make(map[string]*Process)
 
 	for i := 1; i < len(lines); i++ {
-		fields := strings.Fields(lines[i])
-		if len(fields) < 9 {
+		if !strings.Contains(lines[i], "LISTEN") {
 			continue
 		}
 
-		if !strings.Contains(lines[i], "LISTEN") {
+		fields := strings.Fields(lines[i])
+		if len(fields) < 9 {
+			logUnparsed("lsof", lines[i], "too few fields")
 			continue
 		}
 
 		matches := portRegex.FindStringSubmatch(lines[i])
 		if len(matches) < 2 {
+			logUnparsed("lsof", lines[i], "no port")
 			continue
 		}
 
 		port, err := strconv.Atoi(matches[1])
 		if err != nil {
+			logUnparsed("lsof", lines[i], "invalid port")
 			continue
 		}
 
 		pid, err := strconv.Atoi(fields[1])
 		if err != nil {
+			logUnparsed("lsof", lines[i], "invalid PID")
 			continue
 		}
 
@@ -120,9 +168,11 @@ func (f *platformFinder) parseLsofOutputMultiple(output string) ([]*Process, err
 			Name: fields[0],
 			PID:  pid,
 			Port: port,
+			User: fields[2],
 		}
+		proc.Address, _, _ = splitAddress(fields[8])
 
-		f.enrichProcessInfo(proc)
+		f.enrichProcessInfo(ctx, proc)
 		processMap[key] = proc
 	}
 
@@ -130,14 +180,14 @@ func (f *platformFinder) parseLsofOutputMultiple(output string) ([]*Process, err
 	for _, p := range processMap {
 		processes = append(processes, p)
 	}
+	resolveLaunchdJobs(ctx, f.run, processes)
 
 	return processes, nil
 }
 
-func (f *platformFinder) enrichProcessInfo(proc *Process) {
+func (f *platformFinder) enrichProcessInfo(ctx context.Context, proc *Process) {
 	// Get process info using ps
-	cmd := exec.Command("ps", "-p", strconv.Itoa(proc.PID), "-o", "comm=,command=")
-	output, err := cmd.Output()
+	output, err := f.run.Output(ctx, "ps", "-p", strconv.Itoa(proc.PID), "-o", "comm=,command=")
 	if err != nil {
 		return
 	}
@@ -145,14 +195,20 @@ func (f *platformFinder) enrichProcessInfo(proc *Process) {
 	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
 	if len(lines) > 0 {
 		parts := strings.SplitN(lines[0], " ", 2)
+		if strings.HasPrefix(parts[0], "/") {
+			proc.ExePath = parts[0]
+		}
 		if len(parts) > 1 {
 			proc.Command = strings.TrimSpace(parts[1])
 		}
 	}
 
+	if ppid, _, err := parentOf(ctx, f.run, proc.PID); err == nil {
+		proc.PPID = ppid
+	}
+
 	// Get process start time properly on macOS
-	cmd = exec.Command("ps", "-p", strconv.Itoa(proc.PID), "-o", "lstart=")
-	output, err = cmd.Output()
+	output, err = f.run.Output(ctx, "ps", "-p", strconv.Itoa(proc.PID), "-o", "lstart=")
 	if err == nil {
 		startTimeStr := strings.TrimSpace(string(output))
 		// Parse macOS lstart format: "Thu Dec 28 10:30:45 2023"
@@ -165,8 +221,7 @@ func (f *platformFinder) enrichProcessInfo(proc *Process) {
 	}
 
 	// Get working directory
-	cmd = exec.Command("lsof", "-p", strconv.Itoa(proc.PID), "-d", "cwd", "-a")
-	output, err = cmd.Output()
+	output, err = f.run.Output(ctx, "lsof", "-p", strconv.Itoa(proc.PID), "-d", "cwd", "-a")
 	if err == nil {
 		lines := strings.Split(string(output), "\n")
 		for _, line := range lines {
@@ -174,6 +229,7 @@ func (f *platformFinder) enrichProcessInfo(proc *Process) {
 				fields := strings.Fields(line)
 				if len(fields) > 8 {
 					cwd := fields[len(fields)-1]
+					proc.WorkDir = cwd
 					proc.ProjectPath = detectProject(proc.PID, cwd)
 				}
 			}
@@ -185,3 +241,84 @@ func (f *platformFinder) enrichProcessInfo(proc *Process) {
 		proc.IsDocker = true
 	}
 }
+
+// lookupParent returns the parent PID and name of a process
+func lookupParent(pid int) (int, string, error) {
+	return parentOf(context.Background(), execRunner{}, pid)
+}
+
+// parentOf reads the parent natively, falling back to ps
+func parentOf(ctx context.Context, run Runner, pid int) (int, string, error) {
+	if ppid, name, err := nativeParent(pid); err == nil {
+		return ppid, name, nil
+	}
+	return psParent(ctx, run, pid)
+}
+
+// backends lists libproc, when built with cgo, and the lsof fallback, or
+// netstat when neither is available
+func backends() ([]string, bool) {
+	var found []string
+	if libprocAvailable {
+		found = append(found, "libproc")
+	}
+	if found = append(found, installed("lsof")...); len(found) > 0 {
+		return found, false
+	}
+	// netstat only lists ports
+	found = installed("netstat")
+	return found, len(found) > 0
+}
+
+// elevateHint tells how to see every user's processes
+const elevateHint = "rescan with sudo: portfinder list --sudo, or R in the list"
+
+// probeBackends checks the native API and each socket tool
+func probeBackends() []Backend {
+	return []Backend{
+		{Name: "libproc", Available: libprocAvailable, Hint: "this build has no cgo, so lsof is used instead"},
+		probeTool("lsof", "lsof ships with macOS; check that /usr/sbin is on your PATH"),
+		probeTool("netstat", "netstat ships with macOS; check that /usr/sbin is on your PATH"),
+	}
+}
+
+// seesAllUsers reports whether other users' sockets are visible, which
+// libproc and lsof only allow for root
+func seesAllUsers() bool {
+	return os.Geteuid() == 0
+}
+
+// establishedPeers lists the remote addresses of established TCP
+// connections with lsof, keyed by local port
+func establishedPeers(ctx context.Context, run Runner) (map[int][]string, error) {
+	output, err := run.Output(ctx, "lsof", "-iTCP", "-sTCP:ESTABLISHED", "-n", "-P")
+	if err != nil {
+		// lsof exits with 1 when nothing matches
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return map[int][]string{}, nil
+		}
+		return nil, fmt.Errorf("lsof failed: %w", err)
+	}
+	return parseLsofPeers(string(output)), nil
+}
+
+// parseLsofPeers parses lsof -i output, whose NAME column is
+// "local->remote" for connected sockets
+func parseLsofPeers(output string) map[int][]string {
+	peers := make(map[int][]string)
+	for _, line := range strings.Split(output, "\n")[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 9 {
+			continue
+		}
+		// NAME is "local->remote"
+		local, remote, ok := strings.Cut(fields[8], "->")
+		if !ok {
+			continue
+		}
+		if _, port, ok := splitAddress(local); ok {
+			peers[port] = append(peers[port], remote)
+		}
+	}
+	return peers
+}
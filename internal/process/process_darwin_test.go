@@ -0,0 +1,71 @@
+package process
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParseLsofOutputMultiple(t *testing.T) {
+	output, err := os.ReadFile("testdata/lsof-i.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ps and lsof aren't there to enrich the listeners
+	f := &platformFinder{run: &fakeRunner{t: t}}
+	processes, err := f.parseLsofOutputMultiple(context.Background(), string(output))
+	if err != nil {
+		t.Fatalf("parseLsofOutputMultiple() error = %v", err)
+	}
+
+	// A port listened on over IPv4 and IPv6 is kept once, and connections
+	// and UDP sockets are left out
+	want := []fakeListener{
+		{PID: 48211, Name: "node", Port: 3000, Address: "*"},
+		{PID: 51002, Name: "postgres", Port: 5432, Address: "127.0.0.1"},
+		{PID: 641, Name: "ControlCe", Port: 7000, Address: "*"},
+		{PID: 612, Name: "rapportd", Port: 49152, Address: "*"},
+	}
+	if got := listeners(processes); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseLsofOutputMultiple() = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadConnectionsLsof(t *testing.T) {
+	run := &fakeRunner{t: t, outputs: map[string]string{
+		"lsof -iTCP -sTCP:ESTABLISHED -n -P": "lsof-established.txt",
+	}}
+	node := &Process{PID: 48211, Port: 3000}
+	postgres := &Process{PID: 51002, Port: 5432}
+	if err := LoadConnections(context.Background(), run, []*Process{node, postgres}); err != nil {
+		t.Fatalf("LoadConnections() error = %v", err)
+	}
+
+	if want := []string{"127.0.0.1:52118", "[::1]:52114"}; node.Connections != 2 || !reflect.DeepEqual(node.Peers, want) {
+		t.Errorf("node has %d connections from %v, want 2 from %v", node.Connections, node.Peers, want)
+	}
+	if postgres.Connections != 0 || postgres.Peers != nil {
+		t.Errorf("postgres has %d connections from %v, want none", postgres.Connections, postgres.Peers)
+	}
+}
+
+func TestResolveLaunchdJobs(t *testing.T) {
+	run := &fakeRunner{t: t, outputs: map[string]string{"launchctl list": "launchctl-list.txt"}}
+	postgres := &Process{PID: 51002, PPID: 1, Port: 5432}
+	vscode := &Process{PID: 48211, PPID: 1, Port: 3000}
+	child := &Process{PID: 52001, PPID: 51002, Port: 5433}
+	resolveLaunchdJobs(context.Background(), run, []*Process{postgres, vscode, child})
+
+	if postgres.Service != "homebrew.mxcl.postgresql@16" {
+		t.Errorf("postgres runs as %q, want homebrew.mxcl.postgresql@16", postgres.Service)
+	}
+	// Apps opened from the Dock aren't services, and only launchd's
+	// children are looked up
+	if vscode.Service != "" || child.Service != "" {
+		t.Errorf("services = %q and %q, want none", vscode.Service, child.Service)
+	}
+	if len(run.calls) != 1 {
+		t.Errorf("ran %v, want launchctl list once", run.calls)
+	}
+}
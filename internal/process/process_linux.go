@@ -3,54 +3,94 @@
 package process
 
 import (
+	"context"
+	"encoding/hex"
 	"fmt"
+	"log/slog"
+	"net"
 	"os"
-	"os/exec"
 	"strconv"
 	"strings"
 	"syscall"
 	"time"
 )
 
-type platformFinder struct{}
+// clockTicks is USER_HZ, the unit of /proc/[pid]/stat times; 100 is
+// correct on practically every Linux build
+const clockTicks = 100
 
-func (f *platformFinder) FindByPort(port int) (*Process, error) {
+type platformFinder struct {
+	run Runner
+}
+
+func (f *platformFinder) FindByPort(ctx context.Context, port int) (*Process, error) {
 	// First try ss (socket statistics)
-	proc, err := f.findUsingSS(port)
+	proc, err := f.findUsingSS(ctx, port)
 	if err == nil && proc != nil {
+		fillSocketOwners([]*Process{proc})
 		return proc, nil
 	}
 
 	// Fallback to netstat
-	return f.findUsingNetstat(port)
+	proc, err = f.findUsingNetstat(ctx, port)
+	if err == nil {
+		if proc != nil {
+			fillSocketOwners([]*Process{proc})
+		}
+		return proc, nil
+	}
+
+	// Without either tool, the kernel's socket table still shows the port
+	processes, err := procNetListeners()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range processes {
+		if p.Port == port {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+// FindByPorts lists all listeners once rather than looking each port up
+func (f *platformFinder) FindByPorts(ctx context.Context, ports []int) (map[int]*Process, error) {
+	processes, err := f.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ByPorts(processes, ports), nil
 }
 
-func (f *platformFinder) ListAll() ([]*Process, error) {
+func (f *platformFinder) ListAll(ctx context.Context) ([]*Process, error) {
 	processes := make([]*Process, 0)
 
 	// Try ss first
-	cmd := exec.Command("ss", "-tulnp")
-	output, err := cmd.Output()
+	output, err := f.run.Output(ctx, "ss", "-tulnp")
 	if err == nil {
 		procs := f.parseSSOutput(string(output))
 		processes = append(processes, procs...)
 	} else {
 		// Fallback to netstat
-		cmd = exec.Command("netstat", "-tulnp")
-		output, err = cmd.Output()
+		output, err = f.run.Output(ctx, "netstat", "-tulnp")
 		if err != nil {
+			// Degrade to ports without owners rather than failing
+			if listeners, procErr := procNetListeners(); procErr == nil {
+				slog.Info("ss and netstat failed, listing sockets from /proc/net without their processes")
+				return listeners, nil
+			}
 			return nil, fmt.Errorf("failed to list ports: %w", err)
 		}
 		procs := f.parseNetstatOutput(string(output))
 		processes = append(processes, procs...)
 	}
 
+	fillSocketOwners(processes)
 	return processes, nil
 }
 
-func (f *platformFinder) findUsingSS(port int) (*Process, error) {
-	cmd := exec.Command("ss", "-tulnp", fmt.Sprintf("sport = :%d", port))
-	output, err := cmd.Output()
+func (f *platformFinder) findUsingSS(ctx context.Context, port int) (*Process, error) {
+	output, err := f.run.Output(ctx, "ss", "-tulnp", fmt.Sprintf("sport = :%d", port))
 	if err != nil {
 		return nil, err
 	}
@@ -65,9 +105,8 @@ func (f *platformFinder) findUsingSS(port int) (*Process, error) {
 	return nil, nil
 }
 
-func (f *platformFinder) findUsingNetstat(port int) (*Process, error) {
-	cmd := exec.Command("netstat", "-tulnp")
-	output, err := cmd.Output()
+func (f *platformFinder) findUsingNetstat(ctx context.Context, port int) (*Process, error) {
+	output, err := f.run.Output(ctx, "netstat", "-tulnp")
 	if err != nil {
 		return nil, err
 	}
@@ -85,14 +124,16 @@ func (f *platformFinder) findUsingNetstat(port int) (*Process, error) {
 func (f *platformFinder) parseSSLine(line string, port int) (*Process, error) {
 	// Parse ss output format
 	fields := strings.Fields(line)
-	if len(fields) < 7 {
+	if len(fields) < 6 {
 		return nil, nil
 	}
 
 	// Extract PID/Program from last field (format: "users:(("nginx",pid=1234,fd=6))")
 	pidProg := fields[len(fields)-1]
-	if !strings.Contains(pidProg, "pid=") {
-		return nil, nil
+	if len(fields) < 7 || !strings.Contains(pidProg, "pid=") {
+		// The owner is hidden, e.g. another user's process without root
+		address, _, _ := splitAddress(fields[4])
+		return unattributed(port, address), nil
 	}
 
 	pidStart := strings.Index(pidProg, "pid=") + 4
@@ -110,6 +151,7 @@ func (f *platformFinder) parseSSLine(line string, port int) (*Process, error) {
 		PID:  pid,
 		Port: port,
 	}
+	proc.Address, _, _ = splitAddress(fields[4])
 
 	f.enrichProcessInfo(proc)
 	return proc, nil
@@ -124,7 +166,8 @@ func (f *platformFinder) parseNetstatLine(line string, port int) (*Process, erro
 	// Parse PID/Program name
 	pidProg := fields[6]
 	if pidProg == "-" {
-		return nil, nil
+		address, _, _ := splitAddress(fields[3])
+		return unattributed(port, address), nil
 	}
 
 	parts := strings.Split(pidProg, "/")
@@ -142,6 +185,7 @@ func (f *platformFinder) parseNetstatLine(line string, port int) (*Process, erro
 		Name: parts[1],
 		Port: port,
 	}
+	proc.Address, _, _ = splitAddress(fields[3])
 
 	f.enrichProcessInfo(proc)
 	return proc, nil
@@ -158,24 +202,22 @@ func (f *platformFinder) parseSSOutput(output string) []*Process {
 
 		fields := strings.Fields(line)
 		if len(fields) < 5 {
+			logUnparsed("ss", line, "too few fields")
 			continue
 		}
 
 		// Extract port from address
-		addr := fields[4]
-		parts := strings.Split(addr, ":")
-		if len(parts) < 2 {
-			continue
-		}
-
-		port, err := strconv.Atoi(parts[len(parts)-1])
-		if err != nil {
+		_, port, ok := splitAddress(fields[4])
+		if !ok {
+			logUnparsed("ss", line, "no port in the local address")
 			continue
 		}
 
 		proc, err := f.parseSSLine(line, port)
 		if err == nil && proc != nil {
 			processes = append(processes, proc)
+		} else {
+			logUnparsed("ss", line, "unreadable process")
 		}
 	}
 
@@ -193,24 +235,22 @@ func (f *platformFinder) parseNetstatOutput(output string) []*Process {
 
 		fields := strings.Fields(line)
 		if len(fields) < 7 {
+			logUnparsed("netstat", line, "too few fields")
 			continue
 		}
 
 		// Extract port
-		addr := fields[3]
-		parts := strings.Split(addr, ":")
-		if len(parts) < 2 {
-			continue
-		}
-
-		port, err := strconv.Atoi(parts[len(parts)-1])
-		if err != nil {
+		_, port, ok := splitAddress(fields[3])
+		if !ok {
+			logUnparsed("netstat", line, "no port in the local address")
 			continue
 		}
 
 		proc, err := f.parseNetstatLine(line, port)
 		if err == nil && proc != nil {
 			processes = append(processes, proc)
+		} else {
+			logUnparsed("netstat", line, "unreadable process")
 		}
 	}
 
@@ -226,21 +266,11 @@ func getProcessStartTime(pid int) (time.Time, error) {
 		return time.Time{}, err
 	}
 
-	// Parse the stat file
 	// The start time is the 22nd field (21 when 0-indexed)
 	// It's in clock ticks since boot
-	content := string(data)
-
-	// Find the last ) to handle process names with spaces/parentheses
-	lastParen := strings.LastIndex(content, ")")
-	if lastParen == -1 {
-		return time.Time{}, fmt.Errorf("invalid stat format")
-	}
-
-	// Fields after the command name
-	fields := strings.Fields(content[lastParen+1:])
-	if len(fields) < 20 {
-		return time.Time{}, fmt.Errorf("not enough fields in stat")
+	fields, err := parseStatFields(string(data))
+	if err != nil {
+		return time.Time{}, err
 	}
 
 	// Start time is the 20th field after the command name (0-indexed)
@@ -249,22 +279,54 @@ func getProcessStartTime(pid int) (time.Time, error) {
 		return time.Time{}, err
 	}
 
-	// Get system boot time
-	var info syscall.Sysinfo_t
-	if err := syscall.Sysinfo(&info); err != nil {
+	bootTime, err := getBootTime()
+	if err != nil {
 		return time.Time{}, err
 	}
 
-	// Get clock ticks per second
-	clockTicks := int64(100) // Default, usually correct for Linux
-
 	// Calculate start time
-	bootTime := time.Now().Add(-time.Duration(info.Uptime) * time.Second)
 	startTime := bootTime.Add(time.Duration(startTicks*1000/clockTicks) * time.Millisecond)
 
 	return startTime, nil
 }
 
+// getBootTime returns the system boot time. The btime line of /proc/stat is
+// fixed for the life of the system, unlike now minus uptime, which drifts by
+// up to a second between calls and would make start times unstable.
+func getBootTime() (time.Time, error) {
+	if data, err := os.ReadFile("/proc/stat"); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if value, ok := strings.CutPrefix(line, "btime "); ok {
+				if btime, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err == nil {
+					return time.Unix(btime, 0), nil
+				}
+			}
+		}
+	}
+
+	var info syscall.Sysinfo_t
+	if err := syscall.Sysinfo(&info); err != nil {
+		return time.Time{}, err
+	}
+	return time.Now().Add(-time.Duration(info.Uptime) * time.Second), nil
+}
+
+// parseStatFields returns the /proc/[pid]/stat fields that follow the command name
+func parseStatFields(content string) ([]string, error) {
+	// Find the last ) to handle process names with spaces/parentheses
+	lastParen := strings.LastIndex(content, ")")
+	if lastParen == -1 {
+		return nil, fmt.Errorf("invalid stat format")
+	}
+
+	fields := strings.Fields(content[lastParen+1:])
+	if len(fields) < 20 {
+		return nil, fmt.Errorf("not enough fields in stat")
+	}
+
+	return fields, nil
+}
+
 func (f *platformFinder) enrichProcessInfo(proc *Process) {
 	// Get process name if not already set
 	if proc.Name == "" {
@@ -273,17 +335,33 @@ func (f *platformFinder) enrichProcessInfo(proc *Process) {
 		}
 	}
 
-	// Get command line
+	// Get command line, whose arguments are NUL terminated
 	if cmdline, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", proc.PID)); err == nil {
 		proc.Command = strings.ReplaceAll(string(cmdline), "\x00", " ")
 		proc.Command = strings.TrimSpace(proc.Command)
+		if args := strings.TrimRight(string(cmdline), "\x00"); args != "" {
+			proc.Args = strings.Split(args, "\x00")
+		}
+	}
+
+	// Get executable path
+	if exe, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", proc.PID)); err == nil {
+		proc.ExePath = exe
 	}
 
 	// Get working directory
 	if cwd, err := os.Readlink(fmt.Sprintf("/proc/%d/cwd", proc.PID)); err == nil {
+		proc.WorkDir = cwd
 		proc.ProjectPath = detectProject(proc.PID, cwd)
 	}
 
+	// The owner of /proc/<pid> is the process's effective user
+	if info, err := os.Stat(fmt.Sprintf("/proc/%d", proc.PID)); err == nil {
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+			proc.User = userName(strconv.Itoa(int(stat.Uid)))
+		}
+	}
+
 	// Get actual start time
 	if startTime, err := getProcessStartTime(proc.PID); err == nil {
 		proc.StartTime = startTime
@@ -294,6 +372,277 @@ func (f *platformFinder) enrichProcessInfo(proc *Process) {
 		}
 	}
 
-	// Check if Docker
-	proc.IsDocker, proc.DockerID = isDockerProcess(proc.PID)
+	if ppid, _, err := lookupParent(proc.PID); err == nil {
+		proc.PPID = ppid
+	}
+
+	// Check if it runs in a container
+	proc.ContainerRuntime, proc.DockerID = containerOf(proc.PID)
+	proc.IsDocker = proc.ContainerRuntime != ""
+
+	// Check if managed by systemd
+	proc.Service, proc.UserService = systemdUnit(proc.PID)
+}
+
+// systemdUnit returns the systemd service owning a process, read from the
+// last component of its cgroup path (e.g. /system.slice/nginx.service)
+func systemdUnit(pid int) (string, bool) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return "", false
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		// Only the unified hierarchy or the v1 name=systemd hierarchy
+		// mirror the unit layout
+		if parts[1] != "" && parts[1] != "name=systemd" {
+			continue
+		}
+
+		unit := parts[2][strings.LastIndex(parts[2], "/")+1:]
+		if !strings.HasSuffix(unit, ".service") {
+			continue
+		}
+		// user@UID.service is the user manager itself, not a service to stop
+		if strings.HasPrefix(unit, "user@") {
+			continue
+		}
+
+		return unit, strings.Contains(parts[2], "/user@")
+	}
+
+	return "", false
+}
+
+// stopService stops the systemd unit owning the process
+func stopService(p *Process) error {
+	args := []string{"stop", p.Service}
+	if p.UserService {
+		args = append([]string{"--user"}, args...)
+	}
+
+	output, err := command("systemctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl stop %s failed: %s", p.Service, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// lookupParent returns the parent PID and name of a process
+func lookupParent(pid int) (int, string, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, "", err
+	}
+
+	content := string(data)
+	fields, err := parseStatFields(content)
+	if err != nil {
+		return 0, "", err
+	}
+
+	ppid, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, "", err
+	}
+
+	name := ""
+	if open := strings.Index(content, "("); open >= 0 {
+		name = content[open+1 : strings.LastIndex(content, ")")]
+	}
+
+	return ppid, name, nil
+}
+
+// childPIDs returns the direct children of a process
+func childPIDs(pid int) []int {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+
+	var children []int
+	for _, entry := range entries {
+		childPID, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", childPID))
+		if err != nil {
+			continue
+		}
+
+		fields, err := parseStatFields(string(data))
+		if err != nil {
+			continue
+		}
+
+		// PPID is the 4th stat field
+		if ppid, err := strconv.Atoi(fields[1]); err == nil && ppid == pid {
+			children = append(children, childPID)
+		}
+	}
+
+	return children
+}
+
+// backends lists the socket tools available, ss being preferred, or the
+// kernel's socket table when there are none
+func backends() ([]string, bool) {
+	if found := installed("ss", "netstat"); len(found) > 0 {
+		return found, false
+	}
+	if _, err := os.Stat("/proc/net/tcp"); err == nil {
+		return []string{"/proc/net/tcp"}, true
+	}
+	return nil, false
+}
+
+// elevateHint tells how to see every user's processes
+const elevateHint = "rescan with sudo: portfinder list --sudo, or R in the list"
+
+// probeBackends checks each socket tool and the parts of /proc that
+// discovery reads
+func probeBackends() []Backend {
+	return []Backend{
+		probeTool("ss", "install iproute2"),
+		probeTool("netstat", "install net-tools"),
+		probeFile("/proc/net/tcp", "the kernel's socket table is masked, so ports can't be listed without ss or netstat"),
+		probeFile("/proc/self/stat", "/proc is masked, so processes can't be identified; mount it or run pf outside the sandbox"),
+	}
+}
+
+// seesAllUsers reports whether other users' sockets can be attributed to
+// processes, which ss and netstat only do for root
+func seesAllUsers() bool {
+	return os.Geteuid() == 0
+}
+
+// establishedPeers reads the remote addresses of established TCP
+// connections from /proc, keyed by local port; no tool is run
+func establishedPeers(ctx context.Context, run Runner) (map[int][]string, error) {
+	peers := make(map[int][]string)
+	read := false
+	for _, file := range []string{"/proc/net/tcp", "/proc/net/tcp6"} {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		read = true
+
+		lines := strings.Split(string(content), "\n")
+		for _, line := range lines[1:] {
+			fields := strings.Fields(line)
+			// sl local_address rem_address st ...; 01 is ESTABLISHED
+			if len(fields) < 4 || fields[3] != "01" {
+				continue
+			}
+			_, port, ok := parseProcAddress(fields[1])
+			if !ok {
+				continue
+			}
+			host, remotePort, ok := parseProcAddress(fields[2])
+			if !ok {
+				continue
+			}
+			peers[port] = append(peers[port], net.JoinHostPort(host, strconv.Itoa(remotePort)))
+		}
+	}
+
+	if !read {
+		return nil, fmt.Errorf("cannot read /proc/net/tcp")
+	}
+	return peers, nil
+}
+
+// parseProcAddress decodes a /proc/net/tcp address such as
+// "0100007F:0BB8", whose IP is stored as little-endian 32-bit words
+func parseProcAddress(s string) (string, int, bool) {
+	hexIP, hexPort, ok := strings.Cut(s, ":")
+	if !ok {
+		return "", 0, false
+	}
+	port, err := strconv.ParseUint(hexPort, 16, 16)
+	if err != nil {
+		return "", 0, false
+	}
+	raw, err := hex.DecodeString(hexIP)
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return "", 0, false
+	}
+
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		ip[i], ip[i+1], ip[i+2], ip[i+3] = raw[i+3], raw[i+2], raw[i+1], raw[i]
+	}
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	return ip.String(), int(port), true
+}
+
+// fillSocketOwners sets the user of listeners whose process is hidden from
+// the kernel's socket table, which records the uid of every socket
+func fillSocketOwners(processes []*Process) {
+	hidden := false
+	for _, p := range processes {
+		hidden = hidden || (p.SocketOnly && p.User == "")
+	}
+	if !hidden {
+		return
+	}
+
+	listeners, err := procNetListeners()
+	if err != nil {
+		return
+	}
+	owners := make(map[int]string, len(listeners))
+	for _, l := range listeners {
+		owners[l.Port] = l.User
+	}
+	for _, p := range processes {
+		if p.SocketOnly && p.User == "" {
+			p.User = owners[p.Port]
+		}
+	}
+}
+
+// procNetListeners lists listening TCP sockets from the kernel's socket
+// table, with the uid owning each but without processes: the fallback when
+// neither ss nor netstat can run
+func procNetListeners() ([]*Process, error) {
+	var processes []*Process
+	read := false
+	for _, file := range []string{"/proc/net/tcp", "/proc/net/tcp6"} {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		read = true
+
+		lines := strings.Split(string(content), "\n")
+		for _, line := range lines[1:] {
+			fields := strings.Fields(line)
+			// 0A is LISTEN
+			if len(fields) < 8 || fields[3] != "0A" {
+				continue
+			}
+			if host, port, ok := parseProcAddress(fields[1]); ok {
+				proc := unattributed(port, host)
+				proc.User = userName(fields[7])
+				processes = append(processes, proc)
+			}
+		}
+	}
+
+	if !read {
+		return nil, fmt.Errorf("no socket source available: ss and netstat failed and /proc/net/tcp is unreadable")
+	}
+	return processes, nil
 }
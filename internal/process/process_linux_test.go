@@ -0,0 +1,84 @@
+package process
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestListAllSS(t *testing.T) {
+	run := &fakeRunner{t: t, outputs: map[string]string{"ss -tulnp": "ss-tulnp.txt"}}
+	processes, err := (&platformFinder{run: run}).ListAll(context.Background())
+	if err != nil {
+		t.Fatalf("ListAll() error = %v", err)
+	}
+
+	// Names come from /proc rather than ss, and these PIDs aren't running
+	want := []fakeListener{
+		{PID: 3990612, Port: 53, Address: "127.0.0.53"},
+		{PID: 3991203, Port: 80, Address: "0.0.0.0"},
+		{PID: 3992019, Port: 3000, Address: "*"},
+		{PID: 3991455, Port: 5432, Address: "127.0.0.1"},
+		{Name: "(unknown)", Port: 6379, Address: "::1"},
+		{PID: 3992340, Port: 8080, Address: "::"},
+	}
+	if got := listeners(processes); !reflect.DeepEqual(got, want) {
+		t.Errorf("ListAll() = %+v, want %+v", got, want)
+	}
+	if run.ran("netstat -tulnp") {
+		t.Error("ListAll() ran netstat although ss worked")
+	}
+}
+
+func TestListAllNetstatFallback(t *testing.T) {
+	run := &fakeRunner{t: t, outputs: map[string]string{"netstat -tulnp": "netstat-tulnp.txt"}}
+	processes, err := (&platformFinder{run: run}).ListAll(context.Background())
+	if err != nil {
+		t.Fatalf("ListAll() error = %v", err)
+	}
+
+	want := []fakeListener{
+		{PID: 3990612, Name: "systemd-res", Port: 53, Address: "127.0.0.53"},
+		{PID: 3991201, Name: "nginx", Port: 80, Address: "0.0.0.0"},
+		{PID: 3992019, Name: "node", Port: 3000, Address: "::"},
+		{PID: 3991455, Name: "postgres", Port: 5432, Address: "127.0.0.1"},
+		{Name: "(unknown)", Port: 6379, Address: "::1"},
+	}
+	if got := listeners(processes); !reflect.DeepEqual(got, want) {
+		t.Errorf("ListAll() = %+v, want %+v", got, want)
+	}
+	if !run.ran("ss -tulnp") {
+		t.Error("ListAll() didn't try ss first")
+	}
+}
+
+func TestFindByPortSS(t *testing.T) {
+	run := &fakeRunner{t: t, outputs: map[string]string{
+		"ss -tulnp sport = :3000": "ss-tulnp-port.txt",
+		"netstat -tulnp":          "netstat-tulnp.txt",
+	}}
+	f := &platformFinder{run: run}
+
+	proc, err := f.FindByPort(context.Background(), 3000)
+	if err != nil {
+		t.Fatalf("FindByPort(3000) error = %v", err)
+	}
+	if proc == nil || proc.PID != 3992019 || proc.Port != 3000 {
+		t.Fatalf("FindByPort(3000) = %+v, want PID 3992019", proc)
+	}
+
+	// Neither ss nor netstat list anything on a free port
+	proc, err = f.FindByPort(context.Background(), 4000)
+	if err != nil || proc != nil {
+		t.Errorf("FindByPort(4000) = %+v, %v, want nothing", proc, err)
+	}
+}
+
+func TestEnrichProcessInfoArgs(t *testing.T) {
+	proc := &Process{PID: os.Getpid()}
+	(&platformFinder{run: &fakeRunner{t: t}}).enrichProcessInfo(proc)
+	if !reflect.DeepEqual(proc.Args, os.Args) {
+		t.Errorf("Args = %q, want %q", proc.Args, os.Args)
+	}
+}
@@ -3,75 +3,93 @@
 package process
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
-	"os/exec"
-	"regexp"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
 )
 
-type platformFinder struct{}
+type platformFinder struct {
+	run Runner
+}
 
-func (f *platformFinder) FindByPort(port int) (*Process, error) {
+func (f *platformFinder) FindByPort(ctx context.Context, port int) (*Process, error) {
 	// Use netstat on Windows to find process by port
-	cmd := exec.Command("netstat", "-ano", "-p", "tcp")
-	output, err := cmd.Output()
+	output, err := f.run.Output(ctx, "netstat", "-ano", "-p", "tcp")
 	if err != nil {
 		return nil, fmt.Errorf("netstat failed: %w", err)
 	}
 
-	pid := f.findPIDByPort(string(output), port)
+	pid, address := f.findPIDByPort(string(output), port)
 	if pid == 0 {
 		return nil, nil // Port not in use
 	}
 
 	// Get process details
-	return f.getProcessDetails(pid, port)
+	proc, err := f.getProcessDetails(ctx, pid, port)
+	if proc != nil {
+		proc.Address = address
+	}
+	return proc, err
+}
+
+// FindByPorts lists all listeners once rather than looking each port up
+func (f *platformFinder) FindByPorts(ctx context.Context, ports []int) (map[int]*Process, error) {
+	processes, err := f.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ByPorts(processes, ports), nil
 }
 
-func (f *platformFinder) ListAll() ([]*Process, error) {
-	cmd := exec.Command("netstat", "-ano", "-p", "tcp")
-	output, err := cmd.Output()
+func (f *platformFinder) ListAll(ctx context.Context) ([]*Process, error) {
+	output, err := f.run.Output(ctx, "netstat", "-ano", "-p", "tcp")
 	if err != nil {
 		return nil, fmt.Errorf("netstat failed: %w", err)
 	}
 
-	return f.parseNetstatOutput(string(output))
+	return f.parseNetstatOutput(ctx, string(output))
 }
 
-func (f *platformFinder) findPIDByPort(output string, port int) int {
+func (f *platformFinder) findPIDByPort(output string, port int) (int, string) {
 	lines := strings.Split(output, "\n")
-	portPattern := fmt.Sprintf(`:%d\s+`, port)
-	re := regexp.MustCompile(portPattern)
 
 	for _, line := range lines {
 		if !strings.Contains(line, "LISTENING") {
 			continue
 		}
 
-		if re.MatchString(line) {
-			// Extract PID from the end of the line
-			fields := strings.Fields(line)
-			if len(fields) >= 5 {
-				pid, err := strconv.Atoi(fields[len(fields)-1])
-				if err == nil {
-					return pid
-				}
-			}
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+
+		// Match the local address only, not the foreign one
+		host, localPort, ok := splitAddress(fields[1])
+		if !ok || localPort != port {
+			continue
+		}
+
+		// Extract PID from the end of the line
+		if pid, err := strconv.Atoi(fields[len(fields)-1]); err == nil {
+			return pid, host
 		}
 	}
 
-	return 0
+	return 0, ""
 }
 
-func (f *platformFinder) parseNetstatOutput(output string) ([]*Process, error) {
+func (f *platformFinder) parseNetstatOutput(ctx context.Context, output string) ([]*Process, error) {
 	lines := strings.Split(output, "\n")
 	processMap := make(map[string]*Process)
 
-	// Regex to match port number in address (e.g., 0.0.0.0:3000 or 127.0.0.1:8080)
-	portRegex := regexp.MustCompile(`:(\d+)\s+`)
-
 	for _, line := range lines {
 		if !strings.Contains(line, "LISTENING") {
 			continue
@@ -79,23 +97,24 @@ func (f *platformFinder) parseNetstatOutput(output string) ([]*Process, error) {
 
 		fields := strings.Fields(line)
 		if len(fields) < 5 {
+			logUnparsed("netstat", line, "too few fields")
 			continue
 		}
 
-		// Extract port from local address
-		matches := portRegex.FindStringSubmatch(fields[1])
-		if len(matches) < 2 {
+		// Extract port from local address (e.g. 0.0.0.0:3000 or [::]:8080)
+		host, port, ok := splitAddress(fields[1])
+		if !ok {
+			logUnparsed("netstat", line, "no port in the local address")
 			continue
 		}
 
-		port, err := strconv.Atoi(matches[1])
+		// Extract PID
+		pid, err := strconv.Atoi(fields[len(fields)-1])
 		if err != nil {
+			logUnparsed("netstat", line, "invalid PID")
 			continue
 		}
-
-		// Extract PID
-		pid, err := strconv.Atoi(fields[len(fields)-1])
-		if err != nil || pid == 0 {
+		if pid == 0 {
 			continue
 		}
 
@@ -104,10 +123,11 @@ func (f *platformFinder) parseNetstatOutput(output string) ([]*Process, error) {
 			continue
 		}
 
-		proc, err := f.getProcessDetails(pid, port)
+		proc, err := f.getProcessDetails(ctx, pid, port)
 		if err != nil || proc == nil {
 			continue
 		}
+		proc.Address = host
 
 		processMap[key] = proc
 	}
@@ -120,7 +140,7 @@ func (f *platformFinder) parseNetstatOutput(output string) ([]*Process, error) {
 	return processes, nil
 }
 
-func (f *platformFinder) getProcessDetails(pid int, port int) (*Process, error) {
+func (f *platformFinder) getProcessDetails(ctx context.Context, pid int, port int) (*Process, error) {
 	if pid == 0 {
 		return nil, nil
 	}
@@ -131,8 +151,7 @@ func (f *platformFinder) getProcessDetails(pid int, port int) (*Process, error)
 	}
 
 	// Get process name and details using tasklist
-	cmd := exec.Command("tasklist", "/FI", fmt.Sprintf("PID eq %d", pid), "/FO", "CSV", "/V")
-	output, err := cmd.Output()
+	output, err := f.run.Output(ctx, "tasklist", "/FI", fmt.Sprintf("PID eq %d", pid), "/FO", "CSV", "/V")
 	if err != nil {
 		return nil, fmt.Errorf("tasklist failed: %w", err)
 	}
@@ -153,9 +172,16 @@ func (f *platformFinder) getProcessDetails(pid int, port int) (*Process, error)
 		fields := f.parseCSVLine(line)
 		if len(fields) >= 9 {
 			proc.Name = strings.Trim(fields[0], "\"")
+			if user := strings.Trim(fields[6], "\""); user != "N/A" {
+				proc.User = user
+			}
 
 			// Try to get command line using wmic
-			f.enrichProcessInfo(proc)
+			f.enrichProcessInfo(ctx, proc)
+
+			if ppid, _, err := lookupParent(pid); err == nil {
+				proc.PPID = ppid
+			}
 
 			return proc, nil
 		}
@@ -193,44 +219,29 @@ func (f *platformFinder) parseCSVLine(line string) []string {
 	return fields
 }
 
-func (f *platformFinder) enrichProcessInfo(proc *Process) {
-	// Get command line using wmic
-	cmd := exec.Command("wmic", "process", "where", fmt.Sprintf("ProcessId=%d", proc.PID), "get", "CommandLine", "/format:list")
-	output, err := cmd.Output()
-	if err == nil {
-		lines := strings.Split(string(output), "\n")
-		for _, line := range lines {
-			if strings.HasPrefix(line, "CommandLine=") {
-				proc.Command = strings.TrimPrefix(line, "CommandLine=")
-				proc.Command = strings.TrimSpace(proc.Command)
-				break
-			}
-		}
+func (f *platformFinder) enrichProcessInfo(ctx context.Context, proc *Process) {
+	// Executable path and start time come straight from the Windows API
+	if exePath, startTime, err := nativeProcessInfo(proc.PID); err == nil {
+		proc.ExePath = exePath
+		proc.StartTime = startTime
 	}
 
-	// Get process start time
-	cmd = exec.Command("wmic", "process", "where", fmt.Sprintf("ProcessId=%d", proc.PID), "get", "CreationDate", "/format:list")
-	output, err = cmd.Output()
-	if err == nil {
-		lines := strings.Split(string(output), "\n")
-		for _, line := range lines {
-			if strings.HasPrefix(line, "CreationDate=") {
-				dateStr := strings.TrimPrefix(line, "CreationDate=")
-				dateStr = strings.TrimSpace(dateStr)
-				// Parse WMI datetime format: 20231228103045.123456+060
-				if len(dateStr) >= 14 {
-					year, _ := strconv.Atoi(dateStr[0:4])
-					month, _ := strconv.Atoi(dateStr[4:6])
-					day, _ := strconv.Atoi(dateStr[6:8])
-					hour, _ := strconv.Atoi(dateStr[8:10])
-					minute, _ := strconv.Atoi(dateStr[10:12])
-					second, _ := strconv.Atoi(dateStr[12:14])
-
-					proc.StartTime = time.Date(year, time.Month(month), day, hour, minute, second, 0, time.Local)
-				}
-				break
+	// The command line lives in the target's memory, so ask CIM for it,
+	// falling back to wmic on systems without PowerShell
+	if info, err := f.cimProcessInfo(ctx, proc.PID); err == nil {
+		proc.Command = strings.TrimSpace(info.CommandLine)
+		proc.Args = splitCommandLine(proc.Command)
+		if proc.ExePath == "" {
+			proc.ExePath = info.ExecutablePath
+		}
+		if proc.StartTime.IsZero() {
+			if t, err := time.Parse(time.RFC3339Nano, info.CreationDate); err == nil {
+				proc.StartTime = t
 			}
 		}
+	} else if command := f.wmicValue(ctx, proc.PID, "CommandLine"); command != "" {
+		proc.Command = command
+		proc.Args = splitCommandLine(command)
 	}
 
 	// If start time is not set, use current time as fallback
@@ -238,21 +249,10 @@ func (f *platformFinder) enrichProcessInfo(proc *Process) {
 		proc.StartTime = time.Now()
 	}
 
-	// Get working directory (more complex on Windows, using current directory as fallback)
-	cmd = exec.Command("wmic", "process", "where", fmt.Sprintf("ProcessId=%d", proc.PID), "get", "ExecutablePath", "/format:list")
-	output, err = cmd.Output()
-	if err == nil {
-		lines := strings.Split(string(output), "\n")
-		for _, line := range lines {
-			if strings.HasPrefix(line, "ExecutablePath=") {
-				exePath := strings.TrimPrefix(line, "ExecutablePath=")
-				exePath = strings.TrimSpace(exePath)
-				if exePath != "" {
-					proc.ProjectPath = detectProject(proc.PID, exePath)
-				}
-				break
-			}
-		}
+	// Windows doesn't expose another process's working directory, so the
+	// executable location is the best project hint
+	if proc.ExePath != "" {
+		proc.ProjectPath = detectProject(proc.PID, filepath.Dir(proc.ExePath))
 	}
 
 	// If project path is still empty, try to detect from command
@@ -274,4 +274,192 @@ func (f *platformFinder) enrichProcessInfo(proc *Process) {
 		strings.Contains(strings.ToLower(proc.Command), "docker") {
 		proc.IsDocker = true
 	}
+
+	// Check if run by the service control manager
+	proc.Service = windowsService(proc.PID)
+}
+
+// lookupParent returns the parent PID and name of a process
+func lookupParent(pid int) (int, string, error) {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return 0, "", err
+	}
+	defer windows.CloseHandle(snapshot)
+
+	var entry windows.ProcessEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+
+	for err = windows.Process32First(snapshot, &entry); err == nil; err = windows.Process32Next(snapshot, &entry) {
+		if int(entry.ProcessID) == pid {
+			return int(entry.ParentProcessID), windows.UTF16ToString(entry.ExeFile[:]), nil
+		}
+	}
+
+	return 0, "", fmt.Errorf("process %d not found", pid)
+}
+
+// childPIDs returns the direct children of a process
+func childPIDs(pid int) []int {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil
+	}
+	defer windows.CloseHandle(snapshot)
+
+	var entry windows.ProcessEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+
+	var children []int
+	for err = windows.Process32First(snapshot, &entry); err == nil; err = windows.Process32Next(snapshot, &entry) {
+		if int(entry.ParentProcessID) == pid && int(entry.ProcessID) != pid {
+			children = append(children, int(entry.ProcessID))
+		}
+	}
+
+	return children
+}
+
+// nativeProcessInfo reads the executable path and creation time of a
+// process through the Windows API
+func nativeProcessInfo(pid int) (string, time.Time, error) {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer windows.CloseHandle(handle)
+
+	buf := make([]uint16, windows.MAX_LONG_PATH)
+	size := uint32(len(buf))
+	if err := windows.QueryFullProcessImageName(handle, 0, &buf[0], &size); err != nil {
+		return "", time.Time{}, err
+	}
+	exePath := windows.UTF16ToString(buf[:size])
+
+	var creation, exit, kernel, user windows.Filetime
+	if err := windows.GetProcessTimes(handle, &creation, &exit, &kernel, &user); err != nil {
+		return exePath, time.Time{}, err
+	}
+
+	return exePath, time.Unix(0, creation.Nanoseconds()), nil
+}
+
+// splitCommandLine splits a command line into arguments the way the
+// programs started with it do
+func splitCommandLine(line string) []string {
+	if line == "" {
+		return nil
+	}
+	utf16, err := windows.UTF16PtrFromString(line)
+	if err != nil {
+		return nil
+	}
+	var argc int32
+	argv, err := windows.CommandLineToArgv(utf16, &argc)
+	if err != nil {
+		return nil
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(argv)))
+
+	args := make([]string, argc)
+	for i := range args {
+		args[i] = windows.UTF16PtrToString(&argv[i][0])
+	}
+	return args
+}
+
+// cimProcess holds the Win32_Process properties read through PowerShell
+type cimProcess struct {
+	CommandLine    string `json:"CommandLine"`
+	ExecutablePath string `json:"ExecutablePath"`
+	CreationDate   string `json:"CreationDate"`
+}
+
+// cimProcessInfo queries Win32_Process through PowerShell's CIM cmdlets,
+// the supported replacement for wmic
+func (f *platformFinder) cimProcessInfo(ctx context.Context, pid int) (*cimProcess, error) {
+	script := fmt.Sprintf("Get-CimInstance Win32_Process -Filter 'ProcessId=%d' | "+
+		"Select-Object CommandLine,ExecutablePath,@{n='CreationDate';e={$_.CreationDate.ToString('o')}} | "+
+		"ConvertTo-Json -Compress", pid)
+
+	output, err := f.run.Output(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	if err != nil {
+		return nil, fmt.Errorf("powershell failed: %w", err)
+	}
+
+	output = bytes.TrimSpace(output)
+	if len(output) == 0 {
+		return nil, fmt.Errorf("no process found for PID %d", pid)
+	}
+
+	var info cimProcess
+	if err := json.Unmarshal(output, &info); err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// wmicValue reads a single Win32_Process property with the legacy wmic tool
+func (f *platformFinder) wmicValue(ctx context.Context, pid int, property string) string {
+	output, err := f.run.Output(ctx, "wmic", "process", "where", fmt.Sprintf("ProcessId=%d", pid), "get", property, "/format:list")
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.HasPrefix(line, property+"=") {
+			return strings.TrimSpace(strings.TrimPrefix(line, property+"="))
+		}
+	}
+
+	return ""
+}
+
+// backends lists the socket tools available
+func backends() ([]string, bool) {
+	return installed("netstat"), false
+}
+
+// elevateHint tells how to see every user's processes
+const elevateHint = "run pf from a terminal opened with Run as administrator"
+
+// probeBackends checks netstat and the Windows process API
+func probeBackends() []Backend {
+	return []Backend{
+		probeTool("netstat", "netstat ships with Windows; check that System32 is on your PATH"),
+		{Name: "Windows API", Available: true},
+	}
+}
+
+// seesAllUsers reports whether pf runs elevated, without which the details
+// of other users' and system processes can't be read
+func seesAllUsers() bool {
+	return windows.GetCurrentProcessToken().IsElevated()
+}
+
+// establishedPeers lists the remote addresses of established TCP
+// connections with netstat, keyed by local port
+func establishedPeers(ctx context.Context, run Runner) (map[int][]string, error) {
+	output, err := run.Output(ctx, "netstat", "-ano", "-p", "tcp")
+	if err != nil {
+		return nil, fmt.Errorf("netstat failed: %w", err)
+	}
+	return parseNetstatPeers(string(output)), nil
+}
+
+// parseNetstatPeers parses netstat -ano output
+func parseNetstatPeers(output string) map[int][]string {
+	peers := make(map[int][]string)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		// Proto, Local Address, Foreign Address, State, PID
+		if len(fields) < 5 || fields[3] != "ESTABLISHED" {
+			continue
+		}
+		if _, port, ok := splitAddress(fields[1]); ok {
+			peers[port] = append(peers[port], fields[2])
+		}
+	}
+	return peers
 }
@@ -0,0 +1,48 @@
+package process
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestFindByPortNetstat(t *testing.T) {
+	run := &fakeRunner{t: t, outputs: map[string]string{
+		"netstat -ano -p tcp":                  "netstat-ano.txt",
+		"tasklist /FI PID eq 14820 /FO CSV /V": "tasklist-v-14820.txt",
+	}}
+	f := &platformFinder{run: run}
+
+	proc, err := f.FindByPort(context.Background(), 3000)
+	if err != nil {
+		t.Fatalf("FindByPort(3000) error = %v", err)
+	}
+	got := fakeListener{PID: proc.PID, Name: proc.Name, Port: proc.Port, Address: proc.Address}
+	if want := (fakeListener{PID: 14820, Name: "node.exe", Port: 3000, Address: "0.0.0.0"}); got != want {
+		t.Errorf("FindByPort(3000) = %+v, want %+v", got, want)
+	}
+	if proc.User != `DESKTOP-7Q2LK\dev` {
+		t.Errorf("FindByPort(3000) user = %q, want DESKTOP-7Q2LK\\dev", proc.User)
+	}
+
+	// Ports that only show up in connections aren't listened on
+	if proc, err := f.FindByPort(context.Background(), 52114); err != nil || proc != nil {
+		t.Errorf("FindByPort(52114) = %+v, %v, want nothing", proc, err)
+	}
+}
+
+func TestLoadConnectionsNetstat(t *testing.T) {
+	run := &fakeRunner{t: t, outputs: map[string]string{"netstat -ano -p tcp": "netstat-ano.txt"}}
+	node := &Process{PID: 14820, Port: 3000}
+	postgres := &Process{PID: 6244, Port: 5432}
+	if err := LoadConnections(context.Background(), run, []*Process{node, postgres}); err != nil {
+		t.Fatalf("LoadConnections() error = %v", err)
+	}
+
+	if want := []string{"127.0.0.1:52114", "127.0.0.1:52118", "[::1]:52301"}; !reflect.DeepEqual(node.Peers, want) {
+		t.Errorf("node peers = %v, want %v", node.Peers, want)
+	}
+	if want := []string{"192.168.1.31:61022"}; !reflect.DeepEqual(postgres.Peers, want) {
+		t.Errorf("postgres peers = %v, want %v", postgres.Peers, want)
+	}
+}
@@ -0,0 +1,235 @@
+package process
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ProjectDetector recognizes one kind of project by the files at its root
+type ProjectDetector interface {
+	// Type names the kind of project, e.g. "Node.js"
+	Type() string
+	// Detect reports whether dir is the root of such a project, with the
+	// name its manifest declares, or "" when it declares none
+	Detect(dir string) (name string, ok bool)
+}
+
+// NodeDetector recognizes package.json projects
+type NodeDetector struct{}
+
+// GoDetector recognizes Go modules
+type GoDetector struct{}
+
+// RustDetector recognizes Cargo packages and workspaces
+type RustDetector struct{}
+
+// PythonDetector recognizes pyproject.toml, setup.py, setup.cfg and
+// requirements.txt projects
+type PythonDetector struct{}
+
+// JVMDetector recognizes Maven and Gradle builds
+type JVMDetector struct{}
+
+// RubyDetector recognizes Bundler projects
+type RubyDetector struct{}
+
+// GitDetector recognizes any git repository, for projects of other kinds
+type GitDetector struct{}
+
+// Detectors lists the project detectors in the order they are tried, so
+// a language is preferred over the bare git repository it lives in
+var Detectors = []ProjectDetector{
+	NodeDetector{},
+	GoDetector{},
+	RustDetector{},
+	PythonDetector{},
+	JVMDetector{},
+	RubyDetector{},
+	GitDetector{},
+}
+
+// RegisterDetector adds a detector, tried before the built-in ones
+func RegisterDetector(d ProjectDetector) {
+	Detectors = append([]ProjectDetector{d}, Detectors...)
+}
+
+// identifyProject returns the name and type of the project rooted at dir,
+// falling back to the directory name for projects without a declared name
+func identifyProject(dir string) (string, string, bool) {
+	for _, d := range Detectors {
+		if name, ok := d.Detect(dir); ok {
+			if name == "" {
+				name = filepath.Base(dir)
+			}
+			return name, d.Type(), true
+		}
+	}
+	return "", "", false
+}
+
+// identifyProjects fills in the project name and type of the processes,
+// reading each project root once
+func identifyProjects(processes []*Process) {
+	type identity struct{ name, kind string }
+	seen := make(map[string]identity)
+	for _, p := range processes {
+		if !filepath.IsAbs(p.ProjectPath) {
+			continue
+		}
+		id, ok := seen[p.ProjectPath]
+		if !ok {
+			id.name, id.kind, _ = identifyProject(p.ProjectPath)
+			seen[p.ProjectPath] = id
+		}
+		p.ProjectName, p.ProjectType = id.name, id.kind
+	}
+}
+
+func (NodeDetector) Type() string { return "Node.js" }
+
+func (NodeDetector) Detect(dir string) (string, bool) {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return "", false
+	}
+	var pkg struct {
+		Name string `json:"name"`
+	}
+	json.Unmarshal(data, &pkg)
+	return pkg.Name, true
+}
+
+func (GoDetector) Type() string { return "Go" }
+
+func (GoDetector) Detect(dir string) (string, bool) {
+	module, ok := firstMatch(filepath.Join(dir, "go.mod"), goModule)
+	if !ok {
+		return "", false
+	}
+	// github.com/org/app/v2 is named app
+	name := path.Base(module)
+	if majorVersion.MatchString(name) {
+		name = path.Base(path.Dir(module))
+	}
+	return name, true
+}
+
+func (RustDetector) Type() string { return "Rust" }
+
+func (RustDetector) Detect(dir string) (string, bool) {
+	return tomlName(filepath.Join(dir, "Cargo.toml"), "package")
+}
+
+func (PythonDetector) Type() string { return "Python" }
+
+func (PythonDetector) Detect(dir string) (string, bool) {
+	if name, ok := tomlName(filepath.Join(dir, "pyproject.toml"), "project", "tool.poetry"); ok {
+		return name, true
+	}
+	if name, ok := tomlName(filepath.Join(dir, "setup.cfg"), "metadata"); ok {
+		return name, true
+	}
+	return "", exists(dir, "setup.py", "requirements.txt", "Pipfile")
+}
+
+func (JVMDetector) Type() string { return "JVM" }
+
+func (JVMDetector) Detect(dir string) (string, bool) {
+	if data, err := os.ReadFile(filepath.Join(dir, "pom.xml")); err == nil {
+		// The parent's artifactId comes first in many poms
+		pom := pomParent.ReplaceAllString(string(data), "")
+		if m := pomArtifact.FindStringSubmatch(pom); m != nil {
+			return m[1], true
+		}
+		return "", true
+	}
+	for _, settings := range []string{"settings.gradle", "settings.gradle.kts"} {
+		if name, ok := firstMatch(filepath.Join(dir, settings), gradleName); ok {
+			return name, true
+		}
+	}
+	return "", exists(dir, "build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts")
+}
+
+func (RubyDetector) Type() string { return "Ruby" }
+
+func (RubyDetector) Detect(dir string) (string, bool) {
+	return "", exists(dir, "Gemfile")
+}
+
+func (GitDetector) Type() string { return "Git" }
+
+func (GitDetector) Detect(dir string) (string, bool) {
+	return "", exists(dir, ".git")
+}
+
+var (
+	goModule     = regexp.MustCompile(`^module\s+"?([^\s"]+)"?`)
+	majorVersion = regexp.MustCompile(`^v[0-9]+$`)
+	gradleName   = regexp.MustCompile(`rootProject\.name\s*=\s*["']([^"']+)["']`)
+	pomParent    = regexp.MustCompile(`(?s)<parent>.*?</parent>`)
+	pomArtifact  = regexp.MustCompile(`<artifactId>\s*([^<\s]+)\s*</artifactId>`)
+	tomlKeyName  = regexp.MustCompile(`^name\s*=\s*["']?([^"'\s]+)["']?`)
+)
+
+// exists reports whether any of the files exists in dir
+func exists(dir string, names ...string) bool {
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// firstMatch returns the first submatch of re in the lines of a file,
+// reporting whether the file exists
+func firstMatch(file string, re *regexp.Regexp) (string, bool) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if m := re.FindStringSubmatch(strings.TrimSpace(scanner.Text())); m != nil {
+			return m[1], true
+		}
+	}
+	return "", true
+}
+
+// tomlName reads the name key of the first of the given sections in a
+// TOML or INI file, reporting whether the file exists
+func tomlName(file string, sections ...string) (string, bool) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			section = strings.Trim(line, "[] ")
+			continue
+		}
+		for _, s := range sections {
+			if section != s {
+				continue
+			}
+			if m := tomlKeyName.FindStringSubmatch(line); m != nil {
+				return m[1], true
+			}
+		}
+	}
+	return "", true
+}
@@ -0,0 +1,309 @@
+package process
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// protocolProbe recognizes a protocol by talking it to a port
+type protocolProbe struct {
+	// ports are where the protocol usually listens, so it is tried first
+	// there
+	ports []int
+	probe func(addr string, timeout time.Duration) (*Fingerprint, error)
+}
+
+// protocolProbes are tried in order, after the ones for the port. HTTP
+// comes first, as most dev servers speak it.
+var protocolProbes = []protocolProbe{
+	{probe: probeHTTP},
+	{ports: []int{5432}, probe: probePostgres},
+	{ports: []int{6379}, probe: probeRedis},
+	{ports: []int{27017}, probe: probeMongo},
+	{ports: []int{3306, 22, 2222}, probe: probeGreeting},
+	{ports: []int{50051}, probe: probeH2C},
+}
+
+// orderedProbes returns the protocol probes, those for the port first
+func orderedProbes(port int) []protocolProbe {
+	var first, rest []protocolProbe
+	for _, pp := range protocolProbes {
+		if containsPort(pp.ports, port) {
+			first = append(first, pp)
+		} else {
+			rest = append(rest, pp)
+		}
+	}
+	return append(first, rest...)
+}
+
+func containsPort(ports []int, port int) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
+
+// dialProbe connects to addr with every read and write bounded by timeout
+func dialProbe(addr string, timeout time.Duration) (net.Conn, error) {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+	return conn, nil
+}
+
+// probePostgres asks for TLS, which a Postgres server answers with a single
+// S or N, then, over plain connections, logs in as postgres to read the
+// server version, which only trusting servers reveal
+func probePostgres(addr string, timeout time.Duration) (*Fingerprint, error) {
+	conn, err := dialProbe(addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	sslRequest := []byte{0, 0, 0, 8, 0x04, 0xd2, 0x16, 0x2f}
+	if _, err := conn.Write(sslRequest); err != nil {
+		return nil, err
+	}
+	answer := make([]byte, 1)
+	if _, err := io.ReadFull(conn, answer); err != nil {
+		return nil, err
+	}
+	f := &Fingerprint{Protocol: "postgres", Product: "Postgres"}
+	switch answer[0] {
+	case 'S':
+		return f, nil
+	case 'N':
+	default:
+		return nil, fmt.Errorf("not postgres")
+	}
+
+	var startup bytes.Buffer
+	binary.Write(&startup, binary.BigEndian, int32(196608))
+	startup.WriteString("user\x00postgres\x00database\x00postgres\x00\x00")
+	message := binary.BigEndian.AppendUint32(nil, uint32(startup.Len()+4))
+	if _, err := conn.Write(append(message, startup.Bytes()...)); err != nil {
+		return f, nil
+	}
+
+	reader := bufio.NewReader(conn)
+	for {
+		kind, body, err := readPostgresMessage(reader)
+		if err != nil {
+			return f, nil
+		}
+		switch kind {
+		case 'R':
+			// Anything but AuthenticationOk asks for credentials
+			if len(body) < 4 || binary.BigEndian.Uint32(body) != 0 {
+				return f, nil
+			}
+		case 'S':
+			parts := bytes.Split(body, []byte{0})
+			if len(parts) >= 2 && string(parts[0]) == "server_version" {
+				f.Version = strings.Fields(string(parts[1]) + " ")[0]
+			}
+		case 'Z', 'E':
+			conn.Write([]byte{'X', 0, 0, 0, 4})
+			return f, nil
+		}
+	}
+}
+
+// readPostgresMessage reads a backend message's type and body
+func readPostgresMessage(r *bufio.Reader) (byte, []byte, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+	length := binary.BigEndian.Uint32(header[1:])
+	if length < 4 || length > 1<<16 {
+		return 0, nil, fmt.Errorf("unexpected message length %d", length)
+	}
+	body := make([]byte, length-4)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header[0], body, nil
+}
+
+// probeRedis asks for the server section of INFO, whose redis_version a
+// server answers with unless it requires a password
+func probeRedis(addr string, timeout time.Duration) (*Fingerprint, error) {
+	conn, err := dialProbe(addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("*2\r\n$4\r\nINFO\r\n$6\r\nserver\r\n")); err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReader(io.LimitReader(conn, probeBodyLimit))
+	first, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Fingerprint{Protocol: "redis", Product: "Redis"}
+	switch {
+	case strings.HasPrefix(first, "-NOAUTH"), strings.HasPrefix(first, "-DENIED"):
+		return f, nil
+	case !strings.HasPrefix(first, "$"):
+		return nil, fmt.Errorf("not redis")
+	}
+	fields := make(map[string]string)
+	for {
+		line, err := reader.ReadString('\n')
+		if key, value, ok := strings.Cut(strings.TrimSpace(line), ":"); ok {
+			fields[key] = value
+		}
+		if err != nil || strings.TrimSpace(line) == "" && len(fields) > 0 {
+			break
+		}
+	}
+	f.Version = fields["redis_version"]
+	if fields["server_name"] == "valkey" {
+		f.Product, f.Version = "Valkey", fields["valkey_version"]
+	}
+	return f, nil
+}
+
+// probeMongo sends the buildInfo command, which MongoDB answers without
+// authentication, with the server version
+func probeMongo(addr string, timeout time.Duration) (*Fingerprint, error) {
+	conn, err := dialProbe(addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// {buildInfo: 1, $db: "admin"}
+	var doc bytes.Buffer
+	doc.WriteString("\x10buildInfo\x00")
+	binary.Write(&doc, binary.LittleEndian, int32(1))
+	doc.WriteString("\x02$db\x00")
+	binary.Write(&doc, binary.LittleEndian, int32(len("admin")+1))
+	doc.WriteString("admin\x00\x00")
+	bson := binary.LittleEndian.AppendUint32(nil, uint32(doc.Len()+4))
+	bson = append(bson, doc.Bytes()...)
+
+	// An OP_MSG with the document as its body section
+	const opMsg = 2013
+	var msg bytes.Buffer
+	binary.Write(&msg, binary.LittleEndian, []int32{int32(16 + 4 + 1 + len(bson)), 1, 0, opMsg, 0})
+	msg.WriteByte(0)
+	msg.Write(bson)
+	if _, err := conn.Write(msg.Bytes()); err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, err
+	}
+	length := binary.LittleEndian.Uint32(header)
+	if binary.LittleEndian.Uint32(header[12:]) != opMsg || length < 16 || length > probeBodyLimit {
+		return nil, fmt.Errorf("not mongodb")
+	}
+	reply := make([]byte, length-16)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return nil, err
+	}
+	f := &Fingerprint{Protocol: "mongodb", Product: "MongoDB"}
+	// The version is a string element of the reply's document
+	if i := bytes.Index(reply, []byte("\x02version\x00")); i >= 0 && i+14 <= len(reply) {
+		start := i + 10
+		size := int(binary.LittleEndian.Uint32(reply[start:]))
+		if size > 1 && start+4+size <= len(reply) {
+			f.Version = string(reply[start+4 : start+4+size-1])
+		}
+	}
+	return f, nil
+}
+
+// probeGreeting reads what servers that speak first send on connect,
+// recognizing the MySQL handshake and SSH banners
+func probeGreeting(addr string, timeout time.Duration) (*Fingerprint, error) {
+	conn, err := dialProbe(addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	greeting := make([]byte, 256)
+	n, err := conn.Read(greeting)
+	if n == 0 {
+		return nil, fmt.Errorf("no greeting: %v", err)
+	}
+	greeting = greeting[:n]
+
+	if banner, ok := bytes.CutPrefix(greeting, []byte("SSH-")); ok {
+		// e.g. SSH-2.0-OpenSSH_9.6p1 Ubuntu-3ubuntu13
+		f := &Fingerprint{Protocol: "ssh", Product: "SSH"}
+		if fields := strings.Fields(string(banner)); len(fields) > 0 {
+			_, f.Version, _ = strings.Cut(fields[0], "-")
+		}
+		return f, nil
+	}
+
+	// A MySQL packet: a 3 byte length, a sequence number of 0, then
+	// protocol version 10 and the server version, or an error packet
+	length := int(greeting[0]) | int(greeting[1])<<8 | int(greeting[2])<<16
+	if len(greeting) < 6 || greeting[3] != 0 || length < 2 || length > 1024 {
+		return nil, fmt.Errorf("unknown greeting")
+	}
+	f := &Fingerprint{Protocol: "mysql", Product: "MySQL"}
+	switch greeting[4] {
+	case 0xff:
+		return f, nil
+	case 10:
+	default:
+		return nil, fmt.Errorf("unknown greeting")
+	}
+	version, _, _ := bytes.Cut(greeting[5:], []byte{0})
+	f.Version = string(version)
+	// MariaDB prefixes its version for old clients, e.g. 5.5.5-10.11.2-MariaDB
+	if strings.Contains(f.Version, "MariaDB") {
+		f.Product = "MariaDB"
+		f.Version = strings.TrimPrefix(f.Version, "5.5.5-")
+		f.Version, _, _ = strings.Cut(f.Version, "-")
+	}
+	return f, nil
+}
+
+// probeH2C sends the HTTP/2 connection preface, which a server speaking
+// HTTP/2 without TLS, as gRPC servers do, answers with a SETTINGS frame
+func probeH2C(addr string, timeout time.Duration) (*Fingerprint, error) {
+	conn, err := dialProbe(addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	preface := "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+	// An empty SETTINGS frame
+	settings := []byte{0, 0, 0, 4, 0, 0, 0, 0, 0}
+	if _, err := conn.Write(append([]byte(preface), settings...)); err != nil {
+		return nil, err
+	}
+	frame := make([]byte, 9)
+	if _, err := io.ReadFull(conn, frame); err != nil {
+		return nil, err
+	}
+	if frame[3] != 4 {
+		return nil, fmt.Errorf("not http/2")
+	}
+	return &Fingerprint{Protocol: "h2c", Product: "gRPC/h2"}, nil
+}
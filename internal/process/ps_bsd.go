@@ -0,0 +1,165 @@
+//go:build darwin || freebsd || openbsd
+
+package process
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// netstatListeners lists listening TCP sockets without owners, the
+// fallback when the tools revealing processes can't be used
+func netstatListeners(ctx context.Context, run Runner) ([]*Process, error) {
+	output, err := run.Output(ctx, "netstat", "-an", "-p", "tcp")
+	if err != nil {
+		return nil, fmt.Errorf("netstat failed: %w", err)
+	}
+	return parseNetstatListeners(string(output)), nil
+}
+
+// parseNetstatListeners parses BSD netstat -an output
+func parseNetstatListeners(output string) []*Process {
+	var processes []*Process
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		// Proto Recv-Q Send-Q Local Foreign (state); addresses are "host.port"
+		if len(fields) < 6 || fields[5] != "LISTEN" {
+			continue
+		}
+		i := strings.LastIndex(fields[3], ".")
+		if i < 0 {
+			continue
+		}
+		port, err := strconv.Atoi(fields[3][i+1:])
+		if err != nil {
+			continue
+		}
+		processes = append(processes, unattributed(port, fields[3][:i]))
+	}
+	return processes
+}
+
+// psParent returns the parent PID and name of a process with ps
+func psParent(ctx context.Context, run Runner, pid int) (int, string, error) {
+	output, err := run.Output(ctx, "ps", "-o", "ppid=,comm=", "-p", strconv.Itoa(pid))
+	if err != nil {
+		return 0, "", err
+	}
+	return parsePSParent(string(output), pid)
+}
+
+// parsePSParent parses ps -o ppid=,comm= output, e.g. "1234 /usr/bin/node"
+func parsePSParent(output string, pid int) (int, string, error) {
+	fields := strings.Fields(output)
+	if len(fields) < 2 {
+		return 0, "", fmt.Errorf("unexpected ps output for PID %d", pid)
+	}
+	ppid, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, "", err
+	}
+
+	// comm is the executable path; keep its base name
+	comm := strings.Join(fields[1:], " ")
+	return ppid, comm[strings.LastIndex(comm, "/")+1:], nil
+}
+
+// childPIDs returns the direct children of a process
+func childPIDs(pid int) []int {
+	return pgrepChildren(context.Background(), execRunner{}, pid)
+}
+
+// pgrepChildren lists the direct children of a process with pgrep
+func pgrepChildren(ctx context.Context, run Runner, pid int) []int {
+	output, err := run.Output(ctx, "pgrep", "-P", strconv.Itoa(pid))
+	if err != nil {
+		return nil
+	}
+
+	var children []int
+	for _, line := range strings.Fields(string(output)) {
+		if childPID, err := strconv.Atoi(line); err == nil {
+			children = append(children, childPID)
+		}
+	}
+
+	return children
+}
+
+// readUsage reads the CPU time and resident memory of the processes
+func readUsage(pids []int) map[int]usageSample {
+	return psUsage(context.Background(), execRunner{}, pids)
+}
+
+// psUsage reads the CPU time and resident memory of the processes with a
+// single ps call
+func psUsage(ctx context.Context, run Runner, pids []int) map[int]usageSample {
+	if len(pids) == 0 {
+		return map[int]usageSample{}
+	}
+	list := make([]string, len(pids))
+	for i, pid := range pids {
+		list[i] = strconv.Itoa(pid)
+	}
+
+	// ps fails when some of the processes are gone, but lists the others
+	output, _ := run.Output(ctx, "ps", "-o", "pid=,time=,rss=", "-p", strings.Join(list, ","))
+	return parsePSUsage(string(output))
+}
+
+// parsePSUsage parses ps -o pid=,time=,rss= output
+func parsePSUsage(output string) map[int]usageSample {
+	samples := make(map[int]usageSample)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		cpu, err := parsePSTime(fields[1])
+		if err != nil {
+			continue
+		}
+		// rss is in KiB
+		rss, _ := strconv.ParseUint(fields[2], 10, 64)
+		samples[pid] = usageSample{cpu: cpu, rss: rss * 1024}
+	}
+	return samples
+}
+
+// parsePSTime parses the CPU time printed by ps, [dd-][hh:]mm:ss[.cc]
+func parsePSTime(s string) (time.Duration, error) {
+	var total time.Duration
+	if days, rest, ok := strings.Cut(s, "-"); ok {
+		d, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid CPU time %q", s)
+		}
+		total = time.Duration(d) * 24 * time.Hour
+		s = rest
+	}
+
+	parts := strings.Split(s, ":")
+	seconds, err := strconv.ParseFloat(parts[len(parts)-1], 64)
+	if err != nil || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid CPU time %q", s)
+	}
+	total += time.Duration(seconds * float64(time.Second))
+
+	unit := time.Minute
+	for i := len(parts) - 2; i >= 0; i-- {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return 0, fmt.Errorf("invalid CPU time %q", s)
+		}
+		total += time.Duration(n) * unit
+		unit *= 60
+	}
+	return total, nil
+}
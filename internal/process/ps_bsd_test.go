@@ -0,0 +1,131 @@
+//go:build darwin || freebsd || openbsd
+
+package process
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseNetstatListeners(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []fakeListener
+	}{
+		{
+			name: "freebsd",
+			output: `Active Internet connections (including servers)
+Proto Recv-Q Send-Q Local Address          Foreign Address        (state)
+tcp4       0      0 192.168.1.20.22        192.168.1.31.61022     ESTABLISHED
+tcp4       0      0 *.80                   *.*                    LISTEN
+tcp46      0      0 *.3000                 *.*                    LISTEN
+tcp4       0      0 127.0.0.1.5432         *.*                    LISTEN
+tcp6       0      0 ::1.5432               *.*                    LISTEN
+`,
+			want: []fakeListener{
+				{Name: "(unknown)", Port: 80, Address: "*"},
+				{Name: "(unknown)", Port: 3000, Address: "*"},
+				{Name: "(unknown)", Port: 5432, Address: "127.0.0.1"},
+				{Name: "(unknown)", Port: 5432, Address: "::1"},
+			},
+		},
+		{
+			name: "openbsd",
+			output: `Active Internet connections (including servers)
+Proto   Recv-Q Send-Q  Local Address          Foreign Address        TCP-State
+tcp          0      0  192.168.1.20.41022     203.0.113.5.22         ESTABLISHED
+tcp          0      0  *.22                   *.*                    LISTEN
+tcp6         0      0  *.22                   *.*                    LISTEN
+`,
+			want: []fakeListener{
+				{Name: "(unknown)", Port: 22, Address: "*"},
+				{Name: "(unknown)", Port: 22, Address: "*"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := listeners(parseNetstatListeners(tt.output)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseNetstatListeners() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePSParent(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		wantPPID int
+		wantName string
+		wantErr  bool
+	}{
+		{"freebsd", " 1988 node\n", 1988, "node", false},
+		{"macos path", "  412 /usr/local/bin/node\n", 412, "node", false},
+		{"spaces in path", "  733 /Applications/Visual Studio Code.app/Contents/MacOS/Electron\n", 733, "Electron", false},
+		{"gone", "", 0, "", true},
+		{"garbage", "ppid comm\n", 0, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ppid, name, err := parsePSParent(tt.output, 2301)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePSParent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ppid != tt.wantPPID || name != tt.wantName {
+				t.Errorf("parsePSParent() = %d, %q, want %d, %q", ppid, name, tt.wantPPID, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestParsePSUsage(t *testing.T) {
+	output := ` 2301   0:12.50  81240
+  987 1:02:03.00  20480
+ 1244 2-03:04:05   4096
+ bad     0:01.00   1024
+ 1245    garbage   1024
+`
+	want := map[int]usageSample{
+		2301: {cpu: 12500 * time.Millisecond, rss: 81240 * 1024},
+		987:  {cpu: time.Hour + 2*time.Minute + 3*time.Second, rss: 20480 * 1024},
+		1244: {cpu: 51*time.Hour + 4*time.Minute + 5*time.Second, rss: 4096 * 1024},
+	}
+	if got := parsePSUsage(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePSUsage() = %v, want %v", got, want)
+	}
+}
+
+func TestParsePSTime(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"0:00.00", 0, false},
+		{"0:01.23", 1230 * time.Millisecond, false},
+		{"12:34", 12*time.Minute + 34*time.Second, false},
+		{"1:02:03", time.Hour + 2*time.Minute + 3*time.Second, false},
+		{"2-03:04:05", 51*time.Hour + 4*time.Minute + 5*time.Second, false},
+		{"x-01:00", 0, true},
+		{"1:2:3:4", 0, true},
+		{"a:01", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parsePSTime(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePSTime(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parsePSTime(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
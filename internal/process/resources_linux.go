@@ -0,0 +1,140 @@
+//go:build linux
+
+package process
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cpuTicks returns utime+stime for a process in clock ticks
+func cpuTicks(pid int) (int64, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, err
+	}
+
+	fields, err := parseStatFields(string(data))
+	if err != nil {
+		return 0, err
+	}
+
+	// utime and stime are the 14th and 15th stat fields
+	utime, err := strconv.ParseInt(fields[11], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	stime, err := strconv.ParseInt(fields[12], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return utime + stime, nil
+}
+
+// readUsage reads the CPU time and resident memory of the processes from
+// /proc/<pid>/stat and statm
+func readUsage(pids []int) map[int]usageSample {
+	samples := make(map[int]usageSample, len(pids))
+	for _, pid := range pids {
+		ticks, err := cpuTicks(pid)
+		if err != nil {
+			continue
+		}
+		sample := usageSample{cpu: time.Duration(ticks) * time.Second / clockTicks}
+
+		// statm holds sizes in pages, the resident size second
+		if data, err := os.ReadFile(fmt.Sprintf("/proc/%d/statm", pid)); err == nil {
+			if fields := strings.Fields(string(data)); len(fields) > 1 {
+				pages, _ := strconv.ParseUint(fields[1], 10, 64)
+				sample.rss = pages * uint64(os.Getpagesize())
+			}
+		}
+		samples[pid] = sample
+	}
+	return samples
+}
+
+// cgroupCPULimit returns the CPU quota of the process cgroup in cores,
+// or 0 when the cgroup is unlimited or can't be read
+func cgroupCPULimit(pid int) float64 {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return 0
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		// Format: hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		// cgroup v2 unified hierarchy
+		if parts[0] == "0" && parts[1] == "" {
+			if limit := readCPUMax(filepath.Join("/sys/fs/cgroup", parts[2], "cpu.max")); limit > 0 {
+				return limit
+			}
+			continue
+		}
+
+		// cgroup v1 cpu controller
+		for _, controller := range strings.Split(parts[1], ",") {
+			if controller != "cpu" {
+				continue
+			}
+			for _, mount := range []string{"cpu,cpuacct", "cpu"} {
+				dir := filepath.Join("/sys/fs/cgroup", mount, parts[2])
+				quota := readInt(filepath.Join(dir, "cpu.cfs_quota_us"))
+				period := readInt(filepath.Join(dir, "cpu.cfs_period_us"))
+				if quota > 0 && period > 0 {
+					return float64(quota) / float64(period)
+				}
+			}
+		}
+	}
+
+	return 0
+}
+
+// readCPUMax parses a cgroup v2 cpu.max file ("quota period" or "max period")
+func readCPUMax(path string) float64 {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) != 2 || fields[0] == "max" {
+		return 0
+	}
+
+	quota, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0
+	}
+	period, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil || period == 0 {
+		return 0
+	}
+
+	return quota / period
+}
+
+func readInt(path string) int64 {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+
+	value, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return value
+}
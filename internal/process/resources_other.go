@@ -0,0 +1,8 @@
+//go:build !linux
+
+package process
+
+// cgroupCPULimit returns 0, CPU quotas are only read from Linux cgroups
+func cgroupCPULimit(pid int) float64 {
+	return 0
+}
@@ -0,0 +1,56 @@
+//go:build windows
+
+package process
+
+import (
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var procGetProcessMemoryInfo = windows.NewLazySystemDLL("psapi.dll").NewProc("GetProcessMemoryInfo")
+
+// processMemoryCounters is PROCESS_MEMORY_COUNTERS
+type processMemoryCounters struct {
+	cb                         uint32
+	PageFaultCount             uint32
+	PeakWorkingSetSize         uintptr
+	WorkingSetSize             uintptr
+	QuotaPeakPagedPoolUsage    uintptr
+	QuotaPagedPoolUsage        uintptr
+	QuotaPeakNonPagedPoolUsage uintptr
+	QuotaNonPagedPoolUsage     uintptr
+	PagefileUsage              uintptr
+	PeakPagefileUsage          uintptr
+}
+
+// readUsage reads the CPU time and working set of the processes
+func readUsage(pids []int) map[int]usageSample {
+	samples := make(map[int]usageSample, len(pids))
+	for _, pid := range pids {
+		handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+		if err != nil {
+			continue
+		}
+
+		var creation, exit, kernel, user windows.Filetime
+		if err := windows.GetProcessTimes(handle, &creation, &exit, &kernel, &user); err != nil {
+			windows.CloseHandle(handle)
+			continue
+		}
+		// FILETIME durations count 100ns intervals
+		ticks := uint64(kernel.HighDateTime)<<32 | uint64(kernel.LowDateTime)
+		ticks += uint64(user.HighDateTime)<<32 | uint64(user.LowDateTime)
+		sample := usageSample{cpu: time.Duration(ticks) * 100}
+
+		counters := processMemoryCounters{cb: uint32(unsafe.Sizeof(processMemoryCounters{}))}
+		if ok, _, _ := procGetProcessMemoryInfo.Call(uintptr(handle), uintptr(unsafe.Pointer(&counters)), uintptr(counters.cb)); ok != 0 {
+			sample.rss = uint64(counters.WorkingSetSize)
+		}
+
+		windows.CloseHandle(handle)
+		samples[pid] = sample
+	}
+	return samples
+}
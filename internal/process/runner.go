@@ -0,0 +1,29 @@
+package process
+
+import "context"
+
+// Runner runs the external tools the platform finders read, such as ss,
+// lsof or netstat, so their parsers can be fed captured output instead
+type Runner interface {
+	// Output runs the tool with the arguments and returns what it printed
+	// to stdout, failing with an *exec.ExitError when it exits non-zero.
+	// The tool is killed when ctx is done.
+	Output(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+// execRunner runs tools for real, applying the configured overrides
+type execRunner struct{}
+
+func (execRunner) Output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return commandContext(ctx, name, args...).Output()
+}
+
+// WithRunner replaces how the finder runs external tools, e.g. with one
+// replaying the output of ss or lsof captured on another machine. It goes
+// before the options wrapping the platform finder, such as WithWindowsHost.
+func WithRunner(run Runner) Option {
+	return func(f *enrichingFinder) {
+		f.base = &platformFinder{run: run}
+		f.run = run
+	}
+}
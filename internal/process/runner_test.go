@@ -0,0 +1,88 @@
+package process
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeRunner replays tool output recorded in testdata instead of running
+// the tools. Tools it has no output for fail as if they weren't installed.
+type fakeRunner struct {
+	t *testing.T
+	// outputs maps command lines to the testdata files holding their
+	// output, or to "" for tools that print nothing
+	outputs map[string]string
+	// failures maps command lines to the stderr they exit 1 with
+	failures map[string]string
+
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *fakeRunner) Output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	line := strings.Join(append([]string{name}, args...), " ")
+	r.mu.Lock()
+	r.calls = append(r.calls, line)
+	r.mu.Unlock()
+
+	if stderr, ok := r.failures[line]; ok {
+		return nil, &exec.ExitError{Stderr: []byte(stderr)}
+	}
+	file, ok := r.outputs[line]
+	if !ok {
+		return nil, &exec.Error{Name: name, Err: exec.ErrNotFound}
+	}
+	if file == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filepath.Join("testdata", file))
+	if err != nil {
+		r.t.Fatalf("reading the output of %q: %v", line, err)
+	}
+	return data, nil
+}
+
+// ran reports whether the command line was run
+func (r *fakeRunner) ran(line string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, call := range r.calls {
+		if call == line {
+			return true
+		}
+	}
+	return false
+}
+
+// fakeListener is the part of a Process the parsers fill in from tool output
+type fakeListener struct {
+	PID     int
+	Name    string
+	Port    int
+	Address string
+}
+
+// listeners picks out what the parsers read, in port, PID and address
+// order
+func listeners(processes []*Process) []fakeListener {
+	result := make([]fakeListener, 0, len(processes))
+	for _, p := range processes {
+		result = append(result, fakeListener{PID: p.PID, Name: p.Name, Port: p.Port, Address: p.Address})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Port != result[j].Port {
+			return result[i].Port < result[j].Port
+		}
+		if result[i].PID != result[j].PID {
+			return result[i].PID < result[j].PID
+		}
+		return result[i].Address < result[j].Address
+	})
+	return result
+}
@@ -0,0 +1,69 @@
+//go:build darwin
+
+package process
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// resolveLaunchdJobs fills in the launchd jobs running the processes
+// launchd started, its direct children, listing the jobs once for all of
+// them. A job belongs to the user's GUI domain rather than the system one
+// unless we are root, since launchctl only lists the caller's domain.
+func resolveLaunchdJobs(ctx context.Context, run Runner, processes []*Process) {
+	var started []*Process
+	for _, p := range processes {
+		if p.PPID == 1 {
+			started = append(started, p)
+		}
+	}
+	if len(started) == 0 {
+		return
+	}
+
+	output, err := run.Output(ctx, "launchctl", "list")
+	if err != nil {
+		return
+	}
+	for _, p := range started {
+		p.Service = parseLaunchctlList(string(output), p.PID)
+		p.UserService = p.Service != "" && os.Geteuid() != 0
+	}
+}
+
+// parseLaunchctlList finds the label of a process in the "PID Status
+// Label" table printed by launchctl list
+func parseLaunchctlList(output string, pid int) string {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[0] != strconv.Itoa(pid) {
+			continue
+		}
+		// Apps opened from the Dock or Finder run as ad-hoc jobs, which
+		// aren't services to stop
+		if strings.HasPrefix(fields[2], "application.") {
+			return ""
+		}
+		return fields[2]
+	}
+	return ""
+}
+
+// stopService boots the launchd job out of its domain, since a job with
+// KeepAlive would be restarted after a plain stop
+func stopService(p *Process) error {
+	target := "system/" + p.Service
+	if p.UserService {
+		target = fmt.Sprintf("gui/%d/%s", os.Getuid(), p.Service)
+	}
+
+	output, err := command("launchctl", "bootout", target).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("launchctl bootout %s failed: %s", target, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+//go:build windows
+
+package process
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// serviceStopTimeout bounds how long stopService waits for the service to
+// report it stopped
+const serviceStopTimeout = 10 * time.Second
+
+// windowsService returns the name of the service running in a process.
+// Processes hosting several services, like shared svchost instances, are
+// left alone: stopping one of their services doesn't free the port.
+func windowsService(pid int) string {
+	scm, err := windows.OpenSCManager(nil, nil, windows.SC_MANAGER_ENUMERATE_SERVICE)
+	if err != nil {
+		return ""
+	}
+	defer windows.CloseServiceHandle(scm)
+
+	var buf []byte
+	var needed, returned uint32
+	for {
+		var p *byte
+		if len(buf) > 0 {
+			p = &buf[0]
+		}
+		err = windows.EnumServicesStatusEx(scm, windows.SC_ENUM_PROCESS_INFO,
+			windows.SERVICE_WIN32, windows.SERVICE_ACTIVE,
+			p, uint32(len(buf)), &needed, &returned, nil, nil)
+		if err == nil {
+			break
+		}
+		if err != syscall.ERROR_MORE_DATA || needed <= uint32(len(buf)) {
+			return ""
+		}
+		buf = make([]byte, needed)
+	}
+	if returned == 0 {
+		return ""
+	}
+
+	name := ""
+	services := unsafe.Slice((*windows.ENUM_SERVICE_STATUS_PROCESS)(unsafe.Pointer(&buf[0])), int(returned))
+	for _, s := range services {
+		if int(s.ServiceStatusProcess.ProcessId) != pid {
+			continue
+		}
+		if name != "" {
+			return ""
+		}
+		name = windows.UTF16PtrToString(s.ServiceName)
+	}
+	return name
+}
+
+// stopService asks the service control manager to stop the service and
+// waits for it to stop
+func stopService(p *Process) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("cannot connect to the service manager: %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(p.Service)
+	if err != nil {
+		return fmt.Errorf("cannot open service %s: %w", p.Service, err)
+	}
+	defer s.Close()
+
+	status, err := s.Control(svc.Stop)
+	if err != nil {
+		return fmt.Errorf("stopping service %s failed: %w", p.Service, err)
+	}
+	deadline := time.Now().Add(serviceStopTimeout)
+	for status.State != svc.Stopped {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("service %s did not stop within %s", p.Service, serviceStopTimeout)
+		}
+		time.Sleep(300 * time.Millisecond)
+		if status, err = s.Query(); err != nil {
+			return fmt.Errorf("querying service %s failed: %w", p.Service, err)
+		}
+	}
+	return nil
+}
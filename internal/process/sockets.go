@@ -0,0 +1,38 @@
+package process
+
+import (
+	"context"
+	"sort"
+)
+
+// TCPSocket is a TCP socket in any state, with the process holding it
+// when known. Sockets in TIME_WAIT belong to no process.
+type TCPSocket struct {
+	Port   int    `json:"port"`
+	Local  string `json:"local"`
+	Remote string `json:"remote,omitempty"`
+	// State is the TCP state, e.g. LISTEN, TIME_WAIT or CLOSE_WAIT
+	State string `json:"state"`
+	PID   int    `json:"pid,omitempty"`
+	Name  string `json:"name,omitempty"`
+}
+
+// SocketsOnPort lists the TCP sockets on a local port that aren't
+// listening, such as connections waiting out TIME_WAIT, which can keep the
+// port from being bound again when nothing listens on it
+func SocketsOnPort(ctx context.Context, port int) ([]TCPSocket, error) {
+	sockets, err := tcpSockets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var onPort []TCPSocket
+	for _, s := range sockets {
+		if s.Port == port && s.State != "LISTEN" {
+			onPort = append(onPort, s)
+		}
+	}
+	sort.SliceStable(onPort, func(i, j int) bool {
+		return onPort[i].State < onPort[j].State
+	})
+	return onPort, nil
+}
@@ -0,0 +1,71 @@
+package process
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Stats breaks down where a Finder spent its time, accumulated over all
+// calls made through it
+type Stats struct {
+	// Calls counts FindByPort and ListAll calls
+	Calls int
+	// Processes counts the processes returned
+	Processes int
+	// Discovery is the time spent by the platform finder enumerating
+	// sockets and reading process details
+	Discovery time.Duration
+	// Docker is the time spent querying the Docker daemon
+	Docker time.Duration
+	// Enrichment is the time spent on classification and other
+	// platform-independent details
+	Enrichment time.Duration
+	// Render is the time spent displaying results. Finders leave it zero;
+	// callers fill it in before reporting.
+	Render time.Duration
+	// CacheHits and CacheMisses count lookups served from a cache, such as
+	// the Docker client connection, and ones that had to be made afresh
+	CacheHits   int
+	CacheMisses int
+}
+
+// MarshalJSON encodes durations as fractional milliseconds
+func (s Stats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Calls        int     `json:"calls"`
+		Processes    int     `json:"processes"`
+		DiscoveryMS  float64 `json:"discovery_ms"`
+		DockerMS     float64 `json:"docker_ms"`
+		EnrichmentMS float64 `json:"enrichment_ms"`
+		RenderMS     float64 `json:"render_ms"`
+		CacheHits    int     `json:"cache_hits"`
+		CacheMisses  int     `json:"cache_misses"`
+	}{
+		Calls:        s.Calls,
+		Processes:    s.Processes,
+		DiscoveryMS:  milliseconds(s.Discovery),
+		DockerMS:     milliseconds(s.Docker),
+		EnrichmentMS: milliseconds(s.Enrichment),
+		RenderMS:     milliseconds(s.Render),
+		CacheHits:    s.CacheHits,
+		CacheMisses:  s.CacheMisses,
+	})
+}
+
+// statsReporter is implemented by finders that keep Stats
+type statsReporter interface {
+	Stats() Stats
+}
+
+// FinderStats returns the stats collected by a finder created with
+// NewFinder, possibly wrapped by Filtered
+func FinderStats(f Finder) (Stats, bool) {
+	if r, ok := f.(statsReporter); ok {
+		return r.Stats(), true
+	}
+	return Stats{}, false
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,139 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Tool overrides how an external command is run, for machines where it
+// lives outside PATH or needs a wrapper such as sudo
+type Tool struct {
+	// Path is the executable to run instead of the tool's name
+	Path string `json:"path,omitempty"`
+	// Args are inserted before pf's own arguments, e.g. ["-n", "ss"] with
+	// path "sudo"
+	Args []string `json:"args,omitempty"`
+}
+
+// knownTools are the external commands the finders and kill strategies run
+var knownTools = map[string]bool{
+	"ss":         true,
+	"netstat":    true,
+	"lsof":       true,
+	"ps":         true,
+	"pgrep":      true,
+	"systemctl":  true,
+	"tasklist":   true,
+	"taskkill":   true,
+	"powershell": true,
+	"wmic":       true,
+	"kubectl":    true,
+	"journalctl": true,
+	"nerdctl":    true,
+	"sockstat":   true,
+	"fstat":      true,
+	"procstat":   true,
+	"sysctl":     true,
+	"netsh":      true,
+	"launchctl":  true,
+	// Firewalls, queried and changed by pf firewall
+	"ufw":          true,
+	"firewall-cmd": true,
+	"pfctl":        true,
+	// Package managers the inventory asks who owns an executable
+	"dpkg":       true,
+	"dpkg-query": true,
+	"rpm":        true,
+	"pacman":     true,
+	// Windows executables run from WSL through interop
+	"netstat.exe":  true,
+	"tasklist.exe": true,
+	"taskkill.exe": true,
+}
+
+var (
+	toolsMu sync.RWMutex
+	tools   map[string]Tool
+)
+
+// ToolNames returns the names of the external commands that can be
+// overridden
+func ToolNames() []string {
+	names := make([]string, 0, len(knownTools))
+	for name := range knownTools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetTools overrides the paths and arguments of external commands
+func SetTools(overrides map[string]Tool) error {
+	for name := range overrides {
+		if !knownTools[name] {
+			return fmt.Errorf("unknown tool %q", name)
+		}
+	}
+
+	toolsMu.Lock()
+	defer toolsMu.Unlock()
+	tools = overrides
+	return nil
+}
+
+// ToolCommand returns the command line prefix a tool runs with, after
+// overrides: the executable followed by any extra arguments
+func ToolCommand(name string) (string, []string) {
+	toolsMu.RLock()
+	defer toolsMu.RUnlock()
+
+	tool := tools[name]
+	if tool.Path == "" {
+		return name, tool.Args
+	}
+	return tool.Path, tool.Args
+}
+
+// command prepares an external tool run, applying the configured overrides
+func command(name string, args ...string) *toolCmd {
+	path, extra := ToolCommand(name)
+	return &toolCmd{exec.Command(path, append(append([]string{}, extra...), args...)...)}
+}
+
+// killedToolWait bounds how long the output of a tool killed by its
+// context is waited for, as children it started may hold it open
+const killedToolWait = 500 * time.Millisecond
+
+// commandContext is command for runs bounded by a context
+func commandContext(ctx context.Context, name string, args ...string) *toolCmd {
+	path, extra := ToolCommand(name)
+	cmd := exec.CommandContext(ctx, path, append(append([]string{}, extra...), args...)...)
+	cmd.WaitDelay = killedToolWait
+	return &toolCmd{cmd}
+}
+
+// RunTool runs a tool for other packages the way the finders run theirs,
+// with the configured overrides, bounded by ctx, and returns what it
+// printed to stdout and stderr
+func RunTool(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return commandContext(ctx, name, args...).CombinedOutput()
+}
+
+// ToolOutput is RunTool returning only what the tool printed to stdout,
+// with its stderr in the *exec.ExitError
+func ToolOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return commandContext(ctx, name, args...).Output()
+}
+
+// RunToolOn is RunTool with the tool reading and writing the given
+// streams, e.g. the terminal so sudo can ask for a password
+func RunToolOn(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, name string, args ...string) error {
+	cmd := commandContext(ctx, name, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = stdin, stdout, stderr
+	return cmd.Run()
+}
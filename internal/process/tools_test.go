@@ -0,0 +1,62 @@
+package process
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// TestKnownToolsCoverCallSites checks that every tool run through command,
+// commandContext or a Runner, on any platform, can be overridden
+func TestKnownToolsCoverCallSites(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	for _, file := range files {
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+
+			// The tool is the first argument of command, and the one
+			// after the context of commandContext and Output
+			arg := -1
+			switch fun := call.Fun.(type) {
+			case *ast.Ident:
+				switch fun.Name {
+				case "command":
+					arg = 0
+				case "commandContext":
+					arg = 1
+				}
+			case *ast.SelectorExpr:
+				if fun.Sel.Name == "Output" && len(call.Args) > 1 {
+					arg = 1
+				}
+			}
+			if arg < 0 || arg >= len(call.Args) {
+				return true
+			}
+
+			lit, ok := call.Args[arg].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			if name, _ := strconv.Unquote(lit.Value); !knownTools[name] {
+				t.Errorf("%s runs %q, which is missing from knownTools", fset.Position(lit.Pos()), name)
+			}
+			return true
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package process
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Ancestor is a process above a listener in its process tree
+type Ancestor struct {
+	PID  int    `json:"pid"`
+	Name string `json:"name"`
+}
+
+// maxLineageDepth guards against PID cycles from racing process exits
+const maxLineageDepth = 32
+
+// sessionBoundaries are the shells, terminals and service managers a
+// process tree hangs off. They are never treated as part of an app.
+// "sh" and "dash" are left out on purpose: npm and friends run scripts
+// through them.
+var sessionBoundaries = map[string]bool{
+	"bash": true, "zsh": true, "fish": true, "tcsh": true, "csh": true, "ksh": true, "nu": true,
+	"pwsh": true, "powershell": true, "cmd": true, "explorer": true,
+	"tmux": true, "tmux: server": true, "screen": true, "login": true, "sshd": true,
+	"init": true, "systemd": true, "launchd": true, "services": true, "svchost": true,
+	"containerd-shim": true, "containerd-shim-runc-v2": true,
+	"code": true, "code helper": true,
+}
+
+// LoadLineage fills Lineage with the ancestors of the process, nearest
+// first, stopping before PID 1
+func (p *Process) LoadLineage() {
+	// Non-nil even when empty, marking the lineage as loaded
+	p.Lineage = []Ancestor{}
+	// Windows processes seen from WSL have no parents on this side
+	if p.Host != "" {
+		return
+	}
+
+	pid := p.PPID
+	if pid == 0 {
+		pid, _, _ = lookupParent(p.PID)
+	}
+
+	for depth := 0; pid > 1 && depth < maxLineageDepth; depth++ {
+		ppid, name, err := lookupParent(pid)
+		if err != nil {
+			break
+		}
+		p.Lineage = append(p.Lineage, Ancestor{PID: pid, Name: name})
+		if ppid == pid {
+			break
+		}
+		pid = ppid
+	}
+}
+
+// Root returns the outermost process of the app the listener belongs to:
+// the ancestor just below the first shell, terminal or service manager.
+// A listener started directly from a shell is its own root. The lineage
+// is loaded if it hasn't been.
+func (p *Process) Root() Ancestor {
+	if p.Lineage == nil {
+		p.LoadLineage()
+	}
+
+	root := Ancestor{PID: p.PID, Name: p.Name}
+	for _, a := range p.Lineage {
+		if isSessionBoundary(a.Name) {
+			break
+		}
+		root = a
+	}
+	return root
+}
+
+// Tree returns the app's chain of processes from its root down to the
+// listener itself
+func (p *Process) Tree() []Ancestor {
+	root := p.Root()
+
+	chain := []Ancestor{{PID: p.PID, Name: p.Name}}
+	if root.PID != p.PID {
+		for _, a := range p.Lineage {
+			chain = append(chain, a)
+			if a.PID == root.PID {
+				break
+			}
+		}
+	}
+
+	// Reverse into root-first order
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain
+}
+
+func isSessionBoundary(name string) bool {
+	name = strings.ToLower(filepath.Base(name))
+	name = strings.TrimSuffix(name, ".exe")
+	name = strings.TrimPrefix(name, "-") // login shells, e.g. "-zsh"
+	return sessionBoundaries[name]
+}
+
+// KillOrder orders the processes of an app for stopping it as a whole:
+// children before the processes that spawned them, so nothing is respawned
+// halfway, and databases last, after the servers using them. Processes are
+// otherwise kept in their order.
+func KillOrder(processes []*Process) []*Process {
+	pids := make(map[int]bool, len(processes))
+	for _, p := range processes {
+		pids[p.PID] = true
+	}
+	depth := make(map[*Process]int, len(processes))
+	for _, p := range processes {
+		if p.Lineage == nil {
+			p.LoadLineage()
+		}
+		for _, a := range p.Lineage {
+			if pids[a.PID] {
+				depth[p]++
+			}
+		}
+	}
+
+	ordered := append([]*Process(nil), processes...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i], ordered[j]
+		if da, db := a.HasTag(ClassDatabase), b.HasTag(ClassDatabase); da != db {
+			return db
+		}
+		return depth[a] > depth[b]
+	})
+	return ordered
+}
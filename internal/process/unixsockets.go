@@ -0,0 +1,146 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// UnixSocket is a Unix domain socket bound to a path, with the process
+// listening on it, or a socket file nothing listens on
+type UnixSocket struct {
+	// Path is the socket file, or @name for a Linux abstract socket
+	Path string `json:"path"`
+	PID  int    `json:"pid,omitempty"`
+	Name string `json:"name,omitempty"`
+	// Owner and Mode are those of the socket file
+	Owner string `json:"owner,omitempty"`
+	Mode  string `json:"mode,omitempty"`
+	// Stale is set for a socket file left behind by a process that exited
+	// without removing it: connecting is refused, and servers binding the
+	// path fail with "address already in use" until it is deleted
+	Stale bool `json:"stale"`
+}
+
+// socketDirs are where servers commonly put their sockets, e.g.
+// docker.sock, Postgres's .s.PGSQL.5432 or php-fpm.sock, searched for
+// stale socket files
+var socketDirs = []string{
+	"/tmp", "/run", "/var/run", "/run/postgresql", "/var/run/postgresql",
+	"/run/php", "/var/run/php", "/run/mysqld", "/var/run/mysqld",
+}
+
+// socketDialTimeout bounds connecting to a socket file to tell if it's
+// stale
+const socketDialTimeout = 500 * time.Millisecond
+
+// UnixSockets lists the Unix domain sockets listening on a path, and the
+// stale socket files in the directories servers commonly use, by path
+func UnixSockets(ctx context.Context) ([]UnixSocket, error) {
+	sockets, err := unixListeners(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	listening := make(map[string]bool)
+	for i := range sockets {
+		s := &sockets[i]
+		listening[resolvePath(s.Path)] = true
+		if info, err := os.Stat(s.Path); err == nil {
+			s.Owner, s.Mode = fileOwner(info), info.Mode().String()
+		}
+	}
+
+	for _, path := range socketFiles() {
+		if listening[resolvePath(path)] || !refused(path) {
+			continue
+		}
+		listening[resolvePath(path)] = true
+		s := UnixSocket{Path: path, Stale: true}
+		if info, err := os.Stat(path); err == nil {
+			s.Owner, s.Mode = fileOwner(info), info.Mode().String()
+		}
+		sockets = append(sockets, s)
+	}
+
+	sort.Slice(sockets, func(i, j int) bool {
+		if sockets[i].Path != sockets[j].Path {
+			return sockets[i].Path < sockets[j].Path
+		}
+		return sockets[i].PID < sockets[j].PID
+	})
+	return sockets, nil
+}
+
+// StaleSocket reports whether path is a socket file nothing listens on
+func StaleSocket(path string) bool {
+	info, err := os.Lstat(path)
+	return err == nil && info.Mode()&os.ModeSocket != 0 && refused(path)
+}
+
+// RemoveStaleSocket deletes a stale socket file, checking again that
+// nothing listens on it
+func RemoveStaleSocket(path string) error {
+	if !StaleSocket(path) {
+		return fmt.Errorf("%s is not a stale socket file", path)
+	}
+	return os.Remove(path)
+}
+
+// socketFiles lists the socket files in socketDirs and the temporary and
+// runtime directories of the user
+func socketFiles() []string {
+	dirs := append([]string{os.TempDir()}, socketDirs...)
+	if runtime := os.Getenv("XDG_RUNTIME_DIR"); runtime != "" {
+		dirs = append(dirs, runtime)
+	}
+
+	var files []string
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		if seen[resolvePath(dir)] {
+			continue
+		}
+		seen[resolvePath(dir)] = true
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.Type()&os.ModeSocket != 0 {
+				files = append(files, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+	return files
+}
+
+// refused reports whether connecting to a socket file is refused, which
+// means nothing listens on it. Files we may not connect to aren't judged.
+func refused(path string) bool {
+	conn, err := net.DialTimeout("unix", path, socketDialTimeout)
+	if err != nil {
+		return errors.Is(err, syscall.ECONNREFUSED)
+	}
+	conn.Close()
+	return false
+}
+
+// resolvePath follows symlinks, e.g. from /var/run to /run, so a path is
+// recognized under either name
+func resolvePath(path string) string {
+	if strings.HasPrefix(path, "@") {
+		return path
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
@@ -0,0 +1,52 @@
+//go:build darwin || freebsd || openbsd
+
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// unixListeners lists the Unix sockets bound to a path with lsof, of the
+// processes it may inspect. lsof doesn't tell listening sockets from
+// accepted connections, so a path is listed once per process.
+func unixListeners(ctx context.Context) ([]UnixSocket, error) {
+	output, err := commandContext(ctx, "lsof", "-U", "-n", "-F", "pcn").Output()
+	if err != nil {
+		// lsof exits with 1 when some processes can't be read
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || len(output) == 0 {
+			return nil, fmt.Errorf("lsof failed: %w", err)
+		}
+	}
+
+	var sockets []UnixSocket
+	seen := make(map[string]bool)
+	pid, name := 0, ""
+	for _, line := range strings.Split(string(output), "\n") {
+		if line == "" {
+			continue
+		}
+		switch line[0] {
+		case 'p':
+			pid, _ = strconv.Atoi(line[1:])
+		case 'c':
+			name = line[1:]
+		case 'n':
+			// Unbound and client sockets are named by their address,
+			// e.g. "->0x1234"
+			path := line[1:]
+			key := fmt.Sprintf("%d:%s", pid, path)
+			if !strings.HasPrefix(path, "/") || seen[key] {
+				continue
+			}
+			seen[key] = true
+			sockets = append(sockets, UnixSocket{Path: path, PID: pid, Name: name})
+		}
+	}
+	return sockets, nil
+}
@@ -0,0 +1,41 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// unixListeners reads the listening Unix sockets bound to a path from
+// /proc/net/unix, matching their inodes to the processes holding them
+func unixListeners(ctx context.Context) ([]UnixSocket, error) {
+	content, err := os.ReadFile("/proc/net/unix")
+	if err != nil {
+		return nil, fmt.Errorf("cannot read /proc/net/unix: %w", err)
+	}
+
+	var sockets []UnixSocket
+	inodes := make(map[string]int)
+	lines := strings.Split(string(content), "\n")
+	for _, line := range lines[1:] {
+		// Num RefCount Protocol Flags Type St Inode Path; flag 00010000
+		// marks listening sockets
+		fields := strings.Fields(line)
+		if len(fields) < 8 || fields[3] != "00010000" {
+			continue
+		}
+		inodes[fields[6]] = len(sockets)
+		sockets = append(sockets, UnixSocket{Path: strings.Join(fields[7:], " ")})
+	}
+
+	err = inodeOwners(ctx, func(inode string, pid int, name string) {
+		if i, ok := inodes[inode]; ok && sockets[i].PID == 0 {
+			sockets[i].PID, sockets[i].Name = pid, name
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sockets, nil
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package process
+
+import (
+	"context"
+	"fmt"
+)
+
+// unixListeners fails, as Windows doesn't expose which processes hold
+// Unix sockets
+func unixListeners(ctx context.Context) ([]UnixSocket, error) {
+	return nil, fmt.Errorf("listing Unix sockets is not supported on Windows")
+}
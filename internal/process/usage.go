@@ -0,0 +1,72 @@
+package process
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// Usage is the CPU and memory use of a process
+type Usage struct {
+	// CPUPercent is relative to CPULimit when the process runs under a
+	// cgroup CPU quota, else to the whole host
+	CPUPercent float64
+	// CPULimit is the cgroup CPU quota in cores, 0 when unlimited
+	CPULimit float64
+	// RSS is the resident memory in bytes
+	RSS uint64
+}
+
+// usageSample is the CPU time a process used so far and its resident
+// memory
+type usageSample struct {
+	cpu time.Duration
+	rss uint64
+}
+
+// SampleUsage measures the CPU usage of the processes over interval, all
+// at once, along with their resident memory. Processes that can't be read
+// are left out.
+func SampleUsage(pids []int, interval time.Duration) map[int]Usage {
+	before := readUsage(pids)
+	start := time.Now()
+
+	time.Sleep(interval)
+
+	after := readUsage(pids)
+	elapsed := time.Since(start)
+
+	usage := make(map[int]Usage, len(after))
+	for pid, a := range after {
+		b, ok := before[pid]
+		if !ok {
+			continue
+		}
+
+		// Processes confined by a cgroup quota (containers, systemd
+		// slices) are measured against it rather than the whole host
+		limit := cgroupCPULimit(pid)
+		capacity := float64(runtime.NumCPU())
+		if limit > 0 && limit < capacity {
+			capacity = limit
+		}
+
+		usage[pid] = Usage{
+			CPUPercent: float64(a.cpu-b.cpu) / float64(elapsed) / capacity * 100,
+			CPULimit:   limit,
+			RSS:        a.rss,
+		}
+	}
+	return usage
+}
+
+// SampleCPU measures the process CPU usage over the given interval, along
+// with its resident memory
+func (p *Process) SampleCPU(interval time.Duration) error {
+	usage, ok := SampleUsage([]int{p.PID}, interval)[p.PID]
+	if !ok {
+		return fmt.Errorf("cannot sample the CPU usage of PID %d", p.PID)
+	}
+	p.CPUPercent, p.CPULimit, p.RSS = usage.CPUPercent, usage.CPULimit, usage.RSS
+	return nil
+}
@@ -0,0 +1,41 @@
+package process
+
+import (
+	"os/user"
+	"strings"
+	"sync"
+)
+
+// userNames caches uid lookups, which read /etc/passwd or query the
+// directory service on every call
+var userNames sync.Map
+
+// userName resolves a numeric uid to a username, falling back to the uid
+// itself for users without an account entry (e.g. container users)
+func userName(uid string) string {
+	if name, ok := userNames.Load(uid); ok {
+		return name.(string)
+	}
+	name := uid
+	if u, err := user.LookupId(uid); err == nil {
+		name = u.Username
+	}
+	userNames.Store(uid, name)
+	return name
+}
+
+// OwnedBy reports whether the process runs as the given user, matched by
+// name or uid. Windows account names match with or without their domain.
+func (p *Process) OwnedBy(name string) bool {
+	if p.User == "" {
+		return false
+	}
+	if strings.EqualFold(p.User, name) {
+		return true
+	}
+	if _, account, ok := strings.Cut(p.User, `\`); ok && strings.EqualFold(account, name) {
+		return true
+	}
+	// A uid matches the name it resolves to
+	return userName(name) == p.User
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package process
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// fileOwner returns the name of the user owning a file
+func fileOwner(info os.FileInfo) string {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return ""
+	}
+	return userName(strconv.FormatUint(uint64(stat.Uid), 10))
+}
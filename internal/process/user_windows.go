@@ -0,0 +1,10 @@
+//go:build windows
+
+package process
+
+import "os"
+
+// fileOwner is left empty, as Windows files have no uid
+func fileOwner(info os.FileInfo) string {
+	return ""
+}
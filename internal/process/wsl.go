@@ -0,0 +1,249 @@
+package process
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HostWindows marks processes running on the Windows side of WSL
+const HostWindows = "windows"
+
+// IsWSL reports whether this is a Linux distribution running under WSL
+// with Windows interop enabled, so Windows executables can be run
+func IsWSL() bool {
+	if _, err := os.Stat("/proc/sys/fs/binfmt_misc/WSLInterop"); err == nil {
+		return true
+	}
+	version, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	return err == nil && strings.Contains(strings.ToLower(string(version)), "microsoft") && os.Getenv("WSL_INTEROP") != ""
+}
+
+// WithWindowsHost also reports the listeners of Windows processes when
+// running under WSL, where localhost forwarding makes them reachable as if
+// they were local. Elsewhere it has no effect.
+func WithWindowsHost() Option {
+	return func(f *enrichingFinder) {
+		if !IsWSL() {
+			return
+		}
+		// The Windows tools run the way the Linux ones do
+		run := Runner(execRunner{})
+		if base, ok := f.base.(*platformFinder); ok {
+			run = base.run
+		}
+		f.base = &wslFinder{linux: f.base, run: run}
+	}
+}
+
+// wslFinder merges the Linux listeners with those of the Windows host
+type wslFinder struct {
+	linux Finder
+	run   Runner
+}
+
+func (f *wslFinder) FindByPort(ctx context.Context, port int) (*Process, error) {
+	proc, err := f.linux.FindByPort(ctx, port)
+	if err != nil || proc != nil {
+		return proc, err
+	}
+
+	windows, err := windowsListeners(ctx, f.run)
+	if err != nil {
+		return nil, nil
+	}
+	for _, p := range windows {
+		if p.Port == port && !isRelay(p) {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *wslFinder) FindByPorts(ctx context.Context, ports []int) (map[int]*Process, error) {
+	found, err := f.linux.FindByPorts(ctx, ports)
+	if err != nil || len(found) == len(ports) {
+		return found, err
+	}
+
+	windows, err := windowsListeners(ctx, f.run)
+	if err != nil {
+		return found, nil
+	}
+	for port, proc := range ByPorts(Filter(windows, func(p *Process) bool { return !isRelay(p) }), ports) {
+		if found[port] == nil {
+			found[port] = proc
+		}
+	}
+	return found, nil
+}
+
+func (f *wslFinder) ListAll(ctx context.Context) ([]*Process, error) {
+	processes, err := f.linux.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	windows, err := windowsListeners(ctx, f.run)
+	if err != nil {
+		// The Windows side is a bonus, the Linux listing stands on its own
+		return processes, nil
+	}
+
+	linuxPorts := make(map[int]bool, len(processes))
+	for _, p := range processes {
+		linuxPorts[p.Port] = true
+	}
+	for _, p := range windows {
+		// wslrelay listens on Windows for ports forwarded from Linux
+		if isRelay(p) && linuxPorts[p.Port] {
+			continue
+		}
+		processes = append(processes, p)
+	}
+	return processes, nil
+}
+
+func isRelay(p *Process) bool {
+	return strings.EqualFold(p.Name, "wslrelay.exe")
+}
+
+// windowsListeners lists the listening Windows processes through the
+// interop executables netstat.exe and tasklist.exe
+func windowsListeners(ctx context.Context, run Runner) ([]*Process, error) {
+	output, err := run.Output(ctx, "netstat.exe", "-ano", "-p", "tcp")
+	if err != nil {
+		return nil, fmt.Errorf("netstat.exe failed: %w", err)
+	}
+	processes := parseWindowsNetstat(string(output))
+
+	if output, err := run.Output(ctx, "tasklist.exe", "/FO", "CSV", "/NH"); err == nil {
+		names := parseTasklist(string(output))
+		for _, p := range processes {
+			p.Name = names[p.PID]
+		}
+	}
+	for _, p := range processes {
+		if p.Name == "" {
+			p.Name = "(windows)"
+		}
+		p.Host = HostWindows
+		p.ProjectPath = "unknown"
+	}
+	return processes, nil
+}
+
+// parseWindowsNetstat parses the LISTENING lines of netstat -ano:
+// Proto Local Foreign State PID
+func parseWindowsNetstat(output string) []*Process {
+	seen := make(map[string]bool)
+	var processes []*Process
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 || fields[3] != "LISTENING" {
+			continue
+		}
+		host, port, ok := splitAddress(fields[1])
+		if !ok {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[4])
+		if err != nil || pid == 0 {
+			continue
+		}
+
+		key := fmt.Sprintf("%d-%d", pid, port)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		processes = append(processes, &Process{PID: pid, Port: port, Address: host})
+	}
+	return processes
+}
+
+// parseTasklist maps PIDs to image names from tasklist /FO CSV /NH
+func parseTasklist(output string) map[int]string {
+	reader := csv.NewReader(strings.NewReader(output))
+	reader.FieldsPerRecord = -1
+	records, _ := reader.ReadAll()
+
+	names := make(map[int]string, len(records))
+	for _, record := range records {
+		if len(record) < 2 {
+			continue
+		}
+		if pid, err := strconv.Atoi(record[1]); err == nil {
+			names[pid] = record[0]
+		}
+	}
+	return names
+}
+
+// WindowsHostStrategy ends a Windows process from WSL with taskkill.exe
+type WindowsHostStrategy struct {
+	Options KillOptions
+	// run runs taskkill.exe and tasklist.exe, for real when nil
+	run Runner
+}
+
+func (WindowsHostStrategy) Name() string { return "windows" }
+
+func (WindowsHostStrategy) Applicable(p *Process) bool {
+	return p.Host == HostWindows && p.PID > 0
+}
+
+// Kill asks the process to close, then forces it after the grace period,
+// as terminatePIDs does on Windows
+func (s WindowsHostStrategy) Kill(p *Process) error {
+	ctx := context.Background()
+	run := s.run
+	if run == nil {
+		run = execRunner{}
+	}
+
+	pid := strconv.Itoa(p.PID)
+	if !s.Options.Force {
+		run.Output(ctx, "taskkill.exe", "/PID", pid)
+
+		deadline := time.Now().Add(s.Options.grace())
+		for time.Now().Before(deadline) {
+			if !windowsPIDRunning(ctx, run, p.PID) {
+				return nil
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	if !windowsPIDRunning(ctx, run, p.PID) {
+		return nil
+	}
+	if output, err := run.Output(ctx, "taskkill.exe", "/F", "/PID", pid); err != nil {
+		// taskkill.exe explains failures on stderr
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			output = append(output, exitErr.Stderr...)
+		}
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return fmt.Errorf("failed to kill process: %s", message)
+		}
+		return fmt.Errorf("failed to kill process: %w", err)
+	}
+	return nil
+}
+
+// windowsPIDRunning reports whether a Windows process with the PID exists
+func windowsPIDRunning(ctx context.Context, run Runner, pid int) bool {
+	output, err := run.Output(ctx, "tasklist.exe", "/FI", fmt.Sprintf("PID eq %d", pid), "/FO", "CSV", "/NH")
+	if err != nil {
+		return false
+	}
+	_, running := parseTasklist(string(output))[pid]
+	return running
+}
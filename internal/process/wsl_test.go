@@ -0,0 +1,87 @@
+package process
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWindowsListeners(t *testing.T) {
+	run := &fakeRunner{t: t, outputs: map[string]string{
+		"netstat.exe -ano -p tcp":  "netstat-ano.txt",
+		"tasklist.exe /FO CSV /NH": "tasklist-csv.txt",
+	}}
+	processes, err := windowsListeners(context.Background(), run)
+	if err != nil {
+		t.Fatalf("windowsListeners() error = %v", err)
+	}
+
+	// The IPv6 listeners of the same processes are left out, as is the
+	// System Idle Process holding 49664
+	want := []fakeListener{
+		{PID: 1096, Name: "svchost.exe", Port: 135, Address: "0.0.0.0"},
+		{PID: 14820, Name: "node.exe", Port: 3000, Address: "0.0.0.0"},
+		{PID: 6244, Name: "postgres.exe", Port: 5432, Address: "0.0.0.0"},
+	}
+	if got := listeners(processes); !reflect.DeepEqual(got, want) {
+		t.Errorf("windowsListeners() = %+v, want %+v", got, want)
+	}
+	for _, p := range processes {
+		if p.Host != HostWindows {
+			t.Errorf("%s has host %q, want %q", p.Name, p.Host, HostWindows)
+		}
+	}
+}
+
+func TestWindowsListenersWithoutTasklist(t *testing.T) {
+	run := &fakeRunner{t: t, outputs: map[string]string{"netstat.exe -ano -p tcp": "netstat-ano.txt"}}
+	processes, err := windowsListeners(context.Background(), run)
+	if err != nil {
+		t.Fatalf("windowsListeners() error = %v", err)
+	}
+	for _, p := range processes {
+		if p.Name != "(windows)" {
+			t.Errorf("PID %d is named %q without tasklist.exe, want (windows)", p.PID, p.Name)
+		}
+	}
+}
+
+func TestParseTasklist(t *testing.T) {
+	names := parseTasklist(`"node.exe","14820","Console","1","78,916 K"` + "\r\n" + `"Code - Insiders.exe","7012","Console","1","1,024 K"` + "\r\n" + "INFO: No tasks are running\r\n")
+	want := map[int]string{14820: "node.exe", 7012: "Code - Insiders.exe"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("parseTasklist() = %v, want %v", names, want)
+	}
+}
+
+func TestWindowsHostStrategyKill(t *testing.T) {
+	p := &Process{PID: 14820, Name: "node.exe", Host: HostWindows}
+	running := `tasklist.exe /FI PID eq 14820 /FO CSV /NH`
+
+	run := &fakeRunner{t: t, outputs: map[string]string{
+		running:                      "tasklist-csv.txt",
+		"taskkill.exe /F /PID 14820": "",
+	}}
+	s := WindowsHostStrategy{Options: KillOptions{Force: true}, run: run}
+	if err := s.Kill(p); err != nil {
+		t.Fatalf("Kill() error = %v", err)
+	}
+	if run.ran("taskkill.exe /PID 14820") {
+		t.Error("a forced Kill() asked the process to close first")
+	}
+	if !run.ran("taskkill.exe /F /PID 14820") {
+		t.Error("Kill() didn't run taskkill.exe /F")
+	}
+
+	run = &fakeRunner{
+		t:        t,
+		outputs:  map[string]string{running: "tasklist-csv.txt"},
+		failures: map[string]string{"taskkill.exe /F /PID 14820": "ERROR: The process with PID 14820 could not be terminated.\r\nReason: Access is denied.\r\n"},
+	}
+	s.run = run
+	err := s.Kill(p)
+	if err == nil || !strings.Contains(err.Error(), "Access is denied") {
+		t.Errorf("Kill() error = %v, want taskkill.exe's reason", err)
+	}
+}
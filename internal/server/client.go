@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/doganarif/portfinder/internal/process"
+)
+
+// Client reads processes from another portfinder's HTTP API, standing in
+// for a local Finder to show and kill the listeners of a remote host
+type Client struct {
+	base  string
+	token string
+	http  *http.Client
+}
+
+// NewClient creates a client for the API at addr, e.g. "10.0.0.5:7777"
+// or "http://10.0.0.5:7777", sending the token the server requires
+func NewClient(addr, token string) *Client {
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	return &Client{
+		base:  strings.TrimRight(addr, "/"),
+		token: token,
+		http:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (c *Client) FindByPort(ctx context.Context, port int) (*process.Process, error) {
+	var proc process.Process
+	found, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/ports/%d", port), &proc)
+	if err != nil || !found {
+		return nil, err
+	}
+	remote(&proc)
+	return &proc, nil
+}
+
+// FindByPorts fetches the full list once rather than each port
+func (c *Client) FindByPorts(ctx context.Context, ports []int) (map[int]*process.Process, error) {
+	processes, err := c.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return process.ByPorts(processes, ports), nil
+}
+
+func (c *Client) ListAll(ctx context.Context) ([]*process.Process, error) {
+	var processes []*process.Process
+	if _, err := c.do(ctx, http.MethodGet, "/ports", &processes); err != nil {
+		return nil, err
+	}
+	for _, proc := range processes {
+		remote(proc)
+	}
+	return processes, nil
+}
+
+// remote marks the lineage of a remote process as known, so views don't
+// look its PID up on this machine
+func remote(proc *process.Process) {
+	if proc.Lineage == nil {
+		proc.Lineage = []process.Ancestor{}
+	}
+}
+
+// Kill kills the process on the remote host, with the strategy the server
+// selects
+func (c *Client) Kill(p *process.Process) error {
+	found, err := c.do(context.Background(), http.MethodDelete, fmt.Sprintf("/ports/%d", p.Port), &killResponse{})
+	if err == nil && !found {
+		return fmt.Errorf("port %d is no longer in use", p.Port)
+	}
+	return err
+}
+
+// do sends a request and decodes the response into v, reporting false
+// without an error when the port isn't in use
+func (c *Client) do(ctx context.Context, method, path string, v interface{}) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, nil)
+	if err != nil {
+		return false, err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound && strings.HasPrefix(path, "/ports/") {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e errorResponse
+		if json.NewDecoder(resp.Body).Decode(&e) == nil && e.Error != "" {
+			return false, fmt.Errorf("%s: %s", c.base, e.Error)
+		}
+		return false, fmt.Errorf("%s: %s", c.base, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return false, fmt.Errorf("%s: invalid response: %w", c.base, err)
+	}
+	return true, nil
+}
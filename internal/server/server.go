@@ -0,0 +1,231 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/doganarif/portfinder/internal/process"
+)
+
+// Server exposes process discovery and kill actions over HTTP
+type Server struct {
+	finder process.Finder
+	onKill func(*process.Process, process.KillStrategy)
+	guard  func(*process.Process) error
+
+	// token is the bearer token every request must carry, unless empty
+	token string
+	// host is the name the server listens on, accepted in Host headers
+	// along with localhost, this machine's name and IP addresses
+	host string
+}
+
+// New creates a server backed by the given finder. onKill, if set, is
+// called after every successful kill.
+func New(finder process.Finder, onKill func(*process.Process, process.KillStrategy)) *Server {
+	return &Server{finder: finder, onKill: onKill}
+}
+
+// SetKillGuard sets a function run before every kill; an error from it
+// refuses the kill with 403 Forbidden
+func (s *Server) SetKillGuard(guard func(*process.Process) error) {
+	s.guard = guard
+}
+
+// SetToken requires every request to carry the token in an
+// "Authorization: Bearer" header
+func (s *Server) SetToken(token string) {
+	s.token = token
+}
+
+// NewToken makes up a random token for a server that wasn't given one
+func NewToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Handler returns the HTTP handler serving the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ports", s.listPorts)
+	mux.HandleFunc("GET /ports/{port}", s.getPort)
+	mux.HandleFunc("DELETE /ports/{port}", s.killPort)
+	return s.protect(mux)
+}
+
+// protect refuses requests for other hosts, which is how a web page using
+// DNS rebinding gets a browser to call the API, and requests without the
+// token
+func (s *Server) protect(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.allowedHost(r.Host) {
+			writeError(w, http.StatusForbidden, fmt.Errorf("host %q is not served here", r.Host))
+			return
+		}
+		if s.token != "" && !s.authorized(r) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="portfinder"`)
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid API token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowedHost reports whether the Host header names this server: by IP
+// address, which rebinding can't fake, as localhost, by the address it
+// listens on or by this machine's name
+func (s *Server) allowedHost(hostport string) bool {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport
+	}
+	host = strings.TrimSuffix(strings.Trim(host, "[]"), ".")
+	if net.ParseIP(host) != nil {
+		return true
+	}
+	if strings.EqualFold(host, "localhost") || (s.host != "" && strings.EqualFold(host, s.host)) {
+		return true
+	}
+	hostname, err := os.Hostname()
+	return err == nil && strings.EqualFold(host, hostname)
+}
+
+// authorized checks the bearer token of a request
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+// ListenAndServe serves the API on the given address until it fails or
+// ctx is done, which also cancels the lookups of requests in flight
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		s.host = host
+	}
+	srv := &http.Server{
+		Addr:        addr,
+		Handler:     s.Handler(),
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	stop := context.AfterFunc(ctx, func() { srv.Close() })
+	defer stop()
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// errorResponse is the body of every non-2xx response
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// killResponse is the body returned after a successful kill
+type killResponse struct {
+	Killed   *process.Process `json:"killed"`
+	Strategy string           `json:"strategy"`
+}
+
+func (s *Server) listPorts(w http.ResponseWriter, r *http.Request) {
+	processes, err := s.finder.ListAll(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	sort.Slice(processes, func(i, j int) bool {
+		return processes[i].Port < processes[j].Port
+	})
+
+	if processes == nil {
+		processes = []*process.Process{}
+	}
+	writeJSON(w, http.StatusOK, processes)
+}
+
+func (s *Server) getPort(w http.ResponseWriter, r *http.Request) {
+	proc, status, err := s.lookup(r)
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, proc)
+}
+
+func (s *Server) killPort(w http.ResponseWriter, r *http.Request) {
+	proc, status, err := s.lookup(r)
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+
+	if s.guard != nil {
+		if err := s.guard(proc); err != nil {
+			writeError(w, http.StatusForbidden, err)
+			return
+		}
+	}
+
+	strategy := process.SelectStrategy(proc)
+	if name := r.URL.Query().Get("strategy"); name != "" && name != "auto" {
+		if strategy, err = process.StrategyByName(name); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	err = proc.KillWith(strategy)
+	// Even a failed kill may have stopped the process
+	process.Invalidate(s.finder)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if s.onKill != nil {
+		s.onKill(proc, strategy)
+	}
+
+	writeJSON(w, http.StatusOK, killResponse{Killed: proc, Strategy: strategy.Name()})
+}
+
+// lookup resolves the {port} path value to the process using it
+func (s *Server) lookup(r *http.Request) (*process.Process, int, error) {
+	port, err := strconv.Atoi(r.PathValue("port"))
+	if err != nil || port < 1 || port > 65535 {
+		return nil, http.StatusBadRequest, errors.New("invalid port number")
+	}
+
+	proc, err := s.finder.FindByPort(r.Context(), port)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if proc == nil {
+		return nil, http.StatusNotFound, errors.New("port is not in use")
+	}
+
+	return proc, http.StatusOK, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/doganarif/portfinder/internal/process"
+)
+
+// noFinder finds nothing, so requests that get through answer 200 or 404
+type noFinder struct{}
+
+func (noFinder) FindByPort(ctx context.Context, port int) (*process.Process, error) {
+	return nil, nil
+}
+
+func (noFinder) FindByPorts(ctx context.Context, ports []int) (map[int]*process.Process, error) {
+	return map[int]*process.Process{}, nil
+}
+
+func (noFinder) ListAll(ctx context.Context) ([]*process.Process, error) {
+	return nil, nil
+}
+
+func TestProtect(t *testing.T) {
+	s := New(noFinder{}, nil)
+	s.SetToken("s3cret")
+	s.host = "devbox.lan"
+	handler := s.Handler()
+
+	tests := []struct {
+		name   string
+		method string
+		host   string
+		auth   string
+		want   int
+	}{
+		{"list", http.MethodGet, "127.0.0.1:7777", "Bearer s3cret", http.StatusOK},
+		{"localhost", http.MethodGet, "localhost:7777", "Bearer s3cret", http.StatusOK},
+		{"ipv6", http.MethodGet, "[::1]:7777", "Bearer s3cret", http.StatusOK},
+		{"listen address", http.MethodGet, "devbox.lan:7777", "Bearer s3cret", http.StatusOK},
+		{"no token", http.MethodGet, "127.0.0.1:7777", "", http.StatusUnauthorized},
+		{"wrong token", http.MethodGet, "127.0.0.1:7777", "Bearer guess", http.StatusUnauthorized},
+		{"basic auth", http.MethodGet, "127.0.0.1:7777", "Basic czNjcmV0", http.StatusUnauthorized},
+		{"kill without token", http.MethodDelete, "127.0.0.1:7777", "", http.StatusUnauthorized},
+		{"rebound host", http.MethodGet, "attacker.example:7777", "Bearer s3cret", http.StatusForbidden},
+		{"rebound kill", http.MethodDelete, "attacker.example", "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := "/ports"
+			if tt.method == http.MethodDelete {
+				path = "/ports/3000"
+			}
+			req := httptest.NewRequest(tt.method, path, nil)
+			req.Host = tt.host
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("%s %s for %s = %d, want %d", tt.method, path, tt.host, rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientSendsToken(t *testing.T) {
+	s := New(noFinder{}, nil)
+	s.SetToken("s3cret")
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	if _, err := NewClient(ts.URL, "s3cret").ListAll(context.Background()); err != nil {
+		t.Errorf("ListAll() with the token error = %v", err)
+	}
+	if _, err := NewClient(ts.URL, "").ListAll(context.Background()); err == nil {
+		t.Error("ListAll() without the token succeeded")
+	}
+}
@@ -0,0 +1,110 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/doganarif/portfinder/internal/process"
+)
+
+// Change kinds
+const (
+	Appeared  = "appeared"
+	Vanished  = "vanished"
+	Replaced  = "replaced"
+	Restarted = "restarted"
+)
+
+// Snapshot is the port/process map at one point in time
+type Snapshot struct {
+	Time      time.Time          `json:"time"`
+	Host      string             `json:"host,omitempty"`
+	Processes []*process.Process `json:"processes"`
+}
+
+// Change is a port whose listener differs between two snapshots. Before
+// is nil for appeared ports and After for vanished ones.
+type Change struct {
+	Change string           `json:"change"`
+	Port   int              `json:"port"`
+	Before *process.Process `json:"before,omitempty"`
+	After  *process.Process `json:"after,omitempty"`
+}
+
+// New captures the processes as a snapshot taken now
+func New(processes []*process.Process) *Snapshot {
+	host, _ := os.Hostname()
+	return &Snapshot{Time: time.Now(), Host: host, Processes: processes}
+}
+
+// Save writes the snapshot as indented JSON
+func (s *Snapshot) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Load reads a snapshot written by Save
+func Load(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("%s is not a snapshot: %w", path, err)
+	}
+	return &s, nil
+}
+
+// Diff returns the ports whose listener appeared, vanished or changed
+// since the snapshot, in port order. A port held by the same program
+// under a new PID was restarted; one held by another program was
+// replaced.
+func (s *Snapshot) Diff(processes []*process.Process) []Change {
+	before, after := byPort(s.Processes), byPort(processes)
+
+	ports := make([]int, 0, len(before)+len(after))
+	for port := range before {
+		ports = append(ports, port)
+	}
+	for port := range after {
+		if _, ok := before[port]; !ok {
+			ports = append(ports, port)
+		}
+	}
+	sort.Ints(ports)
+
+	changes := []Change{}
+	for _, port := range ports {
+		b, a := before[port], after[port]
+		switch {
+		case b == nil:
+			changes = append(changes, Change{Change: Appeared, Port: port, After: a})
+		case a == nil:
+			changes = append(changes, Change{Change: Vanished, Port: port, Before: b})
+		case b.Name != a.Name || b.Command != a.Command:
+			changes = append(changes, Change{Change: Replaced, Port: port, Before: b, After: a})
+		// Start times are only reliable to the second on every platform
+		case b.PID != a.PID || b.StartTime.Unix() != a.StartTime.Unix():
+			changes = append(changes, Change{Change: Restarted, Port: port, Before: b, After: a})
+		}
+	}
+	return changes
+}
+
+// byPort maps each port to the first process listening on it
+func byPort(processes []*process.Process) map[int]*process.Process {
+	ports := make(map[int]*process.Process, len(processes))
+	for _, p := range processes {
+		if _, seen := ports[p.Port]; !seen {
+			ports[p.Port] = p
+		}
+	}
+	return ports
+}
@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/doganarif/portfinder/internal/advisor"
+	"github.com/doganarif/portfinder/internal/config"
+	"github.com/doganarif/portfinder/internal/process"
+)
+
+// accessibleMode writes results as linear, labeled sentences for screen
+// readers, one line per port, instead of tables
+var accessibleMode bool
+
+// SetAccessible switches to output for screen readers. It implies ASCII
+// mode: no colors, emoji, spinners or full screen views.
+func SetAccessible(enabled bool) {
+	accessibleMode = enabled
+	if enabled {
+		SetASCII(true)
+	}
+}
+
+// describeListener labels each shown column of a listener, or of the
+// listeners of one process, e.g. "Port 3000: Process node, PID 4242,
+// Project myapp", leaving out unknown values
+func describeListener(ports []*process.Process, names []string, labels map[int]string, restarts map[int]int) string {
+	p := ports[0]
+	port := fmt.Sprintf("Port %d", p.Port)
+	if len(ports) > 1 {
+		port = "Ports " + mergedPorts(ports)
+	}
+	var parts []string
+	for _, name := range names {
+		if name == "port" {
+			continue
+		}
+		value := mergedCell(name, ports, labels, restarts)
+		if name == "project" {
+			value = projectLabel(p)
+		}
+		if value == "" || value == "-" || value == "unknown" || p.SocketOnly && name == "name" {
+			continue
+		}
+		parts = append(parts, columnDefs[name].title+" "+value)
+	}
+	if p.SocketOnly {
+		parts = append([]string{"process hidden by this environment"}, parts...)
+	}
+	if len(parts) == 0 {
+		return port + "."
+	}
+	return fmt.Sprintf("%s: %s.", port, strings.Join(parts, ", "))
+}
+
+// accessibleList writes one sentence per listener
+func accessibleList(processes []*process.Process, opts ListOptions) {
+	if len(processes) == 0 {
+		fmt.Println("No processes are using network ports.")
+		return
+	}
+	sort.Slice(processes, func(i, j int) bool {
+		return processes[i].Port < processes[j].Port
+	})
+
+	// As in the plain table, the type is left out unless it was picked
+	names := visibleColumns(usageColumnsPicked() && !opts.Remote, len(opts.Labels) > 0)
+	if shownColumns == nil {
+		names = removeColumn(names, "type")
+	}
+	if usageColumnsPicked() && !opts.Remote {
+		sampleUsageNow(processes)
+	}
+	fmt.Printf("%s using network ports.\n", pluralize(len(processes), "process is", "processes are"))
+	for _, ports := range listedRows(processes, opts.Merge) {
+		fmt.Println(describeListener(ports, names, opts.Labels, opts.Restarts))
+	}
+	if notice := socketOnlyNotice(processes, opts.Elevated); notice != "" {
+		fmt.Println(notice)
+	}
+}
+
+// accessibleCheck writes each group's count of used ports, then a
+// sentence per port
+func accessibleCheck(ports map[int]*process.Process, groups []config.PortGroup, hints map[int][]advisor.Suggestion) {
+	for _, group := range groups {
+		used := 0
+		for _, port := range group.Ports {
+			if ports[port] != nil {
+				used++
+			}
+		}
+		fmt.Printf("%s: %s, %d in use.\n", group.Name, pluralize(len(group.Ports), "port", "ports"), used)
+
+		for _, port := range group.Ports {
+			name := fmt.Sprintf("Port %d", port)
+			if label := group.Labels[port]; label != "" {
+				name += " (" + label + ")"
+			}
+			proc := ports[port]
+			if proc == nil {
+				fmt.Printf("%s: free.\n", name)
+				continue
+			}
+
+			used := name + ": in use by " + proc.Name
+			if proc.SocketOnly {
+				used = name + ": in use by a process hidden by this environment"
+			} else if proc.PID > 0 {
+				used += fmt.Sprintf(", PID %d", proc.PID)
+			}
+			if proc.ProjectPath != "" && proc.ProjectPath != "unknown" {
+				used += ", Project " + projectLabel(proc)
+			}
+			fmt.Println(used + ".")
+			if h := hints[port]; len(h) > 0 {
+				fmt.Printf("Suggestion: %s\n", h[0])
+			}
+		}
+	}
+}
+
+// accessibleKillPlan writes a sentence per process a bulk kill targets
+func accessibleKillPlan(processes []*process.Process, dryRun bool) {
+	verb := "will"
+	if dryRun {
+		verb = "would"
+	}
+	fmt.Printf("%s %s be killed:\n", pluralize(len(processes), "process", "processes"), verb)
+	for _, p := range processes {
+		fmt.Printf("Port %d: Process %s, PID %d, Project %s.\n", p.Port, p.DisplayName(), p.PID, projectLabel(p))
+	}
+}
+
+// pluralize counts n things, e.g. "1 port" or "3 ports"
+func pluralize(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
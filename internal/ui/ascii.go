@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fatih/color"
+	"github.com/muesli/termenv"
+)
+
+// asciiMode replaces emoji, colors and box drawing with plain ASCII, for
+// terminals that can't render them. It implies plain output.
+var asciiMode bool
+
+// LimitedTerminal reports whether the terminal looks unable to render the
+// styled UI: TERM is dumb (or unset outside Windows, as in many CI
+// runners), or the locale doesn't use UTF-8
+func LimitedTerminal() bool {
+	term := os.Getenv("TERM")
+	if term == "dumb" || (term == "" && runtime.GOOS != "windows") {
+		return true
+	}
+
+	// The first locale variable set wins, as in setlocale(3)
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := os.Getenv(name); locale != "" {
+			locale = strings.ToLower(locale)
+			return !strings.Contains(locale, "utf-8") && !strings.Contains(locale, "utf8")
+		}
+	}
+
+	return false
+}
+
+// SetASCII switches between the styled UI and plain ASCII output
+func SetASCII(enabled bool) {
+	asciiMode = enabled
+	if enabled {
+		plainMode = true
+		color.NoColor = true
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+}
+
+// colorMode is the color setting, which only NO_COLOR can't override
+// when "always"
+var colorMode string
+
+// SetColorMode forces colors on ("always") or off ("never"), or leaves
+// them to the terminal ("auto" or "")
+func SetColorMode(mode string) {
+	colorMode = mode
+	switch mode {
+	case "always":
+		color.NoColor = false
+		lipgloss.SetColorProfile(termenv.ANSI256)
+	case "never":
+		color.NoColor = true
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+}
+
+// Icon picks the emoji or its ASCII replacement for the current mode
+func Icon(fancy, plain string) string {
+	if asciiMode {
+		return plain
+	}
+	return fancy
+}
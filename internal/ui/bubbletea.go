@@ -1,7 +1,14 @@
 package ui
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,6 +18,9 @@ import (
 	"github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/doganarif/portfinder/internal/advisor"
+	"github.com/doganarif/portfinder/internal/config"
+	"github.com/doganarif/portfinder/internal/output"
 	"github.com/doganarif/portfinder/internal/process"
 )
 
@@ -20,47 +30,111 @@ var (
 			PaddingRight(1)
 
 	headerStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("39")).
+			Foreground(lipgloss.Color(palette.Accent)).
 			Bold(true).
 			Padding(0, 1)
 
 	titleStyle = lipgloss.NewStyle().
-			Background(lipgloss.Color("62")).
-			Foreground(lipgloss.Color("230")).
+			Background(lipgloss.Color(palette.Title)).
+			Foreground(lipgloss.Color(palette.TitleText)).
 			Bold(true).
 			Padding(0, 1)
 
 	selectedStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("229")).
-			Background(lipgloss.Color("57")).
+			Foreground(lipgloss.Color(palette.SelectionText)).
+			Background(lipgloss.Color(palette.Selection)).
 			Bold(true)
 
 	portUsedStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("196")).
+			Foreground(lipgloss.Color(palette.Used)).
 			Bold(true)
 
 	portFreeStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("46")).
+			Foreground(lipgloss.Color(palette.Free)).
 			Bold(true)
 
 	dimStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240"))
+			Foreground(lipgloss.Color(palette.Dim))
 
 	infoStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("86"))
+			Foreground(lipgloss.Color(palette.Info))
+
+	hintStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color(palette.Hint))
 
 	dockerStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("39")).
+			Foreground(lipgloss.Color(palette.Accent)).
 			Bold(true)
+
+	paneStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color(palette.Border)).
+			Padding(0, 1)
+
+	dialogStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color(palette.Alert)).
+			Padding(1, 2)
+)
+
+const (
+	// splitMinWidth is the terminal width from which the detail pane is
+	// shown beside the table rather than below it
+	splitMinWidth = 140
+	// detailPaneWidth is the width of the side detail pane
+	detailPaneWidth = 44
+	// detailPaneHeight is the height reserved for the detail pane when it
+	// is stacked below the table
+	detailPaneHeight = 12
+	// openPaneWidth is the width of the side detail pane opened with enter,
+	// and openTableHeight how many rows of the table stay in view when it
+	// is stacked below instead
+	openPaneWidth   = 64
+	openTableHeight = 6
+	// usageInterval is how long CPU usage is sampled for the usage columns
+	usageInterval = 500 * time.Millisecond
+	// scrollStep is how many characters the arrow keys scroll long cells
+	scrollStep = 8
+	// niceStep is how much the priority keys change a nice value
+	niceStep = 5
 )
 
 type keyMap struct {
-	Up     key.Binding
-	Down   key.Binding
-	Kill   key.Binding
-	Quit   key.Binding
-	Help   key.Binding
-	Reload key.Binding
+	Up      key.Binding
+	Down    key.Binding
+	Kill    key.Binding
+	Pause   key.Binding
+	Resume  key.Binding
+	Nicer   key.Binding
+	Unnicer key.Binding
+	Logs    key.Binding
+	Quit    key.Binding
+	Help    key.Binding
+	Reload  key.Binding
+	Export  key.Binding
+	Save    key.Binding
+	Tree    key.Binding
+	Select  key.Binding
+	Clear   key.Binding
+	Usage   key.Binding
+	Group   key.Binding
+	Merge   key.Binding
+	Fold    key.Binding
+	Elevate key.Binding
+	Left    key.Binding
+	Right   key.Binding
+	Details key.Binding
+	Copy    key.Binding
+	Open    key.Binding
+
+	SortPort    key.Binding
+	SortPID     key.Binding
+	SortName    key.Binding
+	SortUptime  key.Binding
+	SortProject key.Binding
+	SortConns   key.Binding
+	SortCPU     key.Binding
+	SortMemory  key.Binding
 }
 
 // ShortHelp returns keybindings to be shown in the mini help view
@@ -71,8 +145,9 @@ func (k keyMap) ShortHelp() []key.Binding {
 // FullHelp returns keybindings for the expanded help view
 func (k keyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.Up, k.Down},
-		{k.Kill, k.Reload},
+		{k.Up, k.Down, k.Left, k.Right},
+		{k.Details, k.Kill, k.Pause, k.Resume, k.Nicer, k.Unnicer, k.Logs, k.Select, k.Clear, k.Reload, k.Elevate, k.Copy, k.Open, k.Export, k.Save, k.Tree, k.Usage, k.Group, k.Merge, k.Fold},
+		{k.SortPort, k.SortPID, k.SortName, k.SortUptime, k.SortProject, k.SortConns, k.SortCPU, k.SortMemory},
 		{k.Help, k.Quit},
 	}
 }
@@ -90,6 +165,26 @@ var keys = keyMap{
 		key.WithKeys("delete", "d"),
 		key.WithHelp("del/d", "kill process"),
 	),
+	Pause: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "pause process"),
+	),
+	Resume: key.NewBinding(
+		key.WithKeys("S"),
+		key.WithHelp("S", "resume process"),
+	),
+	Nicer: key.NewBinding(
+		key.WithKeys("-"),
+		key.WithHelp("-", "lower priority"),
+	),
+	Unnicer: key.NewBinding(
+		key.WithKeys("+"),
+		key.WithHelp("+", "raise priority"),
+	),
+	Logs: key.NewBinding(
+		key.WithKeys("l"),
+		key.WithHelp("l", "follow logs"),
+	),
 	Quit: key.NewBinding(
 		key.WithKeys("q", "ctrl+c"),
 		key.WithHelp("q", "quit"),
@@ -102,11 +197,186 @@ var keys = keyMap{
 		key.WithKeys("r"),
 		key.WithHelp("r", "reload"),
 	),
+	Export: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "export view"),
+	),
+	Save: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "export to the --export file"),
+	),
+	Tree: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "toggle tree"),
+	),
+	Select: key.NewBinding(
+		key.WithKeys(" "),
+		key.WithHelp("space", "select for bulk kill"),
+	),
+	Clear: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "clear selection"),
+	),
+	Usage: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "toggle CPU/memory"),
+	),
+	Group: key.NewBinding(
+		key.WithKeys("g"),
+		key.WithHelp("g", "group by project"),
+	),
+	Merge: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "merge ports per process"),
+	),
+	Fold: key.NewBinding(
+		key.WithKeys("z"),
+		key.WithHelp("z", "fold project or ports"),
+	),
+	Elevate: key.NewBinding(
+		key.WithKeys("R"),
+		key.WithHelp("R", "rescan with sudo"),
+	),
+	Left: key.NewBinding(
+		key.WithKeys("left"),
+		key.WithHelp("←", "scroll long fields left"),
+	),
+	Right: key.NewBinding(
+		key.WithKeys("right"),
+		key.WithHelp("→", "scroll long fields right"),
+	),
+	Details: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "toggle details"),
+	),
+	Copy: key.NewBinding(
+		key.WithKeys("y"),
+		key.WithHelp("y", "copy PID, port, kill command or path"),
+	),
+	Open: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "open project"),
+	),
+	SortPort: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "sort by port"),
+	),
+	SortPID: key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "sort by PID"),
+	),
+	SortName: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "sort by name"),
+	),
+	SortUptime: key.NewBinding(
+		key.WithKeys("u"),
+		key.WithHelp("u", "sort by uptime"),
+	),
+	SortProject: key.NewBinding(
+		key.WithKeys("P"),
+		key.WithHelp("P", "sort by project"),
+	),
+	SortConns: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "sort by connections"),
+	),
+	SortCPU: key.NewBinding(
+		key.WithKeys("C"),
+		key.WithHelp("C", "sort by CPU"),
+	),
+	SortMemory: key.NewBinding(
+		key.WithKeys("M"),
+		key.WithHelp("M", "sort by memory"),
+	),
+}
+
+// keyNames maps key identifiers to the short labels shown in the help view
+var keyNames = map[string]string{
+	"up":     "↑",
+	"down":   "↓",
+	"left":   "←",
+	"right":  "→",
+	"delete": "del",
+	" ":      "space",
+}
+
+// SetKeyBindings remaps TUI actions (up, down, kill, pause, resume, logs, quit, help, reload,
+// export, save, tree, select, clear, usage, group, merge, fold, elevate, left, right, details, copy, open,
+// lower_priority, raise_priority, sort_port, sort_pid, sort_name, sort_uptime,
+// sort_project, sort_connections, sort_cpu, sort_memory) to the given keys, updating the help view to match.
+// "sort" is kept as the old name of sort_connections.
+func SetKeyBindings(overrides map[string][]string) error {
+	bindings := map[string]*key.Binding{
+		"up":      &keys.Up,
+		"down":    &keys.Down,
+		"kill":    &keys.Kill,
+		"pause":   &keys.Pause,
+		"resume":  &keys.Resume,
+		"logs":    &keys.Logs,
+		"quit":    &keys.Quit,
+		"help":    &keys.Help,
+		"reload":  &keys.Reload,
+		"export":  &keys.Export,
+		"save":    &keys.Save,
+		"tree":    &keys.Tree,
+		"select":  &keys.Select,
+		"clear":   &keys.Clear,
+		"usage":   &keys.Usage,
+		"group":   &keys.Group,
+		"merge":   &keys.Merge,
+		"fold":    &keys.Fold,
+		"elevate": &keys.Elevate,
+		"left":    &keys.Left,
+		"right":   &keys.Right,
+		"details": &keys.Details,
+		"copy":    &keys.Copy,
+		"open":    &keys.Open,
+
+		"lower_priority": &keys.Nicer,
+		"raise_priority": &keys.Unnicer,
+
+		"sort_port":        &keys.SortPort,
+		"sort_pid":         &keys.SortPID,
+		"sort_name":        &keys.SortName,
+		"sort_uptime":      &keys.SortUptime,
+		"sort_project":     &keys.SortProject,
+		"sort_connections": &keys.SortConns,
+		"sort_cpu":         &keys.SortCPU,
+		"sort_memory":      &keys.SortMemory,
+		"sort":             &keys.SortConns,
+	}
+
+	for action, keyList := range overrides {
+		binding, ok := bindings[action]
+		if !ok {
+			return fmt.Errorf("unknown key binding action %q", action)
+		}
+		if len(keyList) == 0 {
+			return fmt.Errorf("no keys given for action %q", action)
+		}
+
+		labels := make([]string, len(keyList))
+		for i, k := range keyList {
+			labels[i] = k
+			if name, ok := keyNames[k]; ok {
+				labels[i] = name
+			}
+		}
+
+		binding.SetKeys(keyList...)
+		binding.SetHelp(strings.Join(labels, "/"), binding.Help().Desc)
+	}
+
+	return nil
 }
 
 // ProcessListModel represents the process list view
 type ProcessListModel struct {
 	processes    []*process.Process
+	provider     Provider
+	snapshots    <-chan Snapshot
+	cancel       context.CancelFunc
 	table        table.Model
 	spinner      spinner.Model
 	loading      bool
@@ -117,85 +387,450 @@ type ProcessListModel struct {
 	height       int
 	message      string
 	messageTimer *time.Timer
+	exporting    bool
+	// exportPath and exportFormat are the file x writes the view to,
+	// exporting through the picker when there is none
+	exportPath   string
+	exportFormat output.Format
+	// copying shows the copy picker, which swallows the next key press
+	copying bool
+	// tree groups listeners under the app that spawned them
+	tree bool
+	// grouped puts the listeners of each project together under a header
+	// row, hiding those of the collapsed projects. rows maps the table rows
+	// to what they show.
+	grouped   bool
+	collapsed map[string]bool
+	// merged shows each process once with all its ports, followed by a
+	// row per port for the expanded processes, by PID
+	merged   bool
+	expanded map[int]bool
+	rows     []listRow
+	// sortBy is the column the rows are ordered by, reversed by sortDesc
+	sortBy   sortColumn
+	sortDesc bool
+	// selected holds the IDs of the processes marked for a bulk kill, and
+	// confirming the processes awaiting confirmation to be killed
+	selected   map[process.ProcessID]bool
+	confirming []*process.Process
+	// confirmKill asks before killing a single process as well
+	confirmKill bool
+	labels      map[int]string
+	restarts    map[int]int
+	// usage shows the CPU and memory columns, sampled after each refresh
+	// unless the processes are remote
+	usage  bool
+	remote bool
+	// dryRun reports what kills would do instead of killing
+	dryRun bool
+	// scroll is how far long project and command cells are scrolled
+	// sideways
+	scroll int
+	// elevation rescans with more privileges on request, until it is used;
+	// elevated is set from then on
+	elevation *Elevation
+	elevated  bool
+	// details opens the detail pane wide, with the full command and the
+	// environment of the highlighted process, cached in env
+	details bool
+	env     paneEnv
+	// logs follows the log of a process in place of the table
+	logs *logPane
 }
 
-// ProcessDetailModel represents a single process detail view
-type ProcessDetailModel struct {
-	process *process.Process
-	width   int
-	height  int
+// paneEnv is the environment of the process the detail pane shows
+type paneEnv struct {
+	id   process.ProcessID
+	vars []string
+	err  error
 }
 
-// NewProcessListModel creates a new process list model
-func NewProcessListModel(processes []*process.Process) ProcessListModel {
-	columns := []table.Column{
-		{Title: "Port", Width: 8},
-		{Title: "Process", Width: 15},
-		{Title: "PID", Width: 8},
-		{Title: "Project", Width: 30},
-		{Title: "Running For", Width: 15},
-		{Title: "Type", Width: 10},
-	}
+// ListOptions configures the process list view
+type ListOptions struct {
+	// Tree starts the list in tree mode
+	Tree bool
+	// Merge shows each process once with all its ports
+	Merge bool
+	// ExportPath is the file x writes the list to, in ExportFormat
+	ExportPath   string
+	ExportFormat output.Format
+	// Labels annotate ports with notes from the config
+	Labels map[int]string
+	// Restarts flags crash looping ports with their recent restart count
+	Restarts map[int]int
+	// ConfirmKill asks for confirmation before killing a single process;
+	// bulk kills are always confirmed
+	ConfirmKill bool
+	// Remote marks processes of another host, whose CPU and memory usage
+	// can't be sampled
+	Remote bool
+	// DryRun shows which signals kills would send without killing
+	DryRun bool
+	// Elevation offers a rescan seeing every user's processes, when pf
+	// runs without the privileges to
+	Elevation *Elevation
+	// Elevated marks processes already listed with those privileges
+	Elevated bool
+}
 
-	rows := make([]table.Row, len(processes))
-	for i, p := range processes {
-		rows[i] = processToRow(p)
-	}
+// Elevation lets the list rescan with more privileges, e.g. through sudo
+type Elevation struct {
+	// Authenticate returns the command asking for credentials, run with
+	// the terminal handed over to it
+	Authenticate func() *exec.Cmd
+	// Provider lists the processes with the privileges, replacing the
+	// list's provider once authenticated
+	Provider Provider
+}
+
+// elevatedMsg reports the outcome of the elevation's authentication
+type elevatedMsg struct{ err error }
 
+// NewProcessListModel creates a new process list model
+func NewProcessListModel(processes []*process.Process, provider Provider, opts ListOptions) ProcessListModel {
 	t := table.New(
-		table.WithColumns(columns),
-		table.WithRows(rows),
 		table.WithFocused(true),
 		table.WithHeight(15),
 	)
+	// Navigate with the (possibly remapped) bindings shown in the help view
+	t.KeyMap.LineUp = keys.Up
+	t.KeyMap.LineDown = keys.Down
+	// d, u and g are list actions, so the table mustn't scroll on them too
+	t.KeyMap.HalfPageUp.SetKeys("ctrl+u")
+	t.KeyMap.HalfPageDown.SetKeys("ctrl+d")
+	t.KeyMap.GotoTop.SetKeys("home")
 
 	s := table.DefaultStyles()
 	s.Header = s.Header.
 		BorderStyle(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("240")).
+		BorderForeground(lipgloss.Color(palette.Dim)).
 		BorderBottom(true).
 		Bold(false)
 	s.Selected = s.Selected.
-		Foreground(lipgloss.Color("229")).
-		Background(lipgloss.Color("57")).
+		Foreground(lipgloss.Color(palette.SelectionText)).
+		Background(lipgloss.Color(palette.Selection)).
+		Reverse(reverseSelection()).
 		Bold(false)
 	t.SetStyles(s)
 
 	sp := spinner.New()
 	sp.Spinner = spinner.Dot
-	sp.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+	sp.Style = lipgloss.NewStyle().Foreground(lipgloss.Color(palette.Spinner))
 
-	return ProcessListModel{
+	m := ProcessListModel{
 		processes: processes,
+		provider:  provider,
+		snapshots: provider.Subscribe(),
 		table:     t,
 		spinner:   sp,
+		tree:      opts.Tree,
+		merged:    opts.Merge,
+		labels:    opts.Labels,
+		restarts:  opts.Restarts,
 		help:      help.New(),
+
+		exportPath:   opts.ExportPath,
+		exportFormat: opts.ExportFormat,
+
+		confirmKill: opts.ConfirmKill,
+		remote:      opts.Remote,
+		dryRun:      opts.DryRun,
+		elevation:   opts.Elevation,
+		elevated:    opts.Elevated,
+		usage:       usageColumnsPicked() && !opts.Remote,
+	}
+	m.setRows()
+	return m
+}
+
+// layoutColumns returns the columns to show and their widths: those
+// picked with SetColumns, or the default ones, fitted to the table's width
+// once the terminal size is known. It clamps the sideways scroll of long
+// cells to what is hidden.
+func (m *ProcessListModel) layoutColumns() ([]string, []table.Column) {
+	names := visibleColumns(m.usage, len(m.labels) > 0)
+	width := 0
+	if m.width > 0 {
+		width = m.table.Width()
+		if len(m.selected) > 0 {
+			width -= checkboxWidth + cellPadding
+		}
+	}
+
+	longest := make(map[string]int)
+	for _, p := range m.processes {
+		for _, name := range names {
+			if columnDef(name, m.tree).minWidth > 0 {
+				longest[name] = max(longest[name], len([]rune(cellText(name, p, m.tree, m.labels, m.restarts))))
+			}
+		}
+	}
+	names, columns := fitColumns(names, m.tree, width, longest)
+
+	maxScroll := 0
+	for i, name := range names {
+		// A scrolled cell starts with a marker taking one character
+		if scrollable(name) && longest[name] > columns[i].Width {
+			maxScroll = max(maxScroll, longest[name]-columns[i].Width+1)
+		}
+	}
+	m.scroll = max(0, min(m.scroll, maxScroll))
+	return names, columns
+}
+
+// setRows rebuilds the table from the processes, ordered by the sort
+// column. In tree mode listeners of the same app are then kept together,
+// in grouped mode those of the same project, and in merged mode those of
+// the same process share a row. The header follows the
+// mode and sort, with a leading checkbox column while processes are
+// selected.
+func (m *ProcessListModel) setRows() {
+	sortProcesses(m.processes, m.sortBy, m.sortDesc)
+	if m.tree {
+		for _, p := range m.processes {
+			if p.Lineage == nil {
+				p.LoadLineage()
+			}
+		}
+		sort.SliceStable(m.processes, func(i, j int) bool {
+			return m.processes[i].Root().PID < m.processes[j].Root().PID
+		})
+	}
+
+	switch {
+	case m.grouped:
+		m.rows = groupRows(m.processes, m.collapsed)
+	case m.merged:
+		m.rows = mergeRows(m.processes, m.expanded)
+	default:
+		m.rows = make([]listRow, len(m.processes))
+		for i, p := range m.processes {
+			m.rows[i] = listRow{process: p}
+		}
+	}
+
+	names, columns := m.layoutColumns()
+	rows := make([]table.Row, len(m.rows))
+	for i, r := range m.rows {
+		switch {
+		case r.process == nil:
+			rows[i] = groupHeaderRow(r, columns, m.collapsed[r.group])
+		case len(r.ports) > 0:
+			row := processToRow(r.process, names, columns, m.tree, m.labels, m.restarts, m.scroll)
+			rows[i] = mergedRow(r, row, names, columns, m.expanded[r.process.PID], m.labels)
+		default:
+			rows[i] = processToRow(r.process, names, columns, m.tree, m.labels, m.restarts, m.scroll)
+		}
+		if len(m.selected) > 0 {
+			rows[i] = append(table.Row{checkbox(m.allSelected(r))}, rows[i]...)
+		}
+	}
+
+	columns = markSortColumn(columns, m.sortBy, m.sortDesc)
+	if len(m.selected) > 0 {
+		columns = append([]table.Column{{Title: "", Width: checkboxWidth}}, columns...)
+	}
+
+	// The table renders on every change and can't have rows with more
+	// cells than columns
+	if len(columns) > len(m.table.Columns()) {
+		m.table.SetColumns(columns)
+		m.table.SetRows(rows)
+	} else {
+		m.table.SetRows(rows)
+		m.table.SetColumns(columns)
+	}
+}
+
+func processToRow(p *process.Process, names []string, columns []table.Column, tree bool, labels map[int]string, restarts map[int]int, scroll int) table.Row {
+	row := make(table.Row, len(names))
+	for i, name := range names {
+		row[i] = cellText(name, p, tree, labels, restarts)
+		switch {
+		case name == "name" && tree:
+			row[i] = truncateLeft(row[i], columns[i].Width)
+		case scrollable(name):
+			row[i] = truncate(scrollText(row[i], scroll, columns[i].Width), columns[i].Width)
+		case name == "address" || name == "user" || name == "type" || name == "label":
+			row[i] = truncate(row[i], columns[i].Width)
+		}
+	}
+	return row
+}
+
+func checkbox(checked bool) string {
+	if checked {
+		return Icon("☑", "[x]")
 	}
+	return Icon("☐", "[ ]")
 }
 
-func processToRow(p *process.Process) table.Row {
-	projectPath := p.ProjectPath
-	if projectPath == "" || projectPath == "unknown" {
-		projectPath = "-"
+// allSelected reports whether the process of a row, or every process of a
+// group, is selected
+func (m ProcessListModel) allSelected(r listRow) bool {
+	if r.process != nil {
+		return m.selected[r.process.ID]
+	}
+	for _, p := range r.members {
+		if !m.selected[p.ID] {
+			return false
+		}
+	}
+	return true
+}
+
+// current returns the row under the cursor
+func (m ProcessListModel) current() (listRow, bool) {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.rows) {
+		return listRow{}, false
+	}
+	return m.rows[cursor], true
+}
+
+// rowProcesses returns the process of a row, or the processes of a group
+func rowProcesses(r listRow) []*process.Process {
+	if r.process != nil {
+		return []*process.Process{r.process}
+	}
+	return r.members
+}
+
+// selectedProcesses returns the selected processes in table order
+func (m ProcessListModel) selectedProcesses() []*process.Process {
+	var selected []*process.Process
+	for _, p := range m.processes {
+		if m.selected[p.ID] {
+			selected = append(selected, p)
+		}
+	}
+	return selected
+}
+
+// pruneSelection drops selected processes that are no longer listed
+func (m *ProcessListModel) pruneSelection() {
+	listed := make(map[process.ProcessID]bool, len(m.processes))
+	for _, p := range m.processes {
+		listed[p.ID] = true
+	}
+	for id := range m.selected {
+		if !listed[id] {
+			delete(m.selected, id)
+		}
+	}
+}
+
+// kill returns the function killing processes of this list, through the
+// provider when it knows how (e.g. on a remote host)
+func (m ProcessListModel) kill() func(*process.Process) error {
+	if k, ok := m.provider.(Killer); ok {
+		return k.Kill
+	}
+	return killProcess
+}
+
+// killAll kills the processes, removing the killed ones from the list and
+// selection, and returns a message with the outcome of each
+func (m *ProcessListModel) killAll(processes []*process.Process) string {
+	if m.dryRun {
+		return m.describeKills(processes)
+	}
+	kill := m.kill()
+	killed := make(map[process.ProcessID]bool)
+	killedPIDs := make(map[int]bool)
+	var lines []string
+	for _, p := range processes {
+		if err := kill(p); err != nil {
+			lines = append(lines, fmt.Sprintf("❌ %s (PID: %d): %v", p.Name, p.PID, err))
+			continue
+		}
+		killed[p.ID] = true
+		if p.PID > 0 {
+			killedPIDs[p.PID] = true
+		}
+		lines = append(lines, fmt.Sprintf("✅ Killed %s (PID: %d)", p.Name, p.PID))
 	}
 
-	processType := "Native"
-	if p.IsDocker {
-		processType = "Docker"
+	// The other ports of a killed process went with it
+	remaining := m.processes[:0]
+	for _, p := range m.processes {
+		if killed[p.ID] || killedPIDs[p.PID] && !p.SocketOnly {
+			delete(m.selected, p.ID)
+			continue
+		}
+		remaining = append(remaining, p)
 	}
+	m.processes = remaining
+	m.setRows()
+
+	return strings.Join(lines, "\n")
+}
 
-	return table.Row{
-		fmt.Sprintf("%d", p.Port),
-		p.Name,
-		fmt.Sprintf("%d", p.PID),
-		truncate(projectPath, 30),
-		formatDuration(time.Since(p.StartTime)),
-		processType,
+// pauseAll pauses or resumes the processes and returns a message with the
+// outcome of each
+func (m ProcessListModel) pauseAll(processes []*process.Process, pause bool) string {
+	if m.remote {
+		return hintStyle.Render("Processes on a remote host can't be paused here")
+	}
+	var lines []string
+	for _, p := range processes {
+		toggle, done := p.Resume, "▶️ Resumed"
+		if pause {
+			toggle, done = p.Pause, "⏸️ Paused"
+		}
+		if err := toggle(); err != nil {
+			lines = append(lines, fmt.Sprintf("❌ %s (PID: %d): %v", p.Name, p.PID, err))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s %s (PID: %d)", done, p.Name, p.PID))
 	}
+	return strings.Join(lines, "\n")
+}
+
+// renice moves the nice value of the process by step, within the valid
+// range, and returns a message with the outcome
+func renice(p *process.Process, step int) string {
+	before, err := p.Priority()
+	if err == nil {
+		err = p.SetPriority(min(max(before+step, process.MinNice), process.MaxNice))
+	}
+	if err != nil {
+		return portUsedStyle.Render(fmt.Sprintf("❌ %s (PID: %d): %v", p.Name, p.PID, err))
+	}
+	after, _ := p.Priority()
+	return infoStyle.Render(fmt.Sprintf("Priority of %s (PID: %d) changed from %d to %d (-20 highest, 19 lowest)", p.Name, p.PID, before, after))
+}
+
+// describeKills lists the signals killing the processes would send
+func (m ProcessListModel) describeKills(processes []*process.Process) string {
+	lines := []string{hintStyle.Render("Dry run, nothing was killed:")}
+	for _, p := range processes {
+		steps := []string{fmt.Sprintf("ask the remote host to kill PID %d", p.PID)}
+		if !m.remote {
+			steps = process.DescribeKill(process.SelectStrategy(p), p)
+		}
+		lines = append(lines, fmt.Sprintf("  %s (PID: %d): %s", p.Name, p.PID, strings.Join(steps, "; ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// reload refreshes the processes through the provider, abandoning a
+// refresh still in flight so it can't overwrite this one
+func (m *ProcessListModel) reload() tea.Cmd {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	var ctx context.Context
+	ctx, m.cancel = context.WithCancel(context.Background())
+	m.loading = true
+	return tea.Batch(refresh(ctx, m.provider), m.spinner.Tick)
 }
 
 func (m ProcessListModel) Init() tea.Cmd {
-	return m.spinner.Tick
+	if m.usage {
+		return tea.Batch(m.spinner.Tick, waitForSnapshot(m.snapshots), sampleUsage(m.processes))
+	}
+	return tea.Batch(m.spinner.Tick, waitForSnapshot(m.snapshots))
 }
 
 func (m ProcessListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -206,14 +841,84 @@ func (m ProcessListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		m.table.SetHeight(msg.Height - 10)
-		m.table.SetWidth(msg.Width - 4)
+		m.resizeTable()
+		m.setRows()
+		if m.logs != nil {
+			m.logs.resize(m.logPaneSize())
+		}
+
+	case logsMsg:
+		if m.logs != nil {
+			return m, m.logs.add(msg)
+		}
 
 	case tea.KeyMsg:
 		if m.loading {
 			return m, nil
 		}
 
+		// The logs pane takes the keys until it is closed
+		if m.logs != nil {
+			switch {
+			case key.Matches(msg, keys.Quit):
+				return m, tea.Quit
+			case key.Matches(msg, keys.Clear), key.Matches(msg, keys.Logs):
+				m.logs.close()
+				m.logs = nil
+				return m, nil
+			}
+			m.logs.view, cmd = m.logs.view.Update(msg)
+			return m, cmd
+		}
+
+		// The export picker swallows the next key press
+		if m.exporting {
+			m.exporting = false
+			if format, ok := exportFormats[msg.String()]; ok {
+				m.message = exportView(shownProcesses(m.rows), format)
+				m.messageTimer = time.NewTimer(3 * time.Second)
+				cmds = append(cmds, waitForTimer(m.messageTimer))
+			}
+			return m, tea.Batch(cmds...)
+		}
+
+		if m.copying {
+			m.copying = false
+			if target, ok := copyTargets[msg.String()]; ok {
+				m.message = m.copyField(target)
+				m.messageTimer = time.NewTimer(3 * time.Second)
+				cmds = append(cmds, waitForTimer(m.messageTimer))
+			}
+			return m, tea.Batch(cmds...)
+		}
+
+		// The confirmation prompt swallows the next key press as well
+		if m.confirming != nil {
+			if msg.String() == "y" || msg.String() == "Y" {
+				m.message = m.killAll(m.confirming)
+				m.messageTimer = time.NewTimer(5 * time.Second)
+				cmds = append(cmds, waitForTimer(m.messageTimer))
+			}
+			m.confirming = nil
+			return m, tea.Batch(cmds...)
+		}
+
+		// Pressing the active sort key again reverses the direction
+		if column, ok := sortKeyPressed(msg); ok {
+			if column == m.sortBy {
+				m.sortDesc = !m.sortDesc
+			} else {
+				m.sortBy, m.sortDesc = column, sortOrders[column].desc
+			}
+			// Sorting by usage needs it sampled
+			if (column == sortCPU || column == sortMemory) && !m.usage && !m.remote {
+				m.usage = true
+				cmds = append(cmds, sampleUsage(m.processes))
+			}
+			m.setRows()
+			return m, tea.Batch(cmds...)
+		}
+
 		switch {
 		case key.Matches(msg, keys.Quit):
 			return m, tea.Quit
@@ -221,38 +926,264 @@ func (m ProcessListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, keys.Help):
 			m.showHelp = !m.showHelp
 
-		case key.Matches(msg, keys.Kill):
-			if len(m.processes) > 0 && m.table.Cursor() < len(m.processes) {
-				proc := m.processes[m.table.Cursor()]
-				if err := proc.Kill(); err != nil {
-					m.message = fmt.Sprintf("❌ Failed to kill process: %v", err)
-				} else {
-					m.message = fmt.Sprintf("✅ Killed %s (PID: %d)", proc.Name, proc.PID)
-					// Remove from list
-					m.processes = append(m.processes[:m.table.Cursor()], m.processes[m.table.Cursor()+1:]...)
-					rows := make([]table.Row, len(m.processes))
-					for i, p := range m.processes {
-						rows[i] = processToRow(p)
+		case key.Matches(msg, keys.Select):
+			// On a group header, space selects the whole project
+			if r, ok := m.current(); ok {
+				selected := m.allSelected(r)
+				if m.selected == nil {
+					m.selected = make(map[process.ProcessID]bool)
+				}
+				for _, p := range rowProcesses(r) {
+					if selected {
+						delete(m.selected, p.ID)
+					} else {
+						m.selected[p.ID] = true
 					}
-					m.table.SetRows(rows)
 				}
+				m.setRows()
+				m.table.MoveDown(1)
+			}
+			// The table would page down on space as well
+			return m, nil
+
+		case key.Matches(msg, keys.Details):
+			m.details = !m.details
+			m.resizeTable()
+			m.setRows()
+
+		// Esc closes the open detail pane before it clears the selection
+		case key.Matches(msg, keys.Clear) && m.details:
+			m.details = false
+			m.resizeTable()
+			m.setRows()
+
+		case key.Matches(msg, keys.Clear):
+			if len(m.selected) > 0 {
+				m.selected = nil
+				m.setRows()
+			}
+
+		case key.Matches(msg, keys.Kill) && len(m.selected) > 0:
+			m.confirming = m.selectedProcesses()
+
+		case key.Matches(msg, keys.Kill):
+			if r, ok := m.current(); ok {
+				// Killing a project is a bulk kill, always confirmed
+				if r.process == nil {
+					m.confirming = r.members
+					break
+				}
+				proc := r.process
+				if m.confirmKill {
+					m.confirming = []*process.Process{proc}
+					break
+				}
+				m.message = m.killAll([]*process.Process{proc})
+				m.messageTimer = time.NewTimer(3 * time.Second)
+				cmds = append(cmds, waitForTimer(m.messageTimer))
+			}
+
+		case key.Matches(msg, keys.Pause), key.Matches(msg, keys.Resume):
+			processes := m.selectedProcesses()
+			if r, ok := m.current(); ok && len(processes) == 0 {
+				processes = rowProcesses(r)
+			}
+			if len(processes) == 0 {
+				break
+			}
+			m.message = m.pauseAll(processes, key.Matches(msg, keys.Pause))
+			m.messageTimer = time.NewTimer(3 * time.Second)
+			cmds = append(cmds, waitForTimer(m.messageTimer))
+
+		case key.Matches(msg, keys.Nicer), key.Matches(msg, keys.Unnicer):
+			r, ok := m.current()
+			if !ok || r.process == nil {
+				break
+			}
+			step := niceStep
+			if key.Matches(msg, keys.Unnicer) {
+				step = -niceStep
+			}
+			if m.remote {
+				m.message = hintStyle.Render("Processes on a remote host can't be reniced here")
+			} else {
+				m.message = renice(r.process, step)
+			}
+			m.messageTimer = time.NewTimer(3 * time.Second)
+			cmds = append(cmds, waitForTimer(m.messageTimer))
+
+		case key.Matches(msg, keys.Logs):
+			r, ok := m.current()
+			if !ok || r.process == nil {
+				break
+			}
+			width, height := m.logPaneSize()
+			logs, cmd, err := openLogs(r.process, width, height)
+			if err != nil {
+				m.message = portUsedStyle.Render(fmt.Sprintf("❌ %v", err))
 				m.messageTimer = time.NewTimer(3 * time.Second)
 				cmds = append(cmds, waitForTimer(m.messageTimer))
+				break
 			}
+			m.logs = logs
+			return m, cmd
+
+		case key.Matches(msg, keys.Export):
+			m.exporting = true
+
+		case key.Matches(msg, keys.Save):
+			if m.exportPath == "" {
+				m.exporting = true
+				break
+			}
+			m.message = exportFile(shownProcesses(m.rows), m.exportPath, m.exportFormat)
+			m.messageTimer = time.NewTimer(3 * time.Second)
+			cmds = append(cmds, waitForTimer(m.messageTimer))
+
+		case key.Matches(msg, keys.Copy):
+			m.copying = true
+
+		case key.Matches(msg, keys.Open):
+			r, ok := m.current()
+			if !ok || r.process == nil {
+				break
+			}
+			var hint string
+			dir := projectDir(r.process)
+			switch {
+			case m.remote:
+				hint = "Projects on a remote host can't be opened here"
+			case dir == "":
+				hint = "The project directory of this process is unknown"
+			default:
+				return m, openProject(dir)
+			}
+			m.message = hintStyle.Render(hint)
+			m.messageTimer = time.NewTimer(3 * time.Second)
+			cmds = append(cmds, waitForTimer(m.messageTimer))
+
+		case key.Matches(msg, keys.Tree):
+			m.tree = !m.tree
+			m.setRows()
+
+		case key.Matches(msg, keys.Left):
+			m.scroll -= scrollStep
+			m.setRows()
+
+		case key.Matches(msg, keys.Right):
+			m.scroll += scrollStep
+			m.setRows()
+
+		case key.Matches(msg, keys.Group):
+			m.grouped = !m.grouped
+			m.merged = false
+			m.setRows()
+
+		case key.Matches(msg, keys.Merge):
+			m.merged = !m.merged
+			m.grouped = false
+			m.setRows()
+
+		case key.Matches(msg, keys.Fold) && m.merged:
+			r, ok := m.current()
+			if !ok || r.process == nil {
+				break
+			}
+			pid := r.process.PID
+			if m.expanded == nil {
+				m.expanded = make(map[int]bool)
+			}
+			m.expanded[pid] = !m.expanded[pid]
+			m.setRows()
+			// Keep the cursor on the process that was folded
+			for i, row := range m.rows {
+				if len(row.ports) > 0 && row.process.PID == pid {
+					m.table.SetCursor(i)
+				}
+			}
+
+		case key.Matches(msg, keys.Fold) && m.grouped:
+			header, ok := groupOf(m.rows, m.table.Cursor())
+			if !ok {
+				break
+			}
+			if m.collapsed == nil {
+				m.collapsed = make(map[string]bool)
+			}
+			m.collapsed[header.group] = !m.collapsed[header.group]
+			m.setRows()
+			// Keep the cursor on the group that was folded
+			for i, row := range m.rows {
+				if row.process == nil && row.group == header.group {
+					m.table.SetCursor(i)
+				}
+			}
+
+		case key.Matches(msg, keys.Usage):
+			if m.remote {
+				m.message = hintStyle.Render("CPU and memory usage can't be sampled on a remote host")
+				m.messageTimer = time.NewTimer(3 * time.Second)
+				cmds = append(cmds, waitForTimer(m.messageTimer))
+				break
+			}
+			m.usage = !m.usage
+			if m.usage {
+				cmds = append(cmds, sampleUsage(m.processes))
+			}
+			m.setRows()
 
 		case key.Matches(msg, keys.Reload):
-			m.loading = true
-			cmds = append(cmds, reloadProcesses())
+			cmds = append(cmds, m.reload())
+
+		case key.Matches(msg, keys.Elevate):
+			if m.elevation == nil {
+				m.message = hintStyle.Render("Every process is visible already, or there's no sudo to rescan with")
+				m.messageTimer = time.NewTimer(3 * time.Second)
+				cmds = append(cmds, waitForTimer(m.messageTimer))
+				break
+			}
+			return m, tea.ExecProcess(m.elevation.Authenticate(), func(err error) tea.Msg {
+				return elevatedMsg{err: err}
+			})
 		}
 
-	case processesLoadedMsg:
+	case openedMsg:
+		m.message = openedMessage(msg)
+		m.messageTimer = time.NewTimer(3 * time.Second)
+		cmds = append(cmds, waitForTimer(m.messageTimer))
+
+	case elevatedMsg:
+		if msg.err != nil {
+			m.message = portUsedStyle.Render(fmt.Sprintf("❌ Elevation failed: %v", msg.err))
+			m.messageTimer = time.NewTimer(5 * time.Second)
+			cmds = append(cmds, waitForTimer(m.messageTimer))
+			break
+		}
+		m.provider = m.elevation.Provider
+		m.snapshots = m.provider.Subscribe()
+		m.elevation, m.elevated = nil, true
+		cmds = append(cmds, m.reload(), waitForSnapshot(m.snapshots))
+
+	case snapshotMsg:
 		m.loading = false
-		m.processes = msg.processes
-		rows := make([]table.Row, len(m.processes))
-		for i, p := range m.processes {
-			rows[i] = processToRow(p)
+		m.err = msg.Err
+		if msg.Err == nil {
+			m.processes = msg.Processes
+			m.pruneSelection()
+			m.setRows()
+			if m.usage {
+				cmds = append(cmds, sampleUsage(m.processes))
+			}
 		}
-		m.table.SetRows(rows)
+		cmds = append(cmds, waitForSnapshot(m.snapshots))
+
+	case usageMsg:
+		for _, p := range m.processes {
+			if u, ok := msg[p.PID]; ok {
+				p.CPUPercent, p.CPULimit, p.RSS = u.CPUPercent, u.CPULimit, u.RSS
+			}
+		}
+		m.setRows()
 
 	case timerExpiredMsg:
 		m.message = ""
@@ -266,10 +1197,28 @@ func (m ProcessListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	m.table, cmd = m.table.Update(msg)
 	cmds = append(cmds, cmd)
+	if m.details {
+		m.loadEnv()
+	}
 
 	return m, tea.Batch(cmds...)
 }
 
+// loadEnv reads the environment of the highlighted process for the open
+// detail pane, unless it is read already
+func (m *ProcessListModel) loadEnv() {
+	r, ok := m.current()
+	if !ok || r.process == nil || r.process.ID == m.env.id {
+		return
+	}
+	m.env = paneEnv{id: r.process.ID}
+	if m.remote {
+		m.env.err = fmt.Errorf("not available for a remote host")
+		return
+	}
+	m.env.vars, m.env.err = r.process.Environ()
+}
+
 func (m ProcessListModel) View() string {
 	var b strings.Builder
 
@@ -281,17 +1230,46 @@ func (m ProcessListModel) View() string {
 		return b.String()
 	}
 
-	if m.message != "" {
+	if m.logs != nil {
+		b.WriteString(m.logs.View())
+		return baseStyle.Render(b.String())
+	}
+
+	// The confirmation dialog replaces the list until it is answered
+	if m.confirming != nil {
+		b.WriteString(m.confirmView())
+		return baseStyle.Render(b.String())
+	}
+
+	if m.exporting {
+		b.WriteString(infoStyle.Render("Export as: [j] JSON  [y] YAML  [c] CSV  [m] Markdown  [esc] cancel") + "\n\n")
+	} else if m.copying {
+		b.WriteString(infoStyle.Render("Copy: [p] PID  [o] port  [k] kill command  [d] project path  [esc] cancel") + "\n\n")
+	} else if m.err != nil {
+		b.WriteString(portUsedStyle.Render(fmt.Sprintf("❌ Refresh failed: %v", m.err)) + "\n")
+		b.WriteString(dimStyle.Render("Showing the last loaded processes") + "\n\n")
+	} else if m.message != "" {
 		b.WriteString(m.message + "\n\n")
 	}
 
-	count := infoStyle.Render(fmt.Sprintf("Found %d processes using network ports", len(m.processes)))
-	b.WriteString(count + "\n\n")
+	summary := fmt.Sprintf("Found %d processes using network ports", len(m.processes))
+	if m.elevated {
+		summary += " (elevated)"
+	}
+	if len(m.selected) > 0 {
+		summary += fmt.Sprintf(" (%d selected, %s to kill them)", len(m.selected), keys.Kill.Help().Key)
+	}
+	count := infoStyle.Render(summary)
+	b.WriteString(count + "\n")
+	if notice := socketOnlyNotice(m.processes, m.elevated); notice != "" {
+		b.WriteString(hintStyle.Render(notice) + "\n")
+	}
+	b.WriteString("\n")
 
 	if len(m.processes) == 0 {
 		b.WriteString(dimStyle.Render("No processes are using network ports\n"))
 	} else {
-		b.WriteString(m.table.View())
+		b.WriteString(m.tableWithDetail())
 	}
 
 	b.WriteString("\n")
@@ -304,23 +1282,170 @@ func (m ProcessListModel) View() string {
 	return baseStyle.Render(b.String())
 }
 
+// confirmView renders the dialog asking to confirm a kill, with the
+// details that tell a dev server from a database
+func (m ProcessListModel) confirmView() string {
+	var content strings.Builder
+	if len(m.confirming) == 1 {
+		p := m.confirming[0]
+		content.WriteString(portUsedStyle.Render("Kill this process?") + "\n\n")
+		content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Process:"), p.DisplayName()))
+		content.WriteString(fmt.Sprintf("%s %d\n", headerStyle.Render("PID:"), p.PID))
+		content.WriteString(fmt.Sprintf("%s %d\n", headerStyle.Render("Port:"), p.Port))
+		content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Project:"), projectLabel(p)))
+		if p.ContainerName != "" {
+			content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Container:"), p.ContainerName))
+		}
+	} else {
+		content.WriteString(portUsedStyle.Render(fmt.Sprintf("Kill %d selected processes?", len(m.confirming))) + "\n\n")
+		for _, p := range m.confirming {
+			content.WriteString(fmt.Sprintf("  %-6d %s (PID %d)  %s\n", p.Port, p.DisplayName(), p.PID, dimStyle.Render(projectLabel(p))))
+		}
+	}
+	answer := "[y] kill  [any other key] cancel"
+	if m.dryRun {
+		answer = "[y] show the signals (dry run)  [any other key] cancel"
+	}
+	content.WriteString("\n" + dimStyle.Render(answer))
+	return dialogStyle.Render(content.String())
+}
+
+// splitLayout reports whether the detail pane fits beside the table
+func (m ProcessListModel) splitLayout() bool {
+	return m.width >= splitMinWidth
+}
+
+// paneWidth is the width of the side detail pane
+func (m ProcessListModel) paneWidth() int {
+	if m.details {
+		return openPaneWidth
+	}
+	return detailPaneWidth
+}
+
+// resizeTable fits the table into the space left by the detail pane
+func (m *ProcessListModel) resizeTable() {
+	if m.splitLayout() {
+		m.table.SetWidth(m.width - m.paneWidth() - 6)
+		m.table.SetHeight(m.height - 10)
+		return
+	}
+
+	m.table.SetWidth(m.width - 4)
+	height := m.height - 10
+	// The open pane leaves the table a few rows
+	if m.details {
+		m.table.SetHeight(min(height, openTableHeight))
+		return
+	}
+	// Stack the detail pane below the table when there's room for both
+	if height-detailPaneHeight >= 5 {
+		height -= detailPaneHeight
+	}
+	m.table.SetHeight(height)
+}
+
+// logPaneSize is the size of the logs pane, filling the screen below the
+// title
+func (m ProcessListModel) logPaneSize() (int, int) {
+	if m.width == 0 || m.height == 0 {
+		return 100, logPaneHeight
+	}
+	return m.width - 8, max(m.height-10, 5)
+}
+
+// tableWithDetail renders the table together with a live detail pane for
+// the highlighted row, side by side on wide terminals and stacked otherwise
+func (m ProcessListModel) tableWithDetail() string {
+	tableView := m.table.View()
+
+	r, ok := m.current()
+	if !ok || r.process == nil || m.width == 0 {
+		return tableView
+	}
+	proc := r.process
+
+	if m.splitLayout() {
+		pane := paneStyle.
+			Width(m.paneWidth()).
+			Height(m.table.Height()).
+			MaxHeight(m.table.Height() + 2).
+			Render(m.paneDetail(proc, m.paneWidth()-14))
+		return lipgloss.JoinHorizontal(lipgloss.Top, tableView, " ", pane)
+	}
+
+	if m.details {
+		// Below a table of a few rows, or instead of it when there's
+		// no room left for both
+		height := m.height - 10 - m.table.Height()
+		if height < 5 {
+			tableView, height = "", m.height-10
+		}
+		pane := paneStyle.
+			Width(m.width - 8).
+			MaxHeight(max(height, 3)).
+			Render(m.paneDetail(proc, m.width-24))
+		return lipgloss.JoinVertical(lipgloss.Left, tableView, pane)
+	}
+
+	if m.height-10-detailPaneHeight < 5 {
+		return tableView
+	}
+
+	pane := paneStyle.
+		Width(m.width - 8).
+		MaxHeight(detailPaneHeight).
+		Render(processDetail(proc, m.width-24))
+	return lipgloss.JoinVertical(lipgloss.Left, tableView, pane)
+}
+
+// paneDetail renders the detail pane of a process: its properties, and
+// once opened with enter its full command and environment
+func (m ProcessListModel) paneDetail(proc *process.Process, commandWidth int) string {
+	if !m.details {
+		return processDetail(proc, commandWidth)
+	}
+	// The pane wraps the full command line
+	detail := processDetail(proc, len(proc.Command))
+
+	var env string
+	switch {
+	case proc.SocketOnly:
+		return detail
+	case m.env.id != proc.ID:
+	case m.env.err != nil:
+		env = dimStyle.Render(fmt.Sprintf("unavailable (%v)", m.env.err))
+	default:
+		env = fmt.Sprintf("%d variables", len(m.env.vars))
+		for _, v := range envSummary(m.env.vars) {
+			env += "\n  " + v
+		}
+	}
+	return detail + fmt.Sprintf("\n%s %s", headerStyle.Render("Environment:"), env)
+}
+
 // PortCheckModel represents the port check view
 type PortCheckModel struct {
 	ports   map[int]*process.Process
+	groups  []config.PortGroup
+	hints   map[int][]advisor.Suggestion
 	loading bool
 	spinner spinner.Model
 	width   int
 	height  int
 }
 
-// NewPortCheckModel creates a new port check model
-func NewPortCheckModel(ports map[int]*process.Process) PortCheckModel {
+// NewPortCheckModel creates a new port check model. hints holds the
+// suggested fixes for occupied ports, keyed by port.
+func NewPortCheckModel(ports map[int]*process.Process, groups []config.PortGroup, hints map[int][]advisor.Suggestion) PortCheckModel {
 	sp := spinner.New()
 	sp.Spinner = spinner.Dot
-	sp.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+	sp.Style = lipgloss.NewStyle().Foreground(lipgloss.Color(palette.Spinner))
 
 	return PortCheckModel{
 		ports:   ports,
+		groups:  groups,
+		hints:   hints,
 		spinner: sp,
 	}
 }
@@ -353,7 +1478,7 @@ func (m PortCheckModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m PortCheckModel) View() string {
 	var b strings.Builder
 
-	title := titleStyle.Render("📊 Common Development Ports")
+	title := titleStyle.Render("📊 Development Ports")
 	b.WriteString(title + "\n\n")
 
 	if m.loading {
@@ -361,31 +1486,28 @@ func (m PortCheckModel) View() string {
 		return b.String()
 	}
 
-	// Group ports by category
-	categories := []struct {
-		Name  string
-		Ports []int
-	}{
-		{"Frontend", []int{3000, 3001, 4200, 5173, 8080}},
-		{"Backend", []int{4000, 5000, 8000, 9000}},
-		{"Databases", []int{3306, 5432, 6379, 27017}},
-		{"Tools", []int{9200, 9090, 3100, 8983}},
-	}
+	for _, group := range m.groups {
+		b.WriteString(headerStyle.Render(group.Name) + "\n")
 
-	for _, category := range categories {
-		b.WriteString(headerStyle.Render(category.Name) + "\n")
+		for _, port := range group.Ports {
+			label := ""
+			if l := group.Labels[port]; l != "" {
+				label = dimStyle.Render(fmt.Sprintf(" %-16s", l))
+			}
 
-		for _, port := range category.Ports {
 			proc, exists := m.ports[port]
 			if exists && proc != nil {
-				status := portUsedStyle.Render(fmt.Sprintf("● %d", port))
-				info := fmt.Sprintf("%s (%s)", proc.Name, proc.ProjectPath)
+				status := portUsedStyle.Render(fmt.Sprintf("● %d", port)) + label
+				info := fmt.Sprintf("%s (%s)", proc.Name, projectLabel(proc))
 				if proc.IsDocker {
 					info = dockerStyle.Render("[Docker] ") + info
 				}
 				b.WriteString(fmt.Sprintf("  %s %s\n", status, dimStyle.Render(info)))
+				if hints := m.hints[port]; len(hints) > 0 {
+					b.WriteString(fmt.Sprintf("      %s\n", hintStyle.Render("↳ "+hints[0].String())))
+				}
 			} else {
-				status := portFreeStyle.Render(fmt.Sprintf("○ %d", port))
+				status := portFreeStyle.Render(fmt.Sprintf("○ %d", port)) + label
 				b.WriteString(fmt.Sprintf("  %s %s\n", status, dimStyle.Render("available")))
 			}
 		}
@@ -400,27 +1522,152 @@ func (m PortCheckModel) View() string {
 // Helper functions
 
 func truncate(s string, max int) string {
-	if len(s) <= max {
+	runes := []rune(s)
+	if len(runes) <= max {
 		return s
 	}
-	return s[:max-3] + "..."
+	return string(runes[:max-3]) + "..."
 }
 
-// Messages
+// truncateLeft keeps the end of s, which for a process tree is the listener
+func truncateLeft(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return "…" + string(runes[len(runes)-max+1:])
+}
+
+// formatTree renders a root-first process chain, e.g. "npm › sh › node"
+func formatTree(chain []process.Ancestor) string {
+	names := make([]string, len(chain))
+	for i, a := range chain {
+		names[i] = a.Name
+	}
+	return strings.Join(names, Icon(" › ", " > "))
+}
+
+// exportFormats maps the export picker keys to output formats
+var exportFormats = map[string]output.Format{
+	"j": output.JSON,
+	"y": output.YAML,
+	"c": output.CSV,
+	"m": output.Markdown,
+}
+
+// copyTarget is a field of a process the copy picker copies
+type copyTarget struct {
+	name  string
+	value func(*process.Process) string
+}
+
+// copyTargets maps the copy picker keys to the fields they copy
+var copyTargets = map[string]copyTarget{
+	"p": {"PID", func(p *process.Process) string {
+		if p.PID <= 0 {
+			return ""
+		}
+		return strconv.Itoa(p.PID)
+	}},
+	"o": {"port", func(p *process.Process) string { return strconv.Itoa(p.Port) }},
+	"k": {"kill command", killCommand},
+	"d": {"project path", projectDir},
+}
 
-type processesLoadedMsg struct {
-	processes []*process.Process
+// copyField copies a field of the highlighted process to the clipboard
+// and returns a status message
+func (m ProcessListModel) copyField(target copyTarget) string {
+	r, ok := m.current()
+	if !ok || r.process == nil {
+		return hintStyle.Render("Move to a process to copy its " + target.name)
+	}
+	value := target.value(r.process)
+	if value == "" {
+		return hintStyle.Render(fmt.Sprintf("The %s of this process is unknown", target.name))
+	}
+	copyToClipboard(value)
+	return infoStyle.Render("📋 Copied " + value)
 }
 
+// killCommand is the shell command force killing the process
+func killCommand(p *process.Process) string {
+	switch {
+	case p.PID <= 0:
+		return ""
+	case p.Host == process.HostWindows:
+		return fmt.Sprintf("taskkill.exe /F /PID %d", p.PID)
+	case runtime.GOOS == "windows":
+		return fmt.Sprintf("taskkill /F /PID %d", p.PID)
+	}
+	return fmt.Sprintf("kill -9 %d", p.PID)
+}
+
+// exportView writes the processes to a timestamped file in the working
+// directory and returns a status message
+func exportView(processes []*process.Process, format output.Format) string {
+	path := fmt.Sprintf("portfinder-%s.%s", time.Now().Format("20060102-150405"), format.Extension())
+	return exportFile(processes, path, format)
+}
+
+// exportFile writes the processes to a file and returns a status message
+func exportFile(processes []*process.Process, path string, format output.Format) string {
+	if err := ExportList(path, format, processes); err != nil {
+		return fmt.Sprintf("❌ Export failed: %v", err)
+	}
+	return fmt.Sprintf("✅ Exported %d processes to %s", len(processes), path)
+}
+
+// ExportList writes the processes to a file in the format, replacing it
+func ExportList(path string, format output.Format, processes []*process.Process) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := output.Write(file, format, processes); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Messages
+
+// snapshotMsg delivers a provider's refresh result to the list view
+type snapshotMsg Snapshot
+
 type timerExpiredMsg struct{}
 
+// usageMsg delivers sampled resource usage, keyed by PID
+type usageMsg map[int]process.Usage
+
 // Commands
 
-func reloadProcesses() tea.Cmd {
+// refresh asks the provider for fresh processes; the result arrives
+// through the subscription, errors included
+func refresh(ctx context.Context, provider Provider) tea.Cmd {
+	return func() tea.Msg {
+		provider.Refresh(ctx)
+		return nil
+	}
+}
+
+// waitForSnapshot delivers the next snapshot published to the subscription
+func waitForSnapshot(snapshots <-chan Snapshot) tea.Cmd {
+	return func() tea.Msg {
+		return snapshotMsg(<-snapshots)
+	}
+}
+
+// sampleUsage measures the CPU and memory usage of the processes
+func sampleUsage(processes []*process.Process) tea.Cmd {
+	var pids []int
+	for _, p := range processes {
+		if p.PID > 0 && p.Host == "" {
+			pids = append(pids, p.PID)
+		}
+	}
 	return func() tea.Msg {
-		finder := process.NewFinder()
-		processes, _ := finder.ListAll()
-		return processesLoadedMsg{processes: processes}
+		return usageMsg(process.SampleUsage(pids, usageInterval))
 	}
 }
 
@@ -432,57 +1679,187 @@ func waitForTimer(t *time.Timer) tea.Cmd {
 }
 
 // ShowProcessList displays an interactive process list
-func ShowProcessList(processes []*process.Process) error {
-	p := tea.NewProgram(NewProcessListModel(processes), tea.WithAltScreen())
+func ShowProcessList(processes []*process.Process, provider Provider, opts ListOptions) error {
+	if plainMode {
+		plainRenderer{}.processList(processes, opts)
+		if opts.ExportPath == "" {
+			return nil
+		}
+		if err := ExportList(opts.ExportPath, opts.ExportFormat, processes); err != nil {
+			return fmt.Errorf("export failed: %w", err)
+		}
+		SuccessMsg("Exported %d processes to %s", len(processes), opts.ExportPath)
+		return nil
+	}
+
+	p := tea.NewProgram(NewProcessListModel(processes, provider, opts), tea.WithAltScreen())
 	_, err := p.Run()
 	return err
 }
 
 // ShowPortCheck displays the port check view
-func ShowPortCheck(ports map[int]*process.Process) error {
-	p := tea.NewProgram(NewPortCheckModel(ports), tea.WithAltScreen())
+func ShowPortCheck(ports map[int]*process.Process, groups []config.PortGroup, hints map[int][]advisor.Suggestion) error {
+	if plainMode {
+		plainRenderer{}.portCheck(ports, groups, hints)
+		return nil
+	}
+
+	p := tea.NewProgram(NewPortCheckModel(ports, groups, hints), tea.WithAltScreen())
 	_, err := p.Run()
 	return err
 }
 
-// ShowProcessDetail displays detailed information about a single process
-func ShowProcessDetail(proc *process.Process, interactive bool) {
-	var b strings.Builder
+// ShowProcessDetail displays detailed information about a single process.
+// With a finder, it stays open offering actions on the process, looking
+// the port up again through finder on refresh.
+func ShowProcessDetail(proc *process.Process, advice advisor.Advice, finder process.Finder) error {
+	if plainMode {
+		plainRenderer{}.processDetail(proc, advice)
+		if finder != nil {
+			confirmAndKill(proc)
+		}
+		return nil
+	}
 
-	b.WriteString("\n")
-	b.WriteString(portUsedStyle.Render(fmt.Sprintf("🔍 Port %d is in use by:", proc.Port)))
-	b.WriteString("\n\n")
+	m := NewProcessDetailModel(proc, advice, finder)
+	if finder == nil {
+		m.quitting = true
+		fmt.Print(m.View())
+		return nil
+	}
+
+	_, err := tea.NewProgram(m).Run()
+	return err
+}
 
-	// Create a nice box for the process info
-	boxStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("62")).
-		Padding(1, 2)
+// confirmAndKill offers to kill the process shown in the detail view
+func confirmAndKill(proc *process.Process) {
+	if SimpleConfirm("\nKill this process?") {
+		if err := killProcess(proc); err != nil {
+			ErrorMsg("Failed to kill process: %v", err)
+		} else {
+			SuccessMsg("Process killed successfully")
+		}
+	}
+}
 
+// processDetail renders the labeled properties of a process, truncating
+// the command line to commandWidth
+func processDetail(proc *process.Process, commandWidth int) string {
 	var content strings.Builder
-	content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Process:"), proc.Name))
-	content.WriteString(fmt.Sprintf("%s %d\n", headerStyle.Render("PID:"), proc.PID))
-	content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Command:"), truncate(proc.Command, 50)))
-	content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Project:"), formatProject(proc.ProjectPath)))
-	content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Started:"), formatTime(proc.StartTime)))
-	content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Running For:"), formatDuration(time.Since(proc.StartTime))))
+	content.WriteString(fmt.Sprintf("%s %d\n", headerStyle.Render("Port:"), proc.Port))
+	if proc.Address != "" {
+		address := proc.Address
+		switch {
+		case address == "*" || address == "0.0.0.0" || address == "::":
+			address = portUsedStyle.Render(address + " (all interfaces)")
+		case proc.IsPublic():
+			address = portUsedStyle.Render(address + " (exposed beyond localhost)")
+		default:
+			address += " (localhost only)"
+		}
+		content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Address:"), address))
+	}
+	if proc.SocketOnly {
+		content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Process:"), dimStyle.Render("unavailable in this environment")))
+		if proc.User != "" {
+			content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("User:"), proc.User))
+		}
+	} else {
+		content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Process:"), proc.DisplayName()))
+		content.WriteString(fmt.Sprintf("%s %d\n", headerStyle.Render("PID:"), proc.PID))
+		content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("User:"), formatUser(proc)))
+		content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Command:"), truncate(proc.Command, commandWidth)))
+		content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Project:"), projectLabel(proc)))
+		if proc.ProjectName != "" {
+			content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Directory:"), proc.ProjectPath))
+		}
+		content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Started:"), formatTime(proc.StartTime)))
+		content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Running For:"), formatAge(proc)))
+	}
+	if len(proc.Tags) > 0 {
+		content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Class:"), strings.Join(proc.Tags, ", ")))
+	}
+	if cpu := formatCPU(proc); cpu != "" {
+		content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("CPU:"), cpu))
+	}
+	if proc.RSS > 0 {
+		content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Memory:"), formatMemory(proc.RSS)))
+	}
+	connections := formatConnections(proc)
+	if proc.Connections > 0 {
+		connections = portUsedStyle.Render(connections)
+	}
+	content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Connections:"), connections))
+
+	if f := proc.Fingerprint; f != nil {
+		content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Answers:"), formatFingerprint(f)))
+		if f.Framework != "" && f.Status != 0 {
+			content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Framework:"), f.Framework))
+		}
+	}
+	if proc.Firewall != "" {
+		content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Firewall:"), proc.Firewall))
+	}
 
 	if proc.IsDocker {
-		content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Docker:"), dockerStyle.Render("Yes (Container: "+proc.DockerID+")")))
+		content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render(proc.RuntimeName()+":"), dockerStyle.Render("Yes (Container: "+proc.DockerID+")")))
+		if proc.ContainerName != "" {
+			content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Container:"), proc.ContainerName))
+		}
+		if proc.ContainerImage != "" {
+			content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Image:"), proc.ContainerImage))
+		}
+		if mapping := containerMapping(proc); mapping != "" {
+			content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Mapping:"), mapping))
+		}
+		if proc.ComposeProject != "" {
+			content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Compose:"), proc.ComposeProject))
+		}
 	}
 
-	fmt.Print(boxStyle.Render(content.String()))
-	fmt.Println()
+	if target := proc.KubeTarget(); target != "" {
+		content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Kubernetes:"), target))
+		if proc.KubeCluster != "" {
+			content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Cluster:"), proc.KubeCluster))
+		}
+	}
 
-	if interactive {
-		if SimpleConfirm("\nKill this process?") {
-			if err := proc.Kill(); err != nil {
-				ErrorMsg("Failed to kill process: %v", err)
-			} else {
-				SuccessMsg("Process killed successfully")
-			}
+	if proc.Service != "" {
+		content.WriteString(fmt.Sprintf("%s %s (%s)\n", headerStyle.Render("Service:"), proc.Service, proc.ServiceManager()))
+	}
+
+	if proc.Host == process.HostWindows {
+		content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Host:"), "Windows (through WSL interop)"))
+	}
+
+	if proc.Lineage != nil {
+		if tree := proc.Tree(); len(tree) > 1 {
+			content.WriteString(fmt.Sprintf("%s %s\n", headerStyle.Render("Tree:"), formatTree(tree)))
+			content.WriteString(fmt.Sprintf("%s %s (PID %d)\n", headerStyle.Render("Root:"), tree[0].Name, tree[0].PID))
 		}
+	} else if proc.PPID > 0 {
+		content.WriteString(fmt.Sprintf("%s %d\n", headerStyle.Render("Parent PID:"), proc.PPID))
 	}
+
+	return strings.TrimSuffix(content.String(), "\n")
+}
+
+// formatName shows where a Kubernetes forward or a published container
+// port leads next to the process name, e.g. "kubectl → svc/api" or
+// "docker-proxy → 172.17.0.2:80", and what a probe found answering, e.g.
+// "docker-proxy (Postgres 16.1)"
+func formatName(p *process.Process) string {
+	name := p.DisplayName()
+	if p.KubeResource != "" {
+		name += Icon(" → ", " -> ") + p.KubeResource
+	} else if target := p.ContainerTarget(); target != "" {
+		name += Icon(" → ", " -> ") + target
+	}
+	if p.Fingerprint != nil && p.Fingerprint.Label() != "" {
+		name += " (" + p.Fingerprint.Label() + ")"
+	}
+	return name
 }
 
 func formatProject(path string) string {
@@ -492,6 +1869,85 @@ func formatProject(path string) string {
 	return path
 }
 
+// projectLabel names the project of a process with its type, e.g.
+// "myapp (Node.js)", falling back to its directory
+func projectLabel(p *process.Process) string {
+	if p.ProjectName == "" {
+		return formatProject(p.ProjectPath)
+	}
+	return p.ProjectName + " (" + p.ProjectType + ")"
+}
+
+// formatFingerprint describes a probe's response, e.g.
+// "HTTP 200 OK, nginx/1.25.3"
+func formatFingerprint(f *process.Fingerprint) string {
+	if f.Status == 0 {
+		return f.Label()
+	}
+	description := fmt.Sprintf("%s %d %s", strings.ToUpper(f.Protocol), f.Status, http.StatusText(f.Status))
+	for _, header := range []string{f.Server, f.PoweredBy} {
+		if header != "" {
+			description += ", " + header
+		}
+	}
+	return description
+}
+
+// formatConnections describes the established connections and a few of
+// their peers
+func formatConnections(proc *process.Process) string {
+	if proc.Connections == 0 {
+		return "none"
+	}
+
+	const shown = 3
+	peers := proc.Peers[:min(len(proc.Peers), shown)]
+	description := fmt.Sprintf("%d active (%s", proc.Connections, strings.Join(peers, ", "))
+	if more := proc.Connections - len(peers); more > 0 {
+		description += fmt.Sprintf(", +%d more", more)
+	}
+	return description + ")"
+}
+
+// formatCPU describes the sampled CPU usage and the limit it is measured
+// against; every sampled process has some resident memory
+func formatCPU(proc *process.Process) string {
+	if proc.RSS == 0 {
+		return ""
+	}
+	if proc.CPULimit > 0 {
+		return fmt.Sprintf("%.1f%% of %.2g CPU limit", proc.CPUPercent, proc.CPULimit)
+	}
+	return fmt.Sprintf("%.1f%% of host", proc.CPUPercent)
+}
+
+// formatCPUColumn shows the sampled CPU usage in the list, "-" until
+// sampled; every sampled process has some resident memory
+func formatCPUColumn(proc *process.Process) string {
+	if proc.RSS == 0 && proc.CPUPercent == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", proc.CPUPercent)
+}
+
+// formatMemory shows a byte count in binary units, e.g. "245.3 MiB", or
+// "-" when unknown
+func formatMemory(bytes uint64) string {
+	if bytes == 0 {
+		return "-"
+	}
+	switch {
+	case bytes >= 1<<30:
+		return fmt.Sprintf("%.1f GiB", float64(bytes)/(1<<30))
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(bytes)/(1<<20))
+	}
+	return fmt.Sprintf("%.0f KiB", float64(bytes)/(1<<10))
+}
+
 func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
 	return t.Format("Jan 2, 15:04:05")
 }
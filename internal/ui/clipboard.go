@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/muesli/termenv"
+)
+
+// copyToClipboard puts text on the system clipboard through the platform's
+// clipboard tool, falling back to the OSC 52 escape sequence, which most
+// terminals honor even over SSH
+func copyToClipboard(text string) {
+	for _, tool := range clipboardTools() {
+		path, err := exec.LookPath(tool[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, tool[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if cmd.Run() == nil {
+			return
+		}
+	}
+	termenv.NewOutput(os.Stdout).Copy(text)
+}
+
+// clipboardTools lists the commands writing stdin to the clipboard, in
+// order of preference
+func clipboardTools() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	case "windows":
+		return [][]string{{"clip"}}
+	}
+	tools := [][]string{{"xclip", "-selection", "clipboard"}, {"xsel", "--clipboard", "--input"}}
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		tools = append([][]string{{"wl-copy"}}, tools...)
+	}
+	if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+		// Without a display only a WSL host clipboard can be reached
+		return [][]string{{"clip.exe"}}
+	}
+	return tools
+}
@@ -0,0 +1,319 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/doganarif/portfinder/internal/process"
+)
+
+// listColumn describes a column of the process list tables
+type listColumn struct {
+	title string
+	width int
+	// minWidth is how far a flexible column may shrink on a narrow
+	// terminal, 0 for fixed columns. Flexible columns also grow into
+	// spare room.
+	minWidth int
+	// priority orders which columns are kept when not all fit
+	priority int
+}
+
+// columnOrder lists the columns the process list can show, by the names
+// --columns and the columns setting use
+var columnOrder = []string{"port", "address", "name", "pid", "user", "conns", "cpu", "mem", "project", "uptime", "type", "label", "command", "firewall"}
+
+// defaultColumns are the columns shown unless others are picked
+var defaultColumns = columnOrder[:len(columnOrder)-2]
+
+var columnDefs = map[string]listColumn{
+	"port":     {title: "Port", width: 8, priority: 10},
+	"address":  {title: "Address", width: 16, priority: 5},
+	"name":     {title: "Process", width: 15, minWidth: 10, priority: 9},
+	"pid":      {title: "PID", width: 8, priority: 8},
+	"user":     {title: "User", width: 10, priority: 4},
+	"conns":    {title: "Conns", width: 7, priority: 2},
+	"cpu":      {title: "CPU", width: 7, priority: 6},
+	"mem":      {title: "Mem", width: 10, priority: 6},
+	"project":  {title: "Project", width: 30, minWidth: 12, priority: 7},
+	"uptime":   {title: "Running For", width: 15, priority: 3},
+	"type":     {title: "Type", width: 20, priority: 1},
+	"label":    {title: "Label", width: 20, priority: 3},
+	"command":  {title: "Command", width: 40, minWidth: 16, priority: 5},
+	"firewall": {title: "Firewall", width: 10, priority: 4},
+}
+
+// cellPadding is the space the table puts around each cell, and
+// checkboxWidth the width of the selection column
+const (
+	cellPadding   = 2
+	checkboxWidth = 3
+)
+
+// shownColumns are the columns picked with SetColumns, nil for the default
+// layout
+var shownColumns []string
+
+// SetColumns picks the columns of the process list tables and their
+// order. No columns restores the default layout.
+func SetColumns(columns []string) error {
+	seen := make(map[string]bool, len(columns))
+	for _, name := range columns {
+		if _, ok := columnDefs[name]; !ok {
+			return fmt.Errorf("unknown column %q, expected some of %s", name, strings.Join(columnOrder, ", "))
+		}
+		if seen[name] {
+			return fmt.Errorf("column %q is listed twice", name)
+		}
+		seen[name] = true
+	}
+	shownColumns = nil
+	if len(columns) > 0 {
+		shownColumns = columns
+	}
+	return nil
+}
+
+// addedColumns are shown after the picked or default columns
+var addedColumns []string
+
+// AddColumn shows a column after the picked or default ones, unless it is
+// shown already
+func AddColumn(name string) {
+	addedColumns = append(addedColumns, name)
+}
+
+// usageColumnsPicked reports whether CPU or memory columns were picked,
+// so their usage is sampled from the start
+func usageColumnsPicked() bool {
+	for _, name := range shownColumns {
+		if name == "cpu" || name == "mem" {
+			return true
+		}
+	}
+	return false
+}
+
+// visibleColumns returns the names of the columns to show. The CPU and
+// memory columns need usage sampled, the label column labeled ports.
+func visibleColumns(usage, labels bool) []string {
+	columns := shownColumns
+	if columns == nil {
+		columns = defaultColumns
+	}
+	visible := make([]string, 0, len(columns)+len(addedColumns))
+	for _, name := range append(append([]string{}, columns...), addedColumns...) {
+		switch {
+		case slices.Contains(visible, name):
+		case (name == "cpu" || name == "mem") && !usage:
+		case name == "label" && !labels:
+		default:
+			visible = append(visible, name)
+		}
+	}
+	return visible
+}
+
+// tableColumns returns the table columns for the names, widening the
+// process column in tree mode to fit the chain of parent processes
+func tableColumns(names []string, tree bool) []table.Column {
+	columns := make([]table.Column, len(names))
+	for i, name := range names {
+		def := columnDef(name, tree)
+		columns[i] = table.Column{Title: def.title, Width: def.width}
+	}
+	return columns
+}
+
+// columnDef describes a column, which for the process column depends on
+// the tree mode
+func columnDef(name string, tree bool) listColumn {
+	if name == "name" && tree {
+		return listColumn{title: "Process Tree", width: 30, minWidth: 15, priority: columnDefs[name].priority}
+	}
+	return columnDefs[name]
+}
+
+// columnCell renders a process's value for a column, untruncated
+func columnCell(name string, p *process.Process, labels map[int]string, restarts map[int]int) string {
+	switch name {
+	case "port":
+		return formatPort(p.Port, restarts[p.Port])
+	case "address":
+		return formatAddresses(p)
+	case "name":
+		return formatName(p)
+	case "pid":
+		return formatPID(p)
+	case "user":
+		return formatUser(p)
+	case "conns":
+		return fmt.Sprintf("%d", p.Connections)
+	case "cpu":
+		return formatCPUColumn(p)
+	case "mem":
+		return formatMemory(p.RSS)
+	case "project":
+		if p.ProjectPath == "" || p.ProjectPath == "unknown" {
+			return "-"
+		}
+		return projectLabel(p)
+	case "uptime":
+		return formatAge(p)
+	case "type":
+		return processType(p)
+	case "label":
+		return labels[p.Port]
+	case "command":
+		if p.Command == "" {
+			return "-"
+		}
+		return p.Command
+	case "firewall":
+		if p.Firewall == "" {
+			return "-"
+		}
+		return p.Firewall
+	}
+	return ""
+}
+
+// cellText renders a process's value for a column of the list, where the
+// process column shows the chain of parents in tree mode
+func cellText(name string, p *process.Process, tree bool, labels map[int]string, restarts map[int]int) string {
+	if name == "name" && tree {
+		return formatTree(p.Tree())
+	}
+	return columnCell(name, p, labels, restarts)
+}
+
+// fitColumns sizes the columns to the width available, keeping their
+// preferred widths when it is unknown. Flexible columns take the width of
+// their longest cell where there is room; when there isn't, they shrink
+// first, then the least important columns are dropped.
+func fitColumns(names []string, tree bool, width int, longest map[string]int) ([]string, []table.Column) {
+	columns := tableColumns(names, tree)
+	if width <= 0 {
+		return names, columns
+	}
+	used := func() int {
+		total := 0
+		for _, c := range columns {
+			total += c.Width + cellPadding
+		}
+		return total
+	}
+
+	// Flexible columns start no wider than their text
+	for i := range columns {
+		if minWidth := columnDef(names[i], tree).minWidth; minWidth > 0 {
+			columns[i].Width = max(minWidth, min(columns[i].Width, longest[names[i]]))
+		}
+	}
+
+	for i := range columns {
+		over := used() - width
+		if over <= 0 {
+			break
+		}
+		if minWidth := columnDef(names[i], tree).minWidth; minWidth > 0 && columns[i].Width > minWidth {
+			columns[i].Width -= min(over, columns[i].Width-minWidth)
+		}
+	}
+
+	for used() > width && len(names) > 1 {
+		drop := 0
+		for i, name := range names {
+			if columnDefs[name].priority < columnDefs[names[drop]].priority {
+				drop = i
+			}
+		}
+		names = append(names[:drop:drop], names[drop+1:]...)
+		columns = append(columns[:drop:drop], columns[drop+1:]...)
+	}
+
+	for i := range columns {
+		spare := width - used()
+		if spare <= 0 {
+			break
+		}
+		if columnDef(names[i], tree).minWidth > 0 && longest[names[i]] > columns[i].Width {
+			columns[i].Width += min(spare, longest[names[i]]-columns[i].Width)
+		}
+	}
+	return names, columns
+}
+
+// scrollable reports whether a column's text scrolls sideways when it is
+// wider than the column
+func scrollable(name string) bool {
+	return name == "project" || name == "command"
+}
+
+// scrollText shows the part of a cell's text offset characters in, as far
+// as it is wider than the column, marking the cut start
+func scrollText(s string, offset, width int) string {
+	runes := []rune(s)
+	if offset <= 0 || len(runes) <= width {
+		return s
+	}
+	start := min(offset, len(runes)-width+1)
+	return Icon("…", "<") + string(runes[start:])
+}
+
+// processType names the kind of a process: its container or service,
+// else its first class
+func processType(p *process.Process) string {
+	switch {
+	case p.IsDocker && p.ContainerName != "":
+		return p.RuntimeName() + ": " + p.ContainerName
+	case p.IsDocker:
+		return p.RuntimeName()
+	case p.Service != "":
+		return "Service: " + p.Service
+	case p.Host == process.HostWindows:
+		return "Windows"
+	case len(p.Tags) > 0:
+		return p.Tags[0]
+	}
+	return "Native"
+}
+
+// containerMapping shows a published port with where it leads inside the
+// container, e.g. "8080 → 172.17.0.2:80 nginx:alpine", and the VM
+// forwarding it
+func containerMapping(p *process.Process) string {
+	target := p.ContainerTarget()
+	if target == "" {
+		return ""
+	}
+	mapping := strings.TrimSpace(fmt.Sprintf("%d%s%s %s", p.Port, Icon(" → ", " -> "), target, p.ContainerImage))
+	if p.ForwardedBy != "" {
+		mapping += fmt.Sprintf(", forwarded by %s's %s", p.ForwardedBy, p.Name)
+	}
+	return mapping
+}
+
+// removeColumn returns the names without one of them
+func removeColumn(names []string, name string) []string {
+	kept := make([]string, 0, len(names))
+	for _, n := range names {
+		if n != name {
+			kept = append(kept, n)
+		}
+	}
+	return kept
+}
+
+// sampleUsageNow fills in the CPU and memory usage of the processes,
+// taking the sampling interval to do so
+func sampleUsageNow(processes []*process.Process) {
+	usage := sampleUsage(processes)().(usageMsg)
+	for _, p := range processes {
+		if u, ok := usage[p.PID]; ok {
+			p.CPUPercent, p.RSS = u.CPUPercent, u.RSS
+		}
+	}
+}
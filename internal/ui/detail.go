@@ -0,0 +1,481 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/doganarif/portfinder/internal/advisor"
+	"github.com/doganarif/portfinder/internal/process"
+)
+
+// detailMode is what the detail view shows below the process
+type detailMode int
+
+const (
+	detailActions detailMode = iota
+	// detailSignals asks how to kill the process
+	detailSignals
+	detailEnv
+	detailFiles
+	detailLogs
+)
+
+// detailPageHeight is the height of the environment and open files pages
+const detailPageHeight = 12
+
+// detailBoxStyle frames the properties of the process
+var detailBoxStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color(palette.Border)).
+	Padding(1, 2)
+
+type detailKeyMap struct {
+	Open  key.Binding
+	Copy  key.Binding
+	Env   key.Binding
+	Files key.Binding
+	Back  key.Binding
+}
+
+var detailKeys = detailKeyMap{
+	Open: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "open project"),
+	),
+	Copy: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "copy PID"),
+	),
+	Env: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "environment"),
+	),
+	Files: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "open files"),
+	),
+	Back: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "back"),
+	),
+}
+
+// killSignals maps the keys of the kill prompt to the signal they send.
+// Enter kills with the automatically selected strategy instead.
+var killSignals = []struct{ key, signal, desc string }{
+	{"t", "TERM", "graceful"},
+	{"i", "INT", "like Ctrl+C"},
+	{"h", "HUP", "reload or hang up"},
+	{"k", "KILL", "immediately"},
+}
+
+// ProcessDetailModel shows a single process with the actions that can be
+// taken on it, making the port check a small control panel
+type ProcessDetailModel struct {
+	process *process.Process
+	port    int
+	advice  advisor.Advice
+	finder  process.Finder
+
+	mode     detailMode
+	page     viewport.Model
+	logs     *logPane
+	busy     string
+	message  string
+	quitting bool
+	width    int
+	height   int
+}
+
+// NewProcessDetailModel creates the detail view of a process, with advice
+// on working around it. finder looks the port up again when refreshing.
+func NewProcessDetailModel(proc *process.Process, advice advisor.Advice, finder process.Finder) ProcessDetailModel {
+	return ProcessDetailModel{
+		process: proc,
+		port:    proc.Port,
+		advice:  advice,
+		finder:  finder,
+	}
+}
+
+// killDoneMsg reports the outcome of a kill started from the detail view,
+// with the process that took the port right after, if any
+type killDoneMsg struct {
+	respawned *process.Process
+	err       error
+}
+
+// detailRefreshedMsg delivers the port's current occupant, nil once free
+type detailRefreshedMsg struct {
+	process *process.Process
+	err     error
+}
+
+// Init samples the CPU and memory usage in the background, so the view
+// opens without waiting for it. Every sampled process has some resident
+// memory, one sampled already is left as is.
+func (m ProcessDetailModel) Init() tea.Cmd {
+	if m.process != nil && m.process.RSS > 0 {
+		return nil
+	}
+	return m.sampleUsage()
+}
+
+func (m ProcessDetailModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		if m.logs != nil {
+			m.logs.resize(m.width-8, logPaneHeight)
+		}
+
+	case logsMsg:
+		if m.logs != nil {
+			return m, m.logs.add(msg)
+		}
+
+	case killDoneMsg:
+		m.busy = ""
+		if msg.err != nil {
+			m.message = portUsedStyle.Render(fmt.Sprintf("❌ Failed to kill process: %v", msg.err))
+			return m, nil
+		}
+		m.message = portFreeStyle.Render(fmt.Sprintf("✅ Killed %s (PID: %d)", m.process.Name, m.process.PID))
+		if p := msg.respawned; p != nil {
+			m.message += "\n" + portUsedStyle.Render(fmt.Sprintf("↻ %s (PID: %d) took the port right after, something restarts it", p.Name, p.PID))
+			if stop := advisor.StopRespawns(p); stop != "" {
+				m.message += "\n" + hintStyle.Render("  Stop it for good with: "+stop)
+			}
+			m.process = p
+			return m, m.sampleUsage()
+		}
+		cmd := m.refresh()
+		return m, cmd
+
+	case openedMsg:
+		m.message = openedMessage(msg)
+
+	case usageMsg:
+		if m.process != nil {
+			if u, ok := msg[m.process.PID]; ok {
+				m.process.CPUPercent, m.process.CPULimit, m.process.RSS = u.CPUPercent, u.CPULimit, u.RSS
+			}
+		}
+
+	case detailRefreshedMsg:
+		m.busy = ""
+		switch {
+		case msg.err != nil:
+			m.message = portUsedStyle.Render(fmt.Sprintf("❌ Refresh failed: %v", msg.err))
+		case msg.process == nil:
+			m.process = nil
+		default:
+			m.process = msg.process
+			return m, m.sampleUsage()
+		}
+
+	case tea.KeyMsg:
+		// Any key but ctrl+c answers the kill prompt
+		if msg.String() == "ctrl+c" || key.Matches(msg, keys.Quit) && m.mode != detailSignals {
+			m.quitting = true
+			return m, tea.Quit
+		}
+		if m.busy != "" {
+			return m, nil
+		}
+		switch m.mode {
+		case detailSignals:
+			return m.chooseSignal(msg)
+		case detailLogs:
+			if key.Matches(msg, detailKeys.Back) || key.Matches(msg, keys.Logs) {
+				m.logs.close()
+				m.logs, m.mode = nil, detailActions
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.logs.view, cmd = m.logs.view.Update(msg)
+			return m, cmd
+		case detailEnv, detailFiles:
+			if key.Matches(msg, detailKeys.Back) {
+				m.mode = detailActions
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.page, cmd = m.page.Update(msg)
+			return m, cmd
+		}
+		return m.action(msg)
+	}
+	return m, nil
+}
+
+// action runs the action bound to the key
+func (m ProcessDetailModel) action(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.message = ""
+	if key.Matches(msg, keys.Reload) {
+		cmd := m.refresh()
+		return m, cmd
+	}
+	// The port is free, nothing else applies
+	if m.process == nil {
+		return m, nil
+	}
+
+	p := m.process
+	switch {
+	case key.Matches(msg, keys.Kill):
+		m.mode = detailSignals
+
+	case key.Matches(msg, keys.Pause):
+		if err := p.Pause(); err != nil {
+			m.message = portUsedStyle.Render(fmt.Sprintf("❌ Failed to pause process: %v", err))
+			break
+		}
+		m.message = infoStyle.Render(fmt.Sprintf("⏸️ Paused %s (PID: %d), press %s to resume it", p.Name, p.PID, keys.Resume.Help().Key))
+
+	case key.Matches(msg, keys.Resume):
+		if err := p.Resume(); err != nil {
+			m.message = portUsedStyle.Render(fmt.Sprintf("❌ Failed to resume process: %v", err))
+			break
+		}
+		m.message = portFreeStyle.Render(fmt.Sprintf("▶️ Resumed %s (PID: %d)", p.Name, p.PID))
+
+	case key.Matches(msg, keys.Nicer):
+		m.message = renice(p, niceStep)
+
+	case key.Matches(msg, keys.Unnicer):
+		m.message = renice(p, -niceStep)
+
+	case key.Matches(msg, detailKeys.Copy):
+		copyToClipboard(strconv.Itoa(p.PID))
+		m.message = infoStyle.Render(fmt.Sprintf("📋 Copied PID %d", p.PID))
+
+	case key.Matches(msg, detailKeys.Open):
+		dir := projectDir(p)
+		if dir == "" {
+			m.message = hintStyle.Render("The project directory of this process is unknown")
+			break
+		}
+		return m, openProject(dir)
+
+	case key.Matches(msg, detailKeys.Env):
+		env, err := p.Environ()
+		if err != nil {
+			m.message = portUsedStyle.Render(fmt.Sprintf("❌ %v", err))
+			break
+		}
+		m.showPage(detailEnv, env)
+
+	case key.Matches(msg, keys.Logs):
+		width := 100
+		if m.width > 0 {
+			width = m.width - 8
+		}
+		logs, cmd, err := openLogs(p, width, logPaneHeight)
+		if err != nil {
+			m.message = portUsedStyle.Render(fmt.Sprintf("❌ %v", err))
+			break
+		}
+		m.logs, m.mode = logs, detailLogs
+		return m, cmd
+
+	case key.Matches(msg, detailKeys.Files):
+		files, err := p.OpenFiles()
+		if err != nil {
+			m.message = portUsedStyle.Render(fmt.Sprintf("❌ %v", err))
+			break
+		}
+		lines := make([]string, len(files))
+		for i, f := range files {
+			lines[i] = fmt.Sprintf("%6s  %s", f.FD, f.Path)
+		}
+		m.showPage(detailFiles, lines)
+	}
+	return m, nil
+}
+
+// chooseSignal kills the process the way picked in the kill prompt
+func (m ProcessDetailModel) chooseSignal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.mode = detailActions
+	p := m.process
+
+	strategy := process.SelectStrategy(p)
+	if msg.String() != "enter" {
+		name := ""
+		for _, s := range killSignals {
+			if msg.String() == s.key {
+				name = s.signal
+			}
+		}
+		if name == "" {
+			return m, nil
+		}
+		sig, err := process.ParseSignal(name)
+		if err != nil {
+			m.message = portUsedStyle.Render(fmt.Sprintf("❌ %v", err))
+			return m, nil
+		}
+		strategy = process.WithKillOptions(process.SignalStrategy{}, process.KillOptions{Signal: sig})
+	}
+
+	m.busy = fmt.Sprintf("Killing %s (PID: %d) and watching port %d...", p.Name, p.PID, m.port)
+	finder := m.finder
+	return m, func() tea.Msg {
+		respawned, err := killAndVerify(finder, p, strategy)
+		if respawned != nil {
+			respawned.LoadLineage()
+		}
+		return killDoneMsg{respawned: respawned, err: err}
+	}
+}
+
+// sampleUsage measures the usage of the shown process, unless it is
+// unknown or runs on another host
+func (m ProcessDetailModel) sampleUsage() tea.Cmd {
+	if m.process == nil || m.process.SocketOnly || m.process.Host != "" {
+		return nil
+	}
+	return sampleUsage([]*process.Process{m.process})
+}
+
+// refresh looks the port up again, past the finder's cache
+func (m *ProcessDetailModel) refresh() tea.Cmd {
+	m.busy = "Refreshing..."
+	finder, port := m.finder, m.port
+	return func() tea.Msg {
+		process.Invalidate(finder)
+		proc, err := finder.FindByPort(context.Background(), port)
+		if proc != nil {
+			proc.LoadLineage()
+		}
+		return detailRefreshedMsg{process: proc, err: err}
+	}
+}
+
+// showPage switches to a scrollable page of lines
+func (m *ProcessDetailModel) showPage(mode detailMode, lines []string) {
+	// Leave room for the pane's border and padding
+	width := 100
+	if m.width > 0 {
+		width = m.width - 8
+	}
+	if len(lines) == 0 {
+		lines = []string{dimStyle.Render("(none)")}
+	}
+	m.page = viewport.New(width, min(len(lines), detailPageHeight))
+	m.page.SetContent(strings.Join(lines, "\n"))
+	m.mode = mode
+}
+
+// helpLine lists the keys of the actions, e.g. "[o] open project  [q] quit"
+func helpLine(actions ...key.Binding) string {
+	labels := make([]string, len(actions))
+	for i, a := range actions {
+		labels[i] = fmt.Sprintf("[%s] %s", a.Help().Key, strings.TrimSuffix(a.Help().Desc, " process"))
+	}
+	return strings.Join(labels, "  ")
+}
+
+func (m ProcessDetailModel) View() string {
+	var b strings.Builder
+
+	b.WriteString("\n")
+	if m.process == nil {
+		b.WriteString(portFreeStyle.Render(Icon("✅ ", "")+fmt.Sprintf("Port %d is free now", m.port)) + "\n")
+		if m.message != "" {
+			b.WriteString("\n" + m.message + "\n")
+		}
+		if !m.quitting {
+			b.WriteString("\n" + dimStyle.Render(helpLine(keys.Reload, keys.Quit)) + "\n")
+		}
+		return b.String()
+	}
+
+	b.WriteString(portUsedStyle.Render(fmt.Sprintf("🔍 Port %d is in use by:", m.port)))
+	b.WriteString("\n\n")
+	b.WriteString(detailBoxStyle.Render(processDetail(m.process, 50)))
+	b.WriteString("\n")
+
+	if len(m.advice.Suggestions) > 0 {
+		b.WriteString("\n" + headerStyle.Render("💡 To free this port, in "+formatProject(m.process.ProjectPath)+":") + "\n")
+		for _, s := range m.advice.Suggestions {
+			b.WriteString(hintStyle.Render("  • "+s.String()) + "\n")
+		}
+	}
+	if alternatives := m.advice.Alternatives; len(alternatives) > 0 {
+		b.WriteString("\n" + headerStyle.Render(Icon("🔀 ", "")+"Free ports nearby: "+joinPorts(alternatives)) + "\n")
+		if m.advice.Command != "" {
+			b.WriteString(hintStyle.Render(fmt.Sprintf("  Run yours on %d: %s", alternatives[0], m.advice.Command)) + "\n")
+		}
+	}
+
+	if m.message != "" {
+		b.WriteString("\n" + m.message + "\n")
+	}
+	if m.quitting {
+		return b.String()
+	}
+	if m.busy != "" {
+		b.WriteString("\n" + infoStyle.Render(m.busy) + "\n")
+		return b.String()
+	}
+
+	b.WriteString("\n")
+	switch m.mode {
+	case detailSignals:
+		prompt := []string{fmt.Sprintf("[enter] auto (%s)", process.SelectStrategy(m.process).Name())}
+		if m.process.Service != "" {
+			// Signals reach the process alone, and its manager may restart it
+			prompt[0] = fmt.Sprintf("[enter] stop %s through %s", m.process.Service, m.process.ServiceManager())
+		}
+		for _, s := range killSignals {
+			prompt = append(prompt, fmt.Sprintf("[%s] %s (%s)", s.key, s.signal, s.desc))
+		}
+		b.WriteString(portUsedStyle.Render("Kill with:") + " " + strings.Join(prompt, "  ") + "  " + dimStyle.Render("[any other key] cancel"))
+
+	case detailLogs:
+		b.WriteString(m.logs.View())
+
+	case detailEnv, detailFiles:
+		title := "Environment"
+		if m.mode == detailFiles {
+			title = "Open files"
+		}
+		b.WriteString(headerStyle.Render(fmt.Sprintf("%s (%d%%)", title, int(m.page.ScrollPercent()*100))) + "\n")
+		b.WriteString(paneStyle.Render(m.page.View()) + "\n")
+		b.WriteString(dimStyle.Render("↑/↓ scroll  [esc] back  [q] quit"))
+
+	default:
+		b.WriteString(dimStyle.Render(helpLine(keys.Kill, keys.Pause, keys.Resume, keys.Nicer, keys.Unnicer, keys.Logs, detailKeys.Open, detailKeys.Copy, detailKeys.Env, detailKeys.Files, keys.Reload, keys.Quit)))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// notableEnv are the environment variables that tell how a dev server was
+// started, shown in the list's detail pane
+var notableEnv = []string{
+	"PORT", "HOST", "NODE_ENV", "RAILS_ENV", "RACK_ENV", "APP_ENV", "GO_ENV",
+	"FLASK_APP", "FLASK_ENV", "DJANGO_SETTINGS_MODULE", "VIRTUAL_ENV", "CONDA_DEFAULT_ENV",
+	"DEBUG",
+}
+
+// envSummary picks the notable variables out of an environment
+func envSummary(env []string) []string {
+	var summary []string
+	for _, name := range notableEnv {
+		for _, v := range env {
+			if strings.HasPrefix(v, name+"=") {
+				summary = append(summary, v)
+			}
+		}
+	}
+	return summary
+}
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/doganarif/portfinder/internal/process"
+	"github.com/olekukonko/tablewriter"
+)
+
+// ephemeralWarning is the share of the ephemeral range in use from which
+// it is flagged as close to exhaustion
+const ephemeralWarning = 0.8
+
+// DisplayEphemeral shows how much of the ephemeral port range is used and
+// the processes using most of it
+func DisplayEphemeral(u *process.EphemeralUsage) {
+	if accessibleMode {
+		accessibleEphemeral(u)
+		return
+	}
+
+	fmt.Println()
+	infoColor.Printf(Icon("🎲 ", "")+"Ephemeral ports %d-%d (%d ports)\n", u.Low, u.High, u.Size())
+	fmt.Println()
+	fmt.Printf("  %-10s %d (%s)\n", "In use:", u.InUse, ephemeralShare(u.InUse, u))
+	fmt.Printf("  %-10s %d\n", "TIME_WAIT:", u.TimeWait)
+	fmt.Printf("  %-10s %d\n", "Free:", u.Size()-u.InUse)
+
+	if len(u.Consumers) > 0 {
+		fmt.Println()
+		fmt.Println("Top consumers:")
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Process", "PID", "Ports", "Share"})
+		table.SetBorder(false)
+		table.SetHeaderLine(true)
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		for _, c := range u.Consumers {
+			table.Append([]string{ephemeralName(c), strconv.Itoa(c.PID), strconv.Itoa(c.Ports), ephemeralShare(c.Ports, u)})
+		}
+		table.Render()
+	}
+
+	if float64(u.InUse) >= ephemeralWarning*float64(u.Size()) {
+		fmt.Println()
+		WarnMsg("The range is nearly exhausted, new connections may fail with \"cannot assign requested address\"")
+		if u.TimeWait > u.InUse/2 {
+			InfoMsg("Most of it is held by connections in TIME_WAIT: reuse connections, e.g. with HTTP keep-alive, rather than opening one per request")
+		}
+	}
+}
+
+// accessibleEphemeral writes the range and its usage as sentences
+func accessibleEphemeral(u *process.EphemeralUsage) {
+	fmt.Printf("Ephemeral ports %d to %d, %d ports.\n", u.Low, u.High, u.Size())
+	fmt.Printf("In use: %d, %s. In TIME_WAIT: %d. Free: %d.\n", u.InUse, ephemeralShare(u.InUse, u), u.TimeWait, u.Size()-u.InUse)
+	for _, c := range u.Consumers {
+		fmt.Printf("Process %s, PID %d, holds %s.\n", ephemeralName(c), c.PID, pluralize(c.Ports, "port", "ports"))
+	}
+	if float64(u.InUse) >= ephemeralWarning*float64(u.Size()) {
+		fmt.Println("Warning: the range is nearly exhausted.")
+	}
+}
+
+// ephemeralShare is n as a percentage of the range
+func ephemeralShare(n int, u *process.EphemeralUsage) string {
+	return fmt.Sprintf("%.1f%%", 100*float64(n)/float64(max(u.Size(), 1)))
+}
+
+func ephemeralName(c process.EphemeralConsumer) string {
+	if c.Name == "" {
+		return "(unknown)"
+	}
+	return c.Name
+}
@@ -0,0 +1,234 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/doganarif/portfinder/internal/process"
+)
+
+// listRow is what a row of the process list shows: a process, or in
+// grouped mode the header of a project's processes
+type listRow struct {
+	process *process.Process
+	// group is the project of a header row, members its processes
+	group   string
+	members []*process.Process
+	// ports holds every listener of the process of a merged row, which
+	// stands for all of them
+	ports []*process.Process
+}
+
+// groupKey is the project a process is grouped under, "" when unknown
+func groupKey(p *process.Process) string {
+	if p.ProjectPath == "unknown" {
+		return ""
+	}
+	return p.ProjectPath
+}
+
+// groupRows puts the processes of each project together under a header
+// row, in the order their first process is listed, with the processes
+// without a project last. Collapsed projects only show their header.
+func groupRows(processes []*process.Process, collapsed map[string]bool) []listRow {
+	var order []string
+	members := make(map[string][]*process.Process)
+	for _, p := range processes {
+		key := groupKey(p)
+		if _, seen := members[key]; !seen && key != "" {
+			order = append(order, key)
+		}
+		members[key] = append(members[key], p)
+	}
+	if len(members[""]) > 0 {
+		order = append(order, "")
+	}
+
+	var rows []listRow
+	for _, key := range order {
+		rows = append(rows, listRow{group: key, members: members[key]})
+		if collapsed[key] {
+			continue
+		}
+		for _, p := range members[key] {
+			rows = append(rows, listRow{process: p})
+		}
+	}
+	return rows
+}
+
+// shownProcesses returns the listeners the rows show, in their order:
+// none for a group header, whose listeners are rows of their own unless
+// the group is collapsed, and all of them for a merged row
+func shownProcesses(rows []listRow) []*process.Process {
+	var shown []*process.Process
+	seen := make(map[*process.Process]bool)
+	for _, r := range rows {
+		ports := r.ports
+		if len(ports) == 0 && r.process != nil {
+			ports = []*process.Process{r.process}
+		}
+		for _, p := range ports {
+			if !seen[p] {
+				seen[p] = true
+				shown = append(shown, p)
+			}
+		}
+	}
+	return shown
+}
+
+// groupHeaderRow renders the header of a project's group: whether it is
+// folded, its ports and the project, in the columns they belong to
+func groupHeaderRow(r listRow, columns []table.Column, collapsed bool) table.Row {
+	arrow := Icon("▾", "-")
+	if collapsed {
+		arrow = Icon("▸", "+")
+	}
+	name := "No project"
+	if r.group != "" {
+		name = projectLabel(r.members[0])
+	}
+	ports := make([]string, len(r.members))
+	for i, p := range r.members {
+		ports[i] = strconv.Itoa(p.Port)
+	}
+
+	row := make(table.Row, len(columns))
+	for i, c := range columns {
+		switch {
+		case strings.HasPrefix(c.Title, "Port"):
+			row[i] = fmt.Sprintf("%s %d", arrow, len(r.members))
+		case strings.HasPrefix(c.Title, "Address"):
+			row[i] = truncate(strings.Join(ports, ","), c.Width)
+		case strings.HasPrefix(c.Title, "Project"):
+			row[i] = truncate(name, c.Width)
+		}
+	}
+	return row
+}
+
+// groupOf returns the header of the group a table row belongs to
+func groupOf(rows []listRow, index int) (listRow, bool) {
+	if index >= len(rows) {
+		return listRow{}, false
+	}
+	for i := index; i >= 0; i-- {
+		if rows[i].process == nil {
+			return rows[i], true
+		}
+	}
+	return listRow{}, false
+}
+
+// mergeByProcess puts the listeners of each process together, in the
+// order the first of them is listed. Listeners of unknown processes stay
+// apart.
+func mergeByProcess(processes []*process.Process) [][]*process.Process {
+	var merged [][]*process.Process
+	index := make(map[int]int)
+	for _, p := range processes {
+		if i, ok := index[p.PID]; ok && p.PID > 0 && !p.SocketOnly {
+			merged[i] = append(merged[i], p)
+			continue
+		}
+		index[p.PID] = len(merged)
+		merged = append(merged, []*process.Process{p})
+	}
+	return merged
+}
+
+// listedRows returns the listeners of each row of a printed list: one per
+// row, or those of each process when merge is set
+func listedRows(processes []*process.Process, merge bool) [][]*process.Process {
+	if merge {
+		return mergeByProcess(processes)
+	}
+	rows := make([][]*process.Process, len(processes))
+	for i, p := range processes {
+		rows[i] = []*process.Process{p}
+	}
+	return rows
+}
+
+// mergeRows shows each process once with all its ports, followed by a row
+// per port when it is expanded
+func mergeRows(processes []*process.Process, expanded map[int]bool) []listRow {
+	var rows []listRow
+	for _, ports := range mergeByProcess(processes) {
+		if len(ports) == 1 {
+			rows = append(rows, listRow{process: ports[0]})
+			continue
+		}
+		rows = append(rows, listRow{process: ports[0], ports: ports})
+		if expanded[ports[0].PID] {
+			for _, p := range ports {
+				rows = append(rows, listRow{process: p})
+			}
+		}
+	}
+	return rows
+}
+
+// mergedRow turns the row of a process into one standing for all its
+// ports: whether they are expanded and their count in the port column, as
+// in a group header, the ports in the address column
+func mergedRow(r listRow, row table.Row, names []string, columns []table.Column, expanded bool, labels map[int]string) table.Row {
+	arrow := Icon("▸", "+")
+	if expanded {
+		arrow = Icon("▾", "-")
+	}
+	for i, name := range names {
+		switch name {
+		case "port":
+			row[i] = fmt.Sprintf("%s %d", arrow, len(r.ports))
+		case "address":
+			row[i] = truncate(mergedPorts(r.ports), columns[i].Width)
+		case "conns", "label", "firewall":
+			row[i] = truncate(mergedCell(name, r.ports, labels, nil), columns[i].Width)
+		}
+	}
+	return row
+}
+
+// mergedCell is the cell of a column for all the listeners of a process:
+// their ports, addresses, labels and firewall states joined, connections
+// added up
+func mergedCell(name string, ports []*process.Process, labels map[int]string, restarts map[int]int) string {
+	if len(ports) == 1 {
+		return columnCell(name, ports[0], labels, restarts)
+	}
+	switch name {
+	case "port":
+		return mergedPorts(ports)
+	case "conns":
+		total := 0
+		for _, p := range ports {
+			total += p.Connections
+		}
+		return strconv.Itoa(total)
+	case "address", "label", "firewall":
+		var values []string
+		for _, p := range ports {
+			if v := columnCell(name, p, labels, restarts); v != "" && !slices.Contains(values, v) {
+				values = append(values, v)
+			}
+		}
+		return strings.Join(values, ", ")
+	}
+	return columnCell(name, ports[0], labels, restarts)
+}
+
+// mergedPorts lists the ports of a process in order, e.g. "3000, 9229"
+func mergedPorts(ports []*process.Process) string {
+	numbers := make([]int, len(ports))
+	for i, p := range ports {
+		numbers[i] = p.Port
+	}
+	sort.Ints(numbers)
+	return compactPorts(numbers)
+}
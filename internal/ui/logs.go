@@ -0,0 +1,134 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/doganarif/portfinder/internal/process"
+)
+
+const (
+	// logTail is how many of the last lines the logs pane starts with
+	logTail = 200
+	// logHistory bounds the lines the logs pane keeps
+	logHistory = 2000
+	// logPaneHeight is the height of the logs pane when the terminal's
+	// isn't known
+	logPaneHeight = 15
+)
+
+// logsMsg delivers the lines read from a followed log since the last one
+type logsMsg struct {
+	ch    <-chan string
+	lines []string
+	ended bool
+}
+
+// logPane follows the log of a process in a scrollable viewport, which
+// sticks to the newest line unless scrolled up
+type logPane struct {
+	source string
+	lines  []string
+	view   viewport.Model
+	ch     <-chan string
+	cancel context.CancelFunc
+	ended  bool
+}
+
+// openLogs starts following the log of the process in a pane of the given
+// size
+func openLogs(p *process.Process, width, height int) (*logPane, tea.Cmd, error) {
+	source, err := p.Logs()
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := source.Follow(ctx, logTail)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	pane := &logPane{source: source.Name, ch: ch, cancel: cancel, view: viewport.New(width, height)}
+	pane.view.SetContent(dimStyle.Render("Waiting for output..."))
+	return pane, waitForLogs(ch), nil
+}
+
+// waitForLogs delivers the next lines of a followed log, batching those
+// already written
+func waitForLogs(ch <-chan string) tea.Cmd {
+	return func() tea.Msg {
+		line, ok := <-ch
+		if !ok {
+			return logsMsg{ch: ch, ended: true}
+		}
+		lines := []string{line}
+		for len(lines) < logHistory {
+			select {
+			case line, ok := <-ch:
+				if !ok {
+					return logsMsg{ch: ch, lines: lines, ended: true}
+				}
+				lines = append(lines, line)
+			default:
+				return logsMsg{ch: ch, lines: lines}
+			}
+		}
+		return logsMsg{ch: ch, lines: lines}
+	}
+}
+
+// add shows the lines delivered, returning the command waiting for more.
+// Lines from a pane closed since are dropped.
+func (l *logPane) add(msg logsMsg) tea.Cmd {
+	if msg.ch != l.ch {
+		return nil
+	}
+	follow := l.view.AtBottom() || len(l.lines) == 0
+	l.lines = append(l.lines, msg.lines...)
+	if len(l.lines) > logHistory {
+		l.lines = l.lines[len(l.lines)-logHistory:]
+	}
+	l.render()
+	if follow {
+		l.view.GotoBottom()
+	}
+	if msg.ended {
+		l.ended = true
+		return nil
+	}
+	return waitForLogs(l.ch)
+}
+
+// render cuts the lines to the pane's width, keeping color codes intact
+func (l *logPane) render() {
+	if len(l.lines) == 0 {
+		return
+	}
+	l.view.SetContent(lipgloss.NewStyle().MaxWidth(l.view.Width).Render(strings.Join(l.lines, "\n")))
+}
+
+// resize fits the pane to a new terminal size
+func (l *logPane) resize(width, height int) {
+	l.view.Width, l.view.Height = width, height
+	l.render()
+}
+
+// close stops following the log
+func (l *logPane) close() {
+	l.cancel()
+}
+
+func (l *logPane) View() string {
+	title := "Logs: " + l.source
+	if l.ended {
+		title += " (ended)"
+	}
+	scroll := fmt.Sprintf(" (%d%%)", int(l.view.ScrollPercent()*100))
+	return headerStyle.Render(title+scroll) + "\n" +
+		paneStyle.Render(l.view.View()) + "\n" +
+		dimStyle.Render("↑/↓ scroll  [esc] back  [q] quit")
+}
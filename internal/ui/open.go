@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/doganarif/portfinder/internal/process"
+)
+
+// opener is the command opening project directories, set with SetOpener
+var opener string
+
+// SetOpener sets the command the TUIs open project directories with:
+// "editor" for $VISUAL or $EDITOR, a command such as "code" to which the
+// directory is appended, or "" for the file manager
+func SetOpener(command string) {
+	opener = strings.TrimSpace(command)
+}
+
+// openedMsg reports the outcome of opening a project directory
+type openedMsg struct {
+	dir string
+	err error
+}
+
+// openProject opens a project directory with the opener, handing the
+// terminal over to it so terminal editors work, or in the file manager
+func openProject(dir string) tea.Cmd {
+	command := strings.Fields(opener)
+	if opener == "editor" {
+		editor := os.Getenv("VISUAL")
+		if editor == "" {
+			editor = os.Getenv("EDITOR")
+		}
+		if editor == "" {
+			return func() tea.Msg {
+				return openedMsg{dir: dir, err: fmt.Errorf("neither $VISUAL nor $EDITOR is set")}
+			}
+		}
+		command = strings.Fields(editor)
+	}
+	if len(command) == 0 {
+		return func() tea.Msg {
+			return openedMsg{dir: dir, err: openPath(dir)}
+		}
+	}
+
+	cmd := exec.Command(command[0], append(command[1:], dir)...)
+	cmd.Dir = dir
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		if err != nil {
+			err = fmt.Errorf("%s failed: %w", command[0], err)
+		}
+		return openedMsg{dir: dir, err: err}
+	})
+}
+
+// openedMessage is the status message for an openedMsg
+func openedMessage(msg openedMsg) string {
+	if msg.err != nil {
+		return portUsedStyle.Render("❌ " + msg.err.Error())
+	}
+	return infoStyle.Render("📂 Opened " + msg.dir)
+}
+
+// openPath opens a file or directory with the desktop's default
+// application, a file manager for directories
+func openPath(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", path)
+	case "windows":
+		cmd = exec.Command("explorer", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+	// The opener may keep running, e.g. as the file manager itself
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("cannot open %s: %w", path, err)
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// projectDir is the directory of the process's project, else its working
+// directory, "" when neither is known
+func projectDir(p *process.Process) string {
+	if filepath.IsAbs(p.ProjectPath) {
+		return p.ProjectPath
+	}
+	return p.WorkDir
+}
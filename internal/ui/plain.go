@@ -0,0 +1,160 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/doganarif/portfinder/internal/advisor"
+	"github.com/doganarif/portfinder/internal/config"
+	"github.com/doganarif/portfinder/internal/process"
+)
+
+// plainMode prints simple tables and text instead of launching TUIs, for
+// pipelines, CI logs and limited terminals
+var plainMode bool
+
+// SetPlain switches between the interactive UI and plain output
+func SetPlain(enabled bool) {
+	plainMode = enabled
+}
+
+// plainRenderer prints the views that are TUIs or styled boxes in the
+// regular UI as plain text
+type plainRenderer struct{}
+
+// processList prints all listeners as a table
+func (plainRenderer) processList(processes []*process.Process, opts ListOptions) {
+	if accessibleMode {
+		accessibleList(processes, opts)
+		return
+	}
+	DisplayProcessList(processes, opts)
+}
+
+// portCheck prints the status of each port by group
+func (plainRenderer) portCheck(ports map[int]*process.Process, groups []config.PortGroup, hints map[int][]advisor.Suggestion) {
+	if accessibleMode {
+		accessibleCheck(ports, groups, hints)
+		return
+	}
+	fmt.Println()
+	fmt.Println("Development Ports")
+
+	for _, group := range groups {
+		fmt.Printf("\n%s:\n", group.Name)
+		for _, port := range group.Ports {
+			label := ""
+			if l := group.Labels[port]; l != "" {
+				label = " (" + l + ")"
+			}
+
+			proc := ports[port]
+			if proc == nil {
+				fmt.Printf("  [free] %d%s\n", port, label)
+				continue
+			}
+
+			fmt.Printf("  [used] %d%s: %s", port, label, proc.Name)
+			if proc.ProjectPath != "" && proc.ProjectPath != "unknown" {
+				fmt.Printf(" (%s)", projectLabel(proc))
+			}
+			fmt.Println()
+			if h := hints[port]; len(h) > 0 {
+				fmt.Printf("         -> %s\n", h[0])
+			}
+		}
+	}
+}
+
+// processDetail prints the properties of a process as aligned lines
+func (plainRenderer) processDetail(proc *process.Process, advice advisor.Advice) {
+	fmt.Println()
+	fmt.Printf("Port %d is in use by:\n\n", proc.Port)
+
+	lines := [][2]string{
+		{"Port", fmt.Sprintf("%d", proc.Port)},
+		{"Address", formatAddresses(proc)},
+	}
+	if proc.SocketOnly {
+		lines = append(lines, [2]string{"Process", "unavailable in this environment"})
+		if proc.User != "" {
+			lines = append(lines, [2]string{"User", proc.User})
+		}
+	} else {
+		lines = append(lines,
+			[2]string{"Process", proc.DisplayName()},
+			[2]string{"PID", fmt.Sprintf("%d", proc.PID)},
+			[2]string{"User", formatUser(proc)},
+			[2]string{"Command", truncateCommand(proc.Command)},
+			[2]string{"Project", projectLabel(proc)},
+			[2]string{"Started", formatTime(proc.StartTime)},
+			[2]string{"Running For", formatAge(proc)},
+		)
+	}
+	if proc.ProjectName != "" {
+		lines = append(lines, [2]string{"Directory", proc.ProjectPath})
+	}
+	if len(proc.Tags) > 0 {
+		lines = append(lines, [2]string{"Class", strings.Join(proc.Tags, ", ")})
+	}
+	if cpu := formatCPU(proc); cpu != "" {
+		lines = append(lines, [2]string{"CPU", cpu})
+	}
+	if proc.RSS > 0 {
+		lines = append(lines, [2]string{"Memory", formatMemory(proc.RSS)})
+	}
+	lines = append(lines, [2]string{"Connections", formatConnections(proc)})
+	if f := proc.Fingerprint; f != nil {
+		lines = append(lines, [2]string{"Answers", formatFingerprint(f)})
+		if f.Framework != "" && f.Status != 0 {
+			lines = append(lines, [2]string{"Framework", f.Framework})
+		}
+	}
+	if proc.Firewall != "" {
+		lines = append(lines, [2]string{"Firewall", proc.Firewall})
+	}
+	if proc.IsDocker {
+		lines = append(lines, [2]string{proc.RuntimeName(), "Yes (Container: " + proc.DockerID + ")"})
+		if proc.ContainerName != "" {
+			lines = append(lines, [2]string{"Container", proc.ContainerName})
+		}
+		if proc.ContainerImage != "" {
+			lines = append(lines, [2]string{"Image", proc.ContainerImage})
+		}
+		if mapping := containerMapping(proc); mapping != "" {
+			lines = append(lines, [2]string{"Mapping", mapping})
+		}
+	}
+	if target := proc.KubeTarget(); target != "" {
+		lines = append(lines, [2]string{"Kubernetes", target})
+		if proc.KubeCluster != "" {
+			lines = append(lines, [2]string{"Cluster", proc.KubeCluster})
+		}
+	}
+	if proc.Service != "" {
+		lines = append(lines, [2]string{"Service", fmt.Sprintf("%s (%s)", proc.Service, proc.ServiceManager())})
+	}
+	if proc.Host == process.HostWindows {
+		lines = append(lines, [2]string{"Host", "Windows (through WSL interop)"})
+	}
+	if tree := proc.Tree(); proc.Lineage != nil && len(tree) > 1 {
+		lines = append(lines, [2]string{"Tree", formatTree(tree)})
+	}
+
+	for _, line := range lines {
+		fmt.Printf("  %-12s %s\n", line[0]+":", line[1])
+	}
+
+	if len(advice.Suggestions) > 0 {
+		fmt.Printf("\nTo free this port, in %s:\n", formatProject(proc.ProjectPath))
+		for _, s := range advice.Suggestions {
+			fmt.Printf("  - %s\n", s)
+		}
+	}
+	if len(advice.Alternatives) > 0 {
+		fmt.Printf("\nFree ports nearby: %s\n", joinPorts(advice.Alternatives))
+		if advice.Command != "" {
+			fmt.Printf("  Run yours on %d: %s\n", advice.Alternatives[0], advice.Command)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/doganarif/portfinder/internal/process"
+)
+
+// Snapshot is the outcome of one refresh of a Provider
+type Snapshot struct {
+	Processes []*process.Process
+	Err       error
+	Time      time.Time
+}
+
+// Provider supplies the processes shown by the list view. Refresh loads
+// them and publishes the result to every subscriber, so views don't depend
+// on where processes come from: the local machine, or another host's
+// portfinder serve API.
+type Provider interface {
+	// Refresh loads the current processes, publishing the snapshot to
+	// subscribers unless ctx is canceled or a newer refresh completed first
+	Refresh(ctx context.Context) error
+	// Subscribe returns a channel receiving snapshots. A slow subscriber
+	// only receives the latest one.
+	Subscribe() <-chan Snapshot
+}
+
+// Killer is implemented by providers that kill processes themselves, such
+// as remote ones whose PIDs mean nothing locally
+type Killer interface {
+	Kill(p *process.Process) error
+}
+
+// finderProvider provides the processes of a Finder
+type finderProvider struct {
+	finder process.Finder
+	killer Killer
+
+	mu          sync.Mutex
+	generation  uint64
+	subscribers []chan Snapshot
+}
+
+// NewFinderProvider returns a Provider listing processes through finder.
+// Processes are killed through killer, or locally when it is nil.
+func NewFinderProvider(finder process.Finder, killer Killer) Provider {
+	return &finderProvider{finder: finder, killer: killer}
+}
+
+func (p *finderProvider) Refresh(ctx context.Context) error {
+	p.mu.Lock()
+	p.generation++
+	generation := p.generation
+	p.mu.Unlock()
+
+	// A canceled refresh stops the tools it ran and its result is dropped
+	processes, err := p.finder.ListAll(ctx)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	snapshot := Snapshot{Processes: processes, Err: err, Time: time.Now()}
+	p.publish(generation, snapshot)
+	return snapshot.Err
+}
+
+func (p *finderProvider) Subscribe() <-chan Snapshot {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ch := make(chan Snapshot, 1)
+	p.subscribers = append(p.subscribers, ch)
+	return ch
+}
+
+func (p *finderProvider) Kill(proc *process.Process) error {
+	// The next refresh must not show the process from a cached listing
+	defer process.Invalidate(p.finder)
+	if p.killer != nil {
+		return p.killer.Kill(proc)
+	}
+	return killProcess(proc)
+}
+
+// publish hands the snapshot to subscribers, unless a later refresh
+// started meanwhile and will publish fresher data
+func (p *finderProvider) publish(generation uint64, snapshot Snapshot) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if generation != p.generation {
+		return
+	}
+
+	for _, ch := range p.subscribers {
+		// Replace an unread snapshot rather than blocking on the subscriber
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- snapshot
+	}
+}
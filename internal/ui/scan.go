@@ -0,0 +1,213 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/doganarif/portfinder/internal/process"
+	"github.com/olekukonko/tablewriter"
+)
+
+// ScanGroup is a process and the ports of a scanned range it listens on
+type ScanGroup struct {
+	Name    string `json:"name"`
+	PID     int    `json:"pid,omitempty"`
+	User    string `json:"user,omitempty"`
+	Type    string `json:"type"`
+	Project string `json:"project,omitempty"`
+	Ports   []int  `json:"ports"`
+}
+
+// ScanSummary is what a scan of a port range found
+type ScanSummary struct {
+	From      int `json:"from"`
+	To        int `json:"to"`
+	Listeners int `json:"listeners"`
+	Processes int `json:"processes"`
+	// Public counts the listeners reachable from the network
+	Public int `json:"public"`
+	// Types counts the listeners of each kind of process, e.g. "database"
+	// or "Docker"
+	Types  map[string]int `json:"types"`
+	Groups []ScanGroup    `json:"groups"`
+}
+
+// SummarizeScan groups the listeners on ports from low to high by process,
+// ordered by their lowest port. Listeners whose process is hidden share a
+// group.
+func SummarizeScan(processes []*process.Process, low, high int) ScanSummary {
+	summary := ScanSummary{From: low, To: high, Types: map[string]int{}, Groups: []ScanGroup{}}
+	sort.Slice(processes, func(i, j int) bool {
+		return processes[i].Port < processes[j].Port
+	})
+
+	index := make(map[string]int)
+	seen := make(map[string]bool)
+	for _, p := range processes {
+		if p.Port < low || p.Port > high {
+			continue
+		}
+		// A port shows once per address family, count it once
+		listener := fmt.Sprintf("%s:%d:%d", p.Host, p.PID, p.Port)
+		if seen[listener] {
+			continue
+		}
+		seen[listener] = true
+
+		summary.Listeners++
+		if p.IsPublic() {
+			summary.Public++
+		}
+		kind := scanKind(p)
+		summary.Types[kind]++
+
+		key := p.Host + ":" + strconv.Itoa(p.PID)
+		if p.SocketOnly {
+			key = "hidden"
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(summary.Groups)
+			index[key] = i
+			summary.Groups = append(summary.Groups, scanGroup(p))
+		}
+		summary.Groups[i].Ports = append(summary.Groups[i].Ports, p.Port)
+	}
+	summary.Processes = len(summary.Groups)
+	if _, ok := index["hidden"]; ok {
+		summary.Processes--
+	}
+	return summary
+}
+
+func scanGroup(p *process.Process) ScanGroup {
+	if p.SocketOnly {
+		return ScanGroup{Name: "(hidden)", Type: scanKind(p)}
+	}
+	group := ScanGroup{Name: p.Name, PID: p.PID, User: p.User, Type: processType(p)}
+	if project := projectLabel(p); project != "unknown" && project != "-" {
+		group.Project = project
+	}
+	return group
+}
+
+// scanKind is the kind of process a listener is counted under, its type
+// without the container or service name
+func scanKind(p *process.Process) string {
+	if p.SocketOnly {
+		return "unknown"
+	}
+	kind, _, _ := strings.Cut(processType(p), ":")
+	return kind
+}
+
+// DisplayScan displays the processes found listening in a port range and
+// how many of each kind there are
+func DisplayScan(s ScanSummary) {
+	if accessibleMode {
+		accessibleScan(s)
+		return
+	}
+	if s.Listeners == 0 {
+		InfoMsg("Nothing is listening on ports %d-%d", s.From, s.To)
+		return
+	}
+
+	fmt.Println()
+	infoColor.Printf(Icon("🔎 ", "")+"Ports %d-%d: %d listeners, %d processes\n", s.From, s.To, s.Listeners, s.Processes)
+	fmt.Println()
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Process", "PID", "User", "Type", "Project", "Ports"})
+	table.SetBorder(false)
+	table.SetHeaderLine(true)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoWrapText(false)
+
+	for _, g := range s.Groups {
+		pid, user, project := "-", g.User, g.Project
+		if g.PID > 0 {
+			pid = strconv.Itoa(g.PID)
+		}
+		if user == "" {
+			user = "-"
+		}
+		if project == "" {
+			project = "-"
+		}
+		table.Append([]string{g.Name, pid, user, g.Type, project, compactPorts(g.Ports)})
+	}
+	table.Render()
+
+	fmt.Println()
+	fmt.Println("By type: " + scanTypes(s.Types, Icon(" · ", ", ")))
+	if s.Public > 0 {
+		warnColor.Printf("%d of %d listeners are reachable from the network\n", s.Public, s.Listeners)
+	}
+}
+
+// accessibleScan writes a sentence per process, then the counts
+func accessibleScan(s ScanSummary) {
+	if s.Listeners == 0 {
+		fmt.Printf("Nothing is listening on ports %d to %d.\n", s.From, s.To)
+		return
+	}
+	fmt.Printf("Ports %d to %d: %s, %s.\n", s.From, s.To,
+		pluralize(s.Listeners, "listener", "listeners"), pluralize(s.Processes, "process", "processes"))
+	for _, g := range s.Groups {
+		parts := []string{"Process " + g.Name}
+		if g.PID > 0 {
+			parts = append(parts, fmt.Sprintf("PID %d", g.PID))
+		}
+		parts = append(parts, "Type "+g.Type)
+		if g.Project != "" {
+			parts = append(parts, "Project "+g.Project)
+		}
+		parts = append(parts, "Ports "+compactPorts(g.Ports))
+		fmt.Println(strings.Join(parts, ", ") + ".")
+	}
+	fmt.Printf("By type: %s.\n", scanTypes(s.Types, ", "))
+	if s.Public > 0 {
+		fmt.Printf("%d reachable from the network.\n", s.Public)
+	}
+}
+
+// scanTypes lists the counts of each kind, most common first
+func scanTypes(types map[string]int, sep string) string {
+	kinds := make([]string, 0, len(types))
+	for kind := range types {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool {
+		if types[kinds[i]] != types[kinds[j]] {
+			return types[kinds[i]] > types[kinds[j]]
+		}
+		return kinds[i] < kinds[j]
+	})
+	parts := make([]string, len(kinds))
+	for i, kind := range kinds {
+		parts[i] = fmt.Sprintf("%s %d", kind, types[kind])
+	}
+	return strings.Join(parts, sep)
+}
+
+// compactPorts lists sorted ports, collapsing runs, e.g. "3000-3002, 8080"
+func compactPorts(ports []int) string {
+	var parts []string
+	for i := 0; i < len(ports); {
+		j := i
+		for j+1 < len(ports) && ports[j+1] == ports[j]+1 {
+			j++
+		}
+		if j > i {
+			parts = append(parts, fmt.Sprintf("%d-%d", ports[i], ports[j]))
+		} else {
+			parts = append(parts, strconv.Itoa(ports[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
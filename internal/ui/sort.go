@@ -0,0 +1,137 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/doganarif/portfinder/internal/process"
+)
+
+// sortColumn is a column the process list can be ordered by
+type sortColumn int
+
+const (
+	sortPort sortColumn = iota
+	sortPID
+	sortName
+	sortUptime
+	sortProject
+	sortConnections
+	sortCPU
+	sortMemory
+)
+
+// sortOrder describes how a column sorts
+type sortOrder struct {
+	// title is the header of the column showing the sort indicator
+	title string
+	// binding is the key selecting the column
+	binding *key.Binding
+	// less orders two processes ascending
+	less func(a, b *process.Process) bool
+	// desc makes the first press sort descending, for columns where the
+	// largest values are the interesting ones
+	desc bool
+	// unknown reports processes lacking the value, which sort last in
+	// either direction
+	unknown func(p *process.Process) bool
+}
+
+var sortOrders = map[sortColumn]sortOrder{
+	sortPort: {title: "Port", binding: &keys.SortPort, less: func(a, b *process.Process) bool {
+		return a.Port < b.Port
+	}},
+	sortPID: {title: "PID", binding: &keys.SortPID, unknown: isSocketOnly, less: func(a, b *process.Process) bool {
+		return a.PID < b.PID
+	}},
+	sortName: {title: "Process", binding: &keys.SortName, unknown: isSocketOnly, less: func(a, b *process.Process) bool {
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	}},
+	// Longer running processes started earlier
+	sortUptime: {title: "Running For", binding: &keys.SortUptime, desc: true, less: func(a, b *process.Process) bool {
+		return a.StartTime.After(b.StartTime)
+	}, unknown: func(p *process.Process) bool {
+		return p.StartTime.IsZero()
+	}},
+	sortProject: {title: "Project", binding: &keys.SortProject, less: func(a, b *process.Process) bool {
+		return strings.ToLower(projectKey(a)) < strings.ToLower(projectKey(b))
+	}, unknown: func(p *process.Process) bool {
+		return p.ProjectPath == "" || p.ProjectPath == "unknown"
+	}},
+	sortConnections: {title: "Conns", binding: &keys.SortConns, desc: true, less: func(a, b *process.Process) bool {
+		return a.Connections < b.Connections
+	}},
+	sortCPU: {title: "CPU", binding: &keys.SortCPU, desc: true, unknown: isSocketOnly, less: func(a, b *process.Process) bool {
+		return a.CPUPercent < b.CPUPercent
+	}},
+	sortMemory: {title: "Mem", binding: &keys.SortMemory, desc: true, unknown: isSocketOnly, less: func(a, b *process.Process) bool {
+		return a.RSS < b.RSS
+	}},
+}
+
+// projectKey is what the project column shows: the name when known
+func projectKey(p *process.Process) string {
+	if p.ProjectName != "" {
+		return p.ProjectName
+	}
+	return p.ProjectPath
+}
+
+func isSocketOnly(p *process.Process) bool {
+	return p.SocketOnly
+}
+
+// sortKeyPressed returns the column whose sort key was pressed
+func sortKeyPressed(msg tea.KeyMsg) (sortColumn, bool) {
+	for column, order := range sortOrders {
+		if key.Matches(msg, *order.binding) {
+			return column, true
+		}
+	}
+	return 0, false
+}
+
+// sortProcesses orders the processes by the column, keeping the port order
+// among equal values
+func sortProcesses(processes []*process.Process, column sortColumn, desc bool) {
+	order := sortOrders[column]
+	less := order.less
+	sort.SliceStable(processes, func(i, j int) bool {
+		a, b := processes[i], processes[j]
+		if order.unknown != nil {
+			if ua, ub := order.unknown(a), order.unknown(b); ua != ub {
+				return ub
+			}
+		}
+		if desc {
+			a, b = b, a
+		}
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return processes[i].Port < processes[j].Port
+	})
+}
+
+// markSortColumn appends the sort direction to the header of the sorted
+// column
+func markSortColumn(columns []table.Column, column sortColumn, desc bool) []table.Column {
+	arrow := Icon(" ▲", " ^")
+	if desc {
+		arrow = Icon(" ▼", " v")
+	}
+	title := sortOrders[column].title
+	for i := range columns {
+		// Tree mode titles the process column "Process Tree"
+		if strings.HasPrefix(columns[i].Title, title) {
+			columns[i].Title += arrow
+		}
+	}
+	return columns
+}
@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/doganarif/portfinder/internal/config"
+	"github.com/fatih/color"
+	"github.com/muesli/termenv"
+)
+
+// palette holds the colors of the current theme
+var palette = (&config.Config{}).ActiveTheme()
+
+// SetTheme restyles the UI with a theme's colors. A theme without any,
+// like no-color, keeps only bold and reverse video, as does NO_COLOR unless
+// colors are forced on.
+func SetTheme(theme config.Theme) {
+	if os.Getenv("NO_COLOR") != "" && colorMode != "always" {
+		theme = config.Theme{}
+		// lipgloss drops every style under NO_COLOR; bring back bold and
+		// reverse video on terminals
+		if termenv.NewOutput(os.Stdout).ColorProfile() != termenv.Ascii {
+			lipgloss.SetColorProfile(termenv.ANSI)
+		}
+	}
+	palette = theme
+	if theme == (config.Theme{}) {
+		color.NoColor = true
+	}
+
+	headerStyle = headerStyle.Foreground(lipgloss.Color(theme.Accent))
+	titleStyle = titleStyle.
+		Background(lipgloss.Color(theme.Title)).
+		Foreground(lipgloss.Color(theme.TitleText))
+	selectedStyle = selectedStyle.
+		Foreground(lipgloss.Color(theme.SelectionText)).
+		Background(lipgloss.Color(theme.Selection)).
+		Reverse(reverseSelection())
+	portUsedStyle = portUsedStyle.Foreground(lipgloss.Color(theme.Used))
+	portFreeStyle = portFreeStyle.Foreground(lipgloss.Color(theme.Free))
+	dimStyle = dimStyle.Foreground(lipgloss.Color(theme.Dim))
+	infoStyle = infoStyle.Foreground(lipgloss.Color(theme.Info))
+	hintStyle = hintStyle.Foreground(lipgloss.Color(theme.Hint))
+	dockerStyle = dockerStyle.Foreground(lipgloss.Color(theme.Accent))
+	paneStyle = paneStyle.BorderForeground(lipgloss.Color(theme.Border))
+	dialogStyle = dialogStyle.BorderForeground(lipgloss.Color(theme.Alert))
+	detailBoxStyle = detailBoxStyle.BorderForeground(lipgloss.Color(theme.Border))
+}
+
+// reverseSelection reports whether the selected row is told apart in
+// reverse video, as it has no colors to be
+func reverseSelection() bool {
+	return palette.Selection == ""
+}
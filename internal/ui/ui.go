@@ -2,13 +2,21 @@ package ui
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
+	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/doganarif/portfinder/internal/certs"
+	"github.com/doganarif/portfinder/internal/config"
+	"github.com/doganarif/portfinder/internal/history"
+	"github.com/doganarif/portfinder/internal/inventory"
 	"github.com/doganarif/portfinder/internal/process"
+	"github.com/doganarif/portfinder/internal/snapshot"
 	"github.com/fatih/color"
 	"github.com/manifoldco/promptui"
 	"github.com/olekukonko/tablewriter"
@@ -21,24 +29,86 @@ var (
 	warnColor    = color.New(color.FgYellow)
 )
 
+// killHook is notified after the UI successfully kills a process
+var killHook func(*process.Process, process.KillStrategy)
+
+// SetKillHook registers a function called after every successful kill
+func SetKillHook(hook func(*process.Process, process.KillStrategy)) {
+	killHook = hook
+}
+
+// killGuard vetoes kills from the views, e.g. of protected processes
+var killGuard func(*process.Process) error
+
+// SetKillGuard sets a function run before every kill from the list and
+// detail views; an error from it prevents the kill
+func SetKillGuard(guard func(*process.Process) error) {
+	killGuard = guard
+}
+
+// killProcess kills a process with the automatically selected strategy
+func killProcess(p *process.Process) error {
+	return killWith(p, process.SelectStrategy(p))
+}
+
+// killWith kills a process with the given strategy, notifying the kill hook
+func killWith(p *process.Process, strategy process.KillStrategy) error {
+	if killGuard != nil {
+		if err := killGuard(p); err != nil {
+			return err
+		}
+	}
+	if err := p.KillWith(strategy); err != nil {
+		return err
+	}
+	if killHook != nil {
+		killHook(p, strategy)
+	}
+	return nil
+}
+
+// killAndVerify kills a process like killWith, then watches its port,
+// returning the process that bound it again, if any
+func killAndVerify(finder process.Finder, p *process.Process, strategy process.KillStrategy) (*process.Process, error) {
+	if killGuard != nil {
+		if err := killGuard(p); err != nil {
+			return nil, err
+		}
+	}
+	respawned, err := process.KillAndVerify(context.Background(), finder, p, strategy, process.RespawnWindow)
+	if err != nil {
+		return nil, err
+	}
+	if killHook != nil {
+		killHook(p, strategy)
+	}
+	return respawned, nil
+}
+
 // SuccessMsg prints a success message
 func SuccessMsg(format string, args ...interface{}) {
-	successColor.Printf("✅ "+format+"\n", args...)
+	successColor.Printf(Icon("✅ ", "OK: ")+format+"\n", args...)
+}
+
+// MessagesToStderr sends status messages to stderr, keeping stdout clean
+// for machine readable output
+func MessagesToStderr() {
+	color.Output = color.Error
 }
 
 // ErrorMsg prints an error message
 func ErrorMsg(format string, args ...interface{}) {
-	errorColor.Printf("❌ "+format+"\n", args...)
+	errorColor.Printf(Icon("❌ ", "ERROR: ")+format+"\n", args...)
 }
 
 // InfoMsg prints an info message
 func InfoMsg(format string, args ...interface{}) {
-	infoColor.Printf("ℹ️  "+format+"\n", args...)
+	infoColor.Printf(Icon("ℹ️  ", "")+format+"\n", args...)
 }
 
 // WarnMsg prints a warning message
 func WarnMsg(format string, args ...interface{}) {
-	warnColor.Printf("⚠️  "+format+"\n", args...)
+	warnColor.Printf(Icon("⚠️  ", "WARNING: ")+format+"\n", args...)
 }
 
 // DisplayProcess displays detailed information about a process
@@ -55,15 +125,35 @@ func DisplayProcess(p *process.Process) {
 	table.SetAlignment(tablewriter.ALIGN_LEFT)
 
 	data := [][]string{
-		{"Process", p.Name},
+		{"Process", p.DisplayName()},
 		{"PID", fmt.Sprintf("%d", p.PID)},
+		{"User", formatUser(p)},
 		{"Command", truncateCommand(p.Command)},
-		{"Project", formatProject(p.ProjectPath)},
+		{"Project", projectLabel(p)},
 		{"Started", formatDuration(time.Since(p.StartTime)) + " ago"},
 	}
 
+	if p.ProjectName != "" {
+		data = append(data, []string{"Directory", p.ProjectPath})
+	}
+
 	if p.IsDocker {
-		data = append(data, []string{"Docker", fmt.Sprintf("Yes (Container: %s)", p.DockerID)})
+		data = append(data, []string{p.RuntimeName(), fmt.Sprintf("Yes (Container: %s)", p.DockerID)})
+		if p.ContainerName != "" {
+			data = append(data, []string{"Container", p.ContainerName})
+		}
+		if p.ContainerImage != "" {
+			data = append(data, []string{"Image", p.ContainerImage})
+		}
+		if mapping := containerMapping(p); mapping != "" {
+			data = append(data, []string{"Mapping", mapping})
+		}
+	}
+	if target := p.KubeTarget(); target != "" {
+		data = append(data, []string{"Kubernetes", target})
+	}
+	if p.Host == process.HostWindows {
+		data = append(data, []string{"Host", "Windows (through WSL interop)"})
 	}
 
 	table.AppendBulk(data)
@@ -72,46 +162,43 @@ func DisplayProcess(p *process.Process) {
 }
 
 // DisplayPortSummary displays a summary of common ports
-func DisplayPortSummary(ports map[int]*process.Process) {
+func DisplayPortSummary(ports map[int]*process.Process, groups []config.PortGroup) {
 	fmt.Println()
-	infoColor.Println("📊 Common Development Ports:")
+	infoColor.Println("📊 Development Ports:")
 	fmt.Println()
 
-	// Group ports by category
-	categories := map[string][]int{
-		"Frontend":  {3000, 3001, 4200, 5173, 8080},
-		"Backend":   {4000, 5000, 8000, 9000},
-		"Databases": {3306, 5432, 6379, 27017},
-		"Tools":     {9200, 9090, 3100, 8983},
-	}
-
-	for category, categoryPorts := range categories {
-		fmt.Printf("\n%s:\n", category)
-		for _, port := range categoryPorts {
+	for _, group := range groups {
+		fmt.Printf("\n%s:\n", group.Name)
+		for _, port := range group.Ports {
+			label := ""
+			if l := group.Labels[port]; l != "" {
+				label = " (" + l + ")"
+			}
 			if proc, exists := ports[port]; exists {
 				if proc != nil {
-					errorColor.Printf("  ❌ %d: %s", port, proc.Name)
+					errorColor.Printf("  ❌ %d%s: %s", port, label, proc.Name)
 					if proc.ProjectPath != "" && proc.ProjectPath != "unknown" {
-						fmt.Printf(" (%s)", proc.ProjectPath)
+						fmt.Printf(" (%s)", projectLabel(proc))
 					}
 					fmt.Println()
 				} else {
-					successColor.Printf("  ✅ %d: free\n", port)
+					successColor.Printf("  ✅ %d%s: free\n", port, label)
 				}
 			}
 		}
 	}
 }
 
-// DisplayProcessList displays a list of all processes
-func DisplayProcessList(processes []*process.Process) {
+// DisplayProcessList displays a list of all processes, with a label column
+// when any ports are labeled
+func DisplayProcessList(processes []*process.Process, opts ListOptions) {
 	if len(processes) == 0 {
 		InfoMsg("No processes are using network ports")
 		return
 	}
 
 	fmt.Println()
-	infoColor.Printf("📋 Found %d processes using network ports:\n", len(processes))
+	infoColor.Printf(Icon("📋 ", "")+"Found %d processes using network ports:\n", len(processes))
 	fmt.Println()
 
 	// Sort by port number
@@ -119,8 +206,61 @@ func DisplayProcessList(processes []*process.Process) {
 		return processes[i].Port < processes[j].Port
 	})
 
+	// The plain table leaves out the type column unless it was picked
+	names := visibleColumns(usageColumnsPicked() && !opts.Remote, len(opts.Labels) > 0)
+	if shownColumns == nil {
+		names = removeColumn(names, "type")
+	}
+	if usageColumnsPicked() && !opts.Remote {
+		sampleUsageNow(processes)
+	}
+	header := make([]string, len(names))
+	for i, name := range names {
+		header[i] = columnDefs[name].title
+	}
+
 	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Port", "Process", "PID", "Project", "Running For"})
+	table.SetHeader(header)
+	table.SetBorder(false)
+	table.SetHeaderLine(true)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+
+	for _, ports := range listedRows(processes, opts.Merge) {
+		row := make([]string, len(names))
+		for i, name := range names {
+			row[i] = mergedCell(name, ports, opts.Labels, opts.Restarts)
+			// Unlike the TUI, the table spells out unknown projects
+			if name == "project" {
+				row[i] = projectLabel(ports[0])
+			}
+		}
+		table.Append(row)
+	}
+
+	table.Render()
+
+	if notice := socketOnlyNotice(processes, opts.Elevated); notice != "" {
+		fmt.Println()
+		WarnMsg("%s", notice)
+	}
+}
+
+// DisplayKillPlan lists the processes a bulk kill is about to terminate
+func DisplayKillPlan(processes []*process.Process, dryRun bool) {
+	if accessibleMode {
+		accessibleKillPlan(processes, dryRun)
+		return
+	}
+	fmt.Println()
+	if dryRun {
+		warnColor.Println(Icon("💀 ", "") + "The following processes would be killed:")
+	} else {
+		warnColor.Println(Icon("💀 ", "") + "The following processes will be killed:")
+	}
+	fmt.Println()
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Port", "Process", "PID", "Project"})
 	table.SetBorder(false)
 	table.SetHeaderLine(true)
 	table.SetAlignment(tablewriter.ALIGN_LEFT)
@@ -128,16 +268,454 @@ func DisplayProcessList(processes []*process.Process) {
 	for _, p := range processes {
 		table.Append([]string{
 			fmt.Sprintf("%d", p.Port),
-			p.Name,
+			p.DisplayName(),
 			fmt.Sprintf("%d", p.PID),
-			formatProject(p.ProjectPath),
-			formatDuration(time.Since(p.StartTime)),
+			projectLabel(p),
+		})
+	}
+
+	table.Render()
+	fmt.Println()
+}
+
+// DisplayInventory displays the software versions found on listening ports
+func DisplayInventory(items []inventory.Item) {
+	if len(items) == 0 {
+		InfoMsg("No processes are using network ports")
+		return
+	}
+
+	fmt.Println()
+	infoColor.Printf(Icon("📦 ", "")+"Software inventory for %d listeners:\n", len(items))
+	fmt.Println()
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Port", "Process", "PID", "Version", "Source", "Package", "Server"})
+	table.SetBorder(false)
+	table.SetHeaderLine(true)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+
+	for _, item := range items {
+		version := item.Version
+		if version == "" {
+			version = "-"
+		}
+		table.Append([]string{
+			fmt.Sprintf("%d", item.Port),
+			item.Process,
+			fmt.Sprintf("%d", item.PID),
+			version,
+			item.VersionSource,
+			item.Package,
+			item.ServerHeader,
 		})
 	}
 
 	table.Render()
 }
 
+// DisplaySockets displays the sockets a process holds
+func DisplaySockets(p *process.Process, sockets []process.Socket) {
+	fmt.Println()
+	infoColor.Printf(Icon("🔌 ", "")+"Sockets held by %s (PID %d):\n", p.Name, p.PID)
+	fmt.Println()
+	if len(sockets) == 0 {
+		fmt.Println("  (none)")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Proto", "Local", "Remote", "State"})
+	table.SetBorder(false)
+	table.SetHeaderLine(true)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+
+	for _, s := range sockets {
+		remote, state := s.Remote, s.State
+		if remote == "" {
+			remote = "-"
+		}
+		if state == "" {
+			state = "-"
+		}
+		table.Append([]string{s.Proto, s.Local, remote, state})
+	}
+
+	table.Render()
+}
+
+// DisplayUnixSockets displays the Unix sockets listening on a path and the
+// stale socket files found
+func DisplayUnixSockets(sockets []process.UnixSocket) {
+	if len(sockets) == 0 {
+		InfoMsg("No Unix sockets found")
+		return
+	}
+
+	fmt.Println()
+	infoColor.Printf(Icon("🔌 ", "")+"%d Unix sockets:\n", len(sockets))
+	fmt.Println()
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Path", "Process", "PID", "Owner", "Mode", "State"})
+	table.SetBorder(false)
+	table.SetHeaderLine(true)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoWrapText(false)
+
+	stale := 0
+	for _, s := range sockets {
+		name, pid, owner, mode, state := s.Name, "-", s.Owner, s.Mode, "listening"
+		if s.PID > 0 {
+			pid = fmt.Sprintf("%d", s.PID)
+		}
+		if name == "" {
+			name = "-"
+		}
+		if owner == "" {
+			owner = "-"
+		}
+		if mode == "" {
+			mode = "-"
+		}
+		if s.Stale {
+			state = "stale"
+			stale++
+		}
+		table.Append([]string{s.Path, name, pid, owner, mode, state})
+	}
+	table.Render()
+
+	if stale > 0 {
+		fmt.Println()
+		WarnMsg("Nothing listens on the stale socket files: servers binding them fail with \"address already in use\" until they are deleted")
+	}
+}
+
+// stateReasons explain how a socket in each state holds on to its port
+var stateReasons = map[string]string{
+	"ESTABLISHED": "an open connection; one accepted by a listener that has since exited keeps the port bound by whoever inherited it",
+	"SYN_SENT":    "a connection being opened",
+	"SYN_RECV":    "a connection being accepted",
+	"FIN_WAIT1":   "closed here, waiting for the peer to acknowledge; released shortly",
+	"FIN_WAIT2":   "closed here, waiting for the peer to close its side",
+	"TIME_WAIT":   "closed, held by the kernel for up to a few minutes so stray packets die out; servers that set SO_REUSEADDR can bind the port anyway",
+	"CLOSE":       "closed, about to be released",
+	"CLOSE_WAIT":  "the peer closed the connection but the process never closed its socket, which holds the port until it does or exits",
+	"LAST_ACK":    "closed by both sides, waiting for the peer's last acknowledgment",
+	"CLOSING":     "closed by both sides at once, released shortly",
+}
+
+// DisplayPortStates displays the sockets on a port that aren't listening,
+// and how each state keeps the port from being bound again
+func DisplayPortStates(port int, sockets []process.TCPSocket) {
+	fmt.Println()
+	infoColor.Printf(Icon("🧷 ", "")+"Sockets on port %d in other states than LISTEN:\n", port)
+	fmt.Println()
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"State", "Local", "Remote", "Process"})
+	table.SetBorder(false)
+	table.SetHeaderLine(true)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+
+	var states []string
+	for _, s := range sockets {
+		remote, owner := s.Remote, "-"
+		if remote == "" {
+			remote = "-"
+		}
+		if s.PID > 0 {
+			owner = fmt.Sprintf("%s (PID %d)", s.Name, s.PID)
+		}
+		table.Append([]string{s.State, s.Local, remote, owner})
+		if len(states) == 0 || states[len(states)-1] != s.State {
+			states = append(states, s.State)
+		}
+	}
+	table.Render()
+
+	fmt.Println()
+	for _, state := range states {
+		if reason, ok := stateReasons[state]; ok {
+			fmt.Printf("  %s: %s\n", state, reason)
+		}
+	}
+}
+
+// DisplayBindCheck tells whether a server could bind a port that looks
+// occupied, and with which socket options
+func DisplayBindCheck(b process.BindResult) {
+	fmt.Println()
+	switch {
+	case b.Error != "":
+		InfoMsg("Could not test binding port %d: %s", b.Port, b.Error)
+	case b.Plain:
+		SuccessMsg("A server can bind port %d now: its current owner listens on another address or in another network namespace", b.Port)
+	case b.ReuseAddr:
+		WarnMsg("Only lingering connections hold port %d: servers setting SO_REUSEADDR, as Node, Go, Python and nginx do, can bind it now, others must wait for TIME_WAIT to end", b.Port)
+	case b.ReusePort:
+		WarnMsg("Port %d is taken, but a server setting SO_REUSEPORT can share it with its listener, which set it too", b.Port)
+	default:
+		InfoMsg("Port %d is really taken: no server can bind it until its owner releases it", b.Port)
+	}
+}
+
+// DisplayOpenFiles displays the notable files a process holds open
+func DisplayOpenFiles(files []process.OpenFile) {
+	fmt.Println()
+	infoColor.Println(Icon("📄 ", "") + "Open files:")
+	fmt.Println()
+	if len(files) == 0 {
+		fmt.Println("  (none)")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"FD", "Path", "Kind"})
+	table.SetBorder(false)
+	table.SetHeaderLine(true)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+
+	for _, f := range files {
+		kind := f.Kind
+		if kind == "" {
+			kind = "-"
+		}
+		table.Append([]string{f.FD, f.Path, kind})
+	}
+
+	table.Render()
+}
+
+// DisplayHistory displays recorded port events, oldest first
+func DisplayHistory(events []history.Event) {
+	if len(events) == 0 {
+		InfoMsg("No history recorded")
+		return
+	}
+
+	fmt.Println()
+	infoColor.Printf(Icon("🕘 ", "")+"%d recorded port events:\n", len(events))
+	fmt.Println()
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Time", "Event", "Port", "Process", "PID", "Project", "By"})
+	table.SetBorder(false)
+	table.SetHeaderLine(true)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+
+	for _, e := range events {
+		actor := e.Actor
+		if e.Strategy != "" {
+			actor += " (" + e.Strategy + ")"
+		}
+		table.Append([]string{
+			e.Time.Local().Format("Jan 2 15:04:05"),
+			e.Event,
+			fmt.Sprintf("%d", e.Port),
+			e.Name,
+			fmt.Sprintf("%d", e.PID),
+			formatProject(e.ProjectPath),
+			actor,
+		})
+	}
+
+	table.Render()
+}
+
+// DisplaySnapshotDiff shows the listeners that changed since a snapshot
+// taken at the given time
+func DisplaySnapshotDiff(taken time.Time, changes []snapshot.Change) {
+	since := taken.Local().Format("Jan 2 15:04:05")
+	if len(changes) == 0 {
+		SuccessMsg("No listeners changed since %s", since)
+		return
+	}
+
+	fmt.Println()
+	infoColor.Printf(Icon("📸 ", "")+"%d listeners changed since %s:\n", len(changes), since)
+	fmt.Println()
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Port", "Change", "Before", "After"})
+	table.SetBorder(false)
+	table.SetHeaderLine(true)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+
+	for _, c := range changes {
+		table.Append([]string{
+			fmt.Sprintf("%d", c.Port),
+			c.Change,
+			formatListener(c.Before),
+			formatListener(c.After),
+		})
+	}
+
+	table.Render()
+}
+
+// expiryWarning is how close to its expiry a certificate is flagged
+const expiryWarning = 14 * 24 * time.Hour
+
+// DisplayCertificates shows the certificate chain a listener presented,
+// the leaf first
+func DisplayCertificates(p *process.Process, chain *certs.Chain) {
+	fmt.Println()
+	listener := chain.Address
+	if p != nil && !p.SocketOnly {
+		listener += fmt.Sprintf(" (%s, PID %d)", p.Name, p.PID)
+	}
+	infoColor.Printf(Icon("🔒 ", "")+"%s on %s:\n", chain.Version, listener)
+	if chain.Trusted {
+		SuccessMsg("Trusted for %s", chain.ServerName)
+	} else {
+		WarnMsg("Not trusted for %s: %s", chain.ServerName, chain.VerifyError)
+	}
+
+	now := time.Now()
+	for i, c := range chain.Certificates {
+		role := "leaf"
+		if i > 0 {
+			role = "issuer"
+		}
+		fmt.Printf("\nCertificate %d (%s):\n", i+1, role)
+
+		lines := [][2]string{{"Subject", c.Subject}}
+		if sans := append(append([]string{}, c.DNSNames...), c.IPAddresses...); len(sans) > 0 {
+			lines = append(lines, [2]string{"SANs", strings.Join(sans, ", ")})
+		}
+		issuer := c.Issuer
+		if c.SelfSigned {
+			issuer = "self-signed"
+		}
+		lines = append(lines, [2]string{"Issuer", issuer})
+		lines = append(lines, [2]string{"Valid", fmt.Sprintf("%s to %s", c.NotBefore.Local().Format("Jan 2 2006"), c.NotAfter.Local().Format("Jan 2 2006"))})
+		for _, line := range lines {
+			fmt.Printf("  %-10s %s\n", line[0]+":", line[1])
+		}
+
+		switch left := c.NotAfter.Sub(now); {
+		case c.Expired(now) && left < 0:
+			errorColor.Printf("  %-10s expired %s ago\n", "Expiry:", formatDuration(-left))
+		case c.Expired(now):
+			errorColor.Printf("  %-10s not valid yet\n", "Expiry:")
+		case left < expiryWarning:
+			warnColor.Printf("  %-10s in %s\n", "Expiry:", formatDuration(left))
+		default:
+			fmt.Printf("  %-10s in %s\n", "Expiry:", formatDuration(left))
+		}
+		fmt.Printf("  %-10s %s\n", "SHA-256:", c.SHA256)
+	}
+}
+
+// formatListener names a process and its PID, or "-" for none
+func formatListener(p *process.Process) string {
+	if p == nil {
+		return "-"
+	}
+	if p.SocketOnly {
+		return p.Name
+	}
+	return fmt.Sprintf("%s (PID %d)", p.Name, p.PID)
+}
+
+// CheckStatus is the outcome of a diagnostic check
+type CheckStatus int
+
+const (
+	CheckOK CheckStatus = iota
+	CheckWarn
+	CheckFail
+	CheckSkip
+)
+
+func (s CheckStatus) String() string {
+	switch s {
+	case CheckOK:
+		return "ok"
+	case CheckWarn:
+		return "warn"
+	case CheckFail:
+		return "fail"
+	}
+	return "skip"
+}
+
+// Check is one line of a diagnostic report
+type Check struct {
+	Status CheckStatus
+	Name   string
+	Detail string
+}
+
+// ProjectPort is a port a project expects to use and what holds it
+type ProjectPort struct {
+	Port    int
+	Service string
+	// Source is the file declaring the port
+	Source string
+	Status CheckStatus
+	// Detail describes the holder of the port, or how to resolve a conflict
+	Detail string
+}
+
+// DisplayProjectPorts displays the ports a project expects and their state
+func DisplayProjectPorts(dir string, ports []ProjectPort) {
+	fmt.Println()
+	infoColor.Printf(Icon("📁 ", "")+"Ports expected by %s:\n", dir)
+	fmt.Println()
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Port", "Service", "Source", "Status"})
+	table.SetBorder(false)
+	table.SetHeaderLine(true)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoWrapText(false)
+
+	for _, p := range ports {
+		service := p.Service
+		if service == "" {
+			service = "-"
+		}
+		var status string
+		switch p.Status {
+		case CheckOK:
+			status = Icon("✅ ", "[ok] ") + p.Detail
+		case CheckWarn:
+			status = Icon("⚠️  ", "[warn] ") + p.Detail
+		default:
+			status = Icon("❌ ", "[fail] ") + p.Detail
+		}
+		table.Append([]string{fmt.Sprintf("%d", p.Port), service, p.Source, status})
+	}
+
+	table.Render()
+}
+
+// DisplayChecks displays a titled section of a diagnostic report
+func DisplayChecks(title string, checks []Check) {
+	fmt.Println()
+	infoColor.Println(title)
+
+	for _, c := range checks {
+		switch c.Status {
+		case CheckOK:
+			successColor.Printf("  %s%s", Icon("✅ ", "[ok]   "), c.Name)
+		case CheckWarn:
+			warnColor.Printf("  %s%s", Icon("⚠️  ", "[warn] "), c.Name)
+		case CheckFail:
+			errorColor.Printf("  %s%s", Icon("❌ ", "[fail] "), c.Name)
+		default:
+			fmt.Printf("  %s%s", Icon("➖ ", "[--]   "), c.Name)
+		}
+		if c.Detail != "" {
+			fmt.Printf(": %s", c.Detail)
+		}
+		fmt.Println()
+	}
+}
+
 // ConfirmKill asks for confirmation before killing a process
 func ConfirmKill() bool {
 	prompt := promptui.Select{
@@ -174,6 +752,99 @@ func SimpleConfirm(question string) bool {
 
 // Helper functions
 
+// formatAddress describes the address a process is bound to
+func formatAddress(address string) string {
+	switch address {
+	case "":
+		return "-"
+	case "*", "0.0.0.0", "::":
+		return address + " (all)"
+	}
+	return address
+}
+
+// formatAddresses renders every address a process listens on the port
+// on, e.g. "* (all, dual-stack)" for 0.0.0.0 and ::
+func formatAddresses(p *process.Process) string {
+	if len(p.Addresses) < 2 {
+		return formatAddress(p.Address)
+	}
+	wildcards := true
+	for _, address := range p.Addresses {
+		if address != "0.0.0.0" && address != "::" && address != "*" {
+			wildcards = false
+		}
+	}
+	switch {
+	case wildcards && p.DualStack():
+		return "* (all, dual-stack)"
+	case p.DualStack():
+		return strings.Join(p.Addresses, ", ") + " (dual-stack)"
+	}
+	return strings.Join(p.Addresses, ", ")
+}
+
+// formatAge renders how long a process has been running, or "-" when
+// its start time is unknown
+func formatAge(p *process.Process) string {
+	if p.StartTime.IsZero() {
+		return "-"
+	}
+	return formatDuration(time.Since(p.StartTime))
+}
+
+// formatPID renders the PID, or "-" for a listener without a known owner
+func formatPID(p *process.Process) string {
+	if p.SocketOnly {
+		return "-"
+	}
+	return fmt.Sprintf("%d", p.PID)
+}
+
+// formatUser renders the user owning the process, or "-" when unknown
+func formatUser(p *process.Process) string {
+	if p.User == "" {
+		return "-"
+	}
+	return p.User
+}
+
+// socketOnlyNotice explains why some listeners have no owner, if any,
+// offering an elevated rescan unless the processes come from one
+func socketOnlyNotice(processes []*process.Process, elevated bool) string {
+	for _, p := range processes {
+		if p.SocketOnly {
+			notice := "Some ports show no process: this environment hides their owners (restricted /proc, missing socket tools or other users' processes). "
+			if elevated {
+				return notice + "See 'portfinder doctor'."
+			}
+			if runtime.GOOS == "windows" {
+				return notice + "Run as administrator or see 'portfinder doctor'."
+			}
+			return notice + "Rescan with sudo (pf list --sudo, or " + keys.Elevate.Help().Key + " in the list) or see 'portfinder doctor'."
+		}
+	}
+	return ""
+}
+
+// formatPort renders a port, with a badge counting the restarts of a
+// crash looping listener
+func formatPort(port, restarts int) string {
+	if restarts > 0 {
+		return fmt.Sprintf("%d %s%d", port, Icon("↻", "R"), restarts)
+	}
+	return fmt.Sprintf("%d", port)
+}
+
+// joinPorts lists ports, e.g. "3001, 3002, 3003"
+func joinPorts(ports []int) string {
+	names := make([]string, len(ports))
+	for i, port := range ports {
+		names[i] = strconv.Itoa(port)
+	}
+	return strings.Join(names, ", ")
+}
+
 func truncateCommand(cmd string) string {
 	if len(cmd) > 60 {
 		return cmd[:57] + "..."
@@ -0,0 +1,109 @@
+package watch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// webhookTimeout bounds how long a webhook may take to answer
+const webhookTimeout = 10 * time.Second
+
+// Hook reacts to the events its rule selects by posting them to a webhook,
+// running a script, or both
+type Hook struct {
+	Rule
+	// Webhook receives the event as a JSON POST, e.g. a Slack workflow URL
+	Webhook string `json:"webhook,omitempty"`
+	// Script is run through the shell with the event in PORT, PID, EVENT
+	// and PROCESS_NAME
+	Script string `json:"script,omitempty"`
+}
+
+// Validate checks the rule and that the hook has something to run
+func (h Hook) Validate() error {
+	if err := h.Rule.Validate(); err != nil {
+		return err
+	}
+	if h.Webhook == "" && h.Script == "" {
+		return fmt.Errorf("webhook or script is required")
+	}
+	if h.Webhook != "" {
+		u, err := url.Parse(h.Webhook)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid webhook URL %q", h.Webhook)
+		}
+	}
+	return nil
+}
+
+// Run posts the event to the webhook and runs the script, reporting the
+// failures of both
+func (h Hook) Run(e Event) error {
+	var webhookErr, scriptErr error
+	if h.Webhook != "" {
+		webhookErr = post(h.Webhook, e)
+	}
+	if h.Script != "" {
+		scriptErr = runScript(h.Script, e)
+	}
+	return errors.Join(webhookErr, scriptErr)
+}
+
+// HookRules returns the rules of the hooks, to watch the ports they need
+func HookRules(hooks []Hook) []Rule {
+	rules := make([]Rule, len(hooks))
+	for i, h := range hooks {
+		rules[i] = h.Rule
+	}
+	return rules
+}
+
+func post(webhook string, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook failed: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+func runScript(script string, e Event) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", script)
+	} else {
+		cmd = exec.Command("sh", "-c", script)
+	}
+	cmd.Env = append(os.Environ(),
+		"PORT="+strconv.Itoa(e.Port),
+		"PID="+strconv.Itoa(e.PID),
+		"EVENT="+e.Event,
+		"PROCESS_NAME="+e.Name,
+	)
+	// Keep stdout to the events watch prints
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("script %q failed: %w", script, err)
+	}
+	return nil
+}
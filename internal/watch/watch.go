@@ -0,0 +1,183 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/doganarif/portfinder/internal/process"
+)
+
+// Event kinds
+const (
+	Grabbed = "grabbed"
+	Freed   = "freed"
+)
+
+// Event is a port changing state: a process started listening on it, or
+// the process listening on it went away
+type Event struct {
+	Time  time.Time `json:"time"`
+	Event string    `json:"event"`
+	Port  int       `json:"port"`
+	// Process grabbed the port, or held it until it was freed
+	*process.Process
+}
+
+func (e Event) String() string {
+	return fmt.Sprintf("Port %d %s by %s (PID %d)", e.Port, e.Event, e.Name, e.PID)
+}
+
+// Rule selects the events it applies to. Empty fields match everything.
+type Rule struct {
+	Ports  []int    `json:"ports,omitempty"`
+	Events []string `json:"events,omitempty"`
+}
+
+// Matches reports whether the rule applies to the event
+func (r Rule) Matches(e Event) bool {
+	if len(r.Ports) > 0 && !hasPort(r.Ports, e.Port) {
+		return false
+	}
+	if len(r.Events) == 0 {
+		return true
+	}
+	for _, event := range r.Events {
+		if event == e.Event {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the rule's ports and event kinds
+func (r Rule) Validate() error {
+	for _, port := range r.Ports {
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("invalid port %d", port)
+		}
+	}
+	for _, event := range r.Events {
+		if event != Grabbed && event != Freed {
+			return fmt.Errorf("unknown event %q, expected %s or %s", event, Grabbed, Freed)
+		}
+	}
+	return nil
+}
+
+// Ports returns the ports the rules need watched, or nil when some rule
+// applies to every port
+func Ports(rules []Rule) []int {
+	var ports []int
+	for _, r := range rules {
+		if len(r.Ports) == 0 {
+			return nil
+		}
+		for _, port := range r.Ports {
+			if !hasPort(ports, port) {
+				ports = append(ports, port)
+			}
+		}
+	}
+	return ports
+}
+
+// Watch polls the ports, or every port when none are given, each interval
+// until ctx is canceled, calling handle for every change. The first poll
+// only records the initial state.
+func Watch(ctx context.Context, finder process.Finder, ports []int, interval time.Duration, handle func(Event)) error {
+	previous, err := poll(ctx, finder, ports)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := poll(ctx, finder, ports)
+		if err != nil {
+			// A failed scan says nothing about the ports, so wait for the next
+			continue
+		}
+		for _, e := range diff(previous, current, time.Now()) {
+			handle(e)
+		}
+		previous = current
+	}
+}
+
+// poll maps the ports in use to their process, bypassing the finder's
+// cache
+func poll(ctx context.Context, finder process.Finder, ports []int) (map[int]*process.Process, error) {
+	process.Invalidate(finder)
+	if len(ports) > 0 {
+		return finder.FindByPorts(ctx, ports)
+	}
+
+	processes, err := finder.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[int]*process.Process, len(processes))
+	for _, p := range processes {
+		if _, seen := current[p.Port]; !seen {
+			current[p.Port] = p
+		}
+	}
+	return current, nil
+}
+
+// diff returns the events between two polls, in port order. A port that
+// changed hands is freed by the old process and then grabbed by the new.
+func diff(previous, current map[int]*process.Process, now time.Time) []Event {
+	var events []Event
+	for _, port := range sortedPorts(previous, current) {
+		before, after := previous[port], current[port]
+		if before != nil && after != nil && sameProcess(before, after) {
+			continue
+		}
+		if before != nil {
+			events = append(events, Event{Time: now, Event: Freed, Port: port, Process: before})
+		}
+		if after != nil {
+			events = append(events, Event{Time: now, Event: Grabbed, Port: port, Process: after})
+		}
+	}
+	return events
+}
+
+// sameProcess tells apart a process restarted with the same PID, or one
+// whose PID was reused, by its start time
+func sameProcess(a, b *process.Process) bool {
+	return a.PID == b.PID && a.Host == b.Host && a.StartTime.Equal(b.StartTime)
+}
+
+func sortedPorts(previous, current map[int]*process.Process) []int {
+	ports := make([]int, 0, len(current))
+	for port := range current {
+		ports = append(ports, port)
+	}
+	for port := range previous {
+		if _, ok := current[port]; !ok {
+			ports = append(ports, port)
+		}
+	}
+	sort.Ints(ports)
+	return ports
+}
+
+func hasPort(ports []int, port int) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}